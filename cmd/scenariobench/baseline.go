@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// benchBaseline is the file format used to save benchmark results and compare later runs against them.
+type benchBaseline struct {
+	Results []*benchResult `json:"results"`
+}
+
+func loadBaseline(path string) (*benchBaseline, error) {
+	jsonBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	baseline := &benchBaseline{}
+	err = json.Unmarshal(jsonBytes, baseline)
+	if err != nil {
+		return nil, fmt.Errorf("invalid baseline file %s: %w", path, err)
+	}
+	return baseline, nil
+}
+
+func saveBaseline(path string, results []*benchResult) error {
+	jsonBytes, err := json.MarshalIndent(&benchBaseline{Results: results}, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append(jsonBytes, '\n'), 0644)
+}
+
+func (baseline *benchBaseline) find(name string) *benchResult {
+	for _, result := range baseline.Results {
+		if result.Name == name {
+			return result
+		}
+	}
+	return nil
+}
+
+// compareWithBaseline prints the change of every result since the baseline and yields the number of regressions:
+// results where the time or allocations per op grew by more than the threshold percentage, or the gas per op grew at all.
+func compareWithBaseline(w io.Writer, baseline *benchBaseline, results []*benchResult, thresholdPercent float64) int {
+	nrRegressions := 0
+	_, _ = fmt.Fprintf(w, "Comparison with baseline (regression threshold %.2f%%):\n", thresholdPercent)
+	for _, result := range results {
+		previous := baseline.find(result.Name)
+		if previous == nil {
+			_, _ = fmt.Fprintf(w, "  %s: not in baseline\n", result.Name)
+			continue
+		}
+
+		nsChange := percentChange(previous.NsPerOp, result.NsPerOp)
+		allocsChange := percentChange(previous.AllocsPerOp, result.AllocsPerOp)
+		regressed := nsChange > thresholdPercent ||
+			allocsChange > thresholdPercent ||
+			result.GasPerOp > previous.GasPerOp
+
+		status := "ok"
+		if regressed {
+			status = "REGRESSION"
+			nrRegressions++
+		}
+		_, _ = fmt.Fprintf(w, "  %s: ns/op %+.2f%%, allocs/op %+.2f%%, gas/op %d -> %d  %s\n",
+			result.Name,
+			nsChange,
+			allocsChange,
+			previous.GasPerOp,
+			result.GasPerOp,
+			status)
+	}
+
+	return nrRegressions
+}
+
+func percentChange(previous float64, current float64) float64 {
+	if previous == 0 {
+		if current == 0 {
+			return 0
+		}
+		return 100
+	}
+	return (current - previous) / previous * 100
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseline_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	results := []*benchResult{
+		{Name: "a/benchmark/warm", Scenario: "a", Label: "benchmark", Cache: cacheWarm, Iterations: 10, NsPerOp: 1500.5, GasPerOp: 100},
+	}
+	require.Nil(t, saveBaseline(path, results))
+
+	baseline, err := loadBaseline(path)
+	require.Nil(t, err)
+	require.Equal(t, results, baseline.Results)
+	require.Equal(t, results[0], baseline.find("a/benchmark/warm"))
+	require.Nil(t, baseline.find("a/benchmark/cold"))
+
+	require.Nil(t, ioutil.WriteFile(path, []byte("{"), 0644))
+	_, err = loadBaseline(path)
+	require.NotNil(t, err)
+}
+
+func TestCompareWithBaseline(t *testing.T) {
+	baseline := &benchBaseline{Results: []*benchResult{
+		{Name: "same", NsPerOp: 1000, AllocsPerOp: 10, GasPerOp: 100},
+		{Name: "slower", NsPerOp: 1000, AllocsPerOp: 10, GasPerOp: 100},
+		{Name: "moreGas", NsPerOp: 1000, AllocsPerOp: 10, GasPerOp: 100},
+		{Name: "moreAllocs", NsPerOp: 1000, AllocsPerOp: 0, GasPerOp: 100},
+	}}
+	results := []*benchResult{
+		{Name: "same", NsPerOp: 1050, AllocsPerOp: 10, GasPerOp: 100},
+		{Name: "slower", NsPerOp: 1200, AllocsPerOp: 10, GasPerOp: 100},
+		{Name: "moreGas", NsPerOp: 900, AllocsPerOp: 10, GasPerOp: 101},
+		{Name: "moreAllocs", NsPerOp: 1000, AllocsPerOp: 1, GasPerOp: 100},
+		{Name: "new", NsPerOp: 1000, AllocsPerOp: 10, GasPerOp: 100},
+	}
+
+	output := &bytes.Buffer{}
+	require.Equal(t, 3, compareWithBaseline(output, baseline, results, 10))
+	require.Equal(t,
+		"Comparison with baseline (regression threshold 10.00%):\n"+
+			"  same: ns/op +5.00%, allocs/op +0.00%, gas/op 100 -> 100  ok\n"+
+			"  slower: ns/op +20.00%, allocs/op +0.00%, gas/op 100 -> 100  REGRESSION\n"+
+			"  moreGas: ns/op -10.00%, allocs/op +0.00%, gas/op 100 -> 101  REGRESSION\n"+
+			"  moreAllocs: ns/op +0.00%, allocs/op +100.00%, gas/op 100 -> 100  REGRESSION\n"+
+			"  new: not in baseline\n",
+		output.String())
+}
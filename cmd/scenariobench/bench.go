@@ -0,0 +1,455 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/multiversx/mx-chain-core-go/core"
+	"github.com/multiversx/mx-chain-core-go/data/vm"
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+	worldmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/world"
+	am "github.com/multiversx/mx-chain-vm-v1_4-go/scenarioexec"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	mge "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/scenario-exporter"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
+)
+
+// Instance cache modes of a benchmark run.
+const (
+	cacheWarm = "warm"
+	cacheCold = "cold"
+)
+
+var errNoBenchmarkTx = errors.New("scenario has no scCall step with id \"benchmark\" or starting with \"benchmark-\"")
+
+var benchTxHash = []byte("benchmark_______________________")
+
+// benchResult is the outcome of repeatedly executing the benchmark tx of a scenario.
+type benchResult struct {
+	Name        string  `json:"name"`
+	Scenario    string  `json:"scenario"`
+	Label       string  `json:"label"`
+	Cache       string  `json:"cache"`
+	Iterations  int     `json:"iterations"`
+	NsPerOp     float64 `json:"nsPerOp"`
+	GasPerOp    uint64  `json:"gasPerOp"`
+	AllocsPerOp float64 `json:"allocsPerOp"`
+	BytesPerOp  float64 `json:"bytesPerOp"`
+}
+
+// benchmark holds a world where the state of a scenario was set up once, ready to execute one of its benchmark txs.
+// The state right before the benchmark tx is restored after every execution.
+type benchmark struct {
+	scenarioPath string
+	label        string
+	executor     *am.VMTestExecutor
+	host         vmhost.VMHost
+	benchInput   *vmcommon.ContractCallInput
+	initialState *worldmock.WorldState
+}
+
+// loadBenchmarks loads the scenario via the exporter and sets up a separate benchmark for each of its benchmark txs.
+func loadBenchmarks(scenarioPath string, gasSchedule mj.GasSchedule) ([]*benchmark, error) {
+	scenarioInfo, err := mge.GetAccountsAndTransactionsFromScenarios(scenarioPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(scenarioInfo.BenchmarkTxs) == 0 {
+		return nil, errNoBenchmarkTx
+	}
+
+	benchmarks := make([]*benchmark, 0, len(scenarioInfo.BenchmarkTxs))
+	labelCounts := make(map[string]int)
+	for _, benchmarkTx := range scenarioInfo.BenchmarkTxs {
+		bench, err := newBenchmark(scenarioPath, &scenarioInfo, benchmarkTx, gasSchedule)
+		if err != nil {
+			return nil, fmt.Errorf("benchmark %s: %w", benchmarkTx.Label, err)
+		}
+
+		// txs with the same id are told apart by their order
+		labelCounts[benchmarkTx.Label]++
+		if labelCounts[benchmarkTx.Label] > 1 {
+			bench.label = fmt.Sprintf("%s#%d", benchmarkTx.Label, labelCounts[benchmarkTx.Label])
+		}
+		benchmarks = append(benchmarks, bench)
+	}
+	return benchmarks, nil
+}
+
+// newBenchmark creates the accounts of the scenario, then runs the deploy txs
+// and the txs preceding the benchmark tx, committing their results.
+func newBenchmark(
+	scenarioPath string,
+	scenarioInfo *mge.ScenarioWithBenchmark,
+	benchmarkTx *mge.BenchmarkTx,
+	gasSchedule mj.GasSchedule,
+) (*benchmark, error) {
+	executor, err := am.NewVMTestExecutor()
+	if err != nil {
+		return nil, err
+	}
+	err = executor.InitVM(gasSchedule)
+	if err != nil {
+		return nil, err
+	}
+
+	bench := &benchmark{
+		scenarioPath: scenarioPath,
+		label:        benchmarkTx.Label,
+		executor:     executor,
+		host:         executor.GetVMHost(),
+	}
+	bench.putAccounts(scenarioInfo.Accs)
+
+	for i, deployTx := range scenarioInfo.DeployTxs {
+		var newAddress []byte
+		if i < len(scenarioInfo.DeployedAccs) {
+			newAddress = scenarioInfo.DeployedAccs[i].GetAddress()
+		}
+		err = bench.runDeployTx(deployTx, newAddress)
+		if err != nil {
+			return nil, fmt.Errorf("deploy tx %d failed: %w", i, err)
+		}
+	}
+
+	for i, tx := range scenarioInfo.Txs[:benchmarkTx.TxPos] {
+		err = bench.runCallTx(tx)
+		if err != nil {
+			return nil, fmt.Errorf("tx %d failed: %w", i, err)
+		}
+	}
+
+	benchTx := scenarioInfo.Txs[benchmarkTx.TxPos]
+	bench.benchInput, err = bench.prepareCall(benchTx)
+	if err != nil {
+		return nil, fmt.Errorf("benchmark tx failed: %w", err)
+	}
+	bench.initialState = bench.world().CloneState()
+
+	return bench, nil
+}
+
+func (bench *benchmark) world() *worldmock.MockWorld {
+	return bench.executor.World
+}
+
+func (bench *benchmark) putAccounts(accounts []*mge.TestAccount) {
+	for _, testAccount := range accounts {
+		account := bench.world().AcctMap.CreateAccount(testAccount.GetAddress(), bench.world())
+		account.Nonce = testAccount.GetNonce()
+		account.Balance = big.NewInt(0).Set(testAccount.GetBalance())
+		for key, value := range testAccount.GetStorage() {
+			account.Storage[key] = value
+		}
+		if len(testAccount.GetCode()) > 0 {
+			codeMetadata := vmcommon.CodeMetadataFromBytes(testAccount.GetCodeMetadata())
+			account.SetCodeAndMetadata(testAccount.GetCode(), &codeMetadata)
+			account.OwnerAddress = testAccount.GetOwner()
+		}
+	}
+}
+
+func (bench *benchmark) runDeployTx(tx *mge.Transaction, newAddress []byte) error {
+	sender := bench.world().AcctMap.GetAccount(tx.GetSenderAddress())
+	if sender == nil {
+		return fmt.Errorf("sender %s does not exist", hex.EncodeToString(tx.GetSenderAddress()))
+	}
+	if len(newAddress) > 0 {
+		bench.world().NewAddressMocks = append(bench.world().NewAddressMocks, &worldmock.NewAddressMock{
+			CreatorAddress: tx.GetSenderAddress(),
+			CreatorNonce:   sender.Nonce,
+			NewAddress:     newAddress,
+		})
+	}
+
+	code, codeMetadata, arguments, err := parseExportedDeployData(tx.GetDeployData())
+	if err != nil {
+		return err
+	}
+
+	gasLimit, gasPrice := tx.GetGasLimitAndPrice()
+	err = bench.world().UpdateWorldStateBefore(tx.GetSenderAddress(), gasLimit, gasPrice)
+	if err != nil {
+		return err
+	}
+
+	output, err := bench.host.RunSmartContractCreate(&vmcommon.ContractCreateInput{
+		ContractCode:         code,
+		ContractCodeMetadata: codeMetadata,
+		VMInput: vmcommon.VMInput{
+			CallerAddr:     tx.GetSenderAddress(),
+			Arguments:      arguments,
+			CallValue:      tx.GetCallValue(),
+			GasPrice:       gasPrice,
+			GasProvided:    gasLimit,
+			OriginalTxHash: benchTxHash,
+			CurrentTxHash:  benchTxHash,
+			ESDTTransfers:  make([]*vmcommon.ESDTTransfer, 0),
+		},
+	})
+	return bench.commitOutput(tx, output, err)
+}
+
+func (bench *benchmark) runCallTx(tx *mge.Transaction) error {
+	gasLimit, gasPrice := tx.GetGasLimitAndPrice()
+	err := bench.world().UpdateWorldStateBefore(tx.GetSenderAddress(), gasLimit, gasPrice)
+	if err != nil {
+		return err
+	}
+
+	if len(tx.GetCallFunction()) == 0 {
+		return bench.runTransferTx(tx)
+	}
+
+	input, err := bench.prepareCall(tx)
+	if err != nil {
+		return err
+	}
+
+	output, err := bench.host.RunSmartContractCall(input)
+	return bench.commitOutput(tx, output, err)
+}
+
+// runTransferTx moves the EGLD and ESDT payments of a tx that calls no function.
+func (bench *benchmark) runTransferTx(tx *mge.Transaction) error {
+	if len(tx.GetESDTTransfers()) > 0 {
+		_, err := bench.performESDTTransfers(tx, tx.GetESDTTransfers())
+		if err != nil {
+			return err
+		}
+	}
+
+	err := bench.world().UpdateBalanceWithDelta(tx.GetSenderAddress(), big.NewInt(0).Neg(tx.GetCallValue()))
+	if err != nil {
+		return err
+	}
+	if bench.world().AcctMap.GetAccount(tx.GetReceiverAddress()) == nil {
+		bench.world().AcctMap.CreateAccount(tx.GetReceiverAddress(), bench.world())
+	}
+	return bench.world().UpdateBalanceWithDelta(tx.GetReceiverAddress(), tx.GetCallValue())
+}
+
+// prepareCall builds the VM input of a call, performing its ESDT transfers beforehand, as the protocol does.
+// For the benchmark tx, the transfers are only performed once, before all iterations.
+func (bench *benchmark) prepareCall(tx *mge.Transaction) (*vmcommon.ContractCallInput, error) {
+	gasLimit, gasPrice := tx.GetGasLimitAndPrice()
+	gasProvided := gasLimit
+
+	esdtTransfers := tx.GetESDTTransfers()
+	if len(esdtTransfers) > 0 {
+		var err error
+		gasProvided, err = bench.performESDTTransfers(tx, esdtTransfers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &vmcommon.ContractCallInput{
+		RecipientAddr: tx.GetReceiverAddress(),
+		Function:      tx.GetCallFunction(),
+		VMInput: vmcommon.VMInput{
+			CallerAddr:     tx.GetSenderAddress(),
+			Arguments:      tx.GetCallArguments(),
+			CallValue:      tx.GetCallValue(),
+			GasPrice:       gasPrice,
+			GasProvided:    gasProvided,
+			OriginalTxHash: benchTxHash,
+			CurrentTxHash:  benchTxHash,
+			ESDTTransfers:  convertESDTTransfers(esdtTransfers),
+		},
+	}, nil
+}
+
+func (bench *benchmark) performESDTTransfers(tx *mge.Transaction, esdtTransfers []*mj.ESDTTxData) (uint64, error) {
+	gasLimit, gasPrice := tx.GetGasLimitAndPrice()
+	if len(esdtTransfers) == 1 {
+		return bench.world().BuiltinFuncs.PerformDirectESDTTransfer(
+			tx.GetSenderAddress(),
+			tx.GetReceiverAddress(),
+			esdtTransfers[0].TokenIdentifier.Value,
+			esdtTransfers[0].Nonce.Value,
+			esdtTransfers[0].Value.Value,
+			vm.DirectCall,
+			gasLimit,
+			gasPrice)
+	}
+
+	return bench.world().BuiltinFuncs.PerformDirectMultiESDTTransfer(
+		tx.GetSenderAddress(),
+		tx.GetReceiverAddress(),
+		esdtTransfers,
+		vm.DirectCall,
+		gasLimit,
+		gasPrice)
+}
+
+func convertESDTTransfers(esdtTransfers []*mj.ESDTTxData) []*vmcommon.ESDTTransfer {
+	transfers := make([]*vmcommon.ESDTTransfer, 0, len(esdtTransfers))
+	for _, esdtTransfer := range esdtTransfers {
+		tokenType := core.Fungible
+		if esdtTransfer.Nonce.Value != 0 {
+			tokenType = core.NonFungible
+		}
+		transfers = append(transfers, &vmcommon.ESDTTransfer{
+			ESDTTokenName:  esdtTransfer.TokenIdentifier.Value,
+			ESDTValue:      esdtTransfer.Value.Value,
+			ESDTTokenNonce: esdtTransfer.Nonce.Value,
+			ESDTTokenType:  uint32(tokenType),
+		})
+	}
+	return transfers
+}
+
+func (bench *benchmark) commitOutput(tx *mge.Transaction, output *vmcommon.VMOutput, err error) error {
+	if err != nil {
+		return err
+	}
+	if output.ReturnCode != vmcommon.Ok {
+		return fmt.Errorf("retcode=%d, msg=%s", output.ReturnCode, output.ReturnMessage)
+	}
+
+	_ = bench.world().UpdateBalanceWithDelta(tx.GetSenderAddress(), big.NewInt(0).Neg(tx.GetCallValue()))
+	return bench.world().UpdateAccounts(output.OutputAccounts, output.DeletedAccounts)
+}
+
+// parseExportedDeployData splits the deploy data produced by the exporter,
+// "@<code>@<VM type>@<code metadata>@<arg1>@<arg2>...", all hex encoded, into the code, the code metadata and the arguments.
+// The exporter writes the VM type and the code metadata as hex strings, which get hex encoded in turn.
+func parseExportedDeployData(deployData []byte) ([]byte, []byte, [][]byte, error) {
+	tokens := strings.Split(string(deployData), "@")
+	if len(tokens) < 4 {
+		return nil, nil, nil, errors.New("invalid deploy data")
+	}
+
+	code, err := hex.DecodeString(tokens[1])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid deploy code: %w", err)
+	}
+
+	codeMetadataHex, err := hex.DecodeString(tokens[3])
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid deploy code metadata: %w", err)
+	}
+	codeMetadata, err := hex.DecodeString(string(codeMetadataHex))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid deploy code metadata: %w", err)
+	}
+
+	arguments := make([][]byte, 0, len(tokens)-4)
+	for _, token := range tokens[4:] {
+		argument, err := hex.DecodeString(token)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("invalid deploy argument: %w", err)
+		}
+		arguments = append(arguments, argument)
+	}
+
+	return code, codeMetadata, arguments, nil
+}
+
+// run executes the benchmark tx repeatedly, restoring the world state after each iteration, so every iteration starts
+// from the same state. Since they do the same work, all iterations must use the same gas.
+// With a cold cache, the warm instances and the compiled code are cleared before every iteration,
+// so that each iteration compiles the contract again. Clearing the caches and restoring the state are not timed,
+// but their allocations are counted.
+func (bench *benchmark) run(cache string, warmup int, iterations int) (*benchResult, error) {
+	expectedGasUsed := uint64(0)
+	checkGasUsed := func(iteration *iterationResult, index int) error {
+		if index == 0 {
+			expectedGasUsed = iteration.gasUsed
+			return nil
+		}
+		if iteration.gasUsed != expectedGasUsed {
+			return fmt.Errorf("benchmark tx used %d gas in iteration %d, but %d in the first one",
+				iteration.gasUsed, index, expectedGasUsed)
+		}
+		return nil
+	}
+
+	for i := 0; i < warmup; i++ {
+		iteration, err := bench.runIteration(cache)
+		if err != nil {
+			return nil, err
+		}
+		err = checkGasUsed(iteration, i)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var memStatsBefore, memStatsAfter runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&memStatsBefore)
+
+	var elapsed time.Duration
+	for i := 0; i < iterations; i++ {
+		iteration, err := bench.runIteration(cache)
+		if err != nil {
+			return nil, err
+		}
+		err = checkGasUsed(iteration, warmup+i)
+		if err != nil {
+			return nil, err
+		}
+		elapsed += iteration.duration
+	}
+
+	runtime.ReadMemStats(&memStatsAfter)
+
+	return &benchResult{
+		Name:        bench.name(cache),
+		Scenario:    bench.scenarioPath,
+		Label:       bench.label,
+		Cache:       cache,
+		Iterations:  iterations,
+		NsPerOp:     float64(elapsed.Nanoseconds()) / float64(iterations),
+		GasPerOp:    expectedGasUsed,
+		AllocsPerOp: float64(memStatsAfter.Mallocs-memStatsBefore.Mallocs) / float64(iterations),
+		BytesPerOp:  float64(memStatsAfter.TotalAlloc-memStatsBefore.TotalAlloc) / float64(iterations),
+	}, nil
+}
+
+type iterationResult struct {
+	duration time.Duration
+	gasUsed  uint64
+}
+
+func (bench *benchmark) runIteration(cache string) (*iterationResult, error) {
+	if cache == cacheCold {
+		bench.host.Runtime().ClearWarmInstanceCache()
+		bench.world().ClearCompiledCodes()
+	}
+
+	start := time.Now()
+	output, err := bench.host.RunSmartContractCall(bench.benchInput)
+	duration := time.Since(start)
+	bench.restoreInitialState()
+	if err != nil {
+		return nil, err
+	}
+	if output.ReturnCode != vmcommon.Ok {
+		return nil, fmt.Errorf("benchmark tx failed: retcode=%d, msg=%s", output.ReturnCode, output.ReturnMessage)
+	}
+
+	return &iterationResult{
+		duration: duration,
+		gasUsed:  bench.benchInput.GasProvided - output.GasRemaining,
+	}, nil
+}
+
+// restoreInitialState undoes what the benchmark tx changed directly in the world, e.g. via builtin functions.
+// The code compiled in the meantime is kept, since it is a cache, controlled separately.
+func (bench *benchmark) restoreInitialState() {
+	compiledCode := bench.world().CompiledCode
+	bench.world().RestoreState(bench.initialState)
+	bench.world().CompiledCode = compiledCode
+}
+
+func (bench *benchmark) name(cache string) string {
+	return fmt.Sprintf("%s/%s/%s", bench.scenarioPath, bench.label, cache)
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+	contextmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/context"
+	worldmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/world"
+	am "github.com/multiversx/mx-chain-vm-v1_4-go/scenarioexec"
+	mge "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/scenario-exporter"
+	"github.com/stretchr/testify/require"
+)
+
+var benchTestOwner = []byte("owner___________________________")
+var benchTestContract = append(append([]byte{}, mge.ScAddressPrefix...), []byte("contract______________")...)
+
+// newTestBenchmark sets up a contract and its owner, with the VM replaced by the given stub
+func newTestBenchmark(t *testing.T, host *contextmock.VMHostStub) *benchmark {
+	executor, err := am.NewVMTestExecutor()
+	require.Nil(t, err)
+
+	bench := &benchmark{
+		scenarioPath: "test.scen.json",
+		label:        "benchmark",
+		executor:     executor,
+		host:         host,
+		benchInput: &vmcommon.ContractCallInput{
+			RecipientAddr: benchTestContract,
+			Function:      "count",
+			VMInput: vmcommon.VMInput{
+				CallerAddr:  benchTestOwner,
+				CallValue:   big.NewInt(0),
+				GasProvided: 1000,
+			},
+		},
+	}
+	upgradeable := (&vmcommon.CodeMetadata{Upgradeable: true}).ToBytes()
+	bench.putAccounts([]*mge.TestAccount{
+		mge.SetNewAccount(1, benchTestOwner, big.NewInt(100), make(map[string][]byte), nil, nil),
+		mge.SetNewAccount(0, benchTestContract, big.NewInt(0), map[string][]byte{"counter": {1}}, []byte("code"), benchTestOwner).
+			WithCodeMetadata(upgradeable),
+	})
+	bench.initialState = bench.world().CloneState()
+	return bench
+}
+
+// counterCall stands for a contract whose cost depends on its state,
+// which it changes directly in the world, as builtin functions do
+func counterCall(bench **benchmark) func(input *vmcommon.ContractCallInput) (*vmcommon.VMOutput, error) {
+	return func(input *vmcommon.ContractCallInput) (*vmcommon.VMOutput, error) {
+		account := (*bench).world().AcctMap.GetAccount(benchTestContract)
+		counter := account.Storage["counter"][0]
+		account.Storage["counter"] = []byte{counter + 1}
+		return &vmcommon.VMOutput{
+			ReturnCode:   vmcommon.Ok,
+			GasRemaining: input.GasProvided - 100 - uint64(counter),
+		}, nil
+	}
+}
+
+func TestBenchmark_PutAccounts(t *testing.T) {
+	bench := newTestBenchmark(t, &contextmock.VMHostStub{})
+
+	owner := bench.world().AcctMap.GetAccount(benchTestOwner)
+	require.Equal(t, uint64(1), owner.Nonce)
+	require.Equal(t, big.NewInt(100), owner.Balance)
+	require.False(t, owner.IsSmartContract)
+
+	contract := bench.world().AcctMap.GetAccount(benchTestContract)
+	require.True(t, contract.IsSmartContract)
+	require.Equal(t, []byte("code"), contract.Code)
+	require.Equal(t, benchTestOwner, contract.OwnerAddress)
+	require.Equal(t, []byte{1}, contract.Storage["counter"])
+	// the code metadata comes from the account
+	require.Equal(t, (&vmcommon.CodeMetadata{Upgradeable: true}).ToBytes(), contract.CodeMetadata)
+}
+
+func TestBenchmark_RunRestoresTheState(t *testing.T) {
+	var bench *benchmark
+	host := &contextmock.VMHostStub{RunSmartContractCallCalled: counterCall(&bench)}
+	bench = newTestBenchmark(t, host)
+
+	result, err := bench.run(cacheWarm, 2, 5)
+	require.Nil(t, err)
+	require.Equal(t, "test.scen.json/benchmark/warm", result.Name)
+	require.Equal(t, 5, result.Iterations)
+	require.Equal(t, uint64(101), result.GasPerOp)
+	require.Equal(t, []byte{1}, bench.world().AcctMap.GetAccount(benchTestContract).Storage["counter"])
+}
+
+func TestBenchmark_GasMustNotChange(t *testing.T) {
+	nrCalls := uint64(0)
+	host := &contextmock.VMHostStub{
+		RunSmartContractCallCalled: func(input *vmcommon.ContractCallInput) (*vmcommon.VMOutput, error) {
+			nrCalls++
+			gasUsed := uint64(100)
+			if nrCalls == 4 {
+				gasUsed = 120
+			}
+			return &vmcommon.VMOutput{ReturnCode: vmcommon.Ok, GasRemaining: input.GasProvided - gasUsed}, nil
+		},
+	}
+	bench := newTestBenchmark(t, host)
+
+	_, err := bench.run(cacheWarm, 2, 3)
+	require.NotNil(t, err)
+	require.Equal(t, "benchmark tx used 120 gas in iteration 3, but 100 in the first one", err.Error())
+
+	nrCalls = 0
+	_, err = bench.run(cacheWarm, 1, 2)
+	require.Nil(t, err)
+}
+
+func TestBenchmark_FailedIteration(t *testing.T) {
+	host := &contextmock.VMHostStub{
+		RunSmartContractCallCalled: func(input *vmcommon.ContractCallInput) (*vmcommon.VMOutput, error) {
+			return &vmcommon.VMOutput{ReturnCode: vmcommon.UserError, ReturnMessage: "fail"}, nil
+		},
+	}
+	bench := newTestBenchmark(t, host)
+
+	_, err := bench.run(cacheWarm, 0, 1)
+	require.NotNil(t, err)
+	require.Equal(t, "benchmark tx failed: retcode=4, msg=fail", err.Error())
+}
+
+func TestBenchmark_RunDeployTx(t *testing.T) {
+	var deployInput *vmcommon.ContractCreateInput
+	host := &contextmock.VMHostStub{
+		RunSmartContractCreateCalled: func(input *vmcommon.ContractCreateInput) (*vmcommon.VMOutput, error) {
+			deployInput = input
+			return &vmcommon.VMOutput{ReturnCode: vmcommon.Ok}, nil
+		},
+	}
+	bench := newTestBenchmark(t, host)
+
+	codePath := filepath.Join(t.TempDir(), "contract.wasm")
+	require.Nil(t, ioutil.WriteFile(codePath, []byte("code"), 0644))
+	deployTx := mge.CreateDeployTransaction([][]byte{{42}}, "file:"+codePath, benchTestOwner, 5000, 0).
+		WithCallValue(big.NewInt(0))
+	newAddress := append(append([]byte{}, mge.ScAddressPrefix...), []byte("deployed______________")...)
+
+	err := bench.runDeployTx(deployTx, newAddress)
+	require.Nil(t, err)
+	require.Equal(t, []byte("code"), deployInput.ContractCode)
+	require.Equal(t, []byte{1, 2}, deployInput.ContractCodeMetadata)
+	require.Equal(t, [][]byte{{42}}, deployInput.Arguments)
+	require.Equal(t, []*worldmock.NewAddressMock{{
+		CreatorAddress: benchTestOwner,
+		CreatorNonce:   1,
+		NewAddress:     newAddress,
+	}}, bench.world().NewAddressMocks)
+}
+
+func TestParseExportedDeployData(t *testing.T) {
+	deployData := "@" + hex.EncodeToString([]byte("code")) +
+		"@" + hex.EncodeToString([]byte("0500")) +
+		"@" + hex.EncodeToString([]byte("0102")) +
+		"@2a@"
+	code, codeMetadata, arguments, err := parseExportedDeployData([]byte(deployData))
+	require.Nil(t, err)
+	require.Equal(t, []byte("code"), code)
+	require.Equal(t, []byte{1, 2}, codeMetadata)
+	require.Equal(t, [][]byte{{42}, {}}, arguments)
+
+	_, _, _, err = parseExportedDeployData([]byte("@00@00"))
+	require.NotNil(t, err)
+	_, _, _, err = parseExportedDeployData([]byte("@xx@00@00"))
+	require.NotNil(t, err)
+	_, _, _, err = parseExportedDeployData([]byte("@00@00@" + hex.EncodeToString([]byte("xx"))))
+	require.NotNil(t, err)
+	_, _, _, err = parseExportedDeployData([]byte("@00@00@00@xx"))
+	require.NotNil(t, err)
+}
+
+func TestParseCacheModes(t *testing.T) {
+	caches, err := parseCacheModes("warm, cold")
+	require.Nil(t, err)
+	require.Equal(t, []string{cacheWarm, cacheCold}, caches)
+
+	_, err = parseCacheModes("warm,hot")
+	require.NotNil(t, err)
+}
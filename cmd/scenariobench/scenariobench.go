@@ -0,0 +1,100 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+)
+
+func main() {
+	iterations := flag.Int("iterations", 1000, "number of timed executions of the benchmark tx")
+	warmup := flag.Int("warmup", 10, "number of untimed executions of the benchmark tx, before the timed ones")
+	cacheModes := flag.String("cache", "warm,cold", "comma-separated instance cache modes: warm reuses instances, cold compiles the contract every time")
+	gasScheduleName := flag.String("gas-schedule", "v4", "gas schedule to run with (default, dummy, v3, v4)")
+	baselinePath := flag.String("baseline", "", "baseline file to compare the results with")
+	saveBaselinePath := flag.String("save-baseline", "", "saves the results as a baseline file at the given path")
+	regressionThreshold := flag.Float64("regression-threshold", 10, "percentage of ns/op or allocs/op increase reported as regression when comparing with the baseline")
+	flag.Parse()
+
+	if len(flag.Args()) == 0 {
+		panic("At least one argument expected - the paths to the scenario files with \"benchmark\" txs.")
+	}
+
+	gasSchedule, err := mj.GasScheduleFromString(*gasScheduleName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	caches, err := parseCacheModes(*cacheModes)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	var results []*benchResult
+	for _, scenarioPath := range flag.Args() {
+		benchmarks, err := loadBenchmarks(scenarioPath, gasSchedule)
+		if err != nil {
+			fmt.Printf("Cannot set up benchmarks of %s: %s\n", scenarioPath, err.Error())
+			os.Exit(1)
+		}
+
+		for _, bench := range benchmarks {
+			for _, cache := range caches {
+				result, err := bench.run(cache, *warmup, *iterations)
+				if err != nil {
+					fmt.Printf("Benchmark %s failed: %s\n", bench.name(cache), err.Error())
+					os.Exit(1)
+				}
+				printResult(result)
+				results = append(results, result)
+			}
+		}
+	}
+
+	if len(*saveBaselinePath) > 0 {
+		err = saveBaseline(*saveBaselinePath, results)
+		if err != nil {
+			fmt.Printf("Cannot save baseline: %s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	if len(*baselinePath) > 0 {
+		baseline, err := loadBaseline(*baselinePath)
+		if err != nil {
+			fmt.Printf("Cannot load baseline: %s\n", err.Error())
+			os.Exit(1)
+		}
+		nrRegressions := compareWithBaseline(os.Stdout, baseline, results, *regressionThreshold)
+		if nrRegressions > 0 {
+			fmt.Printf("%d regression(s) found.\n", nrRegressions)
+			os.Exit(1)
+		}
+	}
+}
+
+func parseCacheModes(arg string) ([]string, error) {
+	var caches []string
+	for _, cache := range strings.Split(arg, ",") {
+		cache = strings.TrimSpace(cache)
+		if cache != cacheWarm && cache != cacheCold {
+			return nil, fmt.Errorf("unknown cache mode: %s", cache)
+		}
+		caches = append(caches, cache)
+	}
+	return caches, nil
+}
+
+func printResult(result *benchResult) {
+	fmt.Printf("%-60s %8d iterations %14.0f ns/op %12d gas/op %10.1f allocs/op %12.0f B/op\n",
+		result.Name,
+		result.Iterations,
+		result.NsPerOp,
+		result.GasPerOp,
+		result.AllocsPerOp,
+		result.BytesPerOp)
+}
@@ -1,24 +1,42 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	mc "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/controller"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	oj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/orderedjson"
 )
 
 func main() {
-	if len(os.Args) != 2 {
+	toFormatName := flag.String("to", "", "converts the scenario files to the given format (json, jsonc, yaml), replacing the originals, instead of formatting the JSON files in place")
+	flag.Parse()
+
+	if len(flag.Args()) != 1 {
 		panic("One argument expected - the root path where to search.")
 	}
 
-	_ = convertAllInFolder(os.Args[1])
+	if len(*toFormatName) == 0 {
+		_ = convertAllInFolder(flag.Args()[0])
+		return
+	}
+
+	toFormat, err := oj.FormatFromName(*toFormatName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	_ = convertAllInFolderToFormat(flag.Args()[0], toFormat)
 }
 
 var suffixes = []string{".scen.json", ".step.json", ".steps.json"}
 
+// shouldFormatFile only selects plain JSON files, since formatting files
+// in the other formats in place would lose their comments.
 func shouldFormatFile(path string) bool {
 	for _, suffix := range suffixes {
 		if strings.HasSuffix(path, suffix) {
@@ -28,6 +46,18 @@ func shouldFormatFile(path string) bool {
 	return false
 }
 
+func shouldConvertFile(path string, toFormat oj.Format) bool {
+	if oj.FormatFromPath(path) == toFormat {
+		return false
+	}
+	for _, suffix := range suffixes {
+		if mc.HasSuffixInAnyFormat(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
 func convertAllInFolder(path string) error {
 	err := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
 		if shouldFormatFile(filePath) {
@@ -47,3 +77,65 @@ func upgradeScenariosFile(filePath string) {
 		fmt.Printf("Error upgrading: %s\n", err.Error())
 	}
 }
+
+// convertAllInFolderToFormat replaces every scenario file with one in the given format,
+// so that the runner does not find the same scenario twice.
+// The paths of the external steps converted along are updated.
+func convertAllInFolderToFormat(path string, toFormat oj.Format) error {
+	var filePaths []string
+	err := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+		if shouldConvertFile(filePath, toFormat) {
+			filePaths = append(filePaths, filePath)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	converted := make(map[string]bool, len(filePaths))
+	for _, filePath := range filePaths {
+		converted[filepath.Clean(filePath)] = true
+	}
+	for _, filePath := range filePaths {
+		toPath := convertedPath(filePath, toFormat)
+		fmt.Printf("Convert: %s -> %s\n", filePath, toPath)
+		err = convertScenariosFile(filePath, toPath, toFormat, converted)
+		if err != nil {
+			fmt.Printf("Error converting: %s\n", err.Error())
+		}
+	}
+	return nil
+}
+
+func convertedPath(filePath string, toFormat oj.Format) string {
+	return strings.TrimSuffix(filePath, filepath.Ext(filePath)) + toFormat.Extension()
+}
+
+func convertScenariosFile(filePath string, toPath string, toFormat oj.Format, converted map[string]bool) error {
+	if _, err := os.Stat(toPath); err == nil {
+		return fmt.Errorf("%s already exists", toPath)
+	}
+
+	scenario, err := mc.ParseScenariosScenarioDefaultParser(filePath)
+	if err != nil {
+		return err
+	}
+
+	for _, step := range scenario.Steps {
+		externalStepsStep, isExternal := step.(*mj.ExternalStepsStep)
+		if !isExternal {
+			continue
+		}
+		stepsPath := filepath.Clean(filepath.Join(filepath.Dir(filePath), externalStepsStep.Path))
+		if converted[stepsPath] {
+			externalStepsStep.Path = convertedPath(externalStepsStep.Path, toFormat)
+		}
+	}
+
+	err = mc.WriteScenariosScenario(scenario, toPath)
+	if err != nil {
+		return err
+	}
+	return os.Remove(filePath)
+}
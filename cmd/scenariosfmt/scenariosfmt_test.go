@@ -0,0 +1,112 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mc "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/controller"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	oj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/orderedjson"
+	"github.com/stretchr/testify/require"
+)
+
+const fmtTestScenario = `{
+    "steps": [
+        {
+            "step": "externalSteps",
+            "path": "sub/init.steps.json"
+        },
+        {
+            "step": "externalSteps",
+            "path": "../outside.steps.json"
+        },
+        {
+            "step": "checkState",
+            "accounts": {
+                "address:owner": {
+                    "nonce": "0",
+                    "balance": "1"
+                }
+            }
+        }
+    ]
+}`
+
+const fmtTestSteps = `{
+    "steps": [
+        {
+            "step": "setState",
+            "accounts": {
+                "address:owner": {
+                    "nonce": "0",
+                    "balance": "1"
+                }
+            }
+        }
+    ]
+}`
+
+func writeFmtTestFile(t *testing.T, path string, contents string) {
+	require.Nil(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+	require.Nil(t, ioutil.WriteFile(path, []byte(contents), 0644))
+}
+
+func listFmtTestFiles(t *testing.T, root string) []string {
+	var files []string
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if !info.IsDir() {
+			relativePath, _ := filepath.Rel(root, path)
+			files = append(files, relativePath)
+		}
+		return nil
+	})
+	require.Nil(t, err)
+	return files
+}
+
+func TestConvertAllInFolderToFormat(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "scenarios")
+	writeFmtTestFile(t, filepath.Join(root, "test.scen.json"), fmtTestScenario)
+	writeFmtTestFile(t, filepath.Join(root, "sub", "init.steps.json"), fmtTestSteps)
+	writeFmtTestFile(t, filepath.Join(root, "readme.json"), "{}")
+	writeFmtTestFile(t, filepath.Join(parent, "outside.steps.json"), fmtTestSteps)
+
+	require.Nil(t, convertAllInFolderToFormat(root, oj.FormatYAML))
+
+	// the originals are replaced, so the runner finds every scenario once
+	require.Equal(t, []string{"readme.json", "sub/init.steps.yaml", "test.scen.yaml"}, listFmtTestFiles(t, root))
+
+	scenario, err := mc.ParseScenariosScenarioDefaultParser(filepath.Join(root, "test.scen.yaml"))
+	require.Nil(t, err)
+	require.Len(t, scenario.Steps, 3)
+	require.Equal(t, "sub/init.steps.yaml", scenario.Steps[0].(*mj.ExternalStepsStep).Path)
+	// steps outside the converted folder stay as they are
+	require.Equal(t, "../outside.steps.json", scenario.Steps[1].(*mj.ExternalStepsStep).Path)
+
+	steps, err := mc.ParseScenariosScenarioDefaultParser(filepath.Join(root, "sub", "init.steps.yaml"))
+	require.Nil(t, err)
+	require.Len(t, steps.Steps, 1)
+
+	// converting back yields the same scenario
+	require.Nil(t, convertAllInFolderToFormat(root, oj.FormatJSON))
+	require.Equal(t, []string{"readme.json", "sub/init.steps.json", "test.scen.json"}, listFmtTestFiles(t, root))
+	contents, err := ioutil.ReadFile(filepath.Join(root, "test.scen.json"))
+	require.Nil(t, err)
+	require.Equal(t, fmtTestScenario+"\n", string(contents))
+}
+
+func TestConvertScenariosFile_ExistingTarget(t *testing.T) {
+	root := t.TempDir()
+	writeFmtTestFile(t, filepath.Join(root, "test.scen.json"), fmtTestSteps)
+	writeFmtTestFile(t, filepath.Join(root, "test.scen.yaml"), "steps: []\n")
+
+	require.Nil(t, convertAllInFolderToFormat(root, oj.FormatYAML))
+	require.Equal(t, []string{"test.scen.json", "test.scen.yaml"}, listFmtTestFiles(t, root))
+
+	contents, err := ioutil.ReadFile(filepath.Join(root, "test.scen.yaml"))
+	require.Nil(t, err)
+	require.Equal(t, "steps: []\n", string(contents))
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+	worldmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/world"
+	am "github.com/multiversx/mx-chain-vm-v1_4-go/scenarioexec"
+)
+
+func main() {
+	outputPath := flag.String("out", "", "writes the imported accounts as a setState step in the given steps file")
+	checkState := flag.Bool("check-state", false, "also adds a checkState step that expects exactly the imported accounts")
+	accountsStorePath := flag.String("accounts-store", "", "saves the imported accounts to the given key-value store file, to be used with the -accounts-store option of the scenario runner")
+	flag.Parse()
+
+	if len(flag.Args()) != 1 {
+		panic("One argument expected - the path to the file with the accounts exported from a node or a proxy.")
+	}
+	if len(*outputPath) == 0 && len(*accountsStorePath) == 0 {
+		panic("Nothing to do - at least one of -out and -accounts-store expected.")
+	}
+
+	err := importAccounts(flag.Args()[0], *outputPath, *checkState, *accountsStorePath)
+	if err != nil {
+		fmt.Printf("ERROR: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func importAccounts(exportPath string, outputPath string, checkState bool, accountsStorePath string) error {
+	executor, err := am.NewVMTestExecutor()
+	if err != nil {
+		return err
+	}
+
+	if len(accountsStorePath) > 0 {
+		accountsStore, err := worldmock.OpenAccountsStore(accountsStorePath)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = accountsStore.Close()
+		}()
+		executor.World.UseAccountsStore(accountsStore)
+	}
+
+	err = executor.World.ImportAccounts(exportPath)
+	if err != nil {
+		return err
+	}
+	nrAccounts, nrSystemAccounts := countAccounts(executor.World)
+	fmt.Printf("Imported %d accounts from %s, besides %d system accounts\n", nrAccounts, exportPath, nrSystemAccounts)
+
+	err = executor.World.CommitChanges()
+	if err != nil {
+		return err
+	}
+
+	if len(outputPath) == 0 {
+		return nil
+	}
+	outputPath, err = filepath.Abs(outputPath)
+	if err != nil {
+		return err
+	}
+	err = executor.WriteWorldStateScenario(outputPath, checkState)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Saved as setState step to %s\n", outputPath)
+	return nil
+}
+
+// countAccounts counts the accounts in the world, separating the system account and the system SC mocks,
+// which the world always contains, from the others.
+func countAccounts(world *worldmock.MockWorld) (int, int) {
+	nrAccounts, nrSystemAccounts := 0, 0
+	for address := range world.AcctMap {
+		if bytes.Equal([]byte(address), vmcommon.SystemAccountAddress) ||
+			world.IsSystemSCMockAddress([]byte(address)) {
+			nrSystemAccounts++
+		} else {
+			nrAccounts++
+		}
+	}
+	return nrAccounts, nrSystemAccounts
+}
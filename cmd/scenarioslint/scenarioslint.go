@@ -0,0 +1,44 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	scenlint "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/lint"
+)
+
+func main() {
+	gasLimitFactor := flag.Uint64("gas-limit-factor", scenlint.DefaultGasLimitFactor, "reports gas limits this many times larger than the gas used")
+	gasProfileDir := flag.String("gas-profiles", "", "directory with gas profiles, written by the scenario test CLI, to know the gas used by each tx")
+	flag.Parse()
+
+	if len(flag.Args()) != 1 {
+		panic("One argument expected - the path to the scenario file or directory.")
+	}
+
+	linter := scenlint.NewLinter()
+	linter.GasLimitFactor = *gasLimitFactor
+	if len(*gasProfileDir) > 0 {
+		err := linter.LoadGasProfiles(*gasProfileDir)
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err.Error())
+			os.Exit(1)
+		}
+	}
+
+	findings, err := linter.LintPath(flag.Args()[0])
+	if err != nil {
+		fmt.Printf("ERROR: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	for _, finding := range findings {
+		fmt.Println(finding.String())
+	}
+	if len(findings) > 0 {
+		fmt.Printf("Found %d problems.\n", len(findings))
+		os.Exit(1)
+	}
+	fmt.Println("No problems found.")
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mc "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/controller"
+	mjparse "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/parse"
+	scentestconverter "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/testconverter"
+)
+
+const legacyTestSuffix = ".test.json"
+
+type migrationOptions struct {
+	validate  bool
+	overwrite bool
+}
+
+type migrationSummary struct {
+	nrConverted        int
+	nrLossy            int
+	nrFailedValidation int
+	nrErrors           int
+}
+
+func main() {
+	validate := flag.Bool("validate", true, "runs both the legacy test and the converted scenario, and checks that they produce the same results")
+	overwrite := flag.Bool("overwrite", false, "overwrites scenario files that already exist")
+	flag.Parse()
+
+	if len(flag.Args()) != 1 {
+		panic("One argument expected - the path to the legacy test file or to a directory with " + legacyTestSuffix + " files.")
+	}
+
+	options := &migrationOptions{
+		validate:  *validate,
+		overwrite: *overwrite,
+	}
+	summary := &migrationSummary{}
+
+	rootPath := flag.Args()[0]
+	fileInfo, err := os.Stat(rootPath)
+	if err != nil {
+		fmt.Printf("ERROR: %s\n", err.Error())
+		os.Exit(1)
+	}
+	if fileInfo.IsDir() {
+		err = filepath.Walk(rootPath, func(filePath string, info os.FileInfo, err error) error {
+			if strings.HasSuffix(filePath, legacyTestSuffix) {
+				migrateTestFile(filePath, options, summary)
+			}
+			return nil
+		})
+		if err != nil {
+			fmt.Printf("ERROR: %s\n", err.Error())
+			os.Exit(1)
+		}
+	} else {
+		migrateTestFile(rootPath, options, summary)
+	}
+
+	fmt.Printf("Done. Converted: %d. Lossy: %d. Failed validation: %d. Errors: %d.\n",
+		summary.nrConverted, summary.nrLossy, summary.nrFailedValidation, summary.nrErrors)
+	if summary.nrFailedValidation > 0 || summary.nrErrors > 0 {
+		os.Exit(1)
+	}
+}
+
+func migrateTestFile(testPath string, options *migrationOptions, summary *migrationSummary) {
+	fmt.Printf("Legacy test file: %s\n", testPath)
+	err := migrateTests(testPath, options, summary)
+	if err != nil {
+		summary.nrErrors++
+		fmt.Printf("  ERROR: %s\n", err.Error())
+	}
+}
+
+func migrateTests(testPath string, options *migrationOptions, summary *migrationSummary) error {
+	testPath, err := filepath.Abs(testPath)
+	if err != nil {
+		return err
+	}
+	contents, err := ioutil.ReadFile(testPath)
+	if err != nil {
+		return err
+	}
+
+	parser := mjparse.NewParser(mc.NewDefaultFileResolver())
+	parser.ExprInterpreter.FileResolver.SetContext(testPath)
+	tests, err := parser.ParseTestFile(contents)
+	if err != nil {
+		return err
+	}
+
+	for _, test := range tests {
+		conversion := scentestconverter.ConvertTest(test)
+		scenarioPath := scenarioPathForTest(testPath, test.TestName, len(tests))
+		fmt.Printf("  %s -> %s ... ", test.TestName, scenarioPath)
+
+		if !options.overwrite {
+			_, statErr := os.Stat(scenarioPath)
+			if statErr == nil {
+				summary.nrErrors++
+				fmt.Print("  FAIL: scenario file already exists\n")
+				continue
+			}
+		}
+
+		err = mc.WriteScenariosScenario(conversion.Scenario, scenarioPath)
+		if err != nil {
+			summary.nrErrors++
+			fmt.Printf("  FAIL: %s\n", err.Error())
+			continue
+		}
+		summary.nrConverted++
+
+		if options.validate {
+			err = validateConversion(test, scenarioPath)
+			if err != nil {
+				summary.nrFailedValidation++
+				fmt.Printf("  FAIL: %s\n", err.Error())
+				continue
+			}
+		}
+
+		if conversion.IsLossless() {
+			fmt.Print("  ok\n")
+		} else {
+			summary.nrLossy++
+			fmt.Printf("  lossy, dropped: %s\n", strings.Join(conversion.Losses, ", "))
+		}
+	}
+
+	return nil
+}
+
+// scenarioPathForTest yields the path of the converted scenario, next to the legacy test file,
+// so that the relative paths in the test keep working.
+// Files with several tests yield one scenario per test, named after it.
+func scenarioPathForTest(testPath string, testName string, nrTests int) string {
+	basePath := strings.TrimSuffix(testPath, legacyTestSuffix)
+	if basePath == testPath {
+		basePath = strings.TrimSuffix(testPath, filepath.Ext(testPath))
+	}
+	if nrTests > 1 {
+		basePath += "-" + strings.ReplaceAll(testName, "/", "_")
+	}
+	return basePath + ".scen.json"
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+
+	am "github.com/multiversx/mx-chain-vm-v1_4-go/scenarioexec"
+	mc "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/controller"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+)
+
+// runResult is the outcome of running a legacy test or a scenario, with the resulting accounts.
+type runResult struct {
+	err        error
+	worldState string
+}
+
+func (result *runResult) outcome() string {
+	if result.err == nil {
+		return "passes"
+	}
+	return fmt.Sprintf("fails (%s)", result.err.Error())
+}
+
+// validateConversion runs the legacy test and the written scenario on separate executors,
+// and checks that both pass or both fail, leaving the same accounts behind.
+func validateConversion(test *mj.Test, scenarioPath string) error {
+	legacyResult, err := runLegacyTest(test)
+	if err != nil {
+		return err
+	}
+	scenarioResult, err := runScenario(scenarioPath)
+	if err != nil {
+		return err
+	}
+
+	if (legacyResult.err == nil) != (scenarioResult.err == nil) {
+		return fmt.Errorf("legacy test %s, but scenario %s", legacyResult.outcome(), scenarioResult.outcome())
+	}
+	if legacyResult.worldState != scenarioResult.worldState {
+		return fmt.Errorf("legacy test and scenario end in different states:\nlegacy test:\n%s\nscenario:\n%s",
+			legacyResult.worldState, scenarioResult.worldState)
+	}
+	return nil
+}
+
+func runLegacyTest(test *mj.Test) (*runResult, error) {
+	executor, err := am.NewVMTestExecutor()
+	if err != nil {
+		return nil, err
+	}
+	err = executor.InitVM(mj.GasScheduleDefault)
+	if err != nil {
+		return nil, err
+	}
+
+	testErr := executor.ExecuteTest(test)
+	worldState, err := executor.WorldStateJSON()
+	if err != nil {
+		return nil, err
+	}
+	return &runResult{err: testErr, worldState: worldState}, nil
+}
+
+func runScenario(scenarioPath string) (*runResult, error) {
+	executor, err := am.NewVMTestExecutor()
+	if err != nil {
+		return nil, err
+	}
+
+	runner := mc.NewScenarioRunner(executor, mc.NewDefaultFileResolver())
+	scenErr := runner.RunSingleJSONScenario(scenarioPath, mc.DefaultRunScenarioOptions())
+	worldState, err := executor.WorldStateJSON()
+	if err != nil {
+		return nil, err
+	}
+	return &runResult{err: scenErr, worldState: worldState}, nil
+}
@@ -7,8 +7,11 @@ import (
 	"path/filepath"
 	"strings"
 
+	worldmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/world"
 	am "github.com/multiversx/mx-chain-vm-v1_4-go/scenarioexec"
 	mc "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/controller"
+	fr "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/fileresolver"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
 )
 
 func resolveArgument(exeDir string, arg string) (string, bool, error) {
@@ -24,18 +27,181 @@ func resolveArgument(exeDir string, arg string) (string, bool, error) {
 	return arg, fi.IsDir(), nil
 }
 
-func parseOptionFlags() *mc.RunScenarioOptions {
+type cliOptions struct {
+	runOptions             *mc.RunScenarioOptions
+	coveragePath           string
+	coverageVMHooks        bool
+	gasProfileDir          string
+	compareGasProfiles     bool
+	gasRegressionThreshold float64
+	systemSCMocks          bool
+	gasSchedules           []mj.GasSchedule
+	timingTopN             int
+	manifestPath           string
+	debugSteps             []string
+	debugOnFailure         bool
+	accountsStorePath      string
+}
+
+func parseOptionFlags() *cliOptions {
 	forceTraceGas := flag.Bool("force-trace-gas", false, "overrides the traceGas option in the scenarios")
+	coveragePath := flag.String("coverage", "", "records endpoint coverage, prints it and saves it as JSON to the given path")
+	coverageVMHooks := flag.Bool("coverage-vm-hooks", false, "also counts the VM hooks in the coverage, from the gas trace")
+	gasProfileDir := flag.String("gas-profile", "", "writes a gas profile for each scenario in the given directory")
+	compareGasProfiles := flag.Bool("compare-gas-profiles", false, "compares two gas profile files or directories, given as arguments, instead of running scenarios")
+	gasRegressionThreshold := flag.Float64("gas-regression-threshold", 5, "percentage of gas increase reported as regression when comparing gas profiles")
+	systemSCMocks := flag.Bool("system-sc-mocks", false, "provides Go-native mocks of the ESDT, DNS and staking system smart contracts")
+	gasSchedulesArg := flag.String("gas-schedules", "", "comma-separated gas schedules (e.g. v3,v4); runs all scenarios once per schedule and compares the gas used")
+	timingTopN := flag.Int("timing", 0, "times every step and prints the given number of slowest steps and scenarios")
+	manifestPath := flag.String("manifest", "", "checks the files loaded by the scenarios against a manifest of SHA-256 hashes, in the sha256sum format")
+	debugStepsArg := flag.String("debug-step", "", "comma-separated step numbers or step ids; pauses before them and opens a debug prompt")
+	debugOnFailure := flag.Bool("debug-on-failure", false, "pauses on the first failed step and opens a debug prompt")
+	accountsStorePath := flag.String("accounts-store", "", "keeps the accounts in the given key-value store file, loading them when needed and saving the changes after each step")
 	flag.Parse()
 
-	return &mc.RunScenarioOptions{
-		ForceTraceGas: *forceTraceGas,
+	gasSchedules, err := parseGasSchedules(*gasSchedulesArg)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	return &cliOptions{
+		runOptions: &mc.RunScenarioOptions{
+			ForceTraceGas: *forceTraceGas,
+		},
+		coveragePath:           *coveragePath,
+		coverageVMHooks:        *coverageVMHooks,
+		gasProfileDir:          *gasProfileDir,
+		compareGasProfiles:     *compareGasProfiles,
+		gasRegressionThreshold: *gasRegressionThreshold,
+		systemSCMocks:          *systemSCMocks,
+		gasSchedules:           gasSchedules,
+		timingTopN:             *timingTopN,
+		manifestPath:           *manifestPath,
+		debugSteps:             parseDebugSteps(*debugStepsArg),
+		debugOnFailure:         *debugOnFailure,
+		accountsStorePath:      *accountsStorePath,
+	}
+}
+
+func parseGasSchedules(arg string) ([]mj.GasSchedule, error) {
+	if len(arg) == 0 {
+		return nil, nil
+	}
+
+	var gasSchedules []mj.GasSchedule
+	for _, name := range strings.Split(arg, ",") {
+		gasSchedule, err := mj.GasScheduleFromString(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		gasSchedules = append(gasSchedules, gasSchedule)
+	}
+	return gasSchedules, nil
+}
+
+func parseDebugSteps(arg string) []string {
+	var debugSteps []string
+	for _, step := range strings.Split(arg, ",") {
+		step = strings.TrimSpace(step)
+		if len(step) > 0 {
+			debugSteps = append(debugSteps, step)
+		}
+	}
+	return debugSteps
+}
+
+func runWithGasSchedules(cliOpts *cliOptions, scenPath string) {
+	if len(cliOpts.coveragePath) > 0 {
+		fmt.Println("coverage is not recorded when running with multiple gas schedules")
+	}
+
+	configureExecutor := func(executor *am.VMTestExecutor, gasSchedule mj.GasSchedule) {
+		if len(cliOpts.gasProfileDir) > 0 {
+			executor.EnableGasProfile(filepath.Join(cliOpts.gasProfileDir, gasSchedule.String()), scenPath)
+		}
+		if cliOpts.systemSCMocks {
+			executor.EnableSystemSCMocks()
+		}
+	}
+
+	comparison, err := am.RunScenariosWithGasSchedules(
+		scenPath,
+		[]string{},
+		cliOpts.gasSchedules,
+		cliOpts.runOptions,
+		configureExecutor)
+	if err != nil {
+		fmt.Printf("ERROR: %s\n", err.Error())
+		os.Exit(1)
+	}
+
+	comparison.WriteText(os.Stdout)
+	if comparison.HasFailures() {
+		fmt.Println("ERROR: some scenarios failed with some gas schedules")
+		os.Exit(1)
+	}
+	fmt.Println("SUCCESS")
+}
+
+func runGasProfileComparison(exeDir string, args []string, threshold float64) {
+	if len(args) != 2 {
+		panic("Two arguments expected - the old and the new gas profile file or directory.")
+	}
+	oldPath, _, err := resolveArgument(exeDir, args[0])
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	newPath, _, err := resolveArgument(exeDir, args[1])
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	nrRegressions, err := am.CompareGasProfileFiles(oldPath, newPath, threshold, os.Stdout)
+	if err != nil {
+		fmt.Printf("ERROR: %s\n", err.Error())
+		os.Exit(1)
+	}
+	if nrRegressions > 0 {
+		os.Exit(1)
+	}
+}
+
+// newFileResolver yields the resolver for the scenario files, reading from the bundle if there is one,
+// and checking the files against the manifest if one was given.
+func newFileResolver(cliOpts *cliOptions, bundle *fr.BundleFileResolver) fr.FileResolver {
+	var fileResolver fr.FileResolver = mc.NewDefaultFileResolver()
+	if bundle != nil {
+		fileResolver = bundle
 	}
+	if len(cliOpts.manifestPath) == 0 {
+		return fileResolver
+	}
+
+	manifestResolver, err := fr.NewManifestFileResolver(fileResolver, cliOpts.manifestPath)
+	if err != nil {
+		fmt.Printf("could not load manifest: %s\n", err.Error())
+		os.Exit(1)
+	}
+	return manifestResolver
+}
+
+func writeCoverageReport(executor *am.VMTestExecutor, coveragePath string) error {
+	report := executor.GetCoverageReport()
+	if report == nil {
+		return nil
+	}
+
+	report.WriteText(os.Stdout)
+	return report.WriteJSONFile(coveragePath)
 }
 
 // ScenariosTestCLI provides the functionality for any scenarios test executor.
 func ScenariosTestCLI() {
-	options := parseOptionFlags()
+	cliOpts := parseOptionFlags()
+	options := cliOpts.runOptions
 
 	// directory of this executable
 	exeDir, err := os.Getwd()
@@ -46,8 +212,12 @@ func ScenariosTestCLI() {
 
 	// argument
 	args := flag.Args()
+	if cliOpts.compareGasProfiles {
+		runGasProfileComparison(exeDir, args, cliOpts.gasRegressionThreshold)
+		return
+	}
 	if len(args) != 1 {
-		panic("One argument expected - the path to the json test or directory.")
+		panic("One argument expected - the path to the json test, directory or bundle.")
 	}
 	jsonFilePath, isDir, err := resolveArgument(exeDir, args[0])
 	if err != nil {
@@ -55,18 +225,80 @@ func ScenariosTestCLI() {
 		os.Exit(1)
 	}
 
+	isBundle := !isDir && fr.IsBundlePath(jsonFilePath)
+
+	if len(cliOpts.gasSchedules) > 0 {
+		if isBundle {
+			panic("Multiple gas schedules cannot be used with bundles.")
+		}
+		if !isDir && !mc.HasSuffixInAnyFormat(jsonFilePath, ".scen.json") {
+			panic("Multiple gas schedules can only be used with .scen.json scenarios.")
+		}
+		runWithGasSchedules(cliOpts, jsonFilePath)
+		return
+	}
+
 	// init
 	executor, err := am.NewVMTestExecutor()
 	if err != nil {
 		panic("Could not instantiate VM VM")
 	}
+	if len(cliOpts.coveragePath) > 0 {
+		executor.EnableCoverage()
+		if cliOpts.coverageVMHooks {
+			executor.EnableVMHookCoverage()
+		}
+	}
+	if len(cliOpts.gasProfileDir) > 0 {
+		executor.EnableGasProfile(cliOpts.gasProfileDir, jsonFilePath)
+	}
+	if cliOpts.systemSCMocks {
+		executor.EnableSystemSCMocks()
+	}
+	if cliOpts.timingTopN > 0 {
+		executor.EnableStepTiming()
+	}
+	if len(cliOpts.debugSteps) > 0 || cliOpts.debugOnFailure {
+		debugger := am.NewDebugger(os.Stdin, os.Stdout)
+		debugger.BreakOnFailure = cliOpts.debugOnFailure
+		for _, step := range cliOpts.debugSteps {
+			debugger.AddBreakpoint(step)
+		}
+		executor.EnableDebugger(debugger)
+	}
+	if len(cliOpts.accountsStorePath) > 0 {
+		accountsStore, storeErr := worldmock.OpenAccountsStore(cliOpts.accountsStorePath)
+		if storeErr != nil {
+			fmt.Println(storeErr)
+			os.Exit(1)
+		}
+		defer func() {
+			_ = accountsStore.Close()
+		}()
+		executor.World.UseAccountsStore(accountsStore)
+	}
 
 	// execute
 	switch {
+	case isBundle:
+		bundle, bundleErr := fr.LoadBundleFileResolver(jsonFilePath)
+		if bundleErr != nil {
+			fmt.Println(bundleErr)
+			os.Exit(1)
+		}
+		runner := mc.NewScenarioRunner(
+			executor,
+			newFileResolver(cliOpts, bundle),
+		)
+		err = runner.RunAllJSONScenariosInBundle(
+			bundle,
+			".scen.json",
+			[]string{},
+			options)
 	case isDir:
 		runner := mc.NewScenarioRunner(
 			executor,
-			mc.NewDefaultFileResolver(),
+			newFileResolver(cliOpts, nil),
 		)
 		err = runner.RunAllJSONScenariosInDirectory(
 			jsonFilePath,
@@ -74,20 +306,31 @@ func ScenariosTestCLI() {
 			".scen.json",
 			[]string{},
 			options)
-	case strings.HasSuffix(jsonFilePath, ".scen.json"):
+	case mc.HasSuffixInAnyFormat(jsonFilePath, ".scen.json"):
 		runner := mc.NewScenarioRunner(
 			executor,
-			mc.NewDefaultFileResolver(),
+			newFileResolver(cliOpts, nil),
 		)
-		err = runner.RunSingleJSONScenario(jsonFilePath, options)
+		err = runner.RunTopLevelJSONScenario(jsonFilePath, options)
 	default:
 		runner := mc.NewTestRunner(
 			executor,
-			mc.NewDefaultFileResolver(),
+			newFileResolver(cliOpts, nil),
 		)
 		err = runner.RunSingleJSONTest(jsonFilePath)
 	}
 
+	if len(cliOpts.coveragePath) > 0 {
+		coverageErr := writeCoverageReport(executor, cliOpts.coveragePath)
+		if coverageErr != nil {
+			fmt.Printf("could not write coverage report: %s\n", coverageErr.Error())
+		}
+	}
+
+	if cliOpts.timingTopN > 0 {
+		executor.GetTimingReport().WriteText(os.Stdout, cliOpts.timingTopN)
+	}
+
 	// print result
 	if err == nil {
 		fmt.Println("SUCCESS")
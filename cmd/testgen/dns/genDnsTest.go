@@ -7,12 +7,16 @@ import (
 	"os"
 	"path/filepath"
 
+	scenbuilder "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/builder"
 	mc "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/controller"
-	mjparse "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/parse"
+	fr "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/fileresolver"
 	mjwrite "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/write"
 	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
 )
 
+// numDNSShards is the number of shards the generated scenario deploys a dns contract to.
+const numDNSShards = 256
+
 func getTestRoot() string {
 	exePath, err := os.Getwd()
 	if err != nil {
@@ -22,17 +26,51 @@ func getTestRoot() string {
 	return vmTestRoot
 }
 
-type testGenerator struct {
-	parser            mjparse.Parser
-	generatedScenario *mj.Scenario
+// deployExpectation accepts whatever the deploys and calls return, written out as before.
+func deployExpectation() *scenbuilder.ResultBuilder {
+	return scenbuilder.Result().Out().Status("").AnyLogs().Gas("*").Refund("*")
 }
 
-func (tg *testGenerator) addStep(stepSnippet string) {
-	step, err := tg.parser.ParseScenarioStep(stepSnippet)
-	if err != nil {
-		panic(err)
+// generateDNSInitScenario deploys a dns contract in each of the first numShards shards and enables registration.
+func generateDNSInitScenario(fileResolver fr.FileResolver, numShards int) (*mj.Scenario, error) {
+	sb := scenbuilder.NewScenario("dns test").
+		WithFileResolver(fileResolver).
+		CheckGas(false).
+		SetState(scenbuilder.Account("''dns_owner_______________________").
+			Nonce("0").
+			Balance("0"))
+
+	for shard := 0; shard < numShards; shard++ {
+		sb.NewAddress(
+			"''dns_owner_______________________",
+			fmt.Sprintf("0x%02x", shard),
+			fmt.Sprintf("''dns____________________________|0x%02x", shard))
 	}
-	tg.generatedScenario.Steps = append(tg.generatedScenario.Steps, step)
+
+	for shard := 0; shard < numShards; shard++ {
+		sb.Deploy(fmt.Sprintf("deploy-0x%02x", shard), scenbuilder.Tx().
+			From("''dns_owner_______________________").
+			EGLDValue("0").
+			Code("file:dns.wasm").
+			Arguments("123,000").
+			GasLimit("100,000").
+			GasPrice("0")).
+			Expect(deployExpectation())
+	}
+
+	for shard := 0; shard < numShards; shard++ {
+		sb.Call(fmt.Sprintf("feature-register-0x%02x", shard), scenbuilder.Tx().
+			From("''dns_owner_______________________").
+			To(fmt.Sprintf("''dns____________________________|0x%02x", shard)).
+			EGLDValue("0").
+			Function("setFeatureFlag").
+			Arguments("''register", "true").
+			GasLimit("100,000").
+			GasPrice("0")).
+			Expect(deployExpectation())
+	}
+
+	return sb.Build()
 }
 
 func main() {
@@ -40,95 +78,14 @@ func main() {
 		ReplacePath(
 			"dns.wasm",
 			filepath.Join(getTestRoot(), "dns/dns.wasm"))
-	tg := &testGenerator{
-		parser: mjparse.NewParser(fileResolver),
-		generatedScenario: &mj.Scenario{
-			Name: "dns test",
-		},
-	}
-
-	newAddressesSnippets := ""
-	for shard := 0; shard < 256; shard++ {
-		if shard > 0 {
-			newAddressesSnippets += ","
-		}
-		newAddressesSnippets += fmt.Sprintf(`{
-				"creatorAddress": "''dns_owner_______________________",
-				"creatorNonce": "0x%02x",
-				"newAddress": "''dns____________________________|0x%02x"
-			}`,
-			shard,
-			shard)
-	}
-	tg.addStep(fmt.Sprintf(`
-			{
-				"step": "setState",
-				"accounts": {
-					"''dns_owner_______________________": {
-						"nonce": "0",
-						"balance": "0",
-						"storage": {},
-						"code": ""
-					}
-				},
-				"newAddresses": [
-					%s
-				]
-			}`,
-		newAddressesSnippets))
-
-	for shard := 0; shard < 256; shard++ {
-		tg.addStep(fmt.Sprintf(`
-			{
-				"step": "scDeploy",
-				"txId": "deploy-0x%02x",
-				"tx": {
-					"from": "''dns_owner_______________________",
-					"value": "0",
-					"contractCode": "file:dns.wasm",
-					"arguments": [ "123,000" ],
-					"gasLimit": "100,000",
-					"gasPrice": "0"
-				},
-				"expect": {
-					"out": [],
-					"status": "",
-					"logs": "*",
-					"gas": "*",
-					"refund": "*"
-				}
-			}`,
-			shard))
-	}
-
-	for shard := 0; shard < 256; shard++ {
-		tg.addStep(fmt.Sprintf(`
-			{
-				"step": "scCall",
-				"txId": "feature-register-0x%02x",
-				"tx": {
-					"from": "''dns_owner_______________________",
-					"to": "''dns____________________________|0x%02x",
-					"value": "0",
-					"function": "setFeatureFlag",
-					"arguments": [ "''register", "true" ],
-					"gasLimit": "100,000",
-					"gasPrice": "0"
-				},
-				"expect": {
-					"out": [],
-					"status": "",
-					"logs": "*",
-					"gas": "*",
-					"refund": "*"
-				}
-			}`,
-			shard, shard))
+	generatedScenario, err := generateDNSInitScenario(fileResolver, numDNSShards)
+	if err != nil {
+		panic(err)
 	}
 
 	// save
-	serialized := mjwrite.ScenarioToJSONString(tg.generatedScenario)
-	err := ioutil.WriteFile(
+	serialized := mjwrite.ScenarioToJSONString(generatedScenario)
+	err = ioutil.WriteFile(
 		filepath.Join(getTestRoot(), "dns/dns_init.steps.json"),
 		[]byte(serialized), 0644)
 	if err != nil {
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	mc "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/controller"
+	mjwrite "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/write"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDNSInitScenario(t *testing.T) {
+	fileResolver := mc.NewDefaultFileResolver().
+		ReplacePath(
+			"dns.wasm",
+			filepath.Join(getTestRoot(), "dns/output/dns.wasm"))
+	generatedScenario, err := generateDNSInitScenario(fileResolver, 2)
+	require.Nil(t, err)
+	require.False(t, generatedScenario.CheckGas)
+
+	// the output of the generator for 2 shards, as it was before moving it to the scenario builder
+	expected, err := ioutil.ReadFile("testdata/dns_init_2_shards.steps.json")
+	require.Nil(t, err)
+	require.Equal(t, string(expected), mjwrite.ScenarioToJSONString(generatedScenario))
+}
@@ -30,6 +30,12 @@ func initializeCLI(facade *vmserver.DebugFacade) *cli.App {
 		Destination: &args.World,
 	}
 
+	flagAccountsStore := cli.BoolFlag{
+		Name:        "accounts-store",
+		Usage:       "keeps the accounts of the world in a key-value store, instead of the JSON file",
+		Destination: &args.AccountsStore,
+	}
+
 	flagOutcome := cli.StringFlag{
 		Required:    true,
 		Name:        "outcome",
@@ -146,6 +152,7 @@ func initializeCLI(facade *vmserver.DebugFacade) *cli.App {
 				flagOutcome,
 				flagWorld,
 				flagDatabase,
+				flagAccountsStore,
 				flagImpersonated,
 				flagCode,
 				flagCodePath,
@@ -167,6 +174,7 @@ func initializeCLI(facade *vmserver.DebugFacade) *cli.App {
 				flagOutcome,
 				flagWorld,
 				flagDatabase,
+				flagAccountsStore,
 				flagContract,
 				flagImpersonated,
 				flagCode,
@@ -189,6 +197,7 @@ func initializeCLI(facade *vmserver.DebugFacade) *cli.App {
 				flagOutcome,
 				flagWorld,
 				flagDatabase,
+				flagAccountsStore,
 				flagContract,
 				flagImpersonated,
 				flagFunction,
@@ -209,6 +218,7 @@ func initializeCLI(facade *vmserver.DebugFacade) *cli.App {
 				flagOutcome,
 				flagWorld,
 				flagDatabase,
+				flagAccountsStore,
 				flagContract,
 				flagImpersonated,
 				flagFunction,
@@ -227,6 +237,7 @@ func initializeCLI(facade *vmserver.DebugFacade) *cli.App {
 				flagOutcome,
 				flagWorld,
 				flagDatabase,
+				flagAccountsStore,
 				flagAccountAddress,
 				flagAccountBalance,
 				flagAccountNonce,
@@ -10,6 +10,7 @@ type cliArguments struct {
 	ServerAddress string
 	Database      string
 	World         string
+	AccountsStore bool
 	Outcome       string
 	// For contract-related actions
 	Impersonated    string
@@ -57,6 +58,7 @@ func (args *cliArguments) populateContractRequestBase(request *vmserver.Contract
 func (args *cliArguments) populateRequestBase(request *vmserver.RequestBase) {
 	request.DatabasePath = args.Database
 	request.World = args.World
+	request.AccountsStore = args.AccountsStore
 	request.Outcome = args.Outcome
 }
 
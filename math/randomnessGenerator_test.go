@@ -60,3 +60,19 @@ func TestBackwardsCompatible(t *testing.T) {
 	_, _ = randomizer.Read(a)
 	require.Equal(t, "7459d163b20b5b0269ce2211a2cc061cc9e512fdcbe025b0fa359014f6619ed0", hex.EncodeToString(a))
 }
+
+func TestSuccessiveReadsContinueTheSameSequence(t *testing.T) {
+	t.Parallel()
+	seed := []byte("successive reads test string")
+
+	whole := make([]byte, 21)
+	_, _ = NewSeedRandReader(seed).Read(whole)
+
+	randomizer := NewSeedRandReader(seed)
+	first := make([]byte, 3)
+	_, _ = randomizer.Read(first)
+	second := make([]byte, 18)
+	_, _ = randomizer.Read(second)
+
+	require.Equal(t, whole, append(first, second...))
+}
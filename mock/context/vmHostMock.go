@@ -221,6 +221,10 @@ func (host *VMHostMock) CheckExecuteReadOnly() bool {
 	return true
 }
 
+// SetKeepGasTracing mocked method
+func (host *VMHostMock) SetKeepGasTracing(_ bool) {
+}
+
 // Close -
 func (host *VMHostMock) Close() error {
 	return nil
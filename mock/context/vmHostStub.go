@@ -322,6 +322,10 @@ func (vhs *VMHostStub) CheckExecuteReadOnly() bool {
 	return true
 }
 
+// SetKeepGasTracing mocked method
+func (vhs *VMHostStub) SetKeepGasTracing(_ bool) {
+}
+
 // Close -
 func (vhs *VMHostStub) Close() error {
 	return nil
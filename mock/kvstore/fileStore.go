@@ -0,0 +1,530 @@
+package kvstoremock
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	recordPut    = byte(1)
+	recordDelete = byte(2)
+	recordCommit = byte(3)
+
+	// operation, key length, value length
+	recordHeaderLength = 1 + 4 + 4
+
+	// the value of a commit record: number of records in the batch, checksum of the batch
+	commitValueLength = 4 + 4
+
+	commitRecordLength = recordHeaderLength + commitValueLength
+
+	// keys added beyond this are sorted in, before listing keys
+	maxUnsortedKeys = 4096
+)
+
+// ErrStoreClosed signals an operation on a store that was closed.
+var ErrStoreClosed = errors.New("key-value store closed")
+
+// FileStore is an embedded key-value store, kept in a single append-only file.
+// Only the keys and the positions of the values are held in memory, the values are read from the file when needed.
+// Changes are written in batches, each of them appended at the end of the file and closed by a commit record,
+// holding the checksum of the batch; the space taken by the overwritten values is only reclaimed by Compact.
+//
+// The mock world only needs point reads, listing keys by prefix and atomic batches,
+// which an append-only log covers, so the store does not bring in LevelDB or Pebble,
+// along with their dependencies, for a test tool.
+type FileStore struct {
+	path      string
+	file      *os.File
+	index     map[string]valueLocation
+	size      int64
+	deadBytes int64
+
+	// the keys are sorted lazily: those added since the last sort are kept apart,
+	// while those deleted stay in sortedKeys until the next sort
+	sortedKeys   []string
+	unsortedKeys map[string]struct{}
+	nrStaleKeys  int
+}
+
+type valueLocation struct {
+	offset int64
+	length uint32
+}
+
+// Batch groups changes, to be written to the store together.
+type Batch struct {
+	records []batchRecord
+}
+
+type batchRecord struct {
+	operation byte
+	key       string
+	value     []byte
+}
+
+// NewBatch creates an empty batch.
+func NewBatch() *Batch {
+	return &Batch{}
+}
+
+// Put sets the value of a key.
+func (batch *Batch) Put(key []byte, value []byte) {
+	batch.records = append(batch.records, batchRecord{
+		operation: recordPut,
+		key:       string(key),
+		value:     value,
+	})
+}
+
+// Delete removes a key.
+func (batch *Batch) Delete(key []byte) {
+	batch.records = append(batch.records, batchRecord{
+		operation: recordDelete,
+		key:       string(key),
+	})
+}
+
+// Len yields the number of changes in the batch.
+func (batch *Batch) Len() int {
+	return len(batch.records)
+}
+
+// OpenFileStore opens the store kept in the file at the given path, creating it if missing.
+// A batch left incomplete at the end of the file, by a write that was interrupted, is discarded.
+// Any other damage to the file is reported as an error.
+func OpenFileStore(path string) (*FileStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &FileStore{
+		path:         path,
+		file:         file,
+		index:        make(map[string]valueLocation),
+		unsortedKeys: make(map[string]struct{}),
+	}
+	err = store.loadIndex()
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("could not open key-value store %s: %w", path, err)
+	}
+
+	return store, nil
+}
+
+// pendingRecord is a record read from the file, applied once the commit record of its batch is read.
+type pendingRecord struct {
+	operation byte
+	key       string
+	location  valueLocation
+}
+
+func (store *FileStore) loadIndex() error {
+	info, err := store.file.Stat()
+	if err != nil {
+		return err
+	}
+	fileSize := info.Size()
+
+	_, err = store.file.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(store.file)
+	committedSize := int64(0)
+	offset := int64(0)
+	checksum := uint32(0)
+	var pending []pendingRecord
+	header := make([]byte, recordHeaderLength)
+records:
+	for offset < fileSize {
+		_, err = io.ReadFull(reader, header)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break records
+		}
+		if err != nil {
+			return err
+		}
+
+		operation := header[0]
+		keyLength := binary.BigEndian.Uint32(header[1:5])
+		valueLength := binary.BigEndian.Uint32(header[5:9])
+		bodyLength := int64(keyLength) + int64(valueLength)
+		if offset+recordHeaderLength+bodyLength > fileSize {
+			// a write interrupted in the last record of the file leaves it shorter than its header says,
+			// but only if there is no room left for a commit record after the header, otherwise the header is damaged
+			if fileSize-offset < recordHeaderLength+commitRecordLength {
+				break records
+			}
+			return fmt.Errorf("corrupted record at offset %d", offset)
+		}
+		body := make([]byte, bodyLength)
+		_, err = io.ReadFull(reader, body)
+		if err != nil {
+			return err
+		}
+		recordOffset := offset
+		offset += recordHeaderLength + bodyLength
+
+		switch operation {
+		case recordPut, recordDelete:
+			checksum = crc32.Update(checksum, crc32.IEEETable, header)
+			checksum = crc32.Update(checksum, crc32.IEEETable, body)
+			pending = append(pending, pendingRecord{
+				operation: operation,
+				key:       string(body[:keyLength]),
+				location: valueLocation{
+					offset: recordOffset + recordHeaderLength + int64(keyLength),
+					length: valueLength,
+				},
+			})
+		case recordCommit:
+			if keyLength != 0 || valueLength != commitValueLength ||
+				binary.BigEndian.Uint32(body[:4]) != uint32(len(pending)) ||
+				binary.BigEndian.Uint32(body[4:]) != checksum {
+				if offset == fileSize {
+					// the last batch, partly written, ends the file
+					break records
+				}
+				return fmt.Errorf("corrupted batch at offset %d", committedSize)
+			}
+			for _, record := range pending {
+				store.applyToIndex(record.operation, record.key, record.location)
+			}
+			pending = nil
+			checksum = 0
+			committedSize = offset
+		default:
+			return fmt.Errorf("corrupted record at offset %d", recordOffset)
+		}
+	}
+
+	// drops the incomplete batch, if any, so that the next writes follow the last complete one
+	if committedSize < fileSize {
+		err = store.file.Truncate(committedSize)
+		if err != nil {
+			return err
+		}
+	}
+	store.size = committedSize
+	store.sortKeys()
+	return nil
+}
+
+func (store *FileStore) applyToIndex(operation byte, key string, location valueLocation) {
+	previous, existed := store.index[key]
+	if existed {
+		store.deadBytes += int64(previous.length)
+	}
+
+	if operation == recordDelete {
+		if existed {
+			delete(store.index, key)
+			store.removeKey(key)
+		}
+		return
+	}
+
+	store.index[key] = location
+	if !existed {
+		store.addKey(key)
+	}
+}
+
+func (store *FileStore) addKey(key string) {
+	i := sort.SearchStrings(store.sortedKeys, key)
+	if i < len(store.sortedKeys) && store.sortedKeys[i] == key {
+		// deleted, then added again
+		store.nrStaleKeys--
+		return
+	}
+	store.unsortedKeys[key] = struct{}{}
+}
+
+func (store *FileStore) removeKey(key string) {
+	if _, unsorted := store.unsortedKeys[key]; unsorted {
+		delete(store.unsortedKeys, key)
+		return
+	}
+	store.nrStaleKeys++
+}
+
+// sortKeys merges the keys added since the last sort into sortedKeys, and drops the deleted ones.
+func (store *FileStore) sortKeys() {
+	added := make([]string, 0, len(store.unsortedKeys))
+	for key := range store.unsortedKeys {
+		added = append(added, key)
+	}
+	sort.Strings(added)
+
+	merged := make([]string, 0, len(store.index))
+	i := 0
+	for _, key := range store.sortedKeys {
+		if _, exists := store.index[key]; !exists {
+			continue
+		}
+		for i < len(added) && added[i] < key {
+			merged = append(merged, added[i])
+			i++
+		}
+		merged = append(merged, key)
+	}
+	merged = append(merged, added[i:]...)
+
+	store.sortedKeys = merged
+	store.unsortedKeys = make(map[string]struct{})
+	store.nrStaleKeys = 0
+}
+
+// Get yields the value of the key and whether it was found.
+func (store *FileStore) Get(key []byte) ([]byte, bool, error) {
+	if store.file == nil {
+		return nil, false, ErrStoreClosed
+	}
+
+	location, found := store.index[string(key)]
+	if !found {
+		return nil, false, nil
+	}
+
+	value := make([]byte, location.length)
+	_, err := store.file.ReadAt(value, location.offset)
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+// Has tells whether the key is in the store, without reading its value.
+func (store *FileStore) Has(key []byte) bool {
+	_, found := store.index[string(key)]
+	return found
+}
+
+// Len yields the number of keys in the store.
+func (store *FileStore) Len() int {
+	return len(store.index)
+}
+
+// KeysWithPrefix yields the keys starting with the prefix, in ascending order.
+func (store *FileStore) KeysWithPrefix(prefix []byte) [][]byte {
+	if len(store.unsortedKeys) > maxUnsortedKeys || store.nrStaleKeys > len(store.sortedKeys)/2 {
+		store.sortKeys()
+	}
+
+	var added []string
+	for key := range store.unsortedKeys {
+		if strings.HasPrefix(key, string(prefix)) {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+
+	var keys [][]byte
+	i := 0
+	start := sort.SearchStrings(store.sortedKeys, string(prefix))
+	for _, key := range store.sortedKeys[start:] {
+		if !strings.HasPrefix(key, string(prefix)) {
+			break
+		}
+		if _, exists := store.index[key]; !exists {
+			continue
+		}
+		for i < len(added) && added[i] < key {
+			keys = append(keys, []byte(added[i]))
+			i++
+		}
+		keys = append(keys, []byte(key))
+	}
+	for _, key := range added[i:] {
+		keys = append(keys, []byte(key))
+	}
+	return keys
+}
+
+// Write appends the changes in the batch to the file, then makes them visible.
+// The file is not synced, see Sync.
+func (store *FileStore) Write(batch *Batch) error {
+	if store.file == nil {
+		return ErrStoreClosed
+	}
+	if batch.Len() == 0 {
+		return nil
+	}
+
+	var encoded bytes.Buffer
+	encoder := &batchEncoder{writer: &encoded, offset: store.size}
+	locations := make([]valueLocation, len(batch.records))
+	for i, record := range batch.records {
+		location, err := encoder.writeRecord(record.operation, record.key, record.value)
+		if err != nil {
+			return err
+		}
+		locations[i] = location
+	}
+	err := encoder.writeCommit()
+	if err != nil {
+		return err
+	}
+
+	_, err = store.file.WriteAt(encoded.Bytes(), store.size)
+	if err != nil {
+		return err
+	}
+	store.size = encoder.offset
+
+	for i, record := range batch.records {
+		store.applyToIndex(record.operation, record.key, locations[i])
+	}
+	return nil
+}
+
+// Sync flushes the batches written so far to the disk.
+// After a crash, the store opens with the last batch that made it entirely to the disk.
+func (store *FileStore) Sync() error {
+	if store.file == nil {
+		return ErrStoreClosed
+	}
+	return store.file.Sync()
+}
+
+// DeadBytes yields the space taken in the file by values that were overwritten or deleted.
+func (store *FileStore) DeadBytes() int64 {
+	return store.deadBytes
+}
+
+// Compact rewrites the file with only the current values, one at a time, as a single batch.
+func (store *FileStore) Compact() error {
+	if store.file == nil {
+		return ErrStoreClosed
+	}
+
+	// a file left by a compaction that was interrupted is started over
+	compactPath := store.path + ".compact"
+	file, err := os.OpenFile(compactPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	index, size, err := store.writeCompacted(file)
+	if err == nil {
+		err = os.Rename(compactPath, store.path)
+	}
+	if err != nil {
+		_ = file.Close()
+		_ = os.Remove(compactPath)
+		return err
+	}
+
+	_ = store.file.Close()
+	store.file = file
+	store.index = index
+	store.size = size
+	store.deadBytes = 0
+	return nil
+}
+
+func (store *FileStore) writeCompacted(file *os.File) (map[string]valueLocation, int64, error) {
+	store.sortKeys()
+	writer := bufio.NewWriter(file)
+	encoder := &batchEncoder{writer: writer}
+	index := make(map[string]valueLocation, len(store.index))
+	for _, key := range store.sortedKeys {
+		value, _, err := store.Get([]byte(key))
+		if err != nil {
+			return nil, 0, err
+		}
+		index[key], err = encoder.writeRecord(recordPut, key, value)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	err := encoder.writeCommit()
+	if err != nil {
+		return nil, 0, err
+	}
+	err = writer.Flush()
+	if err != nil {
+		return nil, 0, err
+	}
+	err = file.Sync()
+	if err != nil {
+		return nil, 0, err
+	}
+	return index, encoder.offset, nil
+}
+
+// Close syncs and closes the file. The store cannot be used afterwards.
+func (store *FileStore) Close() error {
+	if store.file == nil {
+		return nil
+	}
+
+	err := store.file.Sync()
+	closeErr := store.file.Close()
+	store.file = nil
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// batchEncoder writes the records of a batch, followed by the commit record that validates them.
+type batchEncoder struct {
+	writer    io.Writer
+	offset    int64
+	checksum  uint32
+	nrRecords uint32
+}
+
+// writeRecord yields where the value will be found in the file.
+func (encoder *batchEncoder) writeRecord(operation byte, key string, value []byte) (valueLocation, error) {
+	header := make([]byte, recordHeaderLength)
+	header[0] = operation
+	binary.BigEndian.PutUint32(header[1:5], uint32(len(key)))
+	binary.BigEndian.PutUint32(header[5:9], uint32(len(value)))
+	location := valueLocation{
+		offset: encoder.offset + recordHeaderLength + int64(len(key)),
+		length: uint32(len(value)),
+	}
+
+	for _, part := range [][]byte{header, []byte(key), value} {
+		_, err := encoder.writer.Write(part)
+		if err != nil {
+			return valueLocation{}, err
+		}
+		encoder.checksum = crc32.Update(encoder.checksum, crc32.IEEETable, part)
+		encoder.offset += int64(len(part))
+	}
+	encoder.nrRecords++
+	return location, nil
+}
+
+func (encoder *batchEncoder) writeCommit() error {
+	commit := make([]byte, commitRecordLength)
+	commit[0] = recordCommit
+	binary.BigEndian.PutUint32(commit[5:9], commitValueLength)
+	binary.BigEndian.PutUint32(commit[9:13], encoder.nrRecords)
+	binary.BigEndian.PutUint32(commit[13:17], encoder.checksum)
+
+	_, err := encoder.writer.Write(commit)
+	if err != nil {
+		return err
+	}
+	encoder.offset += int64(len(commit))
+	encoder.checksum = 0
+	encoder.nrRecords = 0
+	return nil
+}
@@ -0,0 +1,310 @@
+package kvstoremock
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func openTestStore(t *testing.T) (*FileStore, string) {
+	path := filepath.Join(t.TempDir(), "test.kv")
+	store, err := OpenFileStore(path)
+	require.Nil(t, err)
+	return store, path
+}
+
+func TestFileStore_PutGetDelete(t *testing.T) {
+	store, _ := openTestStore(t)
+	defer func() { _ = store.Close() }()
+
+	batch := NewBatch()
+	batch.Put([]byte("a"), []byte("1"))
+	batch.Put([]byte("b"), []byte("2"))
+	batch.Put([]byte("a"), []byte("3"))
+	require.Nil(t, store.Write(batch))
+
+	value, found, err := store.Get([]byte("a"))
+	require.Nil(t, err)
+	require.True(t, found)
+	require.Equal(t, []byte("3"), value)
+	require.Equal(t, 2, store.Len())
+
+	batch = NewBatch()
+	batch.Delete([]byte("a"))
+	require.Nil(t, store.Write(batch))
+
+	_, found, err = store.Get([]byte("a"))
+	require.Nil(t, err)
+	require.False(t, found)
+	require.True(t, store.Has([]byte("b")))
+	require.Equal(t, 1, store.Len())
+}
+
+func TestFileStore_Reopen(t *testing.T) {
+	store, path := openTestStore(t)
+
+	batch := NewBatch()
+	batch.Put([]byte("key"), []byte("value"))
+	batch.Put([]byte("empty"), []byte{})
+	batch.Put([]byte("deleted"), []byte("x"))
+	batch.Delete([]byte("deleted"))
+	require.Nil(t, store.Write(batch))
+	require.Nil(t, store.Close())
+
+	_, _, err := store.Get([]byte("key"))
+	require.Equal(t, ErrStoreClosed, err)
+
+	store, err = OpenFileStore(path)
+	require.Nil(t, err)
+	defer func() { _ = store.Close() }()
+
+	value, found, err := store.Get([]byte("key"))
+	require.Nil(t, err)
+	require.True(t, found)
+	require.Equal(t, []byte("value"), value)
+
+	value, found, err = store.Get([]byte("empty"))
+	require.Nil(t, err)
+	require.True(t, found)
+	require.Empty(t, value)
+
+	require.False(t, store.Has([]byte("deleted")))
+}
+
+func TestFileStore_IncompleteRecordDiscarded(t *testing.T) {
+	store, path := openTestStore(t)
+
+	batch := NewBatch()
+	batch.Put([]byte("key"), []byte("value"))
+	require.Nil(t, store.Write(batch))
+	require.Nil(t, store.Close())
+
+	// simulates a write interrupted half way
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.Nil(t, err)
+	_, err = file.Write([]byte{recordPut, 0, 0, 0, 3})
+	require.Nil(t, err)
+	require.Nil(t, file.Close())
+
+	store, err = OpenFileStore(path)
+	require.Nil(t, err)
+	require.Equal(t, 1, store.Len())
+
+	batch = NewBatch()
+	batch.Put([]byte("other"), []byte("value2"))
+	require.Nil(t, store.Write(batch))
+	require.Nil(t, store.Close())
+
+	store, err = OpenFileStore(path)
+	require.Nil(t, err)
+	defer func() { _ = store.Close() }()
+	value, found, err := store.Get([]byte("other"))
+	require.Nil(t, err)
+	require.True(t, found)
+	require.Equal(t, []byte("value2"), value)
+}
+
+func TestFileStore_KeysWithPrefix(t *testing.T) {
+	store, _ := openTestStore(t)
+	defer func() { _ = store.Close() }()
+
+	batch := NewBatch()
+	for _, key := range []string{"b2", "a1", "b1", "c", "b"} {
+		batch.Put([]byte(key), []byte(key))
+	}
+	require.Nil(t, store.Write(batch))
+	require.Equal(t, [][]byte{[]byte("b"), []byte("b1"), []byte("b2")}, store.KeysWithPrefix([]byte("b")))
+
+	batch = NewBatch()
+	batch.Delete([]byte("b1"))
+	batch.Put([]byte("b0"), []byte("b0"))
+	require.Nil(t, store.Write(batch))
+	require.Equal(t, [][]byte{[]byte("b"), []byte("b0"), []byte("b2")}, store.KeysWithPrefix([]byte("b")))
+	require.Nil(t, store.KeysWithPrefix([]byte("d")))
+}
+
+func TestFileStore_Compact(t *testing.T) {
+	store, path := openTestStore(t)
+
+	for i := 0; i < 10; i++ {
+		batch := NewBatch()
+		batch.Put([]byte("key"), []byte{byte(i)})
+		batch.Put([]byte("temp"), []byte{byte(i)})
+		batch.Delete([]byte("temp"))
+		require.Nil(t, store.Write(batch))
+	}
+	require.True(t, store.DeadBytes() > 0)
+
+	infoBefore, err := os.Stat(path)
+	require.Nil(t, err)
+	require.Nil(t, store.Compact())
+	require.Zero(t, store.DeadBytes())
+	infoAfter, err := os.Stat(path)
+	require.Nil(t, err)
+	require.True(t, infoAfter.Size() < infoBefore.Size())
+
+	value, found, err := store.Get([]byte("key"))
+	require.Nil(t, err)
+	require.True(t, found)
+	require.Equal(t, []byte{9}, value)
+	require.Nil(t, store.Close())
+
+	store, err = OpenFileStore(path)
+	require.Nil(t, err)
+	defer func() { _ = store.Close() }()
+	require.Equal(t, 1, store.Len())
+}
+
+func writeTestBatches(t *testing.T, store *FileStore, nrBatches int) {
+	for i := 0; i < nrBatches; i++ {
+		batch := NewBatch()
+		batch.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value"))
+		batch.Put([]byte("last"), []byte{byte(i)})
+		require.Nil(t, store.Write(batch))
+	}
+}
+
+func TestFileStore_IncompleteLastBatchDiscarded(t *testing.T) {
+	store, path := openTestStore(t)
+	writeTestBatches(t, store, 3)
+	require.Nil(t, store.Close())
+	info, err := os.Stat(path)
+	require.Nil(t, err)
+
+	// the commit record of the last batch, partly written or missing
+	for _, cut := range []int64{1, commitRecordLength, commitRecordLength + 3} {
+		require.Nil(t, os.Truncate(path, info.Size()-cut))
+		store, err = OpenFileStore(path)
+		require.Nil(t, err)
+		require.Equal(t, 3, store.Len(), "cut %d", cut)
+		require.False(t, store.Has([]byte("key2")))
+		value, _, err := store.Get([]byte("last"))
+		require.Nil(t, err)
+		require.Equal(t, []byte{1}, value)
+
+		writeTestBatches(t, store, 3)
+		require.Nil(t, store.Close())
+	}
+}
+
+func TestFileStore_CorruptedBatch(t *testing.T) {
+	store, path := openTestStore(t)
+	writeTestBatches(t, store, 3)
+	require.Nil(t, store.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+	original := append([]byte{}, data...)
+
+	// a value changed in a batch that is not the last one
+	valueOffset := bytes.Index(data, []byte("value"))
+	data[valueOffset] = 'V'
+	require.Nil(t, ioutil.WriteFile(path, data, 0644))
+	_, err = OpenFileStore(path)
+	require.EqualError(t, err, fmt.Sprintf("could not open key-value store %s: corrupted batch at offset 0", path))
+
+	// the same change in the last batch is taken for a write that was interrupted
+	data = append([]byte{}, original...)
+	valueOffset = bytes.LastIndex(data, []byte("value"))
+	data[valueOffset] = 'V'
+	require.Nil(t, ioutil.WriteFile(path, data, 0644))
+	store, err = OpenFileStore(path)
+	require.Nil(t, err)
+	require.Equal(t, 3, store.Len())
+	require.Nil(t, store.Close())
+
+	// an unknown operation
+	data = append([]byte{}, original...)
+	data[0] = 7
+	require.Nil(t, ioutil.WriteFile(path, data, 0644))
+	_, err = OpenFileStore(path)
+	require.EqualError(t, err, fmt.Sprintf("could not open key-value store %s: corrupted record at offset 0", path))
+}
+
+func TestFileStore_CorruptedRecordLength(t *testing.T) {
+	store, path := openTestStore(t)
+	for i := 0; i < 3; i++ {
+		batch := NewBatch()
+		batch.Put([]byte(fmt.Sprintf("key%d", i)), []byte("value"))
+		require.Nil(t, store.Write(batch))
+	}
+	require.Nil(t, store.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+
+	// the key length of the first record points past the end of the file, with committed batches after it
+	data[1] ^= 0x80
+	require.Nil(t, ioutil.WriteFile(path, data, 0644))
+	_, err = OpenFileStore(path)
+	require.EqualError(t, err, fmt.Sprintf("could not open key-value store %s: corrupted record at offset 0", path))
+
+	// nothing is discarded
+	unchanged, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+	require.Equal(t, data, unchanged)
+}
+
+func TestFileStore_KeysWithPrefixManyChanges(t *testing.T) {
+	store, _ := openTestStore(t)
+	defer func() { _ = store.Close() }()
+
+	expected := make(map[string]bool)
+	random := rand.New(rand.NewSource(42))
+	for round := 0; round < 20; round++ {
+		batch := NewBatch()
+		for i := 0; i < 1000; i++ {
+			key := fmt.Sprintf("%c%04d", 'a'+random.Intn(3), random.Intn(3000))
+			if random.Intn(3) == 0 {
+				batch.Delete([]byte(key))
+				delete(expected, key)
+			} else {
+				batch.Put([]byte(key), []byte(key))
+				expected[key] = true
+			}
+		}
+		require.Nil(t, store.Write(batch))
+
+		var expectedKeys []string
+		for key := range expected {
+			if key[0] == 'b' {
+				expectedKeys = append(expectedKeys, key)
+			}
+		}
+		sort.Strings(expectedKeys)
+		var keys []string
+		for _, key := range store.KeysWithPrefix([]byte("b")) {
+			keys = append(keys, string(key))
+		}
+		require.Equal(t, expectedKeys, keys, "round %d", round)
+		require.Equal(t, len(expected), store.Len())
+	}
+}
+
+func TestFileStore_CompactOverStaleFile(t *testing.T) {
+	store, path := openTestStore(t)
+	defer func() { _ = store.Close() }()
+	writeTestBatches(t, store, 3)
+
+	// left by a compaction that was interrupted
+	require.Nil(t, ioutil.WriteFile(path+".compact", bytes.Repeat([]byte{recordPut}, 1000), 0644))
+	require.Nil(t, store.Compact())
+	_, err := os.Stat(path + ".compact")
+	require.True(t, os.IsNotExist(err))
+
+	writeTestBatches(t, store, 1)
+	require.Nil(t, store.Close())
+	store, err = OpenFileStore(path)
+	require.Nil(t, err)
+	require.Equal(t, 4, store.Len())
+	value, _, err := store.Get([]byte("last"))
+	require.Nil(t, err)
+	require.Equal(t, []byte{0}, value)
+}
@@ -0,0 +1,278 @@
+package triemock
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/multiversx/mx-chain-core-go/hashing"
+)
+
+const (
+	leafNodeTag      = byte(0)
+	extensionNodeTag = byte(1)
+	branchNodeTag    = byte(2)
+	nrOfChildren     = 16
+)
+
+// PatriciaMerkleTrie is an in-memory Patricia Merkle trie. Keys are hashed before insertion,
+// so all paths have the same length and values only sit in leaves.
+// The root hash only depends on the entries, not on the order in which they were added or removed.
+// Nodes are never modified once created, so their hashes are computed once and cached.
+type PatriciaMerkleTrie struct {
+	hasher hashing.Hasher
+	root   trieNode
+}
+
+type trieNode interface {
+	hash(hasher hashing.Hasher) []byte
+}
+
+type leafNode struct {
+	path       []byte
+	value      []byte
+	cachedHash []byte
+}
+
+type extensionNode struct {
+	path       []byte
+	child      trieNode
+	cachedHash []byte
+}
+
+type branchNode struct {
+	children   [nrOfChildren]trieNode
+	cachedHash []byte
+}
+
+// NewPatriciaMerkleTrie creates an empty trie.
+func NewPatriciaMerkleTrie(hasher hashing.Hasher) *PatriciaMerkleTrie {
+	return &PatriciaMerkleTrie{hasher: hasher}
+}
+
+// EmptyRootHash is the root hash of an empty trie.
+func EmptyRootHash(hasher hashing.Hasher) []byte {
+	return hasher.Compute("")
+}
+
+// Update sets the value for the key. An empty value removes the key.
+func (trie *PatriciaMerkleTrie) Update(key []byte, value []byte) {
+	if len(value) == 0 {
+		trie.Delete(key)
+		return
+	}
+	trie.root = insertNode(trie.root, trie.keyPath(key), cloneBytes(value))
+}
+
+// Delete removes the key, if present.
+func (trie *PatriciaMerkleTrie) Delete(key []byte) {
+	trie.root = deleteNode(trie.root, trie.keyPath(key))
+}
+
+// Get yields the value for the key, nil if missing.
+func (trie *PatriciaMerkleTrie) Get(key []byte) []byte {
+	path := trie.keyPath(key)
+	node := trie.root
+	for {
+		switch n := node.(type) {
+		case nil:
+			return nil
+		case *leafNode:
+			if bytes.Equal(n.path, path) {
+				return n.value
+			}
+			return nil
+		case *extensionNode:
+			if !bytes.HasPrefix(path, n.path) {
+				return nil
+			}
+			path = path[len(n.path):]
+			node = n.child
+		case *branchNode:
+			node = n.children[path[0]]
+			path = path[1:]
+		}
+	}
+}
+
+// Clone yields a trie with the same entries, that can be changed independently.
+// The nodes are shared, since they are never modified.
+func (trie *PatriciaMerkleTrie) Clone() *PatriciaMerkleTrie {
+	return &PatriciaMerkleTrie{hasher: trie.hasher, root: trie.root}
+}
+
+// IsEmpty tells whether the trie has no entries.
+func (trie *PatriciaMerkleTrie) IsEmpty() bool {
+	return trie.root == nil
+}
+
+// RootHash yields the hash of the root node, EmptyRootHash if the trie is empty.
+func (trie *PatriciaMerkleTrie) RootHash() []byte {
+	if trie.root == nil {
+		return EmptyRootHash(trie.hasher)
+	}
+	return trie.root.hash(trie.hasher)
+}
+
+// keyPath converts the hash of the key to nibbles.
+func (trie *PatriciaMerkleTrie) keyPath(key []byte) []byte {
+	keyHash := trie.hasher.Compute(string(key))
+	path := make([]byte, 0, 2*len(keyHash))
+	for _, b := range keyHash {
+		path = append(path, b>>4, b&0x0f)
+	}
+	return path
+}
+
+func insertNode(node trieNode, path []byte, value []byte) trieNode {
+	switch n := node.(type) {
+	case nil:
+		return &leafNode{path: path, value: value}
+	case *leafNode:
+		if bytes.Equal(n.path, path) {
+			return &leafNode{path: path, value: value}
+		}
+		prefixLen := commonPrefixLength(n.path, path)
+		branch := &branchNode{}
+		branch.children[n.path[prefixLen]] = &leafNode{path: n.path[prefixLen+1:], value: n.value}
+		branch.children[path[prefixLen]] = &leafNode{path: path[prefixLen+1:], value: value}
+		return withExtension(path[:prefixLen], branch)
+	case *extensionNode:
+		prefixLen := commonPrefixLength(n.path, path)
+		if prefixLen == len(n.path) {
+			return &extensionNode{path: n.path, child: insertNode(n.child, path[prefixLen:], value)}
+		}
+		branch := &branchNode{}
+		branch.children[n.path[prefixLen]] = withExtension(n.path[prefixLen+1:], n.child)
+		branch.children[path[prefixLen]] = &leafNode{path: path[prefixLen+1:], value: value}
+		return withExtension(path[:prefixLen], branch)
+	case *branchNode:
+		branch := &branchNode{children: n.children}
+		branch.children[path[0]] = insertNode(n.children[path[0]], path[1:], value)
+		return branch
+	}
+	return node
+}
+
+func deleteNode(node trieNode, path []byte) trieNode {
+	switch n := node.(type) {
+	case *leafNode:
+		if bytes.Equal(n.path, path) {
+			return nil
+		}
+		return n
+	case *extensionNode:
+		if !bytes.HasPrefix(path, n.path) {
+			return n
+		}
+		child := deleteNode(n.child, path[len(n.path):])
+		if child == n.child {
+			return n
+		}
+		return prependPath(n.path, child)
+	case *branchNode:
+		child := deleteNode(n.children[path[0]], path[1:])
+		if child == n.children[path[0]] {
+			return n
+		}
+		branch := &branchNode{children: n.children}
+		branch.children[path[0]] = child
+		return collapseBranch(branch)
+	}
+	return node
+}
+
+// collapseBranch keeps the trie canonical: a branch with a single child is replaced by that child,
+// one level deeper.
+func collapseBranch(branch *branchNode) trieNode {
+	remainingIndex := -1
+	for i, child := range branch.children {
+		if child == nil {
+			continue
+		}
+		if remainingIndex >= 0 {
+			return branch
+		}
+		remainingIndex = i
+	}
+	if remainingIndex < 0 {
+		return nil
+	}
+	return prependPath([]byte{byte(remainingIndex)}, branch.children[remainingIndex])
+}
+
+// prependPath moves the node further down the trie, merging the paths of leaves and extensions.
+func prependPath(path []byte, node trieNode) trieNode {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case *leafNode:
+		return &leafNode{path: concatPaths(path, n.path), value: n.value}
+	case *extensionNode:
+		return &extensionNode{path: concatPaths(path, n.path), child: n.child}
+	}
+	return withExtension(path, node)
+}
+
+func withExtension(path []byte, node trieNode) trieNode {
+	if len(path) == 0 {
+		return node
+	}
+	return &extensionNode{path: path, child: node}
+}
+
+func (n *leafNode) hash(hasher hashing.Hasher) []byte {
+	if n.cachedHash == nil {
+		n.cachedHash = hashEncoded(hasher, leafNodeTag, n.path, n.value)
+	}
+	return n.cachedHash
+}
+
+func (n *extensionNode) hash(hasher hashing.Hasher) []byte {
+	if n.cachedHash == nil {
+		n.cachedHash = hashEncoded(hasher, extensionNodeTag, n.path, n.child.hash(hasher))
+	}
+	return n.cachedHash
+}
+
+func (n *branchNode) hash(hasher hashing.Hasher) []byte {
+	if n.cachedHash == nil {
+		childHashes := make([][]byte, nrOfChildren)
+		for i, child := range n.children {
+			if child != nil {
+				childHashes[i] = child.hash(hasher)
+			}
+		}
+		n.cachedHash = hashEncoded(hasher, branchNodeTag, childHashes...)
+	}
+	return n.cachedHash
+}
+
+// hashEncoded hashes the tag followed by the length-prefixed fields, so that distinct nodes never share an encoding.
+func hashEncoded(hasher hashing.Hasher, tag byte, fields ...[]byte) []byte {
+	encoded := []byte{tag}
+	lengthPrefix := make([]byte, 4)
+	for _, field := range fields {
+		binary.BigEndian.PutUint32(lengthPrefix, uint32(len(field)))
+		encoded = append(encoded, lengthPrefix...)
+		encoded = append(encoded, field...)
+	}
+	return hasher.Compute(string(encoded))
+}
+
+func commonPrefixLength(a []byte, b []byte) int {
+	length := 0
+	for length < len(a) && length < len(b) && a[length] == b[length] {
+		length++
+	}
+	return length
+}
+
+func concatPaths(a []byte, b []byte) []byte {
+	result := make([]byte, 0, len(a)+len(b))
+	result = append(result, a...)
+	return append(result, b...)
+}
+
+func cloneBytes(b []byte) []byte {
+	return append([]byte{}, b...)
+}
@@ -0,0 +1,104 @@
+package triemock
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/multiversx/mx-chain-core-go/hashing/blake2b"
+	"github.com/stretchr/testify/require"
+)
+
+var testHasher = blake2b.NewBlake2b()
+
+func TestPatriciaMerkleTrie_Empty(t *testing.T) {
+	trie := NewPatriciaMerkleTrie(testHasher)
+	require.True(t, trie.IsEmpty())
+	require.Equal(t, EmptyRootHash(testHasher), trie.RootHash())
+
+	trie.Update([]byte("key"), []byte("value"))
+	require.NotEqual(t, EmptyRootHash(testHasher), trie.RootHash())
+
+	trie.Delete([]byte("key"))
+	require.True(t, trie.IsEmpty())
+	require.Equal(t, EmptyRootHash(testHasher), trie.RootHash())
+}
+
+func TestPatriciaMerkleTrie_GetUpdateDelete(t *testing.T) {
+	trie := NewPatriciaMerkleTrie(testHasher)
+	trie.Update([]byte("a"), []byte("1"))
+	trie.Update([]byte("b"), []byte("2"))
+	require.Equal(t, []byte("1"), trie.Get([]byte("a")))
+	require.Equal(t, []byte("2"), trie.Get([]byte("b")))
+	require.Nil(t, trie.Get([]byte("c")))
+
+	trie.Update([]byte("a"), []byte("3"))
+	require.Equal(t, []byte("3"), trie.Get([]byte("a")))
+
+	trie.Update([]byte("a"), nil)
+	require.Nil(t, trie.Get([]byte("a")))
+	require.Equal(t, []byte("2"), trie.Get([]byte("b")))
+
+	// deleting a missing key changes nothing
+	rootHash := trie.RootHash()
+	trie.Delete([]byte("missing"))
+	require.Equal(t, rootHash, trie.RootHash())
+}
+
+func TestPatriciaMerkleTrie_RootHashIndependentOfOrder(t *testing.T) {
+	nrEntries := 500
+	keys := make([][]byte, nrEntries)
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("key%d", i))
+	}
+
+	trie1 := NewPatriciaMerkleTrie(testHasher)
+	for _, key := range keys {
+		trie1.Update(key, append([]byte("value-"), key...))
+	}
+
+	random := rand.New(rand.NewSource(42))
+	trie2 := NewPatriciaMerkleTrie(testHasher)
+	for _, i := range random.Perm(nrEntries) {
+		trie2.Update(keys[i], []byte("temporary"))
+	}
+	for _, i := range random.Perm(nrEntries) {
+		trie2.Update(keys[i], append([]byte("value-"), keys[i]...))
+	}
+	require.Equal(t, trie1.RootHash(), trie2.RootHash())
+
+	// removing half of the entries yields the same trie as never adding them
+	trie3 := NewPatriciaMerkleTrie(testHasher)
+	for i, key := range keys {
+		if i%2 == 0 {
+			trie1.Delete(key)
+		} else {
+			trie3.Update(key, append([]byte("value-"), key...))
+		}
+	}
+	require.Equal(t, trie3.RootHash(), trie1.RootHash())
+	for i, key := range keys {
+		if i%2 == 0 {
+			require.Nil(t, trie1.Get(key))
+		} else {
+			require.Equal(t, append([]byte("value-"), key...), trie1.Get(key))
+		}
+	}
+}
+
+func TestPatriciaMerkleTrie_Clone(t *testing.T) {
+	trie := NewPatriciaMerkleTrie(testHasher)
+	trie.Update([]byte("a"), []byte("1"))
+	rootHash := trie.RootHash()
+
+	clone := trie.Clone()
+	clone.Update([]byte("a"), []byte("2"))
+	clone.Update([]byte("b"), []byte("3"))
+	require.Equal(t, rootHash, trie.RootHash())
+	require.Equal(t, []byte("1"), trie.Get([]byte("a")))
+	require.Nil(t, trie.Get([]byte("b")))
+
+	clone.Delete([]byte("b"))
+	clone.Update([]byte("a"), []byte("1"))
+	require.Equal(t, rootHash, clone.RootHash())
+}
@@ -8,7 +8,6 @@ import (
 	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
 	"github.com/multiversx/mx-chain-vm-common-go/builtInFunctions"
 	"github.com/multiversx/mx-chain-vm-v1_4-go/config"
-	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost/mock"
 )
 
 // WorldMarshalizer is the global marshalizer to be used by the components of
@@ -22,6 +21,10 @@ type BuiltinFunctionsWrapper struct {
 	MapDNSAddresses map[string]struct{}
 	World           *MockWorld
 	Marshalizer     vmcommon.Marshalizer
+
+	// RecordGasUsage enables accumulating the gas used by each builtin function in GasUsedByFunction
+	RecordGasUsage    bool
+	GasUsedByFunction map[string]uint64
 }
 
 // NewBuiltinFunctionsWrapper creates a new BuiltinFunctionsWrapper with
@@ -40,32 +43,7 @@ func NewBuiltinFunctionsWrapper(
 		Accounts:                         world.AccountsAdapter,
 		ShardCoordinator:                 world,
 		MaxNumOfAddressesForTransferRole: 100,
-		EnableEpochsHandler: &mock.EnableEpochsHandlerStub{
-			IsStorageAPICostOptimizationFlagEnabledField:         true,
-			IsMultiESDTTransferFixOnCallBackFlagEnabledField:     true,
-			IsFixOOGReturnCodeFlagEnabledField:                   true,
-			IsRemoveNonUpdatedStorageFlagEnabledField:            true,
-			IsCreateNFTThroughExecByCallerFlagEnabledField:       true,
-			IsManagedCryptoAPIsFlagEnabledField:                  true,
-			IsFailExecutionOnEveryAPIErrorFlagEnabledField:       true,
-			IsRefactorContextFlagEnabledField:                    true,
-			IsCheckCorrectTokenIDForTransferRoleFlagEnabledField: true,
-			IsDisableExecByCallerFlagEnabledField:                true,
-			IsESDTTransferRoleFlagEnabledField:                   true,
-			IsGlobalMintBurnFlagEnabledField:                     true,
-			IsTransferToMetaFlagEnabledField:                     true,
-			IsCheckFrozenCollectionFlagEnabledField:              true,
-			IsFixAsyncCallbackCheckFlagEnabledField:              true,
-			IsESDTNFTImprovementV1FlagEnabledField:               true,
-			IsSaveToSystemAccountFlagEnabledField:                true,
-			IsValueLengthCheckFlagEnabledField:                   true,
-			IsSCDeployFlagEnabledField:                           true,
-			IsRepairCallbackFlagEnabledField:                     true,
-			IsAheadOfTimeGasUsageFlagEnabledField:                true,
-			IsCheckFunctionArgumentFlagEnabledField:              true,
-			IsCheckExecuteOnReadOnlyFlagEnabledField:             true,
-			IsFixOldTokenLiquidityEnabledField:                   true,
-		},
+		EnableEpochsHandler:              world.EnableEpochsHandler,
 	}
 
 	builtinFuncFactory, err := builtInFunctions.NewBuiltInFunctionsCreator(argsBuiltIn)
@@ -84,9 +62,10 @@ func NewBuiltinFunctionsWrapper(
 	}
 
 	builtinFuncsWrapper := &BuiltinFunctionsWrapper{
-		Container:       builtinFuncFactory.BuiltInFunctionContainer(),
-		MapDNSAddresses: argsBuiltIn.MapDNSAddresses,
-		World:           world,
+		Container:         builtinFuncFactory.BuiltInFunctionContainer(),
+		MapDNSAddresses:   argsBuiltIn.MapDNSAddresses,
+		World:             world,
+		GasUsedByFunction: make(map[string]uint64),
 	}
 
 	return builtinFuncsWrapper, nil
@@ -108,6 +87,10 @@ func (bf *BuiltinFunctionsWrapper) ProcessBuiltInFunction(input *vmcommon.Contra
 		return nil, err
 	}
 
+	if bf.RecordGasUsage && input.GasProvided > vmOutput.GasRemaining {
+		bf.GasUsedByFunction[input.Function] += input.GasProvided - vmOutput.GasRemaining
+	}
+
 	if !check.IfNil(caller) {
 		err = bf.World.AccountsAdapter.SaveAccount(caller)
 		if err != nil {
@@ -125,16 +108,27 @@ func (bf *BuiltinFunctionsWrapper) ProcessBuiltInFunction(input *vmcommon.Contra
 	return vmOutput, nil
 }
 
+// ResetGasUsage clears the gas usage recorded so far.
+func (bf *BuiltinFunctionsWrapper) ResetGasUsage() {
+	bf.GasUsedByFunction = make(map[string]uint64)
+}
+
 // GetBuiltinFunctionNames returns the list of defined builtin-in functions.
 func (bf *BuiltinFunctionsWrapper) GetBuiltinFunctionNames() vmcommon.FunctionNames {
 	return bf.Container.Keys()
 }
 
+// The system smart contract mocks stand for contracts in the metachain, so they are never handed to builtin functions:
+// tokens sent by them are only credited, just like a cross-shard transfer from the metachain.
 // TODO change AccountMap to support this instead
 func (bf *BuiltinFunctionsWrapper) getAccountSharded(address []byte) vmcommon.UserAccountHandler {
-	accountShard := bf.World.ComputeId(address)
-	if accountShard != bf.World.SelfId() {
+	if bf.World.IsSystemSCMockAddress(address) {
+		return nil
+	}
+	account := bf.World.GetAccount(address)
+	if account == nil || account.ShardID != bf.World.SelfId() {
 		return nil
 	}
-	return bf.World.AcctMap.GetAccount(address)
+	bf.World.journalAccount(address)
+	return bf.World.GetAccount(address)
 }
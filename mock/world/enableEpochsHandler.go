@@ -0,0 +1,468 @@
+package worldmock
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/multiversx/mx-chain-core-go/core/check"
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+	"github.com/pelletier/go-toml"
+)
+
+// Names of the protocol flags, as used in the enable epochs TOML files and in scenarios.
+const (
+	GlobalMintBurnFlag                     = "GlobalMintBurn"
+	ESDTTransferRoleFlag                   = "ESDTTransferRole"
+	BuiltInFunctionsFlag                   = "BuiltInFunctions"
+	CheckCorrectTokenIDForTransferRoleFlag = "CheckCorrectTokenIDForTransferRole"
+	MultiESDTTransferFixOnCallBackFlag     = "MultiESDTTransferFixOnCallBack"
+	FixOOGReturnCodeFlag                   = "FixOOGReturnCode"
+	RemoveNonUpdatedStorageFlag            = "RemoveNonUpdatedStorage"
+	CreateNFTThroughExecByCallerFlag       = "CreateNFTThroughExecByCaller"
+	StorageAPICostOptimizationFlag         = "StorageAPICostOptimization"
+	FailExecutionOnEveryAPIErrorFlag       = "FailExecutionOnEveryAPIError"
+	ManagedCryptoAPIsFlag                  = "ManagedCryptoAPIs"
+	SCDeployFlag                           = "SCDeploy"
+	AheadOfTimeGasUsageFlag                = "AheadOfTimeGasUsage"
+	RepairCallbackFlag                     = "RepairCallback"
+	DisableExecByCallerFlag                = "DisableExecByCaller"
+	RefactorContextFlag                    = "RefactorContext"
+	CheckFunctionArgumentFlag              = "CheckFunctionArgument"
+	CheckExecuteOnReadOnlyFlag             = "CheckExecuteOnReadOnly"
+	FixAsyncCallbackCheckFlag              = "FixAsyncCallbackCheck"
+	SaveToSystemAccountFlag                = "SaveToSystemAccount"
+	CheckFrozenCollectionFlag              = "CheckFrozenCollection"
+	SendAlwaysFlag                         = "SendAlways"
+	ValueLengthCheckFlag                   = "ValueLengthCheck"
+	CheckTransferFlag                      = "CheckTransfer"
+	TransferToMetaFlag                     = "TransferToMeta"
+	ESDTNFTImprovementV1Flag               = "ESDTNFTImprovementV1"
+	FixOldTokenLiquidityFlag               = "FixOldTokenLiquidity"
+	RuntimeMemStoreLimitFlag               = "RuntimeMemStoreLimit"
+	MaxBlockchainHookCountersFlag          = "MaxBlockchainHookCounters"
+	WipeSingleNFTLiquidityDecreaseFlag     = "WipeSingleNFTLiquidityDecrease"
+	AlwaysSaveTokenMetaDataFlag            = "AlwaysSaveTokenMetaData"
+)
+
+// DisabledEpoch is the activation epoch of the flags that never activate.
+const DisabledEpoch = uint32(math.MaxUint32)
+
+// enableEpochsTOMLSuffix can end the flag names in the TOML files, as in the node configuration.
+const enableEpochsTOMLSuffix = "EnableEpoch"
+
+var allEnableEpochsFlags = []string{
+	GlobalMintBurnFlag,
+	ESDTTransferRoleFlag,
+	BuiltInFunctionsFlag,
+	CheckCorrectTokenIDForTransferRoleFlag,
+	MultiESDTTransferFixOnCallBackFlag,
+	FixOOGReturnCodeFlag,
+	RemoveNonUpdatedStorageFlag,
+	CreateNFTThroughExecByCallerFlag,
+	StorageAPICostOptimizationFlag,
+	FailExecutionOnEveryAPIErrorFlag,
+	ManagedCryptoAPIsFlag,
+	SCDeployFlag,
+	AheadOfTimeGasUsageFlag,
+	RepairCallbackFlag,
+	DisableExecByCallerFlag,
+	RefactorContextFlag,
+	CheckFunctionArgumentFlag,
+	CheckExecuteOnReadOnlyFlag,
+	FixAsyncCallbackCheckFlag,
+	SaveToSystemAccountFlag,
+	CheckFrozenCollectionFlag,
+	SendAlwaysFlag,
+	ValueLengthCheckFlag,
+	CheckTransferFlag,
+	TransferToMetaFlag,
+	ESDTNFTImprovementV1Flag,
+	FixOldTokenLiquidityFlag,
+	RuntimeMemStoreLimitFlag,
+	MaxBlockchainHookCountersFlag,
+	WipeSingleNFTLiquidityDecreaseFlag,
+	AlwaysSaveTokenMetaDataFlag,
+}
+
+var _ vmcommon.EnableEpochsHandler = (*EnableEpochsHandler)(nil)
+var _ vmcommon.EpochNotifier = (*EnableEpochsHandler)(nil)
+
+// EnableEpochsHandler activates the protocol flags of the mock world at configured epochs.
+// It follows the epoch of the current block, as confirmed through EpochConfirmed,
+// and passes the epoch changes on to the registered handlers, such as the VM host.
+type EnableEpochsHandler struct {
+	activationEpochs map[string]uint32
+	currentEpoch     uint32
+	subscribers      []vmcommon.EpochSubscriberHandler
+}
+
+// DefaultActivationEpochs yields the flags active in the mock world if nothing else is configured,
+// all of them from epoch 0.
+func DefaultActivationEpochs() map[string]uint32 {
+	return map[string]uint32{
+		GlobalMintBurnFlag:                     0,
+		ESDTTransferRoleFlag:                   0,
+		CheckCorrectTokenIDForTransferRoleFlag: 0,
+		MultiESDTTransferFixOnCallBackFlag:     0,
+		FixOOGReturnCodeFlag:                   0,
+		RemoveNonUpdatedStorageFlag:            0,
+		CreateNFTThroughExecByCallerFlag:       0,
+		StorageAPICostOptimizationFlag:         0,
+		FailExecutionOnEveryAPIErrorFlag:       0,
+		ManagedCryptoAPIsFlag:                  0,
+		SCDeployFlag:                           0,
+		AheadOfTimeGasUsageFlag:                0,
+		RepairCallbackFlag:                     0,
+		DisableExecByCallerFlag:                0,
+		RefactorContextFlag:                    0,
+		CheckFunctionArgumentFlag:              0,
+		CheckExecuteOnReadOnlyFlag:             0,
+		FixAsyncCallbackCheckFlag:              0,
+		SaveToSystemAccountFlag:                0,
+		CheckFrozenCollectionFlag:              0,
+		ValueLengthCheckFlag:                   0,
+		TransferToMetaFlag:                     0,
+		ESDTNFTImprovementV1Flag:               0,
+		FixOldTokenLiquidityFlag:               0,
+	}
+}
+
+// NewEnableEpochsHandler creates an EnableEpochsHandler with the given activation epochs.
+// The flags missing from the map never activate.
+func NewEnableEpochsHandler(activationEpochs map[string]uint32) (*EnableEpochsHandler, error) {
+	handler := &EnableEpochsHandler{}
+	err := handler.SetActivationEpochs(activationEpochs)
+	if err != nil {
+		return nil, err
+	}
+	return handler, nil
+}
+
+// SetActivationEpochs replaces all activation epochs. The flags missing from the map never activate.
+func (handler *EnableEpochsHandler) SetActivationEpochs(activationEpochs map[string]uint32) error {
+	err := validateEnableEpochsFlags(activationEpochs)
+	if err != nil {
+		return err
+	}
+
+	handler.activationEpochs = make(map[string]uint32, len(activationEpochs))
+	for flag, epoch := range activationEpochs {
+		handler.activationEpochs[flag] = epoch
+	}
+	return nil
+}
+
+// ActivationEpochs yields a copy of all activation epochs.
+func (handler *EnableEpochsHandler) ActivationEpochs() map[string]uint32 {
+	activationEpochs := make(map[string]uint32, len(handler.activationEpochs))
+	for flag, epoch := range handler.activationEpochs {
+		activationEpochs[flag] = epoch
+	}
+	return activationEpochs
+}
+
+// ActivationEpoch yields the epoch when the flag activates, DisabledEpoch if never.
+func (handler *EnableEpochsHandler) ActivationEpoch(flag string) uint32 {
+	epoch, found := handler.activationEpochs[flag]
+	if !found {
+		return DisabledEpoch
+	}
+	return epoch
+}
+
+// CurrentEpoch yields the last confirmed epoch.
+func (handler *EnableEpochsHandler) CurrentEpoch() uint32 {
+	return handler.currentEpoch
+}
+
+// IsFlagEnabled tells whether the flag is active in the current epoch.
+func (handler *EnableEpochsHandler) IsFlagEnabled(flag string) bool {
+	epoch, found := handler.activationEpochs[flag]
+	return found && handler.currentEpoch >= epoch
+}
+
+// RegisterNotifyHandler registers a handler for the epoch changes and gives it the current epoch right away.
+func (handler *EnableEpochsHandler) RegisterNotifyHandler(subscriber vmcommon.EpochSubscriberHandler) {
+	if check.IfNil(subscriber) {
+		return
+	}
+
+	handler.subscribers = append(handler.subscribers, subscriber)
+	subscriber.EpochConfirmed(handler.currentEpoch, 0)
+}
+
+// EpochConfirmed switches to a new epoch and notifies the registered handlers. Does nothing if the epoch is unchanged.
+func (handler *EnableEpochsHandler) EpochConfirmed(epoch uint32, timestamp uint64) {
+	if epoch == handler.currentEpoch {
+		return
+	}
+
+	handler.currentEpoch = epoch
+	for _, subscriber := range handler.subscribers {
+		subscriber.EpochConfirmed(epoch, timestamp)
+	}
+}
+
+func validateEnableEpochsFlags(activationEpochs map[string]uint32) error {
+	for flag := range activationEpochs {
+		if !isEnableEpochsFlag(flag) {
+			return fmt.Errorf("unknown enable epochs flag: %s", flag)
+		}
+	}
+	return nil
+}
+
+func isEnableEpochsFlag(flag string) bool {
+	for _, knownFlag := range allEnableEpochsFlags {
+		if flag == knownFlag {
+			return true
+		}
+	}
+	return false
+}
+
+// EnableEpochsFlags yields the names of all the flags that can be configured, sorted.
+func EnableEpochsFlags() []string {
+	flags := append([]string{}, allEnableEpochsFlags...)
+	sort.Strings(flags)
+	return flags
+}
+
+// ParseEnableEpochsTOML reads a TOML map of flag names to activation epochs, e.g.
+//
+//	FixOOGReturnCode = 2
+//	ManagedCryptoAPIsEnableEpoch = 3
+//
+// The "EnableEpoch" suffix of the node configuration is optional.
+func ParseEnableEpochsTOML(fileContents string) (map[string]uint32, error) {
+	tree, err := toml.Load(fileContents)
+	if err != nil {
+		return nil, fmt.Errorf("cannot interpret enable epochs as TOML: %w", err)
+	}
+
+	activationEpochs := make(map[string]uint32)
+	for key, value := range tree.ToMap() {
+		flag := strings.TrimSuffix(key, enableEpochsTOMLSuffix)
+		if !isEnableEpochsFlag(flag) {
+			return nil, fmt.Errorf("unknown enable epochs flag: %s", key)
+		}
+		epoch, isInt := value.(int64)
+		if !isInt || epoch < 0 || epoch > int64(DisabledEpoch) {
+			return nil, fmt.Errorf("invalid activation epoch for flag %s: %v", key, value)
+		}
+		activationEpochs[flag] = uint32(epoch)
+	}
+	return activationEpochs, nil
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (handler *EnableEpochsHandler) IsInterfaceNil() bool {
+	return handler == nil
+}
+
+// IsGlobalMintBurnFlagEnabled returns true if the GlobalMintBurn flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsGlobalMintBurnFlagEnabled() bool {
+	return handler.IsFlagEnabled(GlobalMintBurnFlag)
+}
+
+// IsESDTTransferRoleFlagEnabled returns true if the ESDTTransferRole flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsESDTTransferRoleFlagEnabled() bool {
+	return handler.IsFlagEnabled(ESDTTransferRoleFlag)
+}
+
+// IsBuiltInFunctionsFlagEnabled returns true if the BuiltInFunctions flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsBuiltInFunctionsFlagEnabled() bool {
+	return handler.IsFlagEnabled(BuiltInFunctionsFlag)
+}
+
+// IsCheckCorrectTokenIDForTransferRoleFlagEnabled returns true if the CheckCorrectTokenIDForTransferRole flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsCheckCorrectTokenIDForTransferRoleFlagEnabled() bool {
+	return handler.IsFlagEnabled(CheckCorrectTokenIDForTransferRoleFlag)
+}
+
+// IsMultiESDTTransferFixOnCallBackFlagEnabled returns true if the MultiESDTTransferFixOnCallBack flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsMultiESDTTransferFixOnCallBackFlagEnabled() bool {
+	return handler.IsFlagEnabled(MultiESDTTransferFixOnCallBackFlag)
+}
+
+// IsFixOOGReturnCodeFlagEnabled returns true if the FixOOGReturnCode flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsFixOOGReturnCodeFlagEnabled() bool {
+	return handler.IsFlagEnabled(FixOOGReturnCodeFlag)
+}
+
+// IsRemoveNonUpdatedStorageFlagEnabled returns true if the RemoveNonUpdatedStorage flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsRemoveNonUpdatedStorageFlagEnabled() bool {
+	return handler.IsFlagEnabled(RemoveNonUpdatedStorageFlag)
+}
+
+// IsCreateNFTThroughExecByCallerFlagEnabled returns true if the CreateNFTThroughExecByCaller flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsCreateNFTThroughExecByCallerFlagEnabled() bool {
+	return handler.IsFlagEnabled(CreateNFTThroughExecByCallerFlag)
+}
+
+// IsStorageAPICostOptimizationFlagEnabled returns true if the StorageAPICostOptimization flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsStorageAPICostOptimizationFlagEnabled() bool {
+	return handler.IsFlagEnabled(StorageAPICostOptimizationFlag)
+}
+
+// IsFailExecutionOnEveryAPIErrorFlagEnabled returns true if the FailExecutionOnEveryAPIError flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsFailExecutionOnEveryAPIErrorFlagEnabled() bool {
+	return handler.IsFlagEnabled(FailExecutionOnEveryAPIErrorFlag)
+}
+
+// IsManagedCryptoAPIsFlagEnabled returns true if the ManagedCryptoAPIs flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsManagedCryptoAPIsFlagEnabled() bool {
+	return handler.IsFlagEnabled(ManagedCryptoAPIsFlag)
+}
+
+// IsSCDeployFlagEnabled returns true if the SCDeploy flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsSCDeployFlagEnabled() bool {
+	return handler.IsFlagEnabled(SCDeployFlag)
+}
+
+// IsAheadOfTimeGasUsageFlagEnabled returns true if the AheadOfTimeGasUsage flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsAheadOfTimeGasUsageFlagEnabled() bool {
+	return handler.IsFlagEnabled(AheadOfTimeGasUsageFlag)
+}
+
+// IsRepairCallbackFlagEnabled returns true if the RepairCallback flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsRepairCallbackFlagEnabled() bool {
+	return handler.IsFlagEnabled(RepairCallbackFlag)
+}
+
+// IsDisableExecByCallerFlagEnabled returns true if the DisableExecByCaller flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsDisableExecByCallerFlagEnabled() bool {
+	return handler.IsFlagEnabled(DisableExecByCallerFlag)
+}
+
+// IsRefactorContextFlagEnabled returns true if the RefactorContext flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsRefactorContextFlagEnabled() bool {
+	return handler.IsFlagEnabled(RefactorContextFlag)
+}
+
+// IsCheckFunctionArgumentFlagEnabled returns true if the CheckFunctionArgument flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsCheckFunctionArgumentFlagEnabled() bool {
+	return handler.IsFlagEnabled(CheckFunctionArgumentFlag)
+}
+
+// IsCheckExecuteOnReadOnlyFlagEnabled returns true if the CheckExecuteOnReadOnly flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsCheckExecuteOnReadOnlyFlagEnabled() bool {
+	return handler.IsFlagEnabled(CheckExecuteOnReadOnlyFlag)
+}
+
+// IsFixAsyncCallbackCheckFlagEnabled returns true if the FixAsyncCallbackCheck flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsFixAsyncCallbackCheckFlagEnabled() bool {
+	return handler.IsFlagEnabled(FixAsyncCallbackCheckFlag)
+}
+
+// IsSaveToSystemAccountFlagEnabled returns true if the SaveToSystemAccount flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsSaveToSystemAccountFlagEnabled() bool {
+	return handler.IsFlagEnabled(SaveToSystemAccountFlag)
+}
+
+// IsCheckFrozenCollectionFlagEnabled returns true if the CheckFrozenCollection flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsCheckFrozenCollectionFlagEnabled() bool {
+	return handler.IsFlagEnabled(CheckFrozenCollectionFlag)
+}
+
+// IsSendAlwaysFlagEnabled returns true if the SendAlways flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsSendAlwaysFlagEnabled() bool {
+	return handler.IsFlagEnabled(SendAlwaysFlag)
+}
+
+// IsValueLengthCheckFlagEnabled returns true if the ValueLengthCheck flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsValueLengthCheckFlagEnabled() bool {
+	return handler.IsFlagEnabled(ValueLengthCheckFlag)
+}
+
+// IsCheckTransferFlagEnabled returns true if the CheckTransfer flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsCheckTransferFlagEnabled() bool {
+	return handler.IsFlagEnabled(CheckTransferFlag)
+}
+
+// IsTransferToMetaFlagEnabled returns true if the TransferToMeta flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsTransferToMetaFlagEnabled() bool {
+	return handler.IsFlagEnabled(TransferToMetaFlag)
+}
+
+// IsESDTNFTImprovementV1FlagEnabled returns true if the ESDTNFTImprovementV1 flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsESDTNFTImprovementV1FlagEnabled() bool {
+	return handler.IsFlagEnabled(ESDTNFTImprovementV1Flag)
+}
+
+// IsFixOldTokenLiquidityEnabled returns true if the FixOldTokenLiquidity flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsFixOldTokenLiquidityEnabled() bool {
+	return handler.IsFlagEnabled(FixOldTokenLiquidityFlag)
+}
+
+// IsRuntimeMemStoreLimitEnabled returns true if the RuntimeMemStoreLimit flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsRuntimeMemStoreLimitEnabled() bool {
+	return handler.IsFlagEnabled(RuntimeMemStoreLimitFlag)
+}
+
+// IsMaxBlockchainHookCountersFlagEnabled returns true if the MaxBlockchainHookCounters flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsMaxBlockchainHookCountersFlagEnabled() bool {
+	return handler.IsFlagEnabled(MaxBlockchainHookCountersFlag)
+}
+
+// IsWipeSingleNFTLiquidityDecreaseEnabled returns true if the WipeSingleNFTLiquidityDecrease flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsWipeSingleNFTLiquidityDecreaseEnabled() bool {
+	return handler.IsFlagEnabled(WipeSingleNFTLiquidityDecreaseFlag)
+}
+
+// IsAlwaysSaveTokenMetaDataEnabled returns true if the AlwaysSaveTokenMetaData flag is active in the current epoch
+func (handler *EnableEpochsHandler) IsAlwaysSaveTokenMetaDataEnabled() bool {
+	return handler.IsFlagEnabled(AlwaysSaveTokenMetaDataFlag)
+}
+
+// MultiESDTTransferAsyncCallBackEnableEpoch returns the activation epoch of the MultiESDTTransferFixOnCallBack flag
+func (handler *EnableEpochsHandler) MultiESDTTransferAsyncCallBackEnableEpoch() uint32 {
+	return handler.ActivationEpoch(MultiESDTTransferFixOnCallBackFlag)
+}
+
+// FixOOGReturnCodeEnableEpoch returns the activation epoch of the FixOOGReturnCode flag
+func (handler *EnableEpochsHandler) FixOOGReturnCodeEnableEpoch() uint32 {
+	return handler.ActivationEpoch(FixOOGReturnCodeFlag)
+}
+
+// RemoveNonUpdatedStorageEnableEpoch returns the activation epoch of the RemoveNonUpdatedStorage flag
+func (handler *EnableEpochsHandler) RemoveNonUpdatedStorageEnableEpoch() uint32 {
+	return handler.ActivationEpoch(RemoveNonUpdatedStorageFlag)
+}
+
+// CreateNFTThroughExecByCallerEnableEpoch returns the activation epoch of the CreateNFTThroughExecByCaller flag
+func (handler *EnableEpochsHandler) CreateNFTThroughExecByCallerEnableEpoch() uint32 {
+	return handler.ActivationEpoch(CreateNFTThroughExecByCallerFlag)
+}
+
+// FixFailExecutionOnErrorEnableEpoch returns the activation epoch of the FailExecutionOnEveryAPIError flag
+func (handler *EnableEpochsHandler) FixFailExecutionOnErrorEnableEpoch() uint32 {
+	return handler.ActivationEpoch(FailExecutionOnEveryAPIErrorFlag)
+}
+
+// ManagedCryptoAPIEnableEpoch returns the activation epoch of the ManagedCryptoAPIs flag
+func (handler *EnableEpochsHandler) ManagedCryptoAPIEnableEpoch() uint32 {
+	return handler.ActivationEpoch(ManagedCryptoAPIsFlag)
+}
+
+// DisableExecByCallerEnableEpoch returns the activation epoch of the DisableExecByCaller flag
+func (handler *EnableEpochsHandler) DisableExecByCallerEnableEpoch() uint32 {
+	return handler.ActivationEpoch(DisableExecByCallerFlag)
+}
+
+// RefactorContextEnableEpoch returns the activation epoch of the RefactorContext flag
+func (handler *EnableEpochsHandler) RefactorContextEnableEpoch() uint32 {
+	return handler.ActivationEpoch(RefactorContextFlag)
+}
+
+// CheckExecuteReadOnlyEnableEpoch returns the activation epoch of the CheckExecuteOnReadOnly flag
+func (handler *EnableEpochsHandler) CheckExecuteReadOnlyEnableEpoch() uint32 {
+	return handler.ActivationEpoch(CheckExecuteOnReadOnlyFlag)
+}
+
+// StorageAPICostOptimizationEnableEpoch returns the activation epoch of the StorageAPICostOptimization flag
+func (handler *EnableEpochsHandler) StorageAPICostOptimizationEnableEpoch() uint32 {
+	return handler.ActivationEpoch(StorageAPICostOptimizationFlag)
+}
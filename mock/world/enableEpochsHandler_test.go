@@ -0,0 +1,158 @@
+package worldmock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// epochSubscriberStub records the confirmed epochs
+type epochSubscriberStub struct {
+	confirmedEpochs []uint32
+}
+
+func (subscriber *epochSubscriberStub) EpochConfirmed(epoch uint32, _ uint64) {
+	subscriber.confirmedEpochs = append(subscriber.confirmedEpochs, epoch)
+}
+
+func (subscriber *epochSubscriberStub) IsInterfaceNil() bool {
+	return subscriber == nil
+}
+
+func TestEnableEpochsHandler_ActivationAroundTheBoundary(t *testing.T) {
+	handler, err := NewEnableEpochsHandler(map[string]uint32{
+		FixOOGReturnCodeFlag:  0,
+		ManagedCryptoAPIsFlag: 5,
+	})
+	require.Nil(t, err)
+
+	for _, epoch := range []uint32{0, 4, 5, 6, 4} {
+		handler.EpochConfirmed(epoch, 0)
+		require.Equal(t, epoch, handler.CurrentEpoch())
+		require.True(t, handler.IsFixOOGReturnCodeFlagEnabled())
+		require.Equal(t, epoch >= 5, handler.IsManagedCryptoAPIsFlagEnabled(), "epoch %d", epoch)
+		// the flags that are not configured never activate
+		require.False(t, handler.IsFailExecutionOnEveryAPIErrorFlagEnabled())
+	}
+
+	require.Equal(t, uint32(5), handler.ManagedCryptoAPIEnableEpoch())
+	require.Equal(t, uint32(0), handler.FixOOGReturnCodeEnableEpoch())
+	require.Equal(t, DisabledEpoch, handler.ActivationEpoch(FailExecutionOnEveryAPIErrorFlag))
+	require.Equal(t, DisabledEpoch, handler.FixFailExecutionOnErrorEnableEpoch())
+
+	// the last epoch can activate flags as well
+	require.Nil(t, handler.SetActivationEpochs(map[string]uint32{ManagedCryptoAPIsFlag: DisabledEpoch}))
+	handler.EpochConfirmed(DisabledEpoch-1, 0)
+	require.False(t, handler.IsManagedCryptoAPIsFlagEnabled())
+	handler.EpochConfirmed(DisabledEpoch, 0)
+	require.True(t, handler.IsManagedCryptoAPIsFlagEnabled())
+}
+
+func TestEnableEpochsHandler_SetActivationEpochs(t *testing.T) {
+	handler, err := NewEnableEpochsHandler(DefaultActivationEpochs())
+	require.Nil(t, err)
+	require.Equal(t, DefaultActivationEpochs(), handler.ActivationEpochs())
+	require.True(t, handler.IsFixOOGReturnCodeFlagEnabled())
+
+	activationEpochs := map[string]uint32{FixOOGReturnCodeFlag: 3}
+	require.Nil(t, handler.SetActivationEpochs(activationEpochs))
+	require.False(t, handler.IsFixOOGReturnCodeFlagEnabled())
+	require.False(t, handler.IsGlobalMintBurnFlagEnabled())
+
+	// the handler keeps its own copy
+	activationEpochs[FixOOGReturnCodeFlag] = 0
+	require.False(t, handler.IsFixOOGReturnCodeFlagEnabled())
+	handler.ActivationEpochs()[FixOOGReturnCodeFlag] = 0
+	require.False(t, handler.IsFixOOGReturnCodeFlagEnabled())
+
+	// unknown flags are rejected, and the previous epochs are kept
+	err = handler.SetActivationEpochs(map[string]uint32{"FixOOGReturnCodes": 0})
+	require.EqualError(t, err, "unknown enable epochs flag: FixOOGReturnCodes")
+	require.Equal(t, uint32(3), handler.FixOOGReturnCodeEnableEpoch())
+
+	_, err = NewEnableEpochsHandler(map[string]uint32{"Unknown": 0})
+	require.EqualError(t, err, "unknown enable epochs flag: Unknown")
+}
+
+func TestEnableEpochsHandler_NotifiesSubscribers(t *testing.T) {
+	handler, err := NewEnableEpochsHandler(DefaultActivationEpochs())
+	require.Nil(t, err)
+	handler.EpochConfirmed(2, 0)
+
+	subscriber := &epochSubscriberStub{}
+	handler.RegisterNotifyHandler(subscriber)
+	handler.RegisterNotifyHandler(nil)
+	require.Equal(t, []uint32{2}, subscriber.confirmedEpochs)
+
+	handler.EpochConfirmed(2, 0)
+	handler.EpochConfirmed(3, 0)
+	handler.EpochConfirmed(3, 0)
+	handler.EpochConfirmed(1, 0)
+	require.Equal(t, []uint32{2, 3, 1}, subscriber.confirmedEpochs)
+}
+
+func TestMockWorld_ConfirmCurrentEpoch(t *testing.T) {
+	world := NewMockWorld()
+	require.Nil(t, world.EnableEpochsHandler.SetActivationEpochs(map[string]uint32{ManagedCryptoAPIsFlag: 2}))
+
+	world.ConfirmCurrentEpoch()
+	require.Equal(t, uint32(0), world.EnableEpochsHandler.CurrentEpoch())
+
+	world.CurrentBlockInfo = &BlockInfo{BlockEpoch: 1}
+	world.ConfirmCurrentEpoch()
+	require.False(t, world.EnableEpochsHandler.IsManagedCryptoAPIsFlagEnabled())
+
+	world.CurrentBlockInfo = &BlockInfo{BlockEpoch: 2}
+	world.ConfirmCurrentEpoch()
+	require.True(t, world.EnableEpochsHandler.IsManagedCryptoAPIsFlagEnabled())
+}
+
+func TestParseEnableEpochsTOML(t *testing.T) {
+	activationEpochs, err := ParseEnableEpochsTOML(`
+# as in the node configuration
+FixOOGReturnCodeEnableEpoch = 2
+ManagedCryptoAPIs = 3
+SCDeploy = 0
+`)
+	require.Nil(t, err)
+	require.Equal(t, map[string]uint32{
+		FixOOGReturnCodeFlag:  2,
+		ManagedCryptoAPIsFlag: 3,
+		SCDeployFlag:          0,
+	}, activationEpochs)
+
+	activationEpochs, err = ParseEnableEpochsTOML("")
+	require.Nil(t, err)
+	require.Empty(t, activationEpochs)
+
+	activationEpochs, err = ParseEnableEpochsTOML("SCDeploy = 4294967295")
+	require.Nil(t, err)
+	require.Equal(t, map[string]uint32{SCDeployFlag: DisabledEpoch}, activationEpochs)
+
+	_, err = ParseEnableEpochsTOML("UnknownFlagEnableEpoch = 1")
+	require.EqualError(t, err, "unknown enable epochs flag: UnknownFlagEnableEpoch")
+
+	_, err = ParseEnableEpochsTOML("SCDeploy = -1")
+	require.EqualError(t, err, "invalid activation epoch for flag SCDeploy: -1")
+
+	_, err = ParseEnableEpochsTOML("SCDeploy = 4294967296")
+	require.EqualError(t, err, "invalid activation epoch for flag SCDeploy: 4294967296")
+
+	_, err = ParseEnableEpochsTOML(`SCDeploy = "1"`)
+	require.EqualError(t, err, "invalid activation epoch for flag SCDeploy: 1")
+
+	_, err = ParseEnableEpochsTOML("[EnableEpochs]\nSCDeploy = 1")
+	require.NotNil(t, err)
+
+	_, err = ParseEnableEpochsTOML("SCDeploy = ")
+	require.NotNil(t, err)
+}
+
+func TestEnableEpochsFlags(t *testing.T) {
+	flags := EnableEpochsFlags()
+	require.Len(t, flags, len(allEnableEpochsFlags))
+	require.IsIncreasing(t, flags)
+	for flag := range DefaultActivationEpochs() {
+		require.Contains(t, flags, flag)
+	}
+}
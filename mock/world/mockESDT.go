@@ -8,24 +8,24 @@ import (
 	"github.com/multiversx/mx-chain-core-go/data/esdt"
 	"github.com/multiversx/mx-chain-core-go/data/vm"
 	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
-	"github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/esdtconvert"
 	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
 )
 
 // GetTokenBalance returns the ESDT balance of an account for the given token
 // key (token keys are built from the token identifier using MakeTokenKey).
 func (bf *BuiltinFunctionsWrapper) GetTokenBalance(address []byte, tokenIdentifier []byte, nonce uint64) (*big.Int, error) {
-	account := bf.World.AcctMap.GetAccount(address)
-	return esdtconvert.GetTokenBalance(tokenIdentifier, nonce, account.Storage)
+	account := bf.World.GetAccount(address)
+	return account.GetTokenBalance(tokenIdentifier, nonce)
 }
 
 // GetTokenData gets the ESDT information related to a token from the storage of an account
 // (token keys are built from the token identifier using MakeTokenKey).
 func (bf *BuiltinFunctionsWrapper) GetTokenData(address []byte, tokenIdentifier []byte, nonce uint64) (*esdt.ESDigitalToken, error) {
-	account := bf.World.AcctMap.GetAccount(address)
+	account := bf.World.GetAccount(address)
 	systemAccStorage := make(map[string][]byte)
-	systemAcc := bf.World.AcctMap.GetAccount(vmcommon.SystemAccountAddress)
+	systemAcc := bf.World.GetAccount(vmcommon.SystemAccountAddress)
 	if systemAcc != nil {
+		systemAcc.loadProtectedStorage()
 		systemAccStorage = systemAcc.Storage
 	}
 	return account.GetTokenData(tokenIdentifier, nonce, systemAccStorage)
@@ -34,7 +34,7 @@ func (bf *BuiltinFunctionsWrapper) GetTokenData(address []byte, tokenIdentifier
 // SetTokenData sets the ESDT information related to a token from the storage of an account
 // (token keys are built from the token identifier using MakeTokenKey).
 func (bf *BuiltinFunctionsWrapper) SetTokenData(address []byte, tokenIdentifier []byte, nonce uint64, tokenData *esdt.ESDigitalToken) error {
-	account := bf.World.AcctMap.GetAccount(address)
+	account := bf.World.GetAccount(address)
 	return account.SetTokenData(tokenIdentifier, nonce, tokenData)
 }
 
@@ -10,6 +10,7 @@ import (
 // GetTokenBalance returns the ESDT balance of the account, specified by the
 // token key.
 func (a *Account) GetTokenBalance(tokenIdentifier []byte, nonce uint64) (*big.Int, error) {
+	a.loadProtectedStorage()
 	return esdtconvert.GetTokenBalance(tokenIdentifier, nonce, a.Storage)
 }
 
@@ -26,26 +27,35 @@ func (a *Account) GetTokenBalanceUint64(tokenIdentifier []byte, nonce uint64) (u
 // SetTokenBalance sets the ESDT balance of the account, specified by the token
 // key.
 func (a *Account) SetTokenBalance(tokenIdentifier []byte, nonce uint64, balance *big.Int) error {
+	a.loadProtectedStorage()
+	a.journalWholeStorage()
 	return esdtconvert.SetTokenBalance(tokenIdentifier, nonce, balance, a.Storage)
 }
 
 // SetTokenBalanceUint64 sets the ESDT balance of the account, specified by the
 // token key.
 func (a *Account) SetTokenBalanceUint64(tokenIdentifier []byte, nonce uint64, balance uint64) error {
+	a.loadProtectedStorage()
+	a.journalWholeStorage()
 	return esdtconvert.SetTokenBalance(tokenIdentifier, nonce, big.NewInt(0).SetUint64(balance), a.Storage)
 }
 
 // GetTokenData gets the ESDT information related to a token from the storage of the account.
 func (a *Account) GetTokenData(tokenIdentifier []byte, nonce uint64, systemAccStorage map[string][]byte) (*esdt.ESDigitalToken, error) {
+	a.loadProtectedStorage()
 	return esdtconvert.GetTokenData(tokenIdentifier, nonce, a.Storage, systemAccStorage)
 }
 
 // SetTokenData sets the ESDT information related to a token into the storage of the account.
 func (a *Account) SetTokenData(tokenIdentifier []byte, nonce uint64, tokenData *esdt.ESDigitalToken) error {
+	a.loadProtectedStorage()
+	a.journalWholeStorage()
 	return esdtconvert.SetTokenData(tokenIdentifier, nonce, tokenData, a.Storage)
 }
 
 // SetTokenRolesAsStrings sets the specified roles to the account, corresponding to the given tokenName.
 func (a *Account) SetTokenRolesAsStrings(tokenIdentifier []byte, rolesAsStrings []string) error {
+	a.loadProtectedStorage()
+	a.journalWholeStorage()
 	return esdtconvert.SetTokenRolesAsStrings(tokenIdentifier, rolesAsStrings, a.Storage)
 }
@@ -0,0 +1,29 @@
+package worldmock
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+)
+
+// RandomSeedLength is the length of the block random seeds.
+const RandomSeedLength = 48
+
+// DeriveBlockRandomSeed computes the random seed of a block deterministically,
+// from a master seed and the block nonce.
+func DeriveBlockRandomSeed(masterSeed []byte, blockNonce uint64) []byte {
+	input := make([]byte, len(masterSeed)+8)
+	copy(input, masterSeed)
+	binary.BigEndian.PutUint64(input[len(masterSeed):], blockNonce)
+
+	hash := sha512.Sum512(input)
+	return hash[:RandomSeedLength]
+}
+
+// blockRandomSeed yields the random seed configured explicitly for a block,
+// or else the one derived from the master seed, if there is one.
+func (b *MockWorld) blockRandomSeed(blockInfo *BlockInfo) []byte {
+	if blockInfo.RandomSeed == nil && len(b.RandomSeedMaster) > 0 {
+		return DeriveBlockRandomSeed(b.RandomSeedMaster, blockInfo.BlockNonce)
+	}
+	return blockInfo.GetRandomSeedSlice()
+}
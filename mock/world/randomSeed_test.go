@@ -0,0 +1,47 @@
+package worldmock
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveBlockRandomSeed(t *testing.T) {
+	seed := DeriveBlockRandomSeed([]byte("master"), 5)
+	require.Len(t, seed, RandomSeedLength)
+	require.Equal(t,
+		"02cf1036a7c5c2d1f29b6139a841650576f199aed4fc7e6d759f3cd988276e023879cf5b96c6a9e5dd1475d58b2ec928",
+		hex.EncodeToString(seed))
+
+	require.Equal(t, seed, DeriveBlockRandomSeed([]byte("master"), 5))
+	require.NotEqual(t, seed, DeriveBlockRandomSeed([]byte("master"), 6))
+	require.NotEqual(t, seed, DeriveBlockRandomSeed([]byte("other"), 5))
+	require.Len(t, DeriveBlockRandomSeed(nil, 0), RandomSeedLength)
+}
+
+func TestBlockRandomSeeds(t *testing.T) {
+	world := NewMockWorld()
+	require.Nil(t, world.CurrentRandomSeed())
+	require.Nil(t, world.LastRandomSeed())
+
+	explicitSeed := [48]byte{}
+	copy(explicitSeed[:], bytes.Repeat([]byte{7}, 48))
+	world.PreviousBlockInfo = &BlockInfo{BlockNonce: 4}
+	world.CurrentBlockInfo = &BlockInfo{BlockNonce: 5, RandomSeed: &explicitSeed}
+
+	// without a master seed, unset seeds are zero
+	require.Equal(t, make([]byte, 48), world.LastRandomSeed())
+	require.Equal(t, explicitSeed[:], world.CurrentRandomSeed())
+
+	// with a master seed, unset seeds are derived, explicit seeds are kept
+	world.PreviousBlockInfo = &BlockInfo{BlockNonce: 4}
+	world.RandomSeedMaster = []byte("master")
+	require.Equal(t, DeriveBlockRandomSeed([]byte("master"), 4), world.LastRandomSeed())
+	require.Equal(t, explicitSeed[:], world.CurrentRandomSeed())
+
+	world.CurrentBlockInfo.RandomSeed = nil
+	require.Equal(t, DeriveBlockRandomSeed([]byte("master"), 5), world.CurrentRandomSeed())
+	require.NotEqual(t, world.LastRandomSeed(), world.CurrentRandomSeed())
+}
@@ -0,0 +1,215 @@
+package worldmock
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+
+	triemock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/trie"
+)
+
+// StateTrie computes the state root hash of the world, from a Patricia Merkle trie of the accounts,
+// each of them holding the root hash of a data trie of its storage.
+// It keeps the tries between commits and only updates the accounts and the storage keys
+// marked as changed since the previous commit. The world marks the changes it makes itself;
+// accounts changed directly in the account map need to be marked by the caller.
+type StateTrie struct {
+	accountsTrie    *triemock.PatriciaMerkleTrie
+	encodedAccounts map[string][]byte
+	dataTries       map[string]*accountDataTrie
+	changes         changedAccounts
+	allChanged      bool
+}
+
+type accountDataTrie struct {
+	trie    *triemock.PatriciaMerkleTrie
+	storage map[string][]byte
+	account *Account
+}
+
+// NewStateTrie creates a StateTrie with no accounts.
+// All the accounts given at the first commit are added.
+func NewStateTrie() *StateTrie {
+	return &StateTrie{
+		accountsTrie:    triemock.NewPatriciaMerkleTrie(DefaultHasher),
+		encodedAccounts: make(map[string][]byte),
+		dataTries:       make(map[string]*accountDataTrie),
+		changes:         make(changedAccounts),
+		allChanged:      true,
+	}
+}
+
+// MarkAccountChanged marks the fields of an account as changed, or the account as created or removed.
+func (st *StateTrie) MarkAccountChanged(address []byte) {
+	st.changes.account(address)
+}
+
+// MarkStorageChanged marks a storage key of an account as changed.
+func (st *StateTrie) MarkStorageChanged(address []byte, key string) {
+	st.changes.markStorageKey(address, key)
+}
+
+// MarkWholeStorageChanged marks the account as changed, with any of its storage keys.
+func (st *StateTrie) MarkWholeStorageChanged(address []byte) {
+	st.changes.markWholeStorage(address)
+}
+
+// MarkAllChanged makes the next commit compare all the accounts and all their storage.
+func (st *StateTrie) MarkAllChanged() {
+	st.allChanged = true
+}
+
+// Update brings the tries up to date with the changed accounts, sets their RootHash
+// and yields the state root hash.
+func (st *StateTrie) Update(accounts AccountMap) []byte {
+	rootHash := st.applyChanges(accounts, true)
+	st.changes = make(changedAccounts)
+	st.allChanged = false
+	return rootHash
+}
+
+// RootHash yields the state root hash the next Update would yield,
+// without changing the tries or the accounts.
+func (st *StateTrie) RootHash(accounts AccountMap) []byte {
+	return st.applyChanges(accounts, false)
+}
+
+// applyChanges updates the tries with the changed accounts and yields the state root hash.
+// Unless committing, it works on copies of the tries, which share all unchanged nodes with them.
+func (st *StateTrie) applyChanges(accounts AccountMap, commit bool) []byte {
+	changes := st.changes
+	if st.allChanged {
+		changes = st.allAccountsChanged(accounts)
+	}
+
+	accountsTrie := st.accountsTrie
+	if !commit {
+		accountsTrie = accountsTrie.Clone()
+	}
+
+	for address, accountChanges := range changes {
+		account, exists := accounts[address]
+		if !exists {
+			if _, found := st.encodedAccounts[address]; found {
+				accountsTrie.Delete([]byte(address))
+			}
+			if commit {
+				delete(st.encodedAccounts, address)
+				delete(st.dataTries, address)
+			}
+			continue
+		}
+
+		rootHash := st.applyStorageChanges(address, account, accountChanges, commit)
+		encodedAccount := encodeAccountForTrie(account, rootHash)
+		if !bytes.Equal(st.encodedAccounts[address], encodedAccount) {
+			accountsTrie.Update([]byte(address), encodedAccount)
+			if commit {
+				st.encodedAccounts[address] = encodedAccount
+			}
+		}
+		if commit {
+			account.RootHash = rootHash
+		}
+	}
+
+	return accountsTrie.RootHash()
+}
+
+// applyStorageChanges updates the data trie of an account and yields its root hash.
+// The whole storage is compared when marked so, and for accounts that are new
+// or were replaced by another object since the previous commit.
+func (st *StateTrie) applyStorageChanges(address string, account *Account, changes *accountChanges, commit bool) []byte {
+	dataTrie, found := st.dataTries[address]
+	if !found {
+		dataTrie = &accountDataTrie{
+			trie:    triemock.NewPatriciaMerkleTrie(DefaultHasher),
+			storage: make(map[string][]byte),
+		}
+	}
+
+	trie := dataTrie.trie
+	if !commit {
+		trie = trie.Clone()
+	}
+
+	updateKey := func(key string, value []byte) {
+		if bytes.Equal(dataTrie.storage[key], value) {
+			return
+		}
+		trie.Update([]byte(key), value)
+		if !commit {
+			return
+		}
+		if len(value) == 0 {
+			delete(dataTrie.storage, key)
+		} else {
+			dataTrie.storage[key] = cloneBytes(value)
+		}
+	}
+
+	if changes.wholeStorage || dataTrie.account != account {
+		account.LoadStorage()
+		for key, value := range account.Storage {
+			updateKey(key, value)
+		}
+		for key := range dataTrie.storage {
+			if _, exists := account.Storage[key]; !exists {
+				updateKey(key, nil)
+			}
+		}
+	} else {
+		for key := range changes.keys {
+			value, _ := account.storageValue(key)
+			updateKey(key, value)
+		}
+	}
+
+	if commit {
+		dataTrie.account = account
+		st.dataTries[address] = dataTrie
+	}
+	return trie.RootHash()
+}
+
+func (st *StateTrie) allAccountsChanged(accounts AccountMap) changedAccounts {
+	changes := make(changedAccounts, len(accounts))
+	for address := range accounts {
+		changes.markWholeStorage([]byte(address))
+	}
+	for address := range st.encodedAccounts {
+		changes.markWholeStorage([]byte(address))
+	}
+	return changes
+}
+
+// encodeAccountForTrie serializes the account fields that are part of the state, length-prefixed.
+func encodeAccountForTrie(account *Account, rootHash []byte) []byte {
+	var encoded []byte
+	appendField := func(field []byte) {
+		lengthPrefix := make([]byte, 4)
+		binary.BigEndian.PutUint32(lengthPrefix, uint32(len(field)))
+		encoded = append(encoded, lengthPrefix...)
+		encoded = append(encoded, field...)
+	}
+
+	nonce := make([]byte, 8)
+	binary.BigEndian.PutUint64(nonce, account.Nonce)
+	appendField(nonce)
+	appendField(bigIntBytes(account.Balance))
+	appendField(account.CodeHash)
+	appendField(account.CodeMetadata)
+	appendField(rootHash)
+	appendField(account.OwnerAddress)
+	appendField(account.Username)
+	appendField(bigIntBytes(account.DeveloperReward))
+
+	return encoded
+}
+
+func bigIntBytes(value *big.Int) []byte {
+	if value == nil {
+		return nil
+	}
+	return value.Bytes()
+}
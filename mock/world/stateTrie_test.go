@@ -0,0 +1,175 @@
+package worldmock
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	triemock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/trie"
+	"github.com/stretchr/testify/require"
+)
+
+// newStateTrieTestWorld creates a world with a few accounts and the state trie enabled
+func newStateTrieTestWorld() *MockWorld {
+	world := NewMockWorld()
+	owner := world.AcctMap.CreateAccount([]byte("owner___________________________"), world)
+	owner.Nonce = 5
+	owner.Balance = big.NewInt(1000)
+
+	contract := world.AcctMap.CreateSmartContractAccount(
+		owner.Address, []byte("contract________________________"), []byte("code"), world)
+	contract.Storage["counter"] = []byte{7}
+	contract.Storage["name"] = []byte("contract name")
+
+	world.EnableStateTrie()
+	return world
+}
+
+// rebuiltRootHash computes the state root hash from scratch, with a new trie
+func rebuiltRootHash(world *MockWorld) []byte {
+	return NewStateTrie().Update(world.AcctMap.Clone())
+}
+
+func TestStateTrie_KnownRootHashes(t *testing.T) {
+	world := NewMockWorld()
+	world.EnableStateTrie()
+	require.Equal(t, triemock.EmptyRootHash(DefaultHasher), world.StateRootHash)
+	require.Equal(t,
+		"0e5751c026e543b2e8ab2eb06099daa1d1e5df47778f7787faab45cdf12fe3a8",
+		hex.EncodeToString(world.StateRootHash))
+
+	world = newStateTrieTestWorld()
+	require.Equal(t,
+		"c53c70bcd7c83487d4d4a6559863c7b0cfe43e12a4ca007c2a9ef33fc049ce87",
+		hex.EncodeToString(world.StateRootHash))
+	contract := world.AcctMap.GetAccount([]byte("contract________________________"))
+	require.Equal(t,
+		"8826b4bfd04cf8a1e86e5acf3f503be250fefc74ed02a1930348dd87c6cca657",
+		hex.EncodeToString(contract.RootHash))
+	owner := world.AcctMap.GetAccount([]byte("owner___________________________"))
+	require.Equal(t, triemock.EmptyRootHash(DefaultHasher), owner.RootHash)
+
+	require.Nil(t, contract.SaveKeyValue([]byte("counter"), []byte{8}))
+	require.Nil(t, owner.AddToBalance(big.NewInt(1)))
+	world.CommitStateTrie()
+	require.Equal(t,
+		"23ad1ccc46235a81105534b6d271f92e795e323ac520d2b4f5113880e35904c4",
+		hex.EncodeToString(world.StateRootHash))
+	require.Equal(t,
+		"a28242851f3782537806c60de9a611d30a1e58ff09af680cd74ea602c6d66642",
+		hex.EncodeToString(contract.RootHash))
+}
+
+func TestStateTrie_OnlyMarkedChangesAreCommitted(t *testing.T) {
+	world := newStateTrieTestWorld()
+	rootHash := world.StateRootHash
+	contract := world.AcctMap.GetAccount([]byte("contract________________________"))
+
+	// changes made directly in the account map go unnoticed
+	contract.Storage["counter"] = []byte{8}
+	contract.Nonce = 1
+	world.CommitStateTrie()
+	require.Equal(t, rootHash, world.StateRootHash)
+
+	world.MarkAccountChanged(contract.Address)
+	world.CommitStateTrie()
+	require.NotEqual(t, rootHash, world.StateRootHash)
+	require.Equal(t, rebuiltRootHash(world), world.StateRootHash)
+
+	// a key marked as changed is the only one compared
+	contract.Storage["name"] = nil
+	contract.Storage["other"] = []byte("other")
+	world.StateTrie.MarkStorageChanged(contract.Address, "other")
+	world.CommitStateTrie()
+	require.NotEqual(t, rebuiltRootHash(world), world.StateRootHash)
+
+	world.StateTrie.MarkAllChanged()
+	world.CommitStateTrie()
+	require.Equal(t, rebuiltRootHash(world), world.StateRootHash)
+}
+
+func TestStateTrie_IncrementalUpdatesMatchRebuild(t *testing.T) {
+	world := newStateTrieTestWorld()
+	random := rand.New(rand.NewSource(42))
+	addresses := make([][]byte, 20)
+	for i := range addresses {
+		addresses[i] = []byte(fmt.Sprintf("address-%02d______________________", i))
+	}
+
+	for round := 0; round < 30; round++ {
+		for change := 0; change < 5; change++ {
+			address := addresses[random.Intn(len(addresses))]
+			account := world.GetAccount(address)
+			switch {
+			case account == nil:
+				account = world.AcctMap.CreateAccount(address, world)
+				account.Storage["created"] = []byte{byte(round)}
+			case random.Intn(10) == 0:
+				require.Nil(t, world.AccountsAdapter.RemoveAccount(address))
+			case random.Intn(2) == 0:
+				require.Nil(t, account.AddToBalance(big.NewInt(int64(random.Intn(100)))))
+			default:
+				key := []byte(fmt.Sprintf("key%d", random.Intn(4)))
+				value := []byte{byte(random.Intn(3))}
+				if value[0] == 0 {
+					value = nil
+				}
+				require.Nil(t, account.SaveKeyValue(key, value))
+			}
+		}
+
+		_, err := world.AccountsAdapter.Commit()
+		require.Nil(t, err)
+		require.Equal(t, rebuiltRootHash(world), world.StateRootHash, "round %d", round)
+	}
+}
+
+func TestStateTrie_RevertedChanges(t *testing.T) {
+	world := newStateTrieTestWorld()
+	rootHash := world.StateRootHash
+	contract := world.AcctMap.GetAccount([]byte("contract________________________"))
+
+	world.CreateStateBackup()
+	require.Nil(t, contract.SaveKeyValue([]byte("counter"), []byte{9}))
+	require.Nil(t, contract.AddToBalance(big.NewInt(10)))
+	world.AcctMap.CreateAccount([]byte("new_____________________________"), world)
+	require.Nil(t, world.AccountsAdapter.RevertToSnapshot(0))
+
+	world.CommitStateTrie()
+	require.Equal(t, rootHash, world.StateRootHash)
+}
+
+func TestMockAccountsAdapter_RootHashLeavesTheStateUnchanged(t *testing.T) {
+	world := newStateTrieTestWorld()
+	committedRootHash := world.StateRootHash
+	contract := world.AcctMap.GetAccount([]byte("contract________________________"))
+	contractRootHash := contract.RootHash
+
+	rootHash, err := world.AccountsAdapter.RootHash()
+	require.Nil(t, err)
+	require.Equal(t, committedRootHash, rootHash)
+
+	require.Nil(t, contract.SaveKeyValue([]byte("counter"), []byte{8}))
+	world.AcctMap.CreateAccount([]byte("new_____________________________"), world)
+	require.Nil(t, world.AccountsAdapter.RemoveAccount([]byte("owner___________________________")))
+
+	pendingRootHash, err := world.AccountsAdapter.RootHash()
+	require.Nil(t, err)
+	require.NotEqual(t, committedRootHash, pendingRootHash)
+	require.Equal(t, rebuiltRootHash(world), pendingRootHash)
+
+	// nothing was committed
+	require.Equal(t, committedRootHash, world.StateRootHash)
+	require.Equal(t, contractRootHash, contract.RootHash)
+	sameRootHash, err := world.AccountsAdapter.RootHash()
+	require.Nil(t, err)
+	require.Equal(t, pendingRootHash, sameRootHash)
+
+	// the changes are still committed afterwards
+	committedRootHash, err = world.AccountsAdapter.Commit()
+	require.Nil(t, err)
+	require.Equal(t, pendingRootHash, committedRootHash)
+	require.NotEqual(t, contractRootHash, contract.RootHash)
+}
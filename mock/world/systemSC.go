@@ -0,0 +1,101 @@
+package worldmock
+
+import (
+	"bytes"
+	"math/big"
+
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+)
+
+// SystemSCCodePrefix marks the code of the accounts backed by Go-native system smart contract mocks.
+// The VM never compiles this code, the instance builder recognizes it and runs the mock instead.
+const SystemSCCodePrefix = "system_sc_mock:"
+
+// SystemSCContext gives the system smart contract mocks access to the call they are serving.
+// All the changes made by the mocks go through it, so they end up in the output of the call,
+// and are committed or reverted together with the rest of the transaction.
+type SystemSCContext interface {
+	Input() *vmcommon.ContractCallInput
+	GasLeft() uint64
+	Finish(data []byte)
+	SignalError(message string)
+	GetStorage(key []byte) ([]byte, error)
+	SetStorage(key []byte, value []byte) error
+	TransferValue(destination []byte, value *big.Int) error
+	TransferESDT(destination []byte, tokenID []byte, value *big.Int) error
+	ExecuteBuiltin(recipient []byte, function string, args [][]byte, gasLimit uint64) error
+}
+
+// SystemSCFunction is an endpoint of a system smart contract mock.
+type SystemSCFunction func(ctx SystemSCContext)
+
+// SystemSCMock is a Go-native replacement for a system smart contract, callable from contracts like any other contract.
+type SystemSCMock struct {
+	Name      string
+	Address   []byte
+	Functions map[string]SystemSCFunction
+}
+
+// Code yields the code that the account of the mock holds.
+func (scMock *SystemSCMock) Code() []byte {
+	return append([]byte(SystemSCCodePrefix), scMock.Name...)
+}
+
+// RegisterSystemSCMock makes the mock callable at its address, and creates the account backing it.
+// The account is recreated every time the world is cleared.
+func (b *MockWorld) RegisterSystemSCMock(scMock *SystemSCMock) {
+	if b.SystemSCMocks == nil {
+		b.SystemSCMocks = make(map[string]*SystemSCMock)
+	}
+	b.SystemSCMocks[string(scMock.Address)] = scMock
+	b.createSystemSCMockAccount(scMock)
+}
+
+func (b *MockWorld) createSystemSCMockAccounts() {
+	for _, scMock := range b.SystemSCMocks {
+		b.createSystemSCMockAccount(scMock)
+	}
+}
+
+func (b *MockWorld) createSystemSCMockAccount(scMock *SystemSCMock) {
+	account := b.GetAccount(scMock.Address)
+	if account != nil && bytes.Equal(account.Code, scMock.Code()) {
+		return
+	}
+
+	account = b.AcctMap.CreateAccount(scMock.Address, b)
+	account.SetCodeAndMetadata(scMock.Code(), &vmcommon.CodeMetadata{
+		Payable: true,
+	})
+	account.ShardID = b.SelfShardID
+}
+
+// GetSystemSCMockByCode yields the mock whose account holds the given code, or nil if the code does not belong to a mock.
+func (b *MockWorld) GetSystemSCMockByCode(code []byte) *SystemSCMock {
+	if !bytes.HasPrefix(code, []byte(SystemSCCodePrefix)) {
+		return nil
+	}
+
+	for _, scMock := range b.SystemSCMocks {
+		if bytes.Equal(scMock.Code(), code) {
+			return scMock
+		}
+	}
+	return nil
+}
+
+// IsSystemSCMockAddress returns true if the address belongs to a registered system smart contract mock.
+func (b *MockWorld) IsSystemSCMockAddress(address []byte) bool {
+	_, found := b.SystemSCMocks[string(address)]
+	return found
+}
+
+// RegisterDefaultSystemSCMocks registers the mocks of the ESDT system smart contract,
+// of all the DNS contracts and of the staking (validator) system smart contract.
+func (b *MockWorld) RegisterDefaultSystemSCMocks() {
+	b.RegisterSystemSCMock(NewESDTSystemSCMock())
+	for dnsAddress := range makeDNSAddresses(numDNSAddresses) {
+		b.RegisterSystemSCMock(NewDNSMock([]byte(dnsAddress)))
+	}
+	b.RegisterSystemSCMock(NewStakingSystemSCMock())
+}
@@ -0,0 +1,73 @@
+package worldmock
+
+import (
+	"encoding/hex"
+
+	"github.com/multiversx/mx-chain-core-go/core"
+)
+
+const dnsMockNameKeyPrefix = "name:"
+
+type dnsMock struct{}
+
+// NewDNSMock creates a mock of one of the DNS contracts, at the given address.
+// Registering a name sets the user name of the caller, through the SetUserName builtin function.
+func NewDNSMock(address []byte) *SystemSCMock {
+	dns := &dnsMock{}
+	return &SystemSCMock{
+		Name:    "dns:" + hex.EncodeToString(address),
+		Address: address,
+		Functions: map[string]SystemSCFunction{
+			"register": dns.register,
+			"resolve":  dns.resolve,
+		},
+	}
+}
+
+// register@name
+func (dns *dnsMock) register(ctx SystemSCContext) {
+	args := ctx.Input().Arguments
+	if len(args) != 1 || len(args[0]) == 0 {
+		ctx.SignalError("invalid name")
+		return
+	}
+
+	nameKey := []byte(dnsMockNameKeyPrefix + string(args[0]))
+	owner, err := ctx.GetStorage(nameKey)
+	if err != nil {
+		ctx.SignalError(err.Error())
+		return
+	}
+	if len(owner) > 0 {
+		ctx.SignalError("name already taken")
+		return
+	}
+
+	caller := ctx.Input().CallerAddr
+	err = ctx.ExecuteBuiltin(caller, core.BuiltInFunctionSetUserName, [][]byte{args[0]}, ctx.GasLeft())
+	if err != nil {
+		ctx.SignalError(err.Error())
+		return
+	}
+
+	err = ctx.SetStorage(nameKey, caller)
+	if err != nil {
+		ctx.SignalError(err.Error())
+	}
+}
+
+// resolve@name
+func (dns *dnsMock) resolve(ctx SystemSCContext) {
+	args := ctx.Input().Arguments
+	if len(args) != 1 {
+		ctx.SignalError("invalid number of arguments")
+		return
+	}
+
+	owner, err := ctx.GetStorage([]byte(dnsMockNameKeyPrefix + string(args[0])))
+	if err != nil {
+		ctx.SignalError(err.Error())
+		return
+	}
+	ctx.Finish(owner)
+}
@@ -0,0 +1,313 @@
+package worldmock
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/multiversx/mx-chain-core-go/core"
+	"github.com/multiversx/mx-chain-core-go/data/vm"
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+)
+
+const (
+	esdtMockNrIssuedTokensKey = "nrIssuedTokens"
+	esdtMockOwnerKeyPrefix    = "owner:"
+	esdtMockTypeKeyPrefix     = "type:"
+	esdtMockPausedKeyPrefix   = "paused:"
+	esdtMockFrozenKeyPrefix   = "frozen:"
+)
+
+// not yet defined in core
+const metaESDT = "MetaESDT"
+
+const (
+	minTickerLength = 3
+	maxTickerLength = 10
+)
+
+const tokenIDSuffixLength = 6
+
+type esdtSystemSCMock struct{}
+
+// NewESDTSystemSCMock creates a mock of the ESDT system smart contract, at its real address.
+// It supports token issuance, role assignment, pause and freeze.
+// Token identifiers are generated deterministically: the suffix is derived from the number of tokens issued before.
+func NewESDTSystemSCMock() *SystemSCMock {
+	esdtMock := &esdtSystemSCMock{}
+	return &SystemSCMock{
+		Name:    "esdt",
+		Address: core.ESDTSCAddress,
+		Functions: map[string]SystemSCFunction{
+			"issue":             esdtMock.issueFungible,
+			"issueSemiFungible": esdtMock.issueWithoutSupply(core.SemiFungibleESDT),
+			"issueNonFungible":  esdtMock.issueWithoutSupply(core.NonFungibleESDT),
+			"registerMetaESDT":  esdtMock.issueWithoutSupply(metaESDT),
+			"setSpecialRole":    esdtMock.changeRoles(core.BuiltInFunctionSetESDTRole),
+			"unSetSpecialRole":  esdtMock.changeRoles(core.BuiltInFunctionUnSetESDTRole),
+			"pause":             esdtMock.setPaused(true),
+			"unPause":           esdtMock.setPaused(false),
+			"freeze":            esdtMock.setFrozen(true),
+			"unFreeze":          esdtMock.setFrozen(false),
+		},
+	}
+}
+
+// issue@name@ticker@initialSupply@decimals@properties...
+func (esdtMock *esdtSystemSCMock) issueFungible(ctx SystemSCContext) {
+	args := ctx.Input().Arguments
+	if len(args) < 4 {
+		ctx.SignalError("not enough arguments")
+		return
+	}
+
+	tokenID, err := esdtMock.registerToken(ctx, args[1], core.FungibleESDT)
+	if err != nil {
+		ctx.SignalError(err.Error())
+		return
+	}
+
+	initialSupply := big.NewInt(0).SetBytes(args[2])
+	if initialSupply.Sign() > 0 {
+		err = sendInitialSupply(ctx, tokenID, initialSupply)
+		if err != nil {
+			ctx.SignalError(err.Error())
+			return
+		}
+	}
+
+	ctx.Finish(tokenID)
+}
+
+// sendInitialSupply sends the new tokens to the caller as an ESDT transfer, the way the metachain does.
+// A contract waiting for a callback receives them as the ESDT payment of the callback:
+// the VM recognizes the transfer when it is the first result of the asynchronous call.
+func sendInitialSupply(ctx SystemSCContext, tokenID []byte, initialSupply *big.Int) error {
+	if ctx.Input().CallType == vm.AsynchronousCall {
+		ctx.Finish([]byte(core.BuiltInFunctionESDTTransfer + "@" + hex.EncodeToString(tokenID) + "@" + hex.EncodeToString(initialSupply.Bytes())))
+		return nil
+	}
+
+	return ctx.TransferESDT(ctx.Input().CallerAddr, tokenID, initialSupply)
+}
+
+// issueNonFungible@name@ticker, issueSemiFungible@name@ticker, registerMetaESDT@name@ticker@decimals
+func (esdtMock *esdtSystemSCMock) issueWithoutSupply(tokenType string) SystemSCFunction {
+	return func(ctx SystemSCContext) {
+		args := ctx.Input().Arguments
+		if len(args) < 2 {
+			ctx.SignalError("not enough arguments")
+			return
+		}
+
+		tokenID, err := esdtMock.registerToken(ctx, args[1], tokenType)
+		if err != nil {
+			ctx.SignalError(err.Error())
+			return
+		}
+
+		ctx.Finish(tokenID)
+	}
+}
+
+func (esdtMock *esdtSystemSCMock) registerToken(ctx SystemSCContext, ticker []byte, tokenType string) ([]byte, error) {
+	if !isTickerValid(ticker) {
+		return nil, errors.New("Invalid token ID")
+	}
+
+	nrIssuedTokensBytes, err := ctx.GetStorage([]byte(esdtMockNrIssuedTokensKey))
+	if err != nil {
+		return nil, err
+	}
+	nrIssuedTokens := big.NewInt(0).SetBytes(nrIssuedTokensBytes)
+	tokenID := []byte(fmt.Sprintf("%s-%s", ticker, tokenIDSuffix(nrIssuedTokens)))
+
+	ownerKey := []byte(esdtMockOwnerKeyPrefix + string(tokenID))
+	owner, err := ctx.GetStorage(ownerKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(owner) > 0 {
+		return nil, errors.New("token already registered")
+	}
+
+	err = ctx.SetStorage([]byte(esdtMockNrIssuedTokensKey), nrIssuedTokens.Add(nrIssuedTokens, big.NewInt(1)).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.SetStorage(ownerKey, ctx.Input().CallerAddr)
+	if err != nil {
+		return nil, err
+	}
+	err = ctx.SetStorage([]byte(esdtMockTypeKeyPrefix+string(tokenID)), []byte(tokenType))
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenID, nil
+}
+
+// tokenIDSuffix derives the suffix of a new token identifier from the number of tokens issued before it.
+func tokenIDSuffix(nrIssuedTokens *big.Int) string {
+	hash := DefaultHasher.Compute(nrIssuedTokens.String())
+	return hex.EncodeToString(hash)[:tokenIDSuffixLength]
+}
+
+func isTickerValid(ticker []byte) bool {
+	if len(ticker) < minTickerLength || len(ticker) > maxTickerLength {
+		return false
+	}
+	for _, ch := range ticker {
+		isUpperCaseLetter := ch >= 'A' && ch <= 'Z'
+		isDigit := ch >= '0' && ch <= '9'
+		if !isUpperCaseLetter && !isDigit {
+			return false
+		}
+	}
+	return true
+}
+
+func (esdtMock *esdtSystemSCMock) checkOwner(ctx SystemSCContext, tokenID []byte) bool {
+	owner, err := ctx.GetStorage([]byte(esdtMockOwnerKeyPrefix + string(tokenID)))
+	if err != nil {
+		ctx.SignalError(err.Error())
+		return false
+	}
+	if len(owner) == 0 {
+		ctx.SignalError("no such token")
+		return false
+	}
+	if string(owner) != string(ctx.Input().CallerAddr) {
+		ctx.SignalError("can be called by owner only")
+		return false
+	}
+	return true
+}
+
+// setSpecialRole@tokenID@address@roles..., unSetSpecialRole@tokenID@address@roles...
+func (esdtMock *esdtSystemSCMock) changeRoles(builtinFunction string) SystemSCFunction {
+	return func(ctx SystemSCContext) {
+		args := ctx.Input().Arguments
+		if len(args) < 3 {
+			ctx.SignalError("not enough arguments")
+			return
+		}
+		tokenID := args[0]
+		if !esdtMock.checkOwner(ctx, tokenID) {
+			return
+		}
+		for _, role := range args[2:] {
+			if !isRoleValid(string(role)) {
+				ctx.SignalError(fmt.Sprintf("invalid role: %s", role))
+				return
+			}
+		}
+
+		builtinArgs := append([][]byte{tokenID}, args[2:]...)
+		err := executeESDTBuiltin(ctx, args[1], builtinFunction, builtinArgs)
+		if err != nil {
+			ctx.SignalError(err.Error())
+		}
+	}
+}
+
+func isRoleValid(role string) bool {
+	switch role {
+	case core.ESDTRoleLocalMint,
+		core.ESDTRoleLocalBurn,
+		core.ESDTRoleNFTCreate,
+		core.ESDTRoleNFTAddQuantity,
+		core.ESDTRoleNFTBurn,
+		core.ESDTRoleNFTAddURI,
+		core.ESDTRoleNFTUpdateAttributes,
+		core.ESDTRoleTransfer,
+		core.ESDTRoleNFTCreateMultiShard:
+		return true
+	default:
+		return false
+	}
+}
+
+// pause@tokenID, unPause@tokenID
+func (esdtMock *esdtSystemSCMock) setPaused(paused bool) SystemSCFunction {
+	builtinFunction := core.BuiltInFunctionESDTUnPause
+	if paused {
+		builtinFunction = core.BuiltInFunctionESDTPause
+	}
+
+	return func(ctx SystemSCContext) {
+		args := ctx.Input().Arguments
+		if len(args) != 1 {
+			ctx.SignalError("invalid number of arguments")
+			return
+		}
+		tokenID := args[0]
+		if !esdtMock.checkOwner(ctx, tokenID) {
+			return
+		}
+
+		pausedKey := []byte(esdtMockPausedKeyPrefix + string(tokenID))
+		esdtMock.toggleFlag(ctx, pausedKey, paused, func() error {
+			return executeESDTBuiltin(ctx, vmcommon.SystemAccountAddress, builtinFunction, [][]byte{tokenID})
+		})
+	}
+}
+
+// freeze@tokenID@address, unFreeze@tokenID@address
+func (esdtMock *esdtSystemSCMock) setFrozen(frozen bool) SystemSCFunction {
+	builtinFunction := core.BuiltInFunctionESDTUnFreeze
+	if frozen {
+		builtinFunction = core.BuiltInFunctionESDTFreeze
+	}
+
+	return func(ctx SystemSCContext) {
+		args := ctx.Input().Arguments
+		if len(args) != 2 {
+			ctx.SignalError("invalid number of arguments")
+			return
+		}
+		tokenID := args[0]
+		if !esdtMock.checkOwner(ctx, tokenID) {
+			return
+		}
+
+		frozenKey := []byte(esdtMockFrozenKeyPrefix + string(tokenID) + ":" + hex.EncodeToString(args[1]))
+		esdtMock.toggleFlag(ctx, frozenKey, frozen, func() error {
+			return executeESDTBuiltin(ctx, args[1], builtinFunction, [][]byte{tokenID})
+		})
+	}
+}
+
+// toggleFlag calls the builtin function that applies the flag, unless the flag already has the requested value.
+func (esdtMock *esdtSystemSCMock) toggleFlag(ctx SystemSCContext, key []byte, value bool, apply func() error) {
+	flag, err := ctx.GetStorage(key)
+	if err != nil {
+		ctx.SignalError(err.Error())
+		return
+	}
+	if (len(flag) > 0) == value {
+		return
+	}
+
+	err = apply()
+	if err != nil {
+		ctx.SignalError(err.Error())
+		return
+	}
+
+	var flagValue []byte
+	if value {
+		flagValue = []byte{1}
+	}
+	err = ctx.SetStorage(key, flagValue)
+	if err != nil {
+		ctx.SignalError(err.Error())
+	}
+}
+
+// executeESDTBuiltin calls a builtin function on behalf of the ESDT system smart contract.
+// The metachain sends these calls as separate transactions, so the caller does not pay for their gas.
+func executeESDTBuiltin(ctx SystemSCContext, recipient []byte, function string, args [][]byte) error {
+	return ctx.ExecuteBuiltin(recipient, function, args, 0)
+}
@@ -0,0 +1,141 @@
+package worldmock
+
+import (
+	"math/big"
+)
+
+// ValidatorSCAddress is the address of the validator (staking/auction) system smart contract.
+var ValidatorSCAddress = []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 255, 255}
+
+const (
+	stakingMockStakedKeyPrefix   = "staked:"
+	stakingMockUnStakedKeyPrefix = "unStaked:"
+)
+
+type stakingSystemSCMock struct{}
+
+// NewStakingSystemSCMock creates a mock of the validator system smart contract, at its real address.
+// It only keeps track of the amounts staked, unstaked and unbonded by each caller, there are no nodes or epochs involved.
+func NewStakingSystemSCMock() *SystemSCMock {
+	stakingMock := &stakingSystemSCMock{}
+	return &SystemSCMock{
+		Name:    "staking",
+		Address: ValidatorSCAddress,
+		Functions: map[string]SystemSCFunction{
+			"stake":            stakingMock.stake,
+			"unStake":          stakingMock.unStake,
+			"unBond":           stakingMock.unBond,
+			"getTotalStaked":   stakingMock.getAmount(stakingMockStakedKeyPrefix),
+			"getTotalUnStaked": stakingMock.getAmount(stakingMockUnStakedKeyPrefix),
+		},
+	}
+}
+
+func (stakingMock *stakingSystemSCMock) getValue(ctx SystemSCContext, key string) (*big.Int, error) {
+	value, err := ctx.GetStorage([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+	return big.NewInt(0).SetBytes(value), nil
+}
+
+func (stakingMock *stakingSystemSCMock) setValue(ctx SystemSCContext, key string, value *big.Int) error {
+	return ctx.SetStorage([]byte(key), value.Bytes())
+}
+
+// stake, with the amount as call value
+func (stakingMock *stakingSystemSCMock) stake(ctx SystemSCContext) {
+	value := ctx.Input().CallValue
+	if value == nil || value.Sign() <= 0 {
+		ctx.SignalError("nothing to stake")
+		return
+	}
+
+	stakedKey := stakingMockStakedKeyPrefix + string(ctx.Input().CallerAddr)
+	staked, err := stakingMock.getValue(ctx, stakedKey)
+	if err != nil {
+		ctx.SignalError(err.Error())
+		return
+	}
+	err = stakingMock.setValue(ctx, stakedKey, staked.Add(staked, value))
+	if err != nil {
+		ctx.SignalError(err.Error())
+	}
+}
+
+// unStake@amount, or everything if no amount is given
+func (stakingMock *stakingSystemSCMock) unStake(ctx SystemSCContext) {
+	caller := string(ctx.Input().CallerAddr)
+	stakedKey := stakingMockStakedKeyPrefix + caller
+	unStakedKey := stakingMockUnStakedKeyPrefix + caller
+
+	staked, err := stakingMock.getValue(ctx, stakedKey)
+	if err != nil {
+		ctx.SignalError(err.Error())
+		return
+	}
+	amount := big.NewInt(0).Set(staked)
+	if len(ctx.Input().Arguments) > 0 {
+		amount.SetBytes(ctx.Input().Arguments[0])
+	}
+	if amount.Sign() == 0 || amount.Cmp(staked) > 0 {
+		ctx.SignalError("invalid unStake amount")
+		return
+	}
+
+	unStaked, err := stakingMock.getValue(ctx, unStakedKey)
+	if err != nil {
+		ctx.SignalError(err.Error())
+		return
+	}
+	err = stakingMock.setValue(ctx, stakedKey, staked.Sub(staked, amount))
+	if err != nil {
+		ctx.SignalError(err.Error())
+		return
+	}
+	err = stakingMock.setValue(ctx, unStakedKey, unStaked.Add(unStaked, amount))
+	if err != nil {
+		ctx.SignalError(err.Error())
+	}
+}
+
+// unBond sends all the unstaked amount back to the caller
+func (stakingMock *stakingSystemSCMock) unBond(ctx SystemSCContext) {
+	caller := ctx.Input().CallerAddr
+	unStakedKey := stakingMockUnStakedKeyPrefix + string(caller)
+	unStaked, err := stakingMock.getValue(ctx, unStakedKey)
+	if err != nil {
+		ctx.SignalError(err.Error())
+		return
+	}
+	if unStaked.Sign() == 0 {
+		ctx.SignalError("nothing to unBond")
+		return
+	}
+
+	err = ctx.TransferValue(caller, unStaked)
+	if err != nil {
+		ctx.SignalError(err.Error())
+		return
+	}
+	err = stakingMock.setValue(ctx, unStakedKey, big.NewInt(0))
+	if err != nil {
+		ctx.SignalError(err.Error())
+	}
+}
+
+// getTotalStaked@address, getTotalUnStaked@address, the caller if no address is given
+func (stakingMock *stakingSystemSCMock) getAmount(keyPrefix string) SystemSCFunction {
+	return func(ctx SystemSCContext) {
+		address := ctx.Input().CallerAddr
+		if len(ctx.Input().Arguments) > 0 {
+			address = ctx.Input().Arguments[0]
+		}
+		amount, err := stakingMock.getValue(ctx, keyPrefix+string(address))
+		if err != nil {
+			ctx.SignalError(err.Error())
+			return
+		}
+		ctx.Finish(amount.Bytes())
+	}
+}
@@ -0,0 +1,589 @@
+package worldmock
+
+import (
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/multiversx/mx-chain-core-go/core"
+	"github.com/multiversx/mx-chain-core-go/data/vm"
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+	"github.com/stretchr/testify/require"
+)
+
+var testSystemSCCaller = []byte("caller__________________________")
+var testSystemSCOther = []byte("other___________________________")
+
+type testSystemSCTransfer struct {
+	destination []byte
+	tokenID     []byte
+	value       *big.Int
+}
+
+type testSystemSCBuiltinCall struct {
+	recipient []byte
+	function  string
+	args      [][]byte
+	gasLimit  uint64
+}
+
+// testSystemSCContext records everything a mock does, instead of running it in a VM.
+type testSystemSCContext struct {
+	input        *vmcommon.ContractCallInput
+	storage      map[string][]byte
+	results      [][]byte
+	errorMessage string
+	transfers    []testSystemSCTransfer
+	builtinCalls []testSystemSCBuiltinCall
+	builtinErr   error
+}
+
+func newTestSystemSCContext(storage map[string][]byte, caller []byte, args ...[]byte) *testSystemSCContext {
+	if storage == nil {
+		storage = make(map[string][]byte)
+	}
+	return &testSystemSCContext{
+		input: &vmcommon.ContractCallInput{
+			VMInput: vmcommon.VMInput{
+				CallerAddr:  caller,
+				Arguments:   args,
+				CallValue:   big.NewInt(0),
+				CallType:    vm.DirectCall,
+				GasProvided: 1000,
+			},
+		},
+		storage: storage,
+	}
+}
+
+func (ctx *testSystemSCContext) Input() *vmcommon.ContractCallInput {
+	return ctx.input
+}
+
+func (ctx *testSystemSCContext) GasLeft() uint64 {
+	return ctx.input.GasProvided
+}
+
+func (ctx *testSystemSCContext) Finish(data []byte) {
+	ctx.results = append(ctx.results, data)
+}
+
+func (ctx *testSystemSCContext) SignalError(message string) {
+	ctx.errorMessage = message
+}
+
+func (ctx *testSystemSCContext) GetStorage(key []byte) ([]byte, error) {
+	return ctx.storage[string(key)], nil
+}
+
+func (ctx *testSystemSCContext) SetStorage(key []byte, value []byte) error {
+	if len(value) == 0 {
+		delete(ctx.storage, string(key))
+		return nil
+	}
+	ctx.storage[string(key)] = value
+	return nil
+}
+
+func (ctx *testSystemSCContext) TransferValue(destination []byte, value *big.Int) error {
+	ctx.transfers = append(ctx.transfers, testSystemSCTransfer{destination: destination, value: value})
+	return nil
+}
+
+func (ctx *testSystemSCContext) TransferESDT(destination []byte, tokenID []byte, value *big.Int) error {
+	ctx.transfers = append(ctx.transfers, testSystemSCTransfer{destination: destination, tokenID: tokenID, value: value})
+	return nil
+}
+
+func (ctx *testSystemSCContext) ExecuteBuiltin(recipient []byte, function string, args [][]byte, gasLimit uint64) error {
+	if ctx.builtinErr != nil {
+		return ctx.builtinErr
+	}
+	ctx.builtinCalls = append(ctx.builtinCalls, testSystemSCBuiltinCall{
+		recipient: recipient,
+		function:  function,
+		args:      args,
+		gasLimit:  gasLimit,
+	})
+	return nil
+}
+
+func callSystemSCMock(scMock *SystemSCMock, ctx *testSystemSCContext, function string) {
+	scMock.Functions[function](ctx)
+}
+
+func testTokenID(ticker string, nrIssuedTokens int64) string {
+	return ticker + "-" + tokenIDSuffix(big.NewInt(nrIssuedTokens))
+}
+
+func TestESDTSystemSCMock_Issue(t *testing.T) {
+	testCases := []struct {
+		name              string
+		function          string
+		args              [][]byte
+		callType          vm.CallType
+		expectedError     string
+		expectedResults   [][]byte
+		expectedTransfers []testSystemSCTransfer
+		expectedType      string
+	}{
+		{
+			name:            "fungible",
+			function:        "issue",
+			args:            [][]byte{[]byte("name"), []byte("TOK"), {100}, {18}},
+			callType:        vm.DirectCall,
+			expectedResults: [][]byte{[]byte(testTokenID("TOK", 0))},
+			expectedTransfers: []testSystemSCTransfer{
+				{destination: testSystemSCCaller, tokenID: []byte(testTokenID("TOK", 0)), value: big.NewInt(100)},
+			},
+			expectedType: core.FungibleESDT,
+		},
+		{
+			name:     "fungible, from an asynchronous call",
+			function: "issue",
+			args:     [][]byte{[]byte("name"), []byte("TOK"), {100}, {18}},
+			callType: vm.AsynchronousCall,
+			expectedResults: [][]byte{
+				[]byte("ESDTTransfer@" + hex.EncodeToString([]byte(testTokenID("TOK", 0))) + "@64"),
+				[]byte(testTokenID("TOK", 0)),
+			},
+			expectedType: core.FungibleESDT,
+		},
+		{
+			name:            "fungible without supply",
+			function:        "issue",
+			args:            [][]byte{[]byte("name"), []byte("TOK"), {}, {18}},
+			callType:        vm.DirectCall,
+			expectedResults: [][]byte{[]byte(testTokenID("TOK", 0))},
+			expectedType:    core.FungibleESDT,
+		},
+		{
+			name:            "non fungible",
+			function:        "issueNonFungible",
+			args:            [][]byte{[]byte("name"), []byte("NFT")},
+			callType:        vm.DirectCall,
+			expectedResults: [][]byte{[]byte(testTokenID("NFT", 0))},
+			expectedType:    core.NonFungibleESDT,
+		},
+		{
+			name:            "meta ESDT",
+			function:        "registerMetaESDT",
+			args:            [][]byte{[]byte("name"), []byte("META"), {18}},
+			callType:        vm.DirectCall,
+			expectedResults: [][]byte{[]byte(testTokenID("META", 0))},
+			expectedType:    metaESDT,
+		},
+		{
+			name:          "not enough arguments",
+			function:      "issue",
+			args:          [][]byte{[]byte("name"), []byte("TOK"), {100}},
+			expectedError: "not enough arguments",
+		},
+		{
+			name:          "lowercase ticker",
+			function:      "issueSemiFungible",
+			args:          [][]byte{[]byte("name"), []byte("tok")},
+			expectedError: "Invalid token ID",
+		},
+		{
+			name:          "ticker too long",
+			function:      "issueNonFungible",
+			args:          [][]byte{[]byte("name"), []byte("ABCDEFGHIJK")},
+			expectedError: "Invalid token ID",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := newTestSystemSCContext(nil, testSystemSCCaller, testCase.args...)
+			ctx.input.CallType = testCase.callType
+			callSystemSCMock(NewESDTSystemSCMock(), ctx, testCase.function)
+
+			require.Equal(t, testCase.expectedError, ctx.errorMessage)
+			require.Equal(t, testCase.expectedResults, ctx.results)
+			require.Equal(t, testCase.expectedTransfers, ctx.transfers)
+			if len(testCase.expectedError) > 0 {
+				require.Empty(t, ctx.storage)
+				return
+			}
+
+			tokenID := string(ctx.results[len(ctx.results)-1])
+			require.Equal(t, testSystemSCCaller, ctx.storage[esdtMockOwnerKeyPrefix+tokenID])
+			require.Equal(t, []byte(testCase.expectedType), ctx.storage[esdtMockTypeKeyPrefix+tokenID])
+			require.Equal(t, []byte{1}, ctx.storage[esdtMockNrIssuedTokensKey])
+		})
+	}
+}
+
+func TestESDTSystemSCMock_TokenIDsAreUnique(t *testing.T) {
+	esdtMock := NewESDTSystemSCMock()
+	storage := make(map[string][]byte)
+	tokenIDs := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		ctx := newTestSystemSCContext(storage, testSystemSCCaller, []byte("name"), []byte("TOK"))
+		callSystemSCMock(esdtMock, ctx, "issueNonFungible")
+		require.Empty(t, ctx.errorMessage)
+		tokenID := string(ctx.results[0])
+		require.Len(t, tokenID, len("TOK-")+tokenIDSuffixLength)
+		require.False(t, tokenIDs[tokenID], "duplicate token ID %s", tokenID)
+		tokenIDs[tokenID] = true
+	}
+
+	// an identifier that is already registered is rejected
+	storage[esdtMockNrIssuedTokensKey] = big.NewInt(3).Bytes()
+	ctx := newTestSystemSCContext(storage, testSystemSCOther, []byte("name"), []byte("TOK"))
+	callSystemSCMock(esdtMock, ctx, "issueNonFungible")
+	require.Equal(t, "token already registered", ctx.errorMessage)
+	require.Equal(t, testSystemSCCaller, storage[esdtMockOwnerKeyPrefix+testTokenID("TOK", 3)])
+	require.Equal(t, big.NewInt(3).Bytes(), storage[esdtMockNrIssuedTokensKey])
+}
+
+func newTestESDTStorage() map[string][]byte {
+	return map[string][]byte{
+		esdtMockOwnerKeyPrefix + "TOK-123456": testSystemSCCaller,
+	}
+}
+
+func TestESDTSystemSCMock_ChangeRoles(t *testing.T) {
+	testCases := []struct {
+		name             string
+		function         string
+		caller           []byte
+		args             [][]byte
+		expectedError    string
+		expectedFunction string
+	}{
+		{
+			name:             "set roles",
+			function:         "setSpecialRole",
+			caller:           testSystemSCCaller,
+			args:             [][]byte{[]byte("TOK-123456"), testSystemSCOther, []byte(core.ESDTRoleLocalMint), []byte(core.ESDTRoleLocalBurn)},
+			expectedFunction: core.BuiltInFunctionSetESDTRole,
+		},
+		{
+			name:             "unset roles",
+			function:         "unSetSpecialRole",
+			caller:           testSystemSCCaller,
+			args:             [][]byte{[]byte("TOK-123456"), testSystemSCOther, []byte(core.ESDTRoleLocalMint)},
+			expectedFunction: core.BuiltInFunctionUnSetESDTRole,
+		},
+		{
+			name:          "not the owner",
+			function:      "setSpecialRole",
+			caller:        testSystemSCOther,
+			args:          [][]byte{[]byte("TOK-123456"), testSystemSCOther, []byte(core.ESDTRoleLocalMint)},
+			expectedError: "can be called by owner only",
+		},
+		{
+			name:          "no such token",
+			function:      "setSpecialRole",
+			caller:        testSystemSCCaller,
+			args:          [][]byte{[]byte("TOK-654321"), testSystemSCOther, []byte(core.ESDTRoleLocalMint)},
+			expectedError: "no such token",
+		},
+		{
+			name:          "invalid role",
+			function:      "setSpecialRole",
+			caller:        testSystemSCCaller,
+			args:          [][]byte{[]byte("TOK-123456"), testSystemSCOther, []byte("ESDTRoleUnknown")},
+			expectedError: "invalid role: ESDTRoleUnknown",
+		},
+		{
+			name:          "no roles",
+			function:      "setSpecialRole",
+			caller:        testSystemSCCaller,
+			args:          [][]byte{[]byte("TOK-123456"), testSystemSCOther},
+			expectedError: "not enough arguments",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := newTestSystemSCContext(newTestESDTStorage(), testCase.caller, testCase.args...)
+			callSystemSCMock(NewESDTSystemSCMock(), ctx, testCase.function)
+
+			require.Equal(t, testCase.expectedError, ctx.errorMessage)
+			if len(testCase.expectedError) > 0 {
+				require.Empty(t, ctx.builtinCalls)
+				return
+			}
+			require.Equal(t, []testSystemSCBuiltinCall{{
+				recipient: testSystemSCOther,
+				function:  testCase.expectedFunction,
+				args:      append([][]byte{[]byte("TOK-123456")}, testCase.args[2:]...),
+				gasLimit:  0,
+			}}, ctx.builtinCalls)
+		})
+	}
+
+	ctx := newTestSystemSCContext(newTestESDTStorage(), testSystemSCCaller,
+		[]byte("TOK-123456"), testSystemSCOther, []byte(core.ESDTRoleLocalMint))
+	ctx.builtinErr = errors.New("builtin failed")
+	callSystemSCMock(NewESDTSystemSCMock(), ctx, "setSpecialRole")
+	require.Equal(t, "builtin failed", ctx.errorMessage)
+}
+
+func TestESDTSystemSCMock_FreezeAndPause(t *testing.T) {
+	frozenKey := esdtMockFrozenKeyPrefix + "TOK-123456:" + hex.EncodeToString(testSystemSCOther)
+	pausedKey := esdtMockPausedKeyPrefix + "TOK-123456"
+
+	testCases := []struct {
+		name              string
+		function          string
+		caller            []byte
+		args              [][]byte
+		initialFlags      []string
+		expectedError     string
+		expectedBuiltin   *testSystemSCBuiltinCall
+		expectedFlagKey   string
+		expectedFlagValue []byte
+	}{
+		{
+			name:     "freeze",
+			function: "freeze",
+			caller:   testSystemSCCaller,
+			args:     [][]byte{[]byte("TOK-123456"), testSystemSCOther},
+			expectedBuiltin: &testSystemSCBuiltinCall{
+				recipient: testSystemSCOther,
+				function:  core.BuiltInFunctionESDTFreeze,
+				args:      [][]byte{[]byte("TOK-123456")},
+			},
+			expectedFlagKey:   frozenKey,
+			expectedFlagValue: []byte{1},
+		},
+		{
+			name:              "freeze again",
+			function:          "freeze",
+			caller:            testSystemSCCaller,
+			args:              [][]byte{[]byte("TOK-123456"), testSystemSCOther},
+			initialFlags:      []string{frozenKey},
+			expectedFlagKey:   frozenKey,
+			expectedFlagValue: []byte{1},
+		},
+		{
+			name:         "unfreeze",
+			function:     "unFreeze",
+			caller:       testSystemSCCaller,
+			args:         [][]byte{[]byte("TOK-123456"), testSystemSCOther},
+			initialFlags: []string{frozenKey},
+			expectedBuiltin: &testSystemSCBuiltinCall{
+				recipient: testSystemSCOther,
+				function:  core.BuiltInFunctionESDTUnFreeze,
+				args:      [][]byte{[]byte("TOK-123456")},
+			},
+			expectedFlagKey: frozenKey,
+		},
+		{
+			name:          "freeze by someone else",
+			function:      "freeze",
+			caller:        testSystemSCOther,
+			args:          [][]byte{[]byte("TOK-123456"), testSystemSCOther},
+			expectedError: "can be called by owner only",
+		},
+		{
+			name:          "freeze without address",
+			function:      "freeze",
+			caller:        testSystemSCCaller,
+			args:          [][]byte{[]byte("TOK-123456")},
+			expectedError: "invalid number of arguments",
+		},
+		{
+			name:     "pause",
+			function: "pause",
+			caller:   testSystemSCCaller,
+			args:     [][]byte{[]byte("TOK-123456")},
+			expectedBuiltin: &testSystemSCBuiltinCall{
+				recipient: vmcommon.SystemAccountAddress,
+				function:  core.BuiltInFunctionESDTPause,
+				args:      [][]byte{[]byte("TOK-123456")},
+			},
+			expectedFlagKey:   pausedKey,
+			expectedFlagValue: []byte{1},
+		},
+		{
+			name:            "unpause when not paused",
+			function:        "unPause",
+			caller:          testSystemSCCaller,
+			args:            [][]byte{[]byte("TOK-123456")},
+			expectedFlagKey: pausedKey,
+		},
+		{
+			name:          "pause unknown token",
+			function:      "pause",
+			caller:        testSystemSCCaller,
+			args:          [][]byte{[]byte("TOK-654321")},
+			expectedError: "no such token",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			storage := newTestESDTStorage()
+			for _, flagKey := range testCase.initialFlags {
+				storage[flagKey] = []byte{1}
+			}
+			ctx := newTestSystemSCContext(storage, testCase.caller, testCase.args...)
+			callSystemSCMock(NewESDTSystemSCMock(), ctx, testCase.function)
+
+			require.Equal(t, testCase.expectedError, ctx.errorMessage)
+			if testCase.expectedBuiltin == nil {
+				require.Empty(t, ctx.builtinCalls)
+			} else {
+				require.Equal(t, []testSystemSCBuiltinCall{*testCase.expectedBuiltin}, ctx.builtinCalls)
+			}
+			if len(testCase.expectedFlagKey) > 0 {
+				require.Equal(t, testCase.expectedFlagValue, storage[testCase.expectedFlagKey])
+			}
+		})
+	}
+
+	// the flag is only recorded if the builtin function succeeds
+	storage := newTestESDTStorage()
+	ctx := newTestSystemSCContext(storage, testSystemSCCaller, []byte("TOK-123456"), testSystemSCOther)
+	ctx.builtinErr = errors.New("nil user account")
+	callSystemSCMock(NewESDTSystemSCMock(), ctx, "freeze")
+	require.Equal(t, "nil user account", ctx.errorMessage)
+	require.Nil(t, storage[frozenKey])
+}
+
+func TestStakingSystemSCMock(t *testing.T) {
+	stakedKey := stakingMockStakedKeyPrefix + string(testSystemSCCaller)
+	unStakedKey := stakingMockUnStakedKeyPrefix + string(testSystemSCCaller)
+
+	testCases := []struct {
+		name              string
+		function          string
+		callValue         int64
+		args              [][]byte
+		staked            int64
+		unStaked          int64
+		expectedError     string
+		expectedStaked    int64
+		expectedUnStaked  int64
+		expectedResults   [][]byte
+		expectedTransfers []testSystemSCTransfer
+	}{
+		{name: "stake", function: "stake", callValue: 10, staked: 5, expectedStaked: 15},
+		{name: "stake nothing", function: "stake", staked: 5, expectedError: "nothing to stake", expectedStaked: 5},
+		{name: "unStake all", function: "unStake", staked: 5, unStaked: 1, expectedUnStaked: 6},
+		{name: "unStake part", function: "unStake", args: [][]byte{{2}}, staked: 5, expectedStaked: 3, expectedUnStaked: 2},
+		{name: "unStake too much", function: "unStake", args: [][]byte{{6}}, staked: 5, expectedError: "invalid unStake amount", expectedStaked: 5},
+		{name: "unStake with nothing staked", function: "unStake", expectedError: "invalid unStake amount"},
+		{
+			name:              "unBond",
+			function:          "unBond",
+			staked:            5,
+			unStaked:          3,
+			expectedStaked:    5,
+			expectedTransfers: []testSystemSCTransfer{{destination: testSystemSCCaller, value: big.NewInt(3)}},
+		},
+		{name: "unBond nothing", function: "unBond", staked: 5, expectedError: "nothing to unBond", expectedStaked: 5},
+		{name: "getTotalStaked", function: "getTotalStaked", staked: 5, expectedStaked: 5, expectedResults: [][]byte{{5}}},
+		{name: "getTotalUnStaked of another address", function: "getTotalUnStaked", args: [][]byte{testSystemSCOther}, unStaked: 5, expectedUnStaked: 5, expectedResults: [][]byte{{}}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			storage := map[string][]byte{
+				stakedKey:   big.NewInt(testCase.staked).Bytes(),
+				unStakedKey: big.NewInt(testCase.unStaked).Bytes(),
+			}
+			ctx := newTestSystemSCContext(storage, testSystemSCCaller, testCase.args...)
+			ctx.input.CallValue = big.NewInt(testCase.callValue)
+			callSystemSCMock(NewStakingSystemSCMock(), ctx, testCase.function)
+
+			require.Equal(t, testCase.expectedError, ctx.errorMessage)
+			require.Equal(t, big.NewInt(testCase.expectedStaked), big.NewInt(0).SetBytes(storage[stakedKey]))
+			require.Equal(t, big.NewInt(testCase.expectedUnStaked), big.NewInt(0).SetBytes(storage[unStakedKey]))
+			require.Equal(t, testCase.expectedResults, ctx.results)
+			require.Equal(t, testCase.expectedTransfers, ctx.transfers)
+		})
+	}
+}
+
+func TestDNSMock(t *testing.T) {
+	dnsAddress := []byte("dns_____________________________")
+	nameKey := dnsMockNameKeyPrefix + "alice.elrond"
+
+	testCases := []struct {
+		name            string
+		function        string
+		args            [][]byte
+		registered      bool
+		builtinErr      error
+		expectedError   string
+		expectedBuiltin bool
+		expectedOwner   []byte
+		expectedResults [][]byte
+	}{
+		{
+			name:            "register",
+			function:        "register",
+			args:            [][]byte{[]byte("alice.elrond")},
+			expectedBuiltin: true,
+			expectedOwner:   testSystemSCCaller,
+		},
+		{
+			name:          "register a taken name",
+			function:      "register",
+			args:          [][]byte{[]byte("alice.elrond")},
+			registered:    true,
+			expectedError: "name already taken",
+			expectedOwner: testSystemSCOther,
+		},
+		{
+			name:          "register an empty name",
+			function:      "register",
+			args:          [][]byte{{}},
+			expectedError: "invalid name",
+		},
+		{
+			name:          "user name already set",
+			function:      "register",
+			args:          [][]byte{[]byte("alice.elrond")},
+			builtinErr:    errors.New("user name change is disabled"),
+			expectedError: "user name change is disabled",
+		},
+		{
+			name:            "resolve",
+			function:        "resolve",
+			args:            [][]byte{[]byte("alice.elrond")},
+			registered:      true,
+			expectedOwner:   testSystemSCOther,
+			expectedResults: [][]byte{testSystemSCOther},
+		},
+		{
+			name:            "resolve an unknown name",
+			function:        "resolve",
+			args:            [][]byte{[]byte("alice.elrond")},
+			expectedResults: [][]byte{nil},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			storage := make(map[string][]byte)
+			if testCase.registered {
+				storage[nameKey] = testSystemSCOther
+			}
+			ctx := newTestSystemSCContext(storage, testSystemSCCaller, testCase.args...)
+			ctx.builtinErr = testCase.builtinErr
+			callSystemSCMock(NewDNSMock(dnsAddress), ctx, testCase.function)
+
+			require.Equal(t, testCase.expectedError, ctx.errorMessage)
+			require.Equal(t, testCase.expectedOwner, storage[nameKey])
+			require.Equal(t, testCase.expectedResults, ctx.results)
+			if testCase.expectedBuiltin {
+				require.Equal(t, []testSystemSCBuiltinCall{{
+					recipient: testSystemSCCaller,
+					function:  core.BuiltInFunctionSetUserName,
+					args:      testCase.args,
+					gasLimit:  ctx.GasLeft(),
+				}}, ctx.builtinCalls)
+			} else {
+				require.Empty(t, ctx.builtinCalls)
+			}
+		})
+	}
+}
@@ -34,13 +34,16 @@ type Account struct {
 	ShardID         uint32
 	IsSmartContract bool
 	MockWorld       *MockWorld
+
+	// storageStore holds the storage keys not loaded yet, for the accounts backed by an accounts store
+	storageStore *AccountsStore
 }
 
 var storageDefaultValue = make([]byte, 0)
 
 // StorageValue yields the storage value for key, default 0
 func (a *Account) StorageValue(key string) []byte {
-	value, found := a.Storage[key]
+	value, found := a.storageValue(key)
 	if !found {
 		return storageDefaultValue
 	}
@@ -51,6 +54,7 @@ func (a *Account) StorageValue(key string) []byte {
 // CodeHash, IsSmartContract, CodeMetadata.
 // The code metadata must be given explicitly.
 func (a *Account) SetCodeAndMetadata(code []byte, codeMetadata *vmcommon.CodeMetadata) {
+	a.journal()
 	a.Code = code
 	hash := DefaultHasher.Compute(string(code))
 
@@ -96,6 +100,7 @@ func (a *Account) GetBalance() *big.Int {
 
 // SetBalance -
 func (a *Account) SetBalance(balance int64) {
+	a.journal()
 	a.Balance = big.NewInt(balance)
 }
 
@@ -121,6 +126,7 @@ func (a *Account) IsInterfaceNil() bool {
 
 // SetCode -
 func (a *Account) SetCode(code []byte) {
+	a.journal()
 	a.Code = code
 	a.CodeHash = DefaultHasher.Compute(string(code))
 	a.IsSmartContract = true
@@ -128,16 +134,19 @@ func (a *Account) SetCode(code []byte) {
 
 // SetCodeMetadata -
 func (a *Account) SetCodeMetadata(codeMetadata []byte) {
+	a.journal()
 	a.CodeMetadata = codeMetadata
 }
 
 // SetCodeHash -
 func (a *Account) SetCodeHash(hash []byte) {
+	a.journal()
 	a.CodeHash = hash
 }
 
 // SetRootHash -
 func (a *Account) SetRootHash(hash []byte) {
+	a.journal()
 	a.RootHash = hash
 }
 
@@ -148,6 +157,7 @@ func (a *Account) AccountDataHandler() vmcommon.AccountDataHandler {
 
 // AddToBalance -
 func (a *Account) AddToBalance(value *big.Int) error {
+	a.journal()
 	newBalance := big.NewInt(0).Add(a.Balance, value)
 	if newBalance.Cmp(zero) < 0 {
 		return ErrInsufficientFunds
@@ -159,6 +169,7 @@ func (a *Account) AddToBalance(value *big.Int) error {
 
 // SubFromBalance -
 func (a *Account) SubFromBalance(value *big.Int) error {
+	a.journal()
 	newBalance := big.NewInt(0).Sub(a.Balance, value)
 	if newBalance.Cmp(zero) < 0 {
 		return ErrInsufficientFunds
@@ -170,6 +181,7 @@ func (a *Account) SubFromBalance(value *big.Int) error {
 
 // ClaimDeveloperRewards -
 func (a *Account) ClaimDeveloperRewards(sender []byte) (*big.Int, error) {
+	a.journal()
 	if !bytes.Equal(sender, a.OwnerAddress) {
 		return nil, ErrOperationNotPermitted
 	}
@@ -182,11 +194,13 @@ func (a *Account) ClaimDeveloperRewards(sender []byte) (*big.Int, error) {
 
 // AddToDeveloperReward -
 func (a *Account) AddToDeveloperReward(value *big.Int) {
+	a.journal()
 	a.DeveloperReward = big.NewInt(0).Add(a.DeveloperReward, value)
 }
 
 // ChangeOwnerAddress -
 func (a *Account) ChangeOwnerAddress(sender []byte, newAddress []byte) error {
+	a.journal()
 	if !bytes.Equal(sender, a.OwnerAddress) {
 		return ErrOperationNotPermitted
 	}
@@ -201,27 +215,34 @@ func (a *Account) ChangeOwnerAddress(sender []byte, newAddress []byte) error {
 
 // SetOwnerAddress -
 func (a *Account) SetOwnerAddress(address []byte) {
+	a.journal()
 	a.OwnerAddress = address
 }
 
 // SetUserName -
 func (a *Account) SetUserName(userName []byte) {
+	a.journal()
 	a.Username = make([]byte, len(userName))
 	copy(a.Username, userName)
 }
 
 // IncreaseNonce -
 func (a *Account) IncreaseNonce(nonce uint64) {
+	a.journal()
 	a.Nonce += nonce
 }
 
 // RetrieveValue -
 func (a *Account) RetrieveValue(key []byte) ([]byte, uint32, error) {
-	return a.Storage[string(key)], 0, nil
+	value, _ := a.storageValue(string(key))
+	return value, 0, nil
 }
 
 // SaveKeyValue -
 func (a *Account) SaveKeyValue(key []byte, value []byte) error {
+	if a.MockWorld != nil {
+		a.MockWorld.journalStorage(a, string(key))
+	}
 	a.Storage[string(key)] = value
 	if a.MockWorld == nil {
 		return ErrNilWorldMock
@@ -239,8 +260,9 @@ func (a *Account) DirtyData() map[string][]byte {
 	return a.Storage
 }
 
-// Clone -
+// Clone yields a deep copy of the account, with all its storage loaded.
 func (a *Account) Clone() *Account {
+	a.LoadStorage()
 	return &Account{
 		Exists:          a.Exists,
 		Address:         a.Address,
@@ -262,6 +284,20 @@ func (a *Account) Clone() *Account {
 	}
 }
 
+// journal records the account before a change, so that it can be reverted to a snapshot.
+func (a *Account) journal() {
+	if a.MockWorld != nil {
+		a.MockWorld.journalAccount(a.Address)
+	}
+}
+
+// journalWholeStorage records the storage before a change to unknown keys.
+func (a *Account) journalWholeStorage() {
+	if a.MockWorld != nil {
+		a.MockWorld.journalWholeStorage(a)
+	}
+}
+
 func (a *Account) cloneStorage() map[string][]byte {
 	clone := make(map[string][]byte, len(a.Storage))
 	for key, value := range a.Storage {
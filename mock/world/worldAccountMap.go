@@ -35,6 +35,9 @@ func (am AccountMap) CreateAccount(address []byte, world *MockWorld) *Account {
 		MockWorld:       world,
 	}
 	copy(newAccount.Address, address)
+	if world != nil {
+		world.journalAccount(address)
+	}
 	am.PutAccount(newAccount)
 
 	return newAccount
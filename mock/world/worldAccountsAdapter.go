@@ -10,39 +10,43 @@ import (
 	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
 )
 
-// ErrTrieHandlingNotImplemented indicates that no trie-related operations are
-// currently implemented.
+// ErrTrieHandlingNotImplemented indicates that the trie-related operations are
+// not available, because the state trie of the world is not enabled, or not implemented.
 var ErrTrieHandlingNotImplemented = errors.New("trie handling not implemented")
 
 // MockAccountsAdapter is an implementation of AccountsAdapter based on
 // MockWorld and the accounts within it.
+// Snapshots can be nested to any depth; the changes made after each of them are kept in a journal,
+// instead of copying the accounts.
 type MockAccountsAdapter struct {
-	World     *MockWorld
-	Snapshots []AccountMap
+	World   *MockWorld
+	journal *accountsJournal
 }
 
 // NewMockAccountsAdapter instantiates a new MockAccountsAdapter.
 func NewMockAccountsAdapter(world *MockWorld) *MockAccountsAdapter {
 	return &MockAccountsAdapter{
-		World:     world,
-		Snapshots: make([]AccountMap, 0),
+		World:   world,
+		journal: newAccountsJournal(),
 	}
 }
 
 // GetExistingAccount -
 func (m *MockAccountsAdapter) GetExistingAccount(address []byte) (vmcommon.AccountHandler, error) {
-	account, exists := m.World.AcctMap[string(address)]
-	if !exists {
+	account := m.World.GetAccount(address)
+	if account == nil {
 		return nil, vmhost.ErrInvalidAccount
 	}
 
+	m.World.journalAccount(address)
 	return account, nil
 }
 
 // LoadAccount -
 func (m *MockAccountsAdapter) LoadAccount(address []byte) (vmcommon.AccountHandler, error) {
-	account, exists := m.World.AcctMap[string(address)]
-	if !exists {
+	m.World.journalAccount(address)
+	account := m.World.GetAccount(address)
+	if account == nil {
 		account = m.World.AcctMap.CreateAccount(address, m.World)
 	}
 
@@ -56,58 +60,62 @@ func (m *MockAccountsAdapter) SaveAccount(account vmcommon.AccountHandler) error
 		return errors.New("invalid account to save")
 	}
 
+	m.World.journalAccount(mockAccount.Address)
 	m.World.AcctMap.PutAccount(mockAccount)
 	return nil
 }
 
 // RemoveAccount -
 func (m *MockAccountsAdapter) RemoveAccount(address []byte) error {
-	_, exists := m.World.AcctMap[string(address)]
-	if !exists {
+	if m.World.GetAccount(address) == nil {
 		return vmhost.ErrInvalidAccount
 	}
 
+	m.World.journalAccount(address)
 	m.World.AcctMap.DeleteAccount(address)
 	return nil
 }
 
-// Commit -
+// Commit discards the snapshots and, if the state trie is enabled, yields the new state root hash.
 func (m *MockAccountsAdapter) Commit() ([]byte, error) {
-	m.Snapshots = make([]AccountMap, 0)
-	return nil, nil
+	m.journal.clear()
+	if m.World.StateTrie == nil {
+		return nil, nil
+	}
+
+	m.World.CommitStateTrie()
+	return m.World.StateRootHash, nil
 }
 
 // JournalLen -
 func (m *MockAccountsAdapter) JournalLen() int {
-	return len(m.Snapshots) - 1
+	return m.journal.len() - 1
 }
 
-// RevertToSnapshot -
+// RevertToSnapshot undoes all the changes made since the snapshot with the given index was taken,
+// and discards it, along with the snapshots taken after it.
 func (m *MockAccountsAdapter) RevertToSnapshot(snapshotIndex int) error {
-	if len(m.Snapshots) == 0 {
+	if m.journal.len() == 0 {
 		return errors.New("no snapshots")
 	}
 
-	if snapshotIndex >= len(m.Snapshots) || snapshotIndex < 0 {
+	if snapshotIndex >= m.journal.len() || snapshotIndex < 0 {
 		return fmt.Errorf(
 			"snapshot %d out of bounds (min 0, max %d)",
 			snapshotIndex,
-			len(m.Snapshots)-1)
+			m.journal.len()-1)
 	}
 
-	snapshot := m.Snapshots[snapshotIndex]
-	m.Snapshots = m.Snapshots[:snapshotIndex]
-
-	// TODO should probably set BalanceDelta of all accounts to 0 as well?
-	return m.World.AcctMap.LoadAccountStorageFrom(snapshot)
+	m.journal.revertToSnapshot(m.World.AcctMap, snapshotIndex)
+	return nil
 }
 
 // GetNumCheckpoints -
 func (m *MockAccountsAdapter) GetNumCheckpoints() uint32 {
-	return uint32(len(m.Snapshots))
+	return uint32(m.journal.len())
 }
 
-// GetCode -
+// GetCode yields the code with the given hash, from the accounts in the world or in the accounts store.
 func (m *MockAccountsAdapter) GetCode(codeHash []byte) []byte {
 	for _, account := range m.World.AcctMap {
 		if bytes.Equal(account.GetCodeHash(), codeHash) {
@@ -115,12 +123,20 @@ func (m *MockAccountsAdapter) GetCode(codeHash []byte) []byte {
 		}
 	}
 
+	if m.World.AccountsStore != nil {
+		return m.World.AccountsStore.getCode(codeHash)
+	}
 	return nil
 }
 
-// RootHash -
+// RootHash yields the state root hash of the current accounts, including the changes not yet committed.
+// Only available if the state trie of the world is enabled.
 func (m *MockAccountsAdapter) RootHash() ([]byte, error) {
-	return nil, ErrTrieHandlingNotImplemented
+	if m.World.StateTrie == nil {
+		return nil, ErrTrieHandlingNotImplemented
+	}
+
+	return m.World.StateTrie.RootHash(m.World.AcctMap), nil
 }
 
 // RecreateTrie -
@@ -128,10 +144,9 @@ func (m *MockAccountsAdapter) RecreateTrie(_ []byte) error {
 	return ErrTrieHandlingNotImplemented
 }
 
-// SnapshotState -
+// SnapshotState starts a new snapshot, nested in the previous ones.
 func (m *MockAccountsAdapter) SnapshotState(_ []byte, _ context.Context) {
-	snapshot := m.World.AcctMap.Clone()
-	m.Snapshots = append(m.Snapshots, snapshot)
+	m.journal.snapshot()
 }
 
 // SetStateCheckpoint -
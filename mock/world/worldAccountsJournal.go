@@ -0,0 +1,202 @@
+package worldmock
+
+import "math/big"
+
+// accountsJournal records the changes made to the accounts since the last commit,
+// so that they can be reverted to any of the snapshots taken in the meantime.
+// The first change to an account after a snapshot records all its fields, except the storage,
+// which is recorded key by key, so the cost of a snapshot does not depend on the size of the world.
+type accountsJournal struct {
+	entries   []journalEntry
+	snapshots []int
+	touched   map[string]bool
+}
+
+// journalEntry restores a part of the accounts to how it was when the entry was recorded.
+type journalEntry interface {
+	revert(accounts AccountMap)
+}
+
+// accountEntry holds the fields of an account before a change,
+// or no account, if the address was not in the world.
+type accountEntry struct {
+	address string
+	account *Account
+	fields  Account
+}
+
+// storageEntry holds the value of a storage key before a change.
+type storageEntry struct {
+	storage map[string][]byte
+	key     string
+	value   []byte
+	existed bool
+}
+
+// wholeStorageEntry holds a copy of the entire storage of an account,
+// for changes that do not say which keys they write, such as setting ESDT data.
+type wholeStorageEntry struct {
+	storage map[string][]byte
+	clone   map[string][]byte
+}
+
+func newAccountsJournal() *accountsJournal {
+	return &accountsJournal{
+		touched: make(map[string]bool),
+	}
+}
+
+// isActive tells whether there are any snapshots to revert to.
+// Changes are not recorded otherwise.
+func (journal *accountsJournal) isActive() bool {
+	return len(journal.snapshots) > 0
+}
+
+func (journal *accountsJournal) snapshot() {
+	journal.snapshots = append(journal.snapshots, len(journal.entries))
+	journal.touched = make(map[string]bool)
+}
+
+func (journal *accountsJournal) len() int {
+	return len(journal.snapshots)
+}
+
+func (journal *accountsJournal) clear() {
+	journal.entries = nil
+	journal.snapshots = nil
+	journal.touched = make(map[string]bool)
+}
+
+// revertToSnapshot undoes the changes made since the snapshot with the given index,
+// in reverse order, then drops it and all the snapshots taken after it.
+func (journal *accountsJournal) revertToSnapshot(accounts AccountMap, snapshotIndex int) {
+	start := journal.snapshots[snapshotIndex]
+	for i := len(journal.entries) - 1; i >= start; i-- {
+		journal.entries[i].revert(accounts)
+	}
+
+	journal.entries = journal.entries[:start]
+	journal.snapshots = journal.snapshots[:snapshotIndex]
+	journal.touched = make(map[string]bool)
+}
+
+func (journal *accountsJournal) recordAccount(accounts AccountMap, address []byte) {
+	if !journal.isActive() || journal.touched[string(address)] {
+		return
+	}
+	journal.touched[string(address)] = true
+
+	entry := &accountEntry{
+		address: string(address),
+		account: accounts[string(address)],
+	}
+	if entry.account != nil {
+		entry.fields = *entry.account
+		entry.fields.Balance = cloneBigInt(entry.account.Balance)
+		entry.fields.BalanceDelta = cloneBigInt(entry.account.BalanceDelta)
+		entry.fields.DeveloperReward = cloneBigInt(entry.account.DeveloperReward)
+	}
+	journal.entries = append(journal.entries, entry)
+}
+
+func (journal *accountsJournal) recordStorage(storage map[string][]byte, key string) {
+	if !journal.isActive() {
+		return
+	}
+
+	value, existed := storage[key]
+	journal.entries = append(journal.entries, &storageEntry{
+		storage: storage,
+		key:     key,
+		value:   value,
+		existed: existed,
+	})
+}
+
+func (journal *accountsJournal) recordWholeStorage(storage map[string][]byte) {
+	if !journal.isActive() {
+		return
+	}
+
+	clone := make(map[string][]byte, len(storage))
+	for key, value := range storage {
+		clone[key] = value
+	}
+	journal.entries = append(journal.entries, &wholeStorageEntry{
+		storage: storage,
+		clone:   clone,
+	})
+}
+
+func (entry *accountEntry) revert(accounts AccountMap) {
+	if entry.account == nil {
+		delete(accounts, entry.address)
+		return
+	}
+
+	*entry.account = entry.fields
+	accounts[entry.address] = entry.account
+}
+
+func (entry *storageEntry) revert(_ AccountMap) {
+	if entry.existed {
+		entry.storage[entry.key] = entry.value
+	} else {
+		delete(entry.storage, entry.key)
+	}
+}
+
+func (entry *wholeStorageEntry) revert(_ AccountMap) {
+	for key := range entry.storage {
+		delete(entry.storage, key)
+	}
+	for key, value := range entry.clone {
+		entry.storage[key] = value
+	}
+}
+
+func cloneBigInt(value *big.Int) *big.Int {
+	if value == nil {
+		return nil
+	}
+	return big.NewInt(0).Set(value)
+}
+
+// journal yields the journal of the accounts adapter, nil if the world uses another adapter.
+func (b *MockWorld) journal() *accountsJournal {
+	adapter, ok := b.AccountsAdapter.(*MockAccountsAdapter)
+	if !ok || adapter == nil {
+		return nil
+	}
+	return adapter.journal
+}
+
+// journalAccount records the account before it gets changed, created or removed,
+// so that the change can be reverted. The state trie and the accounts store pick up the change at the next commit.
+func (b *MockWorld) journalAccount(address []byte) {
+	b.trackAccountChange(address)
+	journal := b.journal()
+	if journal != nil && journal.isActive() {
+		// accounts still on disk get loaded first, so that reverting restores them in the world
+		_ = b.GetAccount(address)
+		journal.recordAccount(b.AcctMap, address)
+	}
+}
+
+// journalStorage records a storage value before it gets changed.
+func (b *MockWorld) journalStorage(account *Account, key string) {
+	b.trackStorageChange(account.Address, key)
+	journal := b.journal()
+	if journal != nil {
+		journal.recordStorage(account.Storage, key)
+	}
+}
+
+// journalWholeStorage records the entire storage of an account before it gets changed.
+func (b *MockWorld) journalWholeStorage(account *Account) {
+	b.trackWholeStorageChange(account.Address)
+	journal := b.journal()
+	if journal != nil {
+		journal.recordWholeStorage(account.Storage)
+	}
+}
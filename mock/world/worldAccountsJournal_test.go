@@ -0,0 +1,176 @@
+package worldmock
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+	"github.com/stretchr/testify/require"
+)
+
+func newJournalTestWorld() *MockWorld {
+	world := NewMockWorld()
+	for i := 0; i < 4; i++ {
+		account := world.AcctMap.CreateAccount([]byte(fmt.Sprintf("address-%d", i)), world)
+		account.Balance = big.NewInt(int64(1000 * (i + 1)))
+		account.Storage["key"] = []byte{byte(i)}
+	}
+	return world
+}
+
+func requireSameAccounts(t *testing.T, expected AccountMap, actual AccountMap) {
+	require.Equal(t, len(expected), len(actual))
+	for address, expectedAccount := range expected {
+		actualAccount, found := actual[address]
+		require.True(t, found, "missing account %s", address)
+
+		require.Equal(t, expectedAccount.Nonce, actualAccount.Nonce, address)
+		require.Zero(t, expectedAccount.Balance.Cmp(actualAccount.Balance), address)
+		require.Zero(t, expectedAccount.DeveloperReward.Cmp(actualAccount.DeveloperReward), address)
+		require.True(t, bytes.Equal(expectedAccount.Code, actualAccount.Code), address)
+		require.True(t, bytes.Equal(expectedAccount.CodeHash, actualAccount.CodeHash), address)
+		require.True(t, bytes.Equal(expectedAccount.CodeMetadata, actualAccount.CodeMetadata), address)
+		require.True(t, bytes.Equal(expectedAccount.OwnerAddress, actualAccount.OwnerAddress), address)
+		require.True(t, bytes.Equal(expectedAccount.Username, actualAccount.Username), address)
+		require.Equal(t, expectedAccount.IsSmartContract, actualAccount.IsSmartContract, address)
+
+		require.Equal(t, len(expectedAccount.Storage), len(actualAccount.Storage), address)
+		for key, value := range expectedAccount.Storage {
+			actualValue, found := actualAccount.Storage[key]
+			require.True(t, found, "missing key %s in %s", key, address)
+			require.True(t, bytes.Equal(value, actualValue), "key %s in %s", key, address)
+		}
+	}
+}
+
+// randomChange changes the world the way the VM and the builtin functions do.
+// Yields true if the change also took a snapshot, as saving a storage key does.
+func randomChange(t *testing.T, world *MockWorld, r *rand.Rand) bool {
+	address := []byte(fmt.Sprintf("address-%d", r.Intn(6)))
+	adapter := world.AccountsAdapter
+	switch r.Intn(9) {
+	case 0:
+		account, err := adapter.LoadAccount(address)
+		require.Nil(t, err)
+		require.Nil(t, adapter.SaveAccount(account))
+	case 1:
+		_ = adapter.RemoveAccount(address)
+	case 2:
+		account, err := adapter.LoadAccount(address)
+		require.Nil(t, err)
+		_ = account.(*Account).AddToBalance(big.NewInt(r.Int63n(100) - 50))
+	case 3:
+		account, err := adapter.LoadAccount(address)
+		require.Nil(t, err)
+		account.(*Account).IncreaseNonce(1)
+		account.(*Account).SetCode([]byte(fmt.Sprintf("code-%d", r.Intn(3))))
+	case 4:
+		account, err := adapter.LoadAccount(address)
+		require.Nil(t, err)
+		key := []byte(fmt.Sprintf("key-%d", r.Intn(4)))
+		require.Nil(t, account.(*Account).SaveKeyValue(key, []byte{byte(r.Intn(256))}))
+		return true
+	case 5:
+		account, err := adapter.LoadAccount(address)
+		require.Nil(t, err)
+		require.Nil(t, account.(*Account).SetTokenBalanceUint64([]byte("TOKEN-123456"), 0, uint64(r.Intn(100))))
+	case 6:
+		world.UpdateAccountFromOutputAccount(newOutputAccount(address, r))
+	case 7:
+		if world.AcctMap.GetAccount(address) != nil {
+			require.Nil(t, world.UpdateBalanceWithDelta(address, big.NewInt(r.Int63n(10))))
+		}
+	case 8:
+		account, err := adapter.LoadAccount(address)
+		require.Nil(t, err)
+		account.(*Account).AddToDeveloperReward(big.NewInt(r.Int63n(10)))
+		account.(*Account).SetUserName([]byte(fmt.Sprintf("user-%d", r.Intn(3))))
+	}
+	return false
+}
+
+func TestMockAccountsAdapter_NestedSnapshotsMatchClones(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	for round := 0; round < 50; round++ {
+		world := newJournalTestWorld()
+		adapter := world.AccountsAdapter.(*MockAccountsAdapter)
+
+		// the clone-based approach, as reference
+		var clones []AccountMap
+		for step := 0; step < 100; step++ {
+			switch {
+			case r.Intn(5) == 0:
+				clones = append(clones, world.AcctMap.Clone())
+				adapter.SnapshotState(nil, nil)
+				require.Equal(t, len(clones)-1, adapter.JournalLen())
+			case r.Intn(8) == 0 && len(clones) > 0:
+				snapshotIndex := r.Intn(len(clones))
+				require.Nil(t, adapter.RevertToSnapshot(snapshotIndex))
+				requireSameAccounts(t, clones[snapshotIndex], world.AcctMap)
+				clones = clones[:snapshotIndex]
+				require.Equal(t, uint32(len(clones)), adapter.GetNumCheckpoints())
+			default:
+				if randomChange(t, world, r) {
+					clones = append(clones, world.AcctMap.Clone())
+				}
+			}
+		}
+
+		if len(clones) > 0 {
+			require.Nil(t, adapter.RevertToSnapshot(0))
+			requireSameAccounts(t, clones[0], world.AcctMap)
+		}
+	}
+}
+
+func TestMockAccountsAdapter_CommitDiscardsSnapshots(t *testing.T) {
+	world := newJournalTestWorld()
+	adapter := world.AccountsAdapter.(*MockAccountsAdapter)
+
+	adapter.SnapshotState(nil, nil)
+	require.Nil(t, world.UpdateBalance([]byte("address-0"), big.NewInt(5)))
+	_, err := adapter.Commit()
+	require.Nil(t, err)
+	require.Equal(t, uint32(0), adapter.GetNumCheckpoints())
+	require.NotNil(t, adapter.RevertToSnapshot(0))
+	require.Equal(t, big.NewInt(5), world.AcctMap.GetAccount([]byte("address-0")).Balance)
+}
+
+func TestMockAccountsAdapter_RevertInnerSnapshotKeepsOuterChanges(t *testing.T) {
+	world := newJournalTestWorld()
+	adapter := world.AccountsAdapter.(*MockAccountsAdapter)
+	address := []byte("address-1")
+
+	adapter.SnapshotState(nil, nil)
+	account, _ := adapter.LoadAccount(address)
+	require.Nil(t, account.(*Account).SaveKeyValue([]byte("outer"), []byte("1")))
+
+	adapter.SnapshotState(nil, nil)
+	innerSnapshot := adapter.JournalLen()
+	account.(*Account).IncreaseNonce(3)
+	require.Nil(t, account.(*Account).SaveKeyValue([]byte("outer"), []byte("2")))
+	_, _ = adapter.LoadAccount([]byte("new-address"))
+
+	require.Nil(t, adapter.RevertToSnapshot(innerSnapshot))
+	require.Equal(t, uint64(0), world.AcctMap.GetAccount(address).Nonce)
+	require.Equal(t, []byte("1"), world.AcctMap.GetAccount(address).Storage["outer"])
+	require.Nil(t, world.AcctMap.GetAccount([]byte("new-address")))
+
+	require.Nil(t, adapter.RevertToSnapshot(0))
+	_, found := world.AcctMap.GetAccount(address).Storage["outer"]
+	require.False(t, found)
+}
+
+func newOutputAccount(address []byte, r *rand.Rand) *vmcommon.OutputAccount {
+	return &vmcommon.OutputAccount{
+		Address:      address,
+		BalanceDelta: big.NewInt(r.Int63n(20)),
+		Nonce:        uint64(r.Intn(5)),
+		StorageUpdates: map[string]*vmcommon.StorageUpdate{
+			"key": {Offset: []byte("key"), Data: []byte{byte(r.Intn(256))}},
+		},
+	}
+}
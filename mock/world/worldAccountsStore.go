@@ -0,0 +1,438 @@
+package worldmock
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/multiversx/mx-chain-core-go/core"
+	kvstoremock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/kvstore"
+)
+
+const (
+	storedAccountPrefix = byte('a')
+	storedStoragePrefix = byte('s')
+	storedCodePrefix    = byte('c')
+
+	// DefaultMaxLoadedAccounts is the number of loaded accounts beyond which the world drops them after a write-back.
+	DefaultMaxLoadedAccounts = 10000
+)
+
+// AccountsStore keeps the accounts of a MockWorld on disk, in a key-value store,
+// for worlds too large to be held in memory, e.g. imported from a real chain.
+// The world loads an account the first time it is needed, without its storage, whose keys are loaded when first read,
+// then keeps it in its AccountMap; the changes are only written back on CommitChanges.
+// Only the accounts and the storage keys changed since the previous write-back are written;
+// accounts changed directly in the account map need to be marked with MockWorld.MarkAccountChanged.
+// Once more than MaxLoadedAccounts accounts are loaded, the world drops them after a write-back,
+// unless the state trie is enabled, which needs all of them.
+type AccountsStore struct {
+	MaxLoadedAccounts int
+
+	kv         *kvstoremock.FileStore
+	loaded     map[string]bool
+	changes    changedAccounts
+	allChanged bool
+	err        error
+}
+
+// storedAccount is the encoding of an account in the store, without the storage.
+type storedAccount struct {
+	Exists          bool
+	Nonce           uint64
+	Balance         *big.Int
+	Code            []byte
+	CodeHash        []byte
+	CodeMetadata    []byte
+	RootHash        []byte
+	OwnerAddress    []byte
+	AsyncCallData   string
+	Username        []byte
+	DeveloperReward *big.Int
+	ShardID         uint32
+	IsSmartContract bool
+}
+
+// OpenAccountsStore opens the accounts store kept in the file at the given path, creating it if missing.
+func OpenAccountsStore(path string) (*AccountsStore, error) {
+	kv, err := kvstoremock.OpenFileStore(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AccountsStore{
+		MaxLoadedAccounts: DefaultMaxLoadedAccounts,
+		kv:                kv,
+		loaded:            make(map[string]bool),
+		changes:           make(changedAccounts),
+	}, nil
+}
+
+// Close closes the underlying key-value store. The changes not written back are lost.
+func (as *AccountsStore) Close() error {
+	return as.kv.Close()
+}
+
+// Compact reclaims the space taken on disk by the values that were overwritten.
+func (as *AccountsStore) Compact() error {
+	return as.kv.Compact()
+}
+
+// NumAccounts yields the number of accounts in the store.
+func (as *AccountsStore) NumAccounts() int {
+	return len(as.kv.KeysWithPrefix([]byte{storedAccountPrefix}))
+}
+
+// Addresses yields the addresses of all the accounts in the store, in ascending order.
+func (as *AccountsStore) Addresses() [][]byte {
+	keys := as.kv.KeysWithPrefix([]byte{storedAccountPrefix})
+	addresses := make([][]byte, len(keys))
+	for i, key := range keys {
+		addresses[i] = key[1:]
+	}
+	return addresses
+}
+
+func accountKey(address []byte) []byte {
+	return append([]byte{storedAccountPrefix}, address...)
+}
+
+// storagePrefix includes the length of the address, so that no address is a prefix of another.
+func storagePrefix(address []byte) []byte {
+	prefix := make([]byte, 5, 5+len(address))
+	prefix[0] = storedStoragePrefix
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(address)))
+	return append(prefix, address...)
+}
+
+func storageKey(address []byte, key string) []byte {
+	return append(storagePrefix(address), key...)
+}
+
+func codeKey(codeHash []byte) []byte {
+	return append([]byte{storedCodePrefix}, codeHash...)
+}
+
+// wasLoaded tells whether the world holds the account, or held it since the last write-back.
+// An account loaded but missing from the world was removed, and must not be loaded again.
+func (as *AccountsStore) wasLoaded(address []byte) bool {
+	return as.allChanged || as.loaded[string(address)]
+}
+
+// forgetLoaded drops the record of the loaded accounts and of their changes,
+// when the world drops them without writing them back.
+func (as *AccountsStore) forgetLoaded() {
+	as.loaded = make(map[string]bool)
+	as.changes = make(changedAccounts)
+	as.allChanged = false
+}
+
+// markAllChanged makes the next write-back replace the whole store with the accounts in the world.
+func (as *AccountsStore) markAllChanged() {
+	as.allChanged = true
+}
+
+// loadAccount reads the account, without its storage. Yields nil if the account is not in the store.
+func (as *AccountsStore) loadAccount(address []byte, world *MockWorld) (*Account, error) {
+	encoded, found, err := as.kv.Get(accountKey(address))
+	if err != nil || !found {
+		return nil, err
+	}
+
+	stored := &storedAccount{}
+	err = json.Unmarshal(encoded, stored)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stored account %x: %w", address, err)
+	}
+
+	as.loaded[string(address)] = true
+	return &Account{
+		Exists:          stored.Exists,
+		Address:         cloneBytes(address),
+		Nonce:           stored.Nonce,
+		Balance:         bigIntOrZero(stored.Balance),
+		BalanceDelta:    big.NewInt(0),
+		Storage:         make(map[string][]byte),
+		RootHash:        stored.RootHash,
+		Code:            stored.Code,
+		CodeHash:        stored.CodeHash,
+		CodeMetadata:    stored.CodeMetadata,
+		OwnerAddress:    stored.OwnerAddress,
+		AsyncCallData:   stored.AsyncCallData,
+		Username:        stored.Username,
+		DeveloperReward: bigIntOrZero(stored.DeveloperReward),
+		ShardID:         stored.ShardID,
+		IsSmartContract: stored.IsSmartContract,
+		MockWorld:       world,
+		storageStore:    as,
+	}, nil
+}
+
+// loadStorage adds to the storage of the account the keys starting with the prefix that are not there yet.
+func (as *AccountsStore) loadStorage(account *Account, prefix string) {
+	prefixLength := len(storagePrefix(account.Address))
+	for _, key := range as.kv.KeysWithPrefix(storageKey(account.Address, prefix)) {
+		storageKey := string(key[prefixLength:])
+		if _, loaded := account.Storage[storageKey]; loaded {
+			continue
+		}
+		value, _, err := as.kv.Get(key)
+		if err != nil {
+			as.rememberError(err)
+			return
+		}
+		account.Storage[storageKey] = value
+	}
+}
+
+// loadStorageKey adds the value of a key to the storage of the account, if in the store.
+func (as *AccountsStore) loadStorageKey(account *Account, key string) {
+	value, found, err := as.kv.Get(storageKey(account.Address, key))
+	if err != nil {
+		as.rememberError(err)
+		return
+	}
+	if found {
+		account.Storage[key] = value
+	}
+}
+
+// getCode yields the code with the given hash, from any account written to the store.
+func (as *AccountsStore) getCode(codeHash []byte) []byte {
+	code, _, err := as.kv.Get(codeKey(codeHash))
+	if err != nil {
+		as.rememberError(err)
+		return nil
+	}
+	return code
+}
+
+// writeBack saves the changed accounts and deletes the removed ones.
+func (as *AccountsStore) writeBack(accounts AccountMap) error {
+	changes := as.changes
+	if as.allChanged {
+		changes = as.allAccountsChanged(accounts)
+	}
+
+	batch := kvstoremock.NewBatch()
+	for address, accountChanges := range changes {
+		account, exists := accounts[address]
+		if exists {
+			err := as.writeAccount(batch, account, accountChanges)
+			if err != nil {
+				return err
+			}
+			as.loaded[address] = true
+			continue
+		}
+
+		if as.wasLoaded([]byte(address)) {
+			batch.Delete(accountKey([]byte(address)))
+			for _, key := range as.kv.KeysWithPrefix(storagePrefix([]byte(address))) {
+				batch.Delete(key)
+			}
+			delete(as.loaded, address)
+		}
+	}
+
+	as.changes = make(changedAccounts)
+	as.allChanged = false
+	return as.kv.Write(batch)
+}
+
+// writeAccount saves the fields of the account and the changed storage keys.
+// The storage of an account not backed by the store yet, because it is new or was replaced, is saved entirely,
+// and replaces the one in the store, if any. The account is backed by the store afterwards.
+func (as *AccountsStore) writeAccount(batch *kvstoremock.Batch, account *Account, changes *accountChanges) error {
+	encoded, err := encodeStoredAccount(account)
+	if err != nil {
+		return err
+	}
+	batch.Put(accountKey(account.Address), encoded)
+	if len(account.Code) > 0 && !as.kv.Has(codeKey(account.CodeHash)) {
+		batch.Put(codeKey(account.CodeHash), account.Code)
+	}
+
+	writeKey := func(key string, value []byte) {
+		if len(value) > 0 {
+			batch.Put(storageKey(account.Address, key), value)
+		} else if as.kv.Has(storageKey(account.Address, key)) {
+			batch.Delete(storageKey(account.Address, key))
+		}
+	}
+
+	switch {
+	case account.storageStore != as:
+		prefixLength := len(storagePrefix(account.Address))
+		for _, key := range as.kv.KeysWithPrefix(storagePrefix(account.Address)) {
+			if _, exists := account.Storage[string(key[prefixLength:])]; !exists {
+				batch.Delete(key)
+			}
+		}
+		for key, value := range account.Storage {
+			writeKey(key, value)
+		}
+		account.storageStore = as
+	case changes.wholeStorage:
+		for key, value := range account.Storage {
+			writeKey(key, value)
+		}
+	default:
+		// a key missing from the storage was not loaded, or was reverted to the value in the store
+		for key := range changes.keys {
+			value, loaded := account.Storage[key]
+			if loaded {
+				writeKey(key, value)
+			}
+		}
+	}
+	return nil
+}
+
+func (as *AccountsStore) allAccountsChanged(accounts AccountMap) changedAccounts {
+	changes := make(changedAccounts, len(accounts))
+	for address := range accounts {
+		changes.markWholeStorage([]byte(address))
+	}
+	for _, address := range as.Addresses() {
+		changes.markWholeStorage(address)
+	}
+	return changes
+}
+
+// evictLoaded drops from the world the accounts loaded from the store or written to it,
+// once there are more than MaxLoadedAccounts. They are all written back at this point.
+func (as *AccountsStore) evictLoaded(accounts AccountMap) {
+	if len(as.loaded) <= as.MaxLoadedAccounts {
+		return
+	}
+	for address := range as.loaded {
+		delete(accounts, address)
+	}
+	as.loaded = make(map[string]bool)
+}
+
+func encodeStoredAccount(account *Account) ([]byte, error) {
+	return json.Marshal(&storedAccount{
+		Exists:          account.Exists,
+		Nonce:           account.Nonce,
+		Balance:         account.Balance,
+		Code:            account.Code,
+		CodeHash:        account.CodeHash,
+		CodeMetadata:    account.CodeMetadata,
+		RootHash:        account.RootHash,
+		OwnerAddress:    account.OwnerAddress,
+		AsyncCallData:   account.AsyncCallData,
+		Username:        account.Username,
+		DeveloperReward: account.DeveloperReward,
+		ShardID:         account.ShardID,
+		IsSmartContract: account.IsSmartContract,
+	})
+}
+
+func bigIntOrZero(value *big.Int) *big.Int {
+	if value == nil {
+		return big.NewInt(0)
+	}
+	return value
+}
+
+// UseAccountsStore makes the world load its accounts from the store, when first needed,
+// and write the changes back on CommitChanges.
+// The accounts already in the world are kept, and saved to the store on the next commit.
+func (b *MockWorld) UseAccountsStore(store *AccountsStore) {
+	store.forgetLoaded()
+	for address := range b.AcctMap {
+		store.changes.markWholeStorage([]byte(address))
+	}
+	b.AccountsStore = store
+}
+
+// GetAccount yields the account with the given address, loading it from the accounts store if needed.
+// Yields nil if the account does not exist.
+func (b *MockWorld) GetAccount(address []byte) *Account {
+	account, found := b.AcctMap[string(address)]
+	if found || b.AccountsStore == nil || b.AccountsStore.wasLoaded(address) {
+		return account
+	}
+
+	account, err := b.AccountsStore.loadAccount(address, b)
+	if err != nil {
+		b.AccountsStore.rememberError(err)
+		return nil
+	}
+	if account != nil {
+		b.AcctMap[string(address)] = account
+	}
+	return account
+}
+
+// LoadAllAccounts brings all the accounts in the accounts store into the world, along with all their storage,
+// for operations that need to see every account, such as dumps and complete state checks.
+func (b *MockWorld) LoadAllAccounts() {
+	if b.AccountsStore == nil {
+		return
+	}
+
+	for _, address := range b.AccountsStore.Addresses() {
+		_ = b.GetAccount(address)
+	}
+	for _, account := range b.AcctMap {
+		account.LoadStorage()
+	}
+}
+
+// writeBackAccounts saves the changes to the accounts store, if any.
+// Also reports the errors that occurred while loading accounts since the previous write-back.
+func (b *MockWorld) writeBackAccounts() error {
+	if b.AccountsStore == nil {
+		return nil
+	}
+
+	err := b.AccountsStore.err
+	b.AccountsStore.err = nil
+	if err != nil {
+		return fmt.Errorf("could not load account from store: %w", err)
+	}
+
+	err = b.AccountsStore.writeBack(b.AcctMap)
+	if err != nil {
+		return err
+	}
+	if b.StateTrie == nil {
+		b.AccountsStore.evictLoaded(b.AcctMap)
+	}
+	return nil
+}
+
+func (as *AccountsStore) rememberError(err error) {
+	if as.err == nil {
+		as.err = err
+	}
+}
+
+// LoadStorage brings all the storage of an account loaded from an accounts store into Storage,
+// for operations that need to see every key. The keys are otherwise loaded when first read.
+func (a *Account) LoadStorage() {
+	if a.storageStore != nil {
+		a.storageStore.loadStorage(a, "")
+	}
+}
+
+// loadProtectedStorage brings the protected keys of the account into Storage, such as the ESDT data.
+func (a *Account) loadProtectedStorage() {
+	if a.storageStore != nil {
+		a.storageStore.loadStorage(a, core.ProtectedKeyPrefix)
+	}
+}
+
+// storageValue yields the value of a storage key, loading it if needed, and whether it was found.
+func (a *Account) storageValue(key string) ([]byte, bool) {
+	value, found := a.Storage[key]
+	if !found && a.storageStore != nil {
+		a.storageStore.loadStorageKey(a, key)
+		value, found = a.Storage[key]
+	}
+	return value, found
+}
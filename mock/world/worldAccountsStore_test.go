@@ -0,0 +1,230 @@
+package worldmock
+
+import (
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newStoreTestWorld(t *testing.T, path string) *MockWorld {
+	store, err := OpenAccountsStore(path)
+	require.Nil(t, err)
+
+	world := NewMockWorld()
+	world.UseAccountsStore(store)
+	return world
+}
+
+func TestAccountsStore_WriteBackAndLazyLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.accounts")
+	world := newStoreTestWorld(t, path)
+
+	account := world.AcctMap.CreateAccount([]byte("alice"), world)
+	account.Nonce = 5
+	account.Balance = big.NewInt(100)
+	account.Storage["key1"] = []byte("value1")
+	account.Storage["key2"] = []byte("value2")
+	contract := world.AcctMap.CreateSmartContractAccount([]byte("alice"), []byte("contract"), []byte("code"), world)
+	contract.Storage["counter"] = []byte{1}
+	require.Nil(t, world.CommitChanges())
+	require.Nil(t, world.AccountsStore.Close())
+
+	world = newStoreTestWorld(t, path)
+	defer func() { _ = world.AccountsStore.Close() }()
+	require.Empty(t, world.AcctMap)
+	require.Equal(t, 2, world.AccountsStore.NumAccounts())
+
+	loaded := world.GetAccount([]byte("alice"))
+	require.NotNil(t, loaded)
+	require.Len(t, world.AcctMap, 1)
+	require.Equal(t, uint64(5), loaded.Nonce)
+	require.Equal(t, big.NewInt(100), loaded.Balance)
+	require.Equal(t, world, loaded.MockWorld)
+
+	// the storage keys are loaded when first read
+	require.Empty(t, loaded.Storage)
+	require.Equal(t, []byte("value1"), loaded.StorageValue("key1"))
+	require.Equal(t, map[string][]byte{"key1": []byte("value1")}, loaded.Storage)
+	require.Empty(t, loaded.StorageValue("missing"))
+	loaded.LoadStorage()
+	require.Equal(t, map[string][]byte{"key1": []byte("value1"), "key2": []byte("value2")}, loaded.Storage)
+
+	loadedContract := world.GetAccount([]byte("contract"))
+	require.True(t, loadedContract.IsSmartContract)
+	require.Equal(t, []byte("code"), loadedContract.Code)
+	require.Equal(t, contract.CodeHash, loadedContract.CodeHash)
+	require.Equal(t, []byte("alice"), loadedContract.OwnerAddress)
+
+	require.Nil(t, world.GetAccount([]byte("missing")))
+}
+
+func TestAccountsStore_ChangesAndRemovals(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.accounts")
+	world := newStoreTestWorld(t, path)
+	account := world.AcctMap.CreateAccount([]byte("alice"), world)
+	account.Storage["key1"] = []byte("value1")
+	account.Storage["key2"] = []byte("value2")
+	world.AcctMap.CreateAccount([]byte("bob"), world)
+	require.Nil(t, world.CommitChanges())
+
+	require.Nil(t, world.UpdateBalance([]byte("alice"), big.NewInt(7)))
+	require.Nil(t, world.GetAccount([]byte("alice")).SaveKeyValue([]byte("key1"), []byte{}))
+	alice := world.GetAccount([]byte("alice"))
+	alice.Storage["key2"] = nil
+	alice.Storage["key3"] = []byte("value3")
+	world.MarkAccountChanged(alice.Address)
+	require.Nil(t, world.AccountsAdapter.RemoveAccount([]byte("bob")))
+	require.Nil(t, world.GetAccount([]byte("bob")))
+	require.Nil(t, world.CommitChanges())
+	require.Nil(t, world.AccountsStore.Close())
+
+	world = newStoreTestWorld(t, path)
+	defer func() { _ = world.AccountsStore.Close() }()
+	require.Nil(t, world.GetAccount([]byte("bob")))
+	alice = world.GetAccount([]byte("alice"))
+	require.Equal(t, big.NewInt(7), alice.Balance)
+	alice.LoadStorage()
+	require.Equal(t, map[string][]byte{"key3": []byte("value3")}, alice.Storage)
+	require.Equal(t, [][]byte{[]byte("alice")}, world.AccountsStore.Addresses())
+}
+
+func TestAccountsStore_RevertedChangesNotWritten(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.accounts")
+	world := newStoreTestWorld(t, path)
+	world.AcctMap.CreateAccount([]byte("alice"), world).Storage["key"] = []byte("before")
+	require.Nil(t, world.CommitChanges())
+
+	// the account is not loaded yet when the snapshot is taken
+	world.Clear()
+	require.Empty(t, world.AcctMap)
+	world.CreateStateBackup()
+	account, err := world.AccountsAdapter.LoadAccount([]byte("alice"))
+	require.Nil(t, err)
+	require.Nil(t, account.(*Account).SaveKeyValue([]byte("key"), []byte("after")))
+	require.Nil(t, world.AccountsAdapter.RemoveAccount([]byte("alice")))
+	require.Nil(t, world.RollbackChanges())
+	require.Nil(t, world.CommitChanges())
+	require.Nil(t, world.AccountsStore.Close())
+
+	world = newStoreTestWorld(t, path)
+	defer func() { _ = world.AccountsStore.Close() }()
+	require.Equal(t, []byte("before"), world.GetAccount([]byte("alice")).StorageValue("key"))
+}
+
+func TestAccountsStore_LoadAllAccounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.accounts")
+	world := newStoreTestWorld(t, path)
+	for _, address := range []string{"a", "b", "c"} {
+		world.AcctMap.CreateAccount([]byte(address), world)
+	}
+	require.Nil(t, world.CommitChanges())
+
+	world.Clear()
+	require.Empty(t, world.AcctMap)
+	world.LoadAllAccounts()
+	require.Len(t, world.AcctMap, 3)
+	require.Nil(t, world.AccountsStore.Close())
+}
+
+func TestAccountsStore_OnlyChangesWritten(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.accounts")
+	world := newStoreTestWorld(t, path)
+	account := world.AcctMap.CreateAccount([]byte("alice"), world)
+	account.Storage["key1"] = []byte("value1")
+	account.Storage["key2"] = []byte("value2")
+	require.Nil(t, world.CommitChanges())
+
+	// changes made directly in the account map are not written, unless marked
+	account.Nonce = 3
+	account.Storage["key1"] = []byte("unmarked")
+	require.Nil(t, world.CommitChanges())
+	require.Nil(t, account.SaveKeyValue([]byte("key2"), []byte("changed")))
+	require.Nil(t, world.CommitChanges())
+	require.Nil(t, world.AccountsStore.Close())
+
+	world = newStoreTestWorld(t, path)
+	account = world.GetAccount([]byte("alice"))
+	require.Equal(t, uint64(3), account.Nonce)
+	require.Equal(t, []byte("value1"), account.StorageValue("key1"))
+	require.Equal(t, []byte("changed"), account.StorageValue("key2"))
+
+	account.Storage["key1"] = []byte("marked")
+	world.MarkAccountChanged(account.Address)
+	require.Nil(t, world.CommitChanges())
+	require.Nil(t, world.AccountsStore.Close())
+
+	world = newStoreTestWorld(t, path)
+	defer func() { _ = world.AccountsStore.Close() }()
+	require.Equal(t, []byte("marked"), world.GetAccount([]byte("alice")).StorageValue("key1"))
+}
+
+func TestAccountsStore_EvictsLoadedAccounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.accounts")
+	world := newStoreTestWorld(t, path)
+	defer func() { _ = world.AccountsStore.Close() }()
+	world.AccountsStore.MaxLoadedAccounts = 2
+
+	contract := world.AcctMap.CreateSmartContractAccount([]byte("alice"), []byte("contract"), []byte("code"), world)
+	require.Nil(t, contract.SaveKeyValue([]byte("key"), []byte("value")))
+	world.AcctMap.CreateAccount([]byte("alice"), world)
+	require.Nil(t, world.CommitChanges())
+	require.Len(t, world.AcctMap, 2)
+
+	world.AcctMap.CreateAccount([]byte("bob"), world)
+	require.Nil(t, world.CommitChanges())
+	require.Empty(t, world.AcctMap)
+
+	// the evicted accounts are loaded again, as written
+	require.Equal(t, []byte("value"), world.GetAccount([]byte("contract")).StorageValue("key"))
+	require.NotNil(t, world.GetAccount([]byte("bob")))
+	require.Nil(t, world.AccountsAdapter.RemoveAccount([]byte("bob")))
+	require.Nil(t, world.CommitChanges())
+	require.Nil(t, world.GetAccount([]byte("bob")))
+	require.Equal(t, 2, world.AccountsStore.NumAccounts())
+
+	// not while the state trie needs all the accounts
+	world.EnableStateTrie()
+	world.AcctMap.CreateAccount([]byte("carol"), world)
+	require.Nil(t, world.CommitChanges())
+	require.Len(t, world.AcctMap, 3)
+}
+
+func TestAccountsStore_GetCodeOfAccountsNotLoaded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.accounts")
+	world := newStoreTestWorld(t, path)
+	contract := world.AcctMap.CreateSmartContractAccount([]byte("alice"), []byte("contract"), []byte("code"), world)
+	codeHash := contract.CodeHash
+	require.Nil(t, world.CommitChanges())
+	require.Nil(t, world.AccountsStore.Close())
+
+	world = newStoreTestWorld(t, path)
+	defer func() { _ = world.AccountsStore.Close() }()
+	require.Equal(t, []byte("code"), world.AccountsAdapter.GetCode(codeHash))
+	require.Empty(t, world.AcctMap)
+	require.Nil(t, world.AccountsAdapter.GetCode([]byte("missing")))
+}
+
+func TestAccountsStore_RestoreStateReplacesTheStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "world.accounts")
+	world := newStoreTestWorld(t, path)
+	defer func() { _ = world.AccountsStore.Close() }()
+	world.AcctMap.CreateAccount([]byte("alice"), world).Storage["key"] = []byte("before")
+	require.Nil(t, world.CommitChanges())
+	state := world.CloneState()
+
+	require.Nil(t, world.GetAccount([]byte("alice")).SaveKeyValue([]byte("key"), []byte("after")))
+	require.Nil(t, world.GetAccount([]byte("alice")).SaveKeyValue([]byte("other"), []byte("after")))
+	world.AcctMap.CreateAccount([]byte("bob"), world)
+	require.Nil(t, world.CommitChanges())
+
+	world.RestoreState(state)
+	require.Nil(t, world.GetAccount([]byte("bob")))
+	require.Nil(t, world.CommitChanges())
+	world.Clear()
+	require.Equal(t, [][]byte{[]byte("alice")}, world.AccountsStore.Addresses())
+	alice := world.GetAccount([]byte("alice"))
+	alice.LoadStorage()
+	require.Equal(t, map[string][]byte{"key": []byte("before")}, alice.Storage)
+}
@@ -20,8 +20,9 @@ var ErrBuiltinFuncWrapperNotInitialized = errors.New("builtin function not found
 var zero = big.NewInt(0)
 
 // NewAddress provides the address for a new account.
-// It looks up the explicit new address mocks, if none found generates one using a fake but realistic algorithm.
-func (b *MockWorld) NewAddress(creatorAddress []byte, creatorNonce uint64, _ []byte) ([]byte, error) {
+// It looks up the explicit new address mocks, if none found generates one using a fake but realistic algorithm,
+// or, if DeriveContractAddresses is set, derives it exactly like the protocol.
+func (b *MockWorld) NewAddress(creatorAddress []byte, creatorNonce uint64, vmType []byte) ([]byte, error) {
 	// custom error
 	if b.Err != nil {
 		return nil, b.Err
@@ -36,6 +37,15 @@ func (b *MockWorld) NewAddress(creatorAddress []byte, creatorNonce uint64, _ []b
 		}
 	}
 
+	if b.DeriveContractAddresses {
+		result, err := GenerateContractAddress(creatorAddress, creatorNonce, vmType)
+		if err != nil {
+			return nil, err
+		}
+		b.LastCreatedContractAddress = result
+		return result, nil
+	}
+
 	// If a mock address wasn't registered for the specified creatorAddress, generate one automatically.
 	// This is not the real algorithm but it's simple and close enough.
 	result := GenerateMockAddress(creatorAddress, creatorNonce)
@@ -51,7 +61,7 @@ func (b *MockWorld) GetStorageData(accountAddress []byte, key []byte) ([]byte, u
 		return nil, 0, b.Err
 	}
 
-	acct := b.AcctMap.GetAccount(accountAddress)
+	acct := b.GetAccount(accountAddress)
 	if acct == nil {
 		return []byte{}, 0, nil
 	}
@@ -104,7 +114,7 @@ func (b *MockWorld) LastRandomSeed() []byte {
 	if b.PreviousBlockInfo == nil {
 		return nil
 	}
-	return b.PreviousBlockInfo.GetRandomSeedSlice()
+	return b.blockRandomSeed(b.PreviousBlockInfo)
 }
 
 // LastEpoch returns the epoch from the last committed block
@@ -149,7 +159,7 @@ func (b *MockWorld) CurrentRandomSeed() []byte {
 	if b.CurrentBlockInfo == nil {
 		return nil
 	}
-	return b.CurrentBlockInfo.GetRandomSeedSlice()
+	return b.blockRandomSeed(b.CurrentBlockInfo)
 }
 
 // CurrentEpoch returns the current epoch
@@ -195,10 +205,11 @@ func (b *MockWorld) GetBuiltinFunctionNames() vmcommon.FunctionNames {
 
 // GetAllState simply returns the storage as-is.
 func (b *MockWorld) GetAllState(accountAddress []byte) (map[string][]byte, error) {
-	account := b.AcctMap.GetAccount(accountAddress)
+	account := b.GetAccount(accountAddress)
 	if account == nil {
 		return nil, fmt.Errorf("account not found: %s", hex.EncodeToString(accountAddress))
 	}
+	account.LoadStorage()
 	return account.Storage, nil
 }
 
@@ -209,17 +220,18 @@ func (b *MockWorld) GetUserAccount(address []byte) (vmcommon.UserAccountHandler,
 		return nil, b.Err
 	}
 
-	account := b.AcctMap.GetAccount(address)
+	account := b.GetAccount(address)
 	if account == nil {
 		return nil, fmt.Errorf("account not found: %s", hex.EncodeToString(address))
 	}
 
+	b.journalAccount(address)
 	return account, nil
 }
 
 // GetCode retrieves the code from the given account, or nil if not found
 func (b *MockWorld) GetCode(acc vmcommon.UserAccountHandler) []byte {
-	account := b.AcctMap.GetAccount(acc.AddressBytes())
+	account := b.GetAccount(acc.AddressBytes())
 	if account == nil {
 		return nil
 	}
@@ -229,7 +241,7 @@ func (b *MockWorld) GetCode(acc vmcommon.UserAccountHandler) []byte {
 
 // GetShardOfAddress -
 func (b *MockWorld) GetShardOfAddress(address []byte) uint32 {
-	account := b.AcctMap.GetAccount(address)
+	account := b.GetAccount(address)
 	if account == nil {
 		return 0
 	}
@@ -239,7 +251,7 @@ func (b *MockWorld) GetShardOfAddress(address []byte) uint32 {
 
 // IsSmartContract -
 func (b *MockWorld) IsSmartContract(address []byte) bool {
-	account := b.AcctMap.GetAccount(address)
+	account := b.GetAccount(address)
 	if account == nil {
 		return vmcommon.IsSmartContractAddress(address)
 	}
@@ -249,7 +261,7 @@ func (b *MockWorld) IsSmartContract(address []byte) bool {
 
 // IsPayable -
 func (b *MockWorld) IsPayable(sndAddress []byte, rcvAddress []byte) (bool, error) {
-	account := b.AcctMap.GetAccount(rcvAddress)
+	account := b.GetAccount(rcvAddress)
 	if account == nil {
 		return true, nil
 	}
@@ -0,0 +1,60 @@
+package worldmock
+
+// accountChanges tells what changed in an account since the changes were last picked up.
+// The fields are always picked up, the storage only for the changed keys, unless it changed as a whole.
+type accountChanges struct {
+	keys         map[string]struct{}
+	wholeStorage bool
+}
+
+// changedAccounts holds the changes to the accounts, by address.
+type changedAccounts map[string]*accountChanges
+
+func (changes changedAccounts) account(address []byte) *accountChanges {
+	changed, found := changes[string(address)]
+	if !found {
+		changed = &accountChanges{keys: make(map[string]struct{})}
+		changes[string(address)] = changed
+	}
+	return changed
+}
+
+func (changes changedAccounts) markStorageKey(address []byte, key string) {
+	changes.account(address).keys[key] = struct{}{}
+}
+
+func (changes changedAccounts) markWholeStorage(address []byte) {
+	changes.account(address).wholeStorage = true
+}
+
+// trackAccountChange tells the state trie and the accounts store, if any, that the fields of an account changed,
+// or that the account was created or removed.
+func (b *MockWorld) trackAccountChange(address []byte) {
+	if b.StateTrie != nil {
+		b.StateTrie.MarkAccountChanged(address)
+	}
+	if b.AccountsStore != nil {
+		b.AccountsStore.changes.account(address)
+	}
+}
+
+// trackStorageChange tells the state trie and the accounts store, if any, that a storage key changed.
+func (b *MockWorld) trackStorageChange(address []byte, key string) {
+	if b.StateTrie != nil {
+		b.StateTrie.MarkStorageChanged(address, key)
+	}
+	if b.AccountsStore != nil {
+		b.AccountsStore.changes.markStorageKey(address, key)
+	}
+}
+
+// trackWholeStorageChange tells the state trie and the accounts store, if any,
+// that any of the storage keys of an account might have changed.
+func (b *MockWorld) trackWholeStorageChange(address []byte) {
+	if b.StateTrie != nil {
+		b.StateTrie.MarkWholeStorageChanged(address)
+	}
+	if b.AccountsStore != nil {
+		b.AccountsStore.changes.markWholeStorage(address)
+	}
+}
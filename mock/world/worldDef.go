@@ -48,6 +48,12 @@ type MockWorld struct {
 	BuiltinFuncs               *BuiltinFunctionsWrapper
 	IsPausedValue              bool
 	IsLimitedTransferValue     bool
+	SystemSCMocks              map[string]*SystemSCMock
+	RandomSeedMaster           []byte
+	EnableEpochsHandler        *EnableEpochsHandler
+	StateTrie                  *StateTrie
+	AccountsStore              *AccountsStore
+	DeriveContractAddresses    bool
 }
 
 // NewMockWorld creates a new MockWorld instance
@@ -65,6 +71,7 @@ func NewMockWorld() *MockWorld {
 		BuiltinFuncs:      nil,
 	}
 	world.AccountsAdapter = NewMockAccountsAdapter(world)
+	world.EnableEpochsHandler, _ = NewEnableEpochsHandler(DefaultActivationEpochs())
 
 	return world
 }
@@ -90,6 +97,55 @@ func (b *MockWorld) Clear() {
 	b.Blockhashes = nil
 	b.NewAddressMocks = nil
 	b.CompiledCode = make(map[string][]byte)
+	b.RandomSeedMaster = nil
+	if b.AccountsStore != nil {
+		// the accounts on disk stay, only the changes not written back are dropped
+		b.AccountsStore.forgetLoaded()
+	}
+	b.createSystemSCMockAccounts()
+	if b.StateTrie != nil {
+		b.StateTrie = NewStateTrie()
+		b.LoadAllAccounts()
+		b.CommitStateTrie()
+	}
+}
+
+// EnableStateTrie makes the world compute the state root hash and the account root hashes
+// from Merkle tries, after each commit. Until enabled, the state root hash is the fixed StateRootHash.
+func (b *MockWorld) EnableStateTrie() {
+	if b.StateTrie != nil {
+		return
+	}
+	b.StateTrie = NewStateTrie()
+	b.LoadAllAccounts()
+	b.CommitStateTrie()
+}
+
+// MarkAccountChanged tells the state trie and the accounts store that an account, storage included,
+// was changed directly in the account map, so that the next commit picks up the change.
+// The changes made through the world and the accounts adapter are tracked already.
+func (b *MockWorld) MarkAccountChanged(address []byte) {
+	b.trackWholeStorageChange(address)
+}
+
+// CommitStateTrie updates the state root hash and the account root hashes to the current accounts.
+// Does nothing if the state trie is not enabled.
+func (b *MockWorld) CommitStateTrie() {
+	if b.StateTrie == nil {
+		return
+	}
+	b.StateRootHash = b.StateTrie.Update(b.AcctMap)
+}
+
+// ConfirmCurrentEpoch activates the protocol flags of the epoch of the current block.
+func (b *MockWorld) ConfirmCurrentEpoch() {
+	epoch := uint32(0)
+	timestamp := uint64(0)
+	if b.CurrentBlockInfo != nil {
+		epoch = b.CurrentBlockInfo.BlockEpoch
+		timestamp = b.CurrentBlockInfo.BlockTimestamp
+	}
+	b.EnableEpochsHandler.EpochConfirmed(epoch, timestamp)
 }
 
 // SetCurrentBlockHash -
@@ -114,7 +170,7 @@ func (b *MockWorld) NumberOfShards() uint32 {
 
 // ComputeId -
 func (b *MockWorld) ComputeId(address []byte) uint32 {
-	return b.AcctMap.GetAccount(address).ShardID
+	return b.GetAccount(address).ShardID
 }
 
 // SelfId -
@@ -124,8 +180,8 @@ func (b *MockWorld) SelfId() uint32 {
 
 // SameShard -
 func (b *MockWorld) SameShard(firstAddress []byte, secondAddress []byte) bool {
-	firstAccount := b.AcctMap.GetAccount(firstAddress)
-	secondAccount := b.AcctMap.GetAccount(secondAddress)
+	firstAccount := b.GetAccount(firstAddress)
+	secondAccount := b.GetAccount(secondAddress)
 	return firstAccount.ShardID == secondAccount.ShardID
 }
 
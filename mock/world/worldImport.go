@@ -0,0 +1,325 @@
+package worldmock
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"strings"
+
+	"github.com/multiversx/mx-chain-core-go/core"
+	"github.com/multiversx/mx-chain-core-go/core/pubkeyConverter"
+	"github.com/multiversx/mx-chain-core-go/data/esdt"
+	logger "github.com/multiversx/mx-chain-logger-go"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/esdtconvert"
+)
+
+const apiResponseCodeSuccessful = "successful"
+
+var logImport = logger.GetOrCreate("mock/world/import")
+
+// exportedAccountEntry is what the node and proxy APIs yield about an account, merged into one object:
+// the data of /address/<address> under "account", of /address/<address>/keys under "pairs"
+// and of /address/<address>/esdt under "esdts".
+type exportedAccountEntry struct {
+	Account *exportedAccount                `json:"account"`
+	Pairs   map[string]string               `json:"pairs"`
+	ESDTs   map[string]*exportedESDTHolding `json:"esdts"`
+}
+
+type exportedAccount struct {
+	Address         string    `json:"address"`
+	Nonce           uint64    `json:"nonce"`
+	Balance         apiBigInt `json:"balance"`
+	Username        string    `json:"username"`
+	Code            string    `json:"code"`
+	CodeMetadata    []byte    `json:"codeMetadata"`
+	OwnerAddress    string    `json:"ownerAddress"`
+	DeveloperReward apiBigInt `json:"developerReward"`
+}
+
+type exportedESDTHolding struct {
+	TokenIdentifier string    `json:"tokenIdentifier"`
+	Nonce           uint64    `json:"nonce"`
+	Balance         apiBigInt `json:"balance"`
+	Properties      string    `json:"properties"`
+	Name            string    `json:"name"`
+	Creator         string    `json:"creator"`
+	Royalties       apiBigInt `json:"royalties"`
+	Hash            []byte    `json:"hash"`
+	URIs            [][]byte  `json:"uris"`
+	Attributes      []byte    `json:"attributes"`
+}
+
+// apiBigInt is a number that the APIs give either as a decimal string or as a plain JSON number.
+type apiBigInt struct {
+	value *big.Int
+}
+
+// UnmarshalJSON accepts both quoted and unquoted decimal numbers.
+func (number *apiBigInt) UnmarshalJSON(data []byte) error {
+	text := strings.Trim(string(data), "\"")
+	if len(text) == 0 || text == "null" {
+		number.value = nil
+		return nil
+	}
+
+	value, ok := big.NewInt(0).SetString(text, 10)
+	if !ok {
+		return fmt.Errorf("invalid number: %s", string(data))
+	}
+	number.value = value
+	return nil
+}
+
+func (number apiBigInt) orZero() *big.Int {
+	return bigIntOrZero(number.value)
+}
+
+// ImportAccountsFile reads the accounts exported from a node or a proxy into a file,
+// without any network access.
+// The file holds one account entry, a list of entries, or an object with the list under "accounts".
+// An entry merges the data of the API responses for the account:
+//
+//	{
+//	    "account": { "address": "erd1...", "nonce": 1, "balance": "100", "code": "<hex>", ... },
+//	    "pairs": { "<hex key>": "<hex value>" },
+//	    "esdts": { "TOKEN-123456": { "tokenIdentifier": "TOKEN-123456", "balance": "5" } }
+//	}
+//
+// An entry may also be an entire API response, with the fields above under "data".
+// Addresses are either bech32 or hex. The accounts belong to the given world, which can be nil.
+func ImportAccountsFile(path string, world *MockWorld) (AccountMap, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := ParseExportedAccounts(data, world)
+	if err != nil {
+		return nil, fmt.Errorf("could not import accounts from %s: %w", path, err)
+	}
+	return accounts, nil
+}
+
+// ParseExportedAccounts converts accounts in the format of the node and proxy APIs, as described in ImportAccountsFile.
+func ParseExportedAccounts(data []byte, world *MockWorld) (AccountMap, error) {
+	rawEntries, err := splitExportedEntries(data)
+	if err != nil {
+		return nil, err
+	}
+
+	converter, err := pubkeyConverter.NewBech32PubkeyConverter(32, logImport)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := NewAccountMap()
+	for i, rawEntry := range rawEntries {
+		account, err := parseExportedAccountEntry(rawEntry, converter, world)
+		if err != nil {
+			return nil, fmt.Errorf("account entry %d: %w", i, err)
+		}
+		if _, exists := accounts[string(account.Address)]; exists {
+			return nil, fmt.Errorf("account entry %d: duplicate account %x", i, account.Address)
+		}
+		accounts.PutAccount(account)
+	}
+
+	return accounts, nil
+}
+
+// ImportAccounts adds the accounts exported from a node or a proxy to the world, replacing the existing ones.
+// The import is recorded by the accounts journal, so it can be rolled back.
+func (b *MockWorld) ImportAccounts(path string) error {
+	accounts, err := ImportAccountsFile(path, b)
+	if err != nil {
+		return err
+	}
+
+	for _, account := range accounts {
+		b.journalAccount(account.Address)
+		b.AcctMap.PutAccount(account)
+	}
+	return nil
+}
+
+func splitExportedEntries(data []byte) ([]json.RawMessage, error) {
+	var document json.RawMessage
+	err := json.Unmarshal(data, &document)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []json.RawMessage
+	if json.Unmarshal(document, &entries) == nil {
+		return entries, nil
+	}
+
+	var wrapper struct {
+		Accounts []json.RawMessage `json:"accounts"`
+	}
+	err = json.Unmarshal(document, &wrapper)
+	if err != nil {
+		return nil, errors.New("expected an account entry, a list of entries or an object with \"accounts\"")
+	}
+	if wrapper.Accounts != nil {
+		return wrapper.Accounts, nil
+	}
+	return []json.RawMessage{document}, nil
+}
+
+// unwrapAPIResponse yields the data of an API response, or the entry itself, if it is not wrapped.
+func unwrapAPIResponse(rawEntry json.RawMessage) (json.RawMessage, error) {
+	var response struct {
+		Data  json.RawMessage `json:"data"`
+		Error string          `json:"error"`
+		Code  string          `json:"code"`
+	}
+	err := json.Unmarshal(rawEntry, &response)
+	if err != nil {
+		return nil, err
+	}
+	if response.Data == nil {
+		return rawEntry, nil
+	}
+	if len(response.Code) > 0 && response.Code != apiResponseCodeSuccessful {
+		return nil, fmt.Errorf("unsuccessful API response: %s %s", response.Code, response.Error)
+	}
+	return response.Data, nil
+}
+
+func parseExportedAccountEntry(rawEntry json.RawMessage, converter core.PubkeyConverter, world *MockWorld) (*Account, error) {
+	rawEntry, err := unwrapAPIResponse(rawEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &exportedAccountEntry{}
+	err = json.Unmarshal(rawEntry, entry)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Account == nil || len(entry.Account.Address) == 0 {
+		return nil, errors.New("missing account address")
+	}
+
+	address, err := decodeExportedAddress(entry.Account.Address, converter)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := decodeExportedAddress(entry.Account.OwnerAddress, converter)
+	if err != nil {
+		return nil, fmt.Errorf("owner of %s: %w", entry.Account.Address, err)
+	}
+	code, err := hex.DecodeString(entry.Account.Code)
+	if err != nil {
+		return nil, fmt.Errorf("code of %s: %w", entry.Account.Address, err)
+	}
+
+	account := &Account{
+		Exists:          true,
+		Address:         address,
+		Nonce:           entry.Account.Nonce,
+		Balance:         entry.Account.Balance.orZero(),
+		BalanceDelta:    big.NewInt(0),
+		Storage:         make(map[string][]byte),
+		OwnerAddress:    owner,
+		Username:        []byte(entry.Account.Username),
+		DeveloperReward: entry.Account.DeveloperReward.orZero(),
+		IsSmartContract: core.IsSmartContractAddress(address) || len(code) > 0,
+		MockWorld:       world,
+	}
+	if len(code) > 0 {
+		account.Code = code
+		account.CodeMetadata = entry.Account.CodeMetadata
+	}
+
+	for hexKey, hexValue := range entry.Pairs {
+		key, err := hex.DecodeString(hexKey)
+		if err != nil {
+			return nil, fmt.Errorf("storage key of %s: %w", entry.Account.Address, err)
+		}
+		value, err := hex.DecodeString(hexValue)
+		if err != nil {
+			return nil, fmt.Errorf("storage value of %s: %w", entry.Account.Address, err)
+		}
+		if len(value) > 0 {
+			account.Storage[string(key)] = value
+		}
+	}
+
+	for name, holding := range entry.ESDTs {
+		err = writeExportedESDTHolding(account, name, holding, converter)
+		if err != nil {
+			return nil, fmt.Errorf("token %s of %s: %w", name, entry.Account.Address, err)
+		}
+	}
+
+	return account, nil
+}
+
+// writeExportedESDTHolding saves a token balance in the storage, the way the ESDT system functions do.
+// Holdings listed both here and in the storage pairs end up the same.
+func writeExportedESDTHolding(account *Account, name string, holding *exportedESDTHolding, converter core.PubkeyConverter) error {
+	tokenIdentifier := holding.TokenIdentifier
+	if len(tokenIdentifier) == 0 {
+		tokenIdentifier = name
+	}
+	if holding.Nonce > 0 {
+		// the APIs sometimes give the identifier of the instance, e.g. NFT-123456-0a, instead of that of the collection
+		tokenIdentifier = strings.TrimSuffix(tokenIdentifier, "-"+hex.EncodeToString(big.NewInt(0).SetUint64(holding.Nonce).Bytes()))
+	}
+
+	properties, err := hex.DecodeString(holding.Properties)
+	if err != nil {
+		return err
+	}
+	if len(properties) == 0 {
+		properties = esdtconvert.MakeESDTUserMetadataBytes(false)
+	}
+	creator, err := decodeExportedAddress(holding.Creator, converter)
+	if err != nil {
+		return err
+	}
+
+	tokenData := &esdt.ESDigitalToken{
+		Value:      holding.Balance.orZero(),
+		Type:       uint32(core.Fungible),
+		Properties: properties,
+	}
+	if holding.Nonce > 0 {
+		tokenData.Type = uint32(core.NonFungible)
+		tokenData.TokenMetaData = &esdt.MetaData{
+			Name:       []byte(holding.Name),
+			Nonce:      holding.Nonce,
+			Creator:    creator,
+			Royalties:  uint32(holding.Royalties.orZero().Uint64()),
+			Hash:       holding.Hash,
+			URIs:       holding.URIs,
+			Attributes: holding.Attributes,
+		}
+	}
+
+	return esdtconvert.SetTokenData([]byte(tokenIdentifier), holding.Nonce, tokenData, account.Storage)
+}
+
+// decodeExportedAddress accepts bech32 and hex addresses, with or without the 0x prefix.
+func decodeExportedAddress(address string, converter core.PubkeyConverter) ([]byte, error) {
+	if len(address) == 0 {
+		return nil, nil
+	}
+
+	decoded, err := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+	if err == nil && len(decoded) == converter.Len() {
+		return decoded, nil
+	}
+
+	decoded, err = converter.Decode(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address %s: %w", address, err)
+	}
+	return decoded, nil
+}
@@ -0,0 +1,142 @@
+package worldmock
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/multiversx/mx-chain-core-go/core/pubkeyConverter"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/esdtconvert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAddress(prefix string) []byte {
+	address := make([]byte, 32)
+	copy(address, prefix)
+	return address
+}
+
+func testContractAddress(name string) []byte {
+	address := make([]byte, 32)
+	copy(address[10:], name)
+	return address
+}
+
+func TestImportAccountsFile(t *testing.T) {
+	converter, err := pubkeyConverter.NewBech32PubkeyConverter(32, logImport)
+	require.Nil(t, err)
+	owner := testAddress("owner")
+	contract := testContractAddress("contract")
+
+	export := fmt.Sprintf(`{
+		"accounts": [
+			{
+				"data": {
+					"account": {
+						"address": "%s",
+						"nonce": 3,
+						"balance": "1000000000000000000000",
+						"username": "owner.elrond",
+						"code": "",
+						"developerReward": "0"
+					},
+					"esdts": {
+						"TOKEN-123456": { "tokenIdentifier": "TOKEN-123456", "balance": "500" },
+						"NFT-123456-0a": {
+							"tokenIdentifier": "NFT-123456-0a",
+							"nonce": 10,
+							"balance": "1",
+							"creator": "%s",
+							"royalties": "250",
+							"attributes": "YXR0cg==",
+							"uris": ["dXJp"]
+						}
+					}
+				},
+				"error": "",
+				"code": "successful"
+			},
+			{
+				"account": {
+					"address": "0x%s",
+					"nonce": 0,
+					"balance": 5,
+					"code": "0061736d01000000",
+					"codeMetadata": "BQA=",
+					"ownerAddress": "%s"
+				},
+				"pairs": {
+					"%s": "%s",
+					"%s": ""
+				}
+			}
+		]
+	}`,
+		converter.Encode(owner), converter.Encode(owner),
+		hex.EncodeToString(contract), converter.Encode(owner),
+		hex.EncodeToString([]byte("counter")), hex.EncodeToString([]byte{7}),
+		hex.EncodeToString([]byte("empty")),
+	)
+	path := filepath.Join(t.TempDir(), "export.json")
+	require.Nil(t, ioutil.WriteFile(path, []byte(export), 0644))
+
+	world := NewMockWorld()
+	require.Nil(t, world.ImportAccounts(path))
+	require.Len(t, world.AcctMap, 2)
+
+	ownerAccount := world.AcctMap.GetAccount(owner)
+	require.NotNil(t, ownerAccount)
+	require.Equal(t, uint64(3), ownerAccount.Nonce)
+	expectedBalance, _ := big.NewInt(0).SetString("1000000000000000000000", 10)
+	require.Equal(t, expectedBalance, ownerAccount.Balance)
+	require.Equal(t, []byte("owner.elrond"), ownerAccount.Username)
+	require.False(t, ownerAccount.IsSmartContract)
+	require.Equal(t, world, ownerAccount.MockWorld)
+
+	balance, err := ownerAccount.GetTokenBalance([]byte("TOKEN-123456"), 0)
+	require.Nil(t, err)
+	require.Equal(t, big.NewInt(500), balance)
+	nftData, err := esdtconvert.GetTokenData([]byte("NFT-123456"), 10, ownerAccount.Storage, make(map[string][]byte))
+	require.Nil(t, err)
+	require.Equal(t, big.NewInt(1), nftData.Value)
+	require.Equal(t, owner, nftData.TokenMetaData.Creator)
+	require.Equal(t, uint32(250), nftData.TokenMetaData.Royalties)
+	require.Equal(t, []byte("attr"), nftData.TokenMetaData.Attributes)
+	require.Equal(t, [][]byte{[]byte("uri")}, nftData.TokenMetaData.URIs)
+
+	contractAccount := world.AcctMap.GetAccount(contract)
+	require.NotNil(t, contractAccount)
+	require.True(t, contractAccount.IsSmartContract)
+	require.Equal(t, big.NewInt(5), contractAccount.Balance)
+	require.Equal(t, []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}, contractAccount.Code)
+	require.NotEmpty(t, contractAccount.CodeHash)
+	require.Equal(t, []byte{5, 0}, contractAccount.CodeMetadata)
+	require.Equal(t, owner, contractAccount.OwnerAddress)
+	require.Equal(t, map[string][]byte{"counter": {7}}, contractAccount.Storage)
+}
+
+func TestParseExportedAccounts_Errors(t *testing.T) {
+	_, err := ParseExportedAccounts([]byte(`{"account": {"nonce": 1}}`), nil)
+	require.NotNil(t, err)
+
+	_, err = ParseExportedAccounts([]byte(`{"data": null, "error": "not found", "code": "internal_issue"}`), nil)
+	require.NotNil(t, err)
+
+	_, err = ParseExportedAccounts([]byte(`{"data": {}, "error": "not found", "code": "internal_issue"}`), nil)
+	require.NotNil(t, err)
+
+	_, err = ParseExportedAccounts([]byte(`{"account": {"address": "erd1invalid"}}`), nil)
+	require.NotNil(t, err)
+
+	address := hex.EncodeToString(testAddress("alice"))
+	duplicate := fmt.Sprintf(`[{"account": {"address": "%s"}}, {"account": {"address": "%s"}}]`, address, address)
+	_, err = ParseExportedAccounts([]byte(duplicate), nil)
+	require.NotNil(t, err)
+
+	accounts, err := ParseExportedAccounts([]byte(fmt.Sprintf(`{"account": {"address": "%s"}}`, address)), nil)
+	require.Nil(t, err)
+	require.Equal(t, big.NewInt(0), accounts.GetAccount(testAddress("alice")).Balance)
+}
@@ -0,0 +1,165 @@
+package worldmock
+
+// WorldState is a deep copy of the mutable parts of a MockWorld,
+// which can be restored any number of times.
+// The components of the world, like the accounts adapter, the builtin functions,
+// the state trie and the accounts store, are not part of it.
+type WorldState struct {
+	SelfShardID                uint32
+	AcctMap                    AccountMap
+	PreviousBlockInfo          *BlockInfo
+	CurrentBlockInfo           *BlockInfo
+	Blockhashes                [][]byte
+	NewAddressMocks            []*NewAddressMock
+	StateRootHash              []byte
+	Err                        error
+	LastCreatedContractAddress []byte
+	CompiledCode               map[string][]byte
+	IsPausedValue              bool
+	IsLimitedTransferValue     bool
+	SystemSCMocks              map[string]*SystemSCMock
+	RandomSeedMaster           []byte
+	ActivationEpochs           map[string]uint32
+	CurrentEpoch               uint32
+	DeriveContractAddresses    bool
+}
+
+// CloneState creates a copy of the mutable parts of the world.
+// With an accounts store, all the accounts get loaded, so that restoring the state
+// also undoes the changes written back to the store in the meantime.
+func (b *MockWorld) CloneState() *WorldState {
+	b.LoadAllAccounts()
+	state := &WorldState{
+		SelfShardID:                b.SelfShardID,
+		AcctMap:                    b.AcctMap.Clone(),
+		PreviousBlockInfo:          b.PreviousBlockInfo.Clone(),
+		CurrentBlockInfo:           b.CurrentBlockInfo.Clone(),
+		Blockhashes:                cloneBytesList(b.Blockhashes),
+		NewAddressMocks:            cloneNewAddressMocks(b.NewAddressMocks),
+		StateRootHash:              cloneBytesOrNil(b.StateRootHash),
+		Err:                        b.Err,
+		LastCreatedContractAddress: cloneBytesOrNil(b.LastCreatedContractAddress),
+		CompiledCode:               cloneBytesMap(b.CompiledCode),
+		IsPausedValue:              b.IsPausedValue,
+		IsLimitedTransferValue:     b.IsLimitedTransferValue,
+		SystemSCMocks:              cloneSystemSCMocks(b.SystemSCMocks),
+		RandomSeedMaster:           cloneBytesOrNil(b.RandomSeedMaster),
+		DeriveContractAddresses:    b.DeriveContractAddresses,
+	}
+	if b.EnableEpochsHandler != nil {
+		state.ActivationEpochs = b.EnableEpochsHandler.ActivationEpochs()
+		state.CurrentEpoch = b.EnableEpochsHandler.CurrentEpoch()
+	}
+	return state
+}
+
+// RestoreState replaces the mutable parts of the world with a copy of those in the given state.
+func (b *MockWorld) RestoreState(state *WorldState) {
+	b.SelfShardID = state.SelfShardID
+	b.AcctMap = state.AcctMap.Clone()
+	b.PreviousBlockInfo = state.PreviousBlockInfo.Clone()
+	b.CurrentBlockInfo = state.CurrentBlockInfo.Clone()
+	b.Blockhashes = cloneBytesList(state.Blockhashes)
+	b.NewAddressMocks = cloneNewAddressMocks(state.NewAddressMocks)
+	b.StateRootHash = cloneBytesOrNil(state.StateRootHash)
+	b.Err = state.Err
+	b.LastCreatedContractAddress = cloneBytesOrNil(state.LastCreatedContractAddress)
+	b.CompiledCode = cloneBytesMap(state.CompiledCode)
+	b.IsPausedValue = state.IsPausedValue
+	b.IsLimitedTransferValue = state.IsLimitedTransferValue
+	b.SystemSCMocks = cloneSystemSCMocks(state.SystemSCMocks)
+	b.RandomSeedMaster = cloneBytesOrNil(state.RandomSeedMaster)
+	b.DeriveContractAddresses = state.DeriveContractAddresses
+	if b.EnableEpochsHandler != nil && state.ActivationEpochs != nil {
+		// the epochs were valid when cloned
+		_ = b.EnableEpochsHandler.SetActivationEpochs(state.ActivationEpochs)
+		b.EnableEpochsHandler.EpochConfirmed(state.CurrentEpoch, 0)
+	}
+	b.createSystemSCMockAccounts()
+	if journal := b.journal(); journal != nil {
+		// the snapshots refer to the accounts that were replaced
+		journal.clear()
+	}
+	// the trie follows the restored accounts, but the root hash stays the one saved,
+	// which belongs to the last commit before the state was cloned
+	if b.StateTrie != nil {
+		b.StateTrie.MarkAllChanged()
+	}
+	b.CommitStateTrie()
+	if b.AccountsStore != nil {
+		// the restored accounts replace those in the store on the next write-back
+		b.AccountsStore.markAllChanged()
+	}
+	b.StateRootHash = cloneBytesOrNil(state.StateRootHash)
+}
+
+// Clone creates a deep copy of the block info. Nil is cloned as nil.
+func (bi *BlockInfo) Clone() *BlockInfo {
+	if bi == nil {
+		return nil
+	}
+
+	clone := *bi
+	if bi.RandomSeed != nil {
+		randomSeed := *bi.RandomSeed
+		clone.RandomSeed = &randomSeed
+	}
+
+	return &clone
+}
+
+func cloneBytesList(list [][]byte) [][]byte {
+	if list == nil {
+		return nil
+	}
+
+	clone := make([][]byte, len(list))
+	for i, b := range list {
+		clone[i] = cloneBytes(b)
+	}
+	return clone
+}
+
+func cloneBytesOrNil(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return cloneBytes(b)
+}
+
+func cloneBytesMap(m map[string][]byte) map[string][]byte {
+	if m == nil {
+		return nil
+	}
+
+	clone := make(map[string][]byte, len(m))
+	for key, value := range m {
+		clone[key] = cloneBytes(value)
+	}
+	return clone
+}
+
+// the mocks keep their state in the storage of their accounts, so they can be shared
+func cloneSystemSCMocks(systemSCMocks map[string]*SystemSCMock) map[string]*SystemSCMock {
+	if systemSCMocks == nil {
+		return nil
+	}
+
+	clone := make(map[string]*SystemSCMock, len(systemSCMocks))
+	for address, scMock := range systemSCMocks {
+		clone[address] = scMock
+	}
+	return clone
+}
+
+func cloneNewAddressMocks(newAddressMocks []*NewAddressMock) []*NewAddressMock {
+	var clone []*NewAddressMock
+	for _, newAddressMock := range newAddressMocks {
+		clone = append(clone, &NewAddressMock{
+			CreatorAddress: cloneBytes(newAddressMock.CreatorAddress),
+			CreatorNonce:   newAddressMock.CreatorNonce,
+			NewAddress:     cloneBytes(newAddressMock.NewAddress),
+		})
+	}
+	return clone
+}
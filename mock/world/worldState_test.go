@@ -0,0 +1,64 @@
+package worldmock
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func requireSameWorldState(t *testing.T, expected *WorldState, actual *WorldState) {
+	requireSameAccounts(t, expected.AcctMap, actual.AcctMap)
+
+	expectedCopy := *expected
+	actualCopy := *actual
+	expectedCopy.AcctMap = nil
+	actualCopy.AcctMap = nil
+	require.Equal(t, expectedCopy, actualCopy)
+}
+
+func TestMockWorld_RestoreStateAfterMutations(t *testing.T) {
+	world := newJournalTestWorld()
+	world.EnableStateTrie()
+	world.RegisterSystemSCMock(&SystemSCMock{Name: "first", Address: testContractAddress("first")})
+	world.CurrentBlockInfo = &BlockInfo{BlockNonce: 3, BlockEpoch: 1}
+	world.Blockhashes = [][]byte{[]byte("hash")}
+	world.CompiledCode["code hash"] = []byte("compiled")
+	world.LastCreatedContractAddress = testContractAddress("created")
+	world.ConfirmCurrentEpoch()
+	state := world.CloneState()
+
+	world.AcctMap.GetAccount([]byte("address-0")).Balance = big.NewInt(1)
+	world.AcctMap.GetAccount([]byte("address-1")).Storage["key"] = []byte("changed")
+	world.AcctMap.CreateAccount([]byte("address-new"), world)
+	world.SelfShardID = 1
+	world.PreviousBlockInfo = &BlockInfo{BlockNonce: 3}
+	world.CurrentBlockInfo = &BlockInfo{BlockNonce: 4, BlockEpoch: 5}
+	world.Blockhashes = append(world.Blockhashes, []byte("other hash"))
+	world.NewAddressMocks = []*NewAddressMock{{CreatorAddress: []byte("creator"), NewAddress: []byte("new")}}
+	world.Err = errors.New("world error")
+	world.LastCreatedContractAddress = testContractAddress("other")
+	world.CompiledCode["other code hash"] = []byte("other compiled")
+	world.IsPausedValue = true
+	world.IsLimitedTransferValue = true
+	world.RegisterSystemSCMock(&SystemSCMock{Name: "second", Address: testContractAddress("second")})
+	world.RandomSeedMaster = []byte("seed")
+	require.Nil(t, world.EnableEpochsHandler.SetActivationEpochs(map[string]uint32{GlobalMintBurnFlag: 5}))
+	world.ConfirmCurrentEpoch()
+	world.DeriveContractAddresses = true
+	world.CommitStateTrie()
+	require.NotEqual(t, state.StateRootHash, world.StateRootHash)
+
+	world.RestoreState(state)
+	requireSameWorldState(t, state, world.CloneState())
+	require.Nil(t, world.GetAccount(testContractAddress("second")))
+	require.Equal(t, uint32(1), world.EnableEpochsHandler.CurrentEpoch())
+	require.True(t, world.EnableEpochsHandler.IsFlagEnabled(GlobalMintBurnFlag))
+
+	// the state can be restored again, the restored world does not share data with it
+	world.CompiledCode["code hash"][0] = 'X'
+	world.AcctMap.GetAccount([]byte("address-2")).Storage["key"] = nil
+	world.RestoreState(state)
+	requireSameWorldState(t, state, world.CloneState())
+}
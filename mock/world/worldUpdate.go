@@ -9,20 +9,22 @@ import (
 
 // UpdateBalance sets a new balance to an account
 func (b *MockWorld) UpdateBalance(address []byte, newBalance *big.Int) error {
-	acct := b.AcctMap.GetAccount(address)
+	acct := b.GetAccount(address)
 	if acct == nil {
 		return errors.New("method UpdateBalance expects an existing address")
 	}
+	b.journalAccount(address)
 	acct.Balance = newBalance
 	return nil
 }
 
 // UpdateBalanceWithDelta changes balance of an account by a given amount
 func (b *MockWorld) UpdateBalanceWithDelta(address []byte, balanceDelta *big.Int) error {
-	acct := b.AcctMap.GetAccount(address)
+	acct := b.GetAccount(address)
 	if acct == nil {
 		return errors.New("method UpdateBalanceWithDelta expects an existing address")
 	}
+	b.journalAccount(address)
 	acct.Balance = big.NewInt(0).Add(acct.Balance, balanceDelta)
 	return nil
 }
@@ -33,10 +35,11 @@ func (b *MockWorld) UpdateWorldStateBefore(
 	gasLimit uint64,
 	gasPrice uint64) error {
 
-	acct := b.AcctMap.GetAccount(fromAddr)
+	acct := b.GetAccount(fromAddr)
 	if acct == nil {
 		return errors.New("method UpdateWorldStateBefore expects an existing address")
 	}
+	b.journalAccount(fromAddr)
 	acct.Nonce++
 	gasPayment := big.NewInt(0).Mul(
 		big.NewInt(0).SetUint64(gasLimit),
@@ -58,6 +61,7 @@ func (b *MockWorld) UpdateAccounts(
 	}
 
 	for _, delAddr := range accountsToDelete {
+		b.journalAccount(delAddr)
 		b.AcctMap.DeleteAccount(delAddr)
 	}
 
@@ -66,7 +70,8 @@ func (b *MockWorld) UpdateAccounts(
 
 // UpdateAccountFromOutputAccount updates a single account from a transaction output.
 func (b *MockWorld) UpdateAccountFromOutputAccount(modAcct *vmcommon.OutputAccount) {
-	acct := b.AcctMap.GetAccount(modAcct.Address)
+	b.journalAccount(modAcct.Address)
+	acct := b.GetAccount(modAcct.Address)
 	if acct == nil {
 		acct = b.AcctMap.CreateAccount(modAcct.Address, b)
 		acct.OwnerAddress = modAcct.CodeDeployerAddress
@@ -94,6 +99,7 @@ func (b *MockWorld) UpdateAccountFromOutputAccount(modAcct *vmcommon.OutputAccou
 	}
 
 	for _, stu := range modAcct.StorageUpdates {
+		b.journalStorage(acct, string(stu.Offset))
 		acct.Storage[string(stu.Offset)] = stu.Data
 	}
 }
@@ -103,10 +109,13 @@ func (b *MockWorld) CreateStateBackup() {
 	b.AccountsAdapter.(*MockAccountsAdapter).SnapshotState(nil, nil)
 }
 
-// CommitChanges -
+// CommitChanges discards the snapshots and writes the changes back to the accounts store, if any.
 func (b *MockWorld) CommitChanges() error {
 	_, err := b.AccountsAdapter.Commit()
-	return err
+	if err != nil {
+		return err
+	}
+	return b.writeBackAccounts()
 }
 
 // RollbackChanges should be called after the VM test has run, if the tx has failed
@@ -1,11 +1,26 @@
 package worldmock
 
-import "github.com/multiversx/mx-chain-core-go/hashing/blake2b"
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/multiversx/mx-chain-core-go/core"
+	"github.com/multiversx/mx-chain-core-go/hashing/blake2b"
+	"github.com/multiversx/mx-chain-core-go/hashing/keccak"
+)
 
 // DefaultHasher is an exposed value to use in tests
 var DefaultHasher = blake2b.NewBlake2b()
 
+var contractAddressHasher = keccak.NewKeccak()
+
+// ErrInvalidContractAddressInput signals a creator address or a VM type
+// of the wrong length, from which the protocol cannot derive a contract address.
+var ErrInvalidContractAddressInput = errors.New("invalid creator address or VM type length for contract address")
+
 // GenerateMockAddress simulates creation of a new address by the protocol.
+// The result is easy to recognize in tests, but differs from the address the protocol derives,
+// see GenerateContractAddress for that.
 func GenerateMockAddress(creatorAddress []byte, creatorNonce uint64) []byte {
 	result := make([]byte, 32)
 	result[10] = 0x11
@@ -19,3 +34,24 @@ func GenerateMockAddress(creatorAddress []byte, creatorNonce uint64) []byte {
 	copy(result[30:], creatorAddress[30:])
 	return result
 }
+
+// GenerateContractAddress derives the address of a new contract the way the protocol does:
+// the keccak hash of the creator address followed by the creator nonce, as 8 little endian bytes,
+// with the first bytes replaced by zeros and the VM type, and the last 2 bytes by those of the creator,
+// so that the contract ends up in the same shard as its creator.
+func GenerateContractAddress(creatorAddress []byte, creatorNonce uint64, vmType []byte) ([]byte, error) {
+	if len(creatorAddress) != 32 || len(vmType) != core.VMTypeLen {
+		return nil, ErrInvalidContractAddressInput
+	}
+
+	nonceBytes := make([]byte, 8)
+	binary.LittleEndian.PutUint64(nonceBytes, creatorNonce)
+	hashInput := append(cloneBytes(creatorAddress), nonceBytes...)
+	result := contractAddressHasher.Compute(string(hashInput))
+
+	prefixLength := core.NumInitCharactersForScAddress - core.VMTypeLen
+	copy(result[:prefixLength], make([]byte, prefixLength))
+	copy(result[prefixLength:core.NumInitCharactersForScAddress], vmType)
+	copy(result[len(result)-core.ShardIdentiferLen:], creatorAddress[len(creatorAddress)-core.ShardIdentiferLen:])
+	return result, nil
+}
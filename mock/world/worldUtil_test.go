@@ -0,0 +1,60 @@
+package worldmock
+
+import (
+	"testing"
+
+	"github.com/multiversx/mx-chain-core-go/core"
+	"github.com/multiversx/mx-chain-core-go/core/pubkeyConverter"
+	"github.com/stretchr/testify/require"
+)
+
+var wasmVMType = []byte{5, 0}
+
+func TestGenerateContractAddress(t *testing.T) {
+	converter, err := pubkeyConverter.NewBech32PubkeyConverter(32, logImport)
+	require.Nil(t, err)
+	creator, err := converter.Decode("erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th")
+	require.Nil(t, err)
+
+	address, err := GenerateContractAddress(creator, 0, wasmVMType)
+	require.Nil(t, err)
+	require.Equal(t, "erd1qqqqqqqqqqqqqpgqak8zt22wl2ph4tswtyc39namqx6ysa2sd8ss4xmlj3", converter.Encode(address))
+	require.True(t, core.IsSmartContractAddress(address))
+	require.Equal(t, creator[30:], address[30:])
+
+	nextAddress, err := GenerateContractAddress(creator, 1, wasmVMType)
+	require.Nil(t, err)
+	require.NotEqual(t, address, nextAddress)
+
+	_, err = GenerateContractAddress(creator[:31], 0, wasmVMType)
+	require.Equal(t, ErrInvalidContractAddressInput, err)
+	_, err = GenerateContractAddress(creator, 0, []byte{5})
+	require.Equal(t, ErrInvalidContractAddressInput, err)
+}
+
+func TestNewAddress_DeriveContractAddresses(t *testing.T) {
+	creator := testAddress("creator")
+	world := NewMockWorld()
+
+	address, err := world.NewAddress(creator, 3, wasmVMType)
+	require.Nil(t, err)
+	require.Equal(t, GenerateMockAddress(creator, 3), address)
+
+	world.DeriveContractAddresses = true
+	address, err = world.NewAddress(creator, 3, wasmVMType)
+	require.Nil(t, err)
+	expected, _ := GenerateContractAddress(creator, 3, wasmVMType)
+	require.Equal(t, expected, address)
+	require.Equal(t, expected, world.LastCreatedContractAddress)
+
+	// the explicit mocks still come first
+	mockAddress := testContractAddress("mocked")
+	world.NewAddressMocks = append(world.NewAddressMocks, &NewAddressMock{
+		CreatorAddress: creator,
+		CreatorNonce:   3,
+		NewAddress:     mockAddress,
+	})
+	address, err = world.NewAddress(creator, 3, wasmVMType)
+	require.Nil(t, err)
+	require.Equal(t, mockAddress, address)
+}
@@ -0,0 +1,237 @@
+package scenarioexec
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	er "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/expression/reconstructor"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/wasmer"
+)
+
+// ContractCoverage holds the endpoints and VM hooks used by one contract code, over all scenarios run.
+// The endpoint calls are recorded by the contract instances themselves.
+// The VM hooks are only counted if enabled, from the gas trace of the host, so hooks that trace no gas are missing.
+type ContractCoverage struct {
+	CodeHash             string            `json:"codeHash"`
+	Addresses            []string          `json:"addresses"`
+	Endpoints            map[string]uint64 `json:"endpoints"`
+	NeverCalledEndpoints []string          `json:"neverCalledEndpoints"`
+	VMHooks              map[string]uint64 `json:"vmHooksFromGasTrace,omitempty"`
+}
+
+// CoverageReport is the coverage of all contract codes encountered by the executor.
+type CoverageReport struct {
+	Contracts []*ContractCoverage `json:"contracts"`
+}
+
+type contractCoverageData struct {
+	exports       map[string]struct{}
+	addresses     map[string]struct{}
+	endpointCalls map[string]uint64
+	vmHookCalls   map[string]uint64
+}
+
+// coverageTracker collects the endpoint and VM hook calls, grouped by contract code hash.
+type coverageTracker struct {
+	contracts         map[string]*contractCoverageData
+	addressCodeHashes map[string]string
+	vmHooks           bool
+}
+
+func newCoverageTracker() *coverageTracker {
+	return &coverageTracker{
+		contracts:         make(map[string]*contractCoverageData),
+		addressCodeHashes: make(map[string]string),
+	}
+}
+
+func (tracker *coverageTracker) getContractData(codeHash []byte) *contractCoverageData {
+	data, found := tracker.contracts[string(codeHash)]
+	if !found {
+		data = &contractCoverageData{
+			exports:       make(map[string]struct{}),
+			addresses:     make(map[string]struct{}),
+			endpointCalls: make(map[string]uint64),
+			vmHookCalls:   make(map[string]uint64),
+		}
+		tracker.contracts[string(codeHash)] = data
+	}
+	return data
+}
+
+func (tracker *coverageTracker) addContractExports(codeHash []byte, exports wasmer.ExportsMap) {
+	data := tracker.getContractData(codeHash)
+	for exportName := range exports {
+		data.exports[exportName] = struct{}{}
+	}
+}
+
+func (tracker *coverageTracker) addEndpointCall(codeHash []byte, address []byte, funcName string) {
+	data := tracker.getContractData(codeHash)
+	data.endpointCalls[funcName]++
+	if len(address) > 0 {
+		data.addresses[string(address)] = struct{}{}
+		tracker.addressCodeHashes[string(address)] = string(codeHash)
+	}
+}
+
+// addGasTrace counts the VM hook calls from a gas trace, which is keyed by contract address
+func (tracker *coverageTracker) addGasTrace(gasTrace map[string]map[string][]uint64) {
+	for scAddress, apiTraces := range gasTrace {
+		codeHash, found := tracker.addressCodeHashes[scAddress]
+		if !found {
+			continue
+		}
+		data := tracker.getContractData([]byte(codeHash))
+		for apiName, gasValues := range apiTraces {
+			data.vmHookCalls[apiName] += uint64(len(gasValues))
+		}
+	}
+}
+
+// EnableCoverage makes the executor record, per contract code hash, the endpoints
+// called during all subsequent scenarios.
+func (ae *VMTestExecutor) EnableCoverage() {
+	if ae.coverage != nil {
+		return
+	}
+
+	ae.coverage = newCoverageTracker()
+	if ae.vmHost != nil {
+		ae.installInstanceBuilder()
+	}
+}
+
+// EnableVMHookCoverage also makes the executor count the VM hooks called by each contract code.
+// The counts come from the gas trace, which the host is told to keep during the steps.
+func (ae *VMTestExecutor) EnableVMHookCoverage() {
+	ae.EnableCoverage()
+	ae.coverage.vmHooks = true
+}
+
+func (ae *VMTestExecutor) isVMHookCoverageActive() bool {
+	return ae.coverage != nil && ae.coverage.vmHooks
+}
+
+func (ae *VMTestExecutor) recordVMHookCoverage() {
+	if !ae.isVMHookCoverageActive() {
+		return
+	}
+
+	ae.coverage.addGasTrace(ae.vmHost.Metering().GetGasTrace())
+}
+
+// GetCoverageReport yields the coverage gathered so far, or nil if coverage is not enabled.
+func (ae *VMTestExecutor) GetCoverageReport() *CoverageReport {
+	if ae.coverage == nil {
+		return nil
+	}
+
+	var codeHashes []string
+	for codeHash := range ae.coverage.contracts {
+		codeHashes = append(codeHashes, codeHash)
+	}
+	sort.Strings(codeHashes)
+
+	report := &CoverageReport{}
+	for _, codeHash := range codeHashes {
+		data := ae.coverage.contracts[codeHash]
+
+		contractCoverage := &ContractCoverage{
+			CodeHash:             hex.EncodeToString([]byte(codeHash)),
+			Addresses:            make([]string, 0, len(data.addresses)),
+			Endpoints:            make(map[string]uint64),
+			NeverCalledEndpoints: make([]string, 0),
+		}
+		if ae.coverage.vmHooks {
+			contractCoverage.VMHooks = make(map[string]uint64)
+		}
+		for address := range data.addresses {
+			contractCoverage.Addresses = append(
+				contractCoverage.Addresses,
+				ae.exprReconstructor.Reconstruct([]byte(address), er.AddressHint))
+		}
+		sort.Strings(contractCoverage.Addresses)
+
+		for exportName := range data.exports {
+			contractCoverage.Endpoints[exportName] = 0
+		}
+		for funcName, calls := range data.endpointCalls {
+			contractCoverage.Endpoints[funcName] = calls
+		}
+		for exportName := range data.exports {
+			if data.endpointCalls[exportName] == 0 {
+				contractCoverage.NeverCalledEndpoints = append(contractCoverage.NeverCalledEndpoints, exportName)
+			}
+		}
+		sort.Strings(contractCoverage.NeverCalledEndpoints)
+
+		for apiName, calls := range data.vmHookCalls {
+			contractCoverage.VMHooks[apiName] = calls
+		}
+
+		report.Contracts = append(report.Contracts, contractCoverage)
+	}
+
+	return report
+}
+
+// WriteText prints the coverage report in a human-readable form.
+func (report *CoverageReport) WriteText(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "Coverage report:")
+	for _, contract := range report.Contracts {
+		_, _ = fmt.Fprintf(w, "contract code hash %s\n", contract.CodeHash)
+		for _, address := range contract.Addresses {
+			_, _ = fmt.Fprintf(w, "  address: %s\n", address)
+		}
+
+		nrCalled := len(contract.Endpoints) - len(contract.NeverCalledEndpoints)
+		_, _ = fmt.Fprintf(w, "  endpoints called: %d/%d\n", nrCalled, len(contract.Endpoints))
+		for _, name := range sortedKeys(contract.Endpoints) {
+			calls := contract.Endpoints[name]
+			if calls == 0 {
+				_, _ = fmt.Fprintf(w, "    %-40s never called\n", name)
+			} else {
+				_, _ = fmt.Fprintf(w, "    %-40s %d\n", name, calls)
+			}
+		}
+
+		if contract.VMHooks == nil {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "  vm hooks called (from gas trace): %d\n", len(contract.VMHooks))
+		for _, name := range sortedKeys(contract.VMHooks) {
+			_, _ = fmt.Fprintf(w, "    %-40s %d\n", name, contract.VMHooks[name])
+		}
+	}
+}
+
+// WriteJSONFile saves the coverage report as JSON.
+func (report *CoverageReport) WriteJSONFile(toPath string) error {
+	jsonBytes, err := json.MarshalIndent(report, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(toPath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(toPath, jsonBytes, 0644)
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,86 @@
+package scenarioexec
+
+import (
+	"bytes"
+
+	worldmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/world"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/wasmer"
+)
+
+// coverageInstanceBuilder wraps the default Wasmer instance builder and
+// produces instances that report their calls to the coverage tracker.
+type coverageInstanceBuilder struct {
+	innerBuilder vmhost.InstanceBuilder
+	host         vmhost.VMHost
+	world        *worldmock.MockWorld
+	tracker      *coverageTracker
+}
+
+// NewInstanceWithOptions creates a new tracked instance from WASM bytecode.
+func (builder *coverageInstanceBuilder) NewInstanceWithOptions(
+	contractCode []byte,
+	options wasmer.CompilationOptions,
+) (wasmer.InstanceHandler, error) {
+	instance, err := builder.innerBuilder.NewInstanceWithOptions(contractCode, options)
+	if err != nil {
+		return nil, err
+	}
+
+	codeHash := worldmock.DefaultHasher.Compute(string(contractCode))
+	return builder.wrapInstance(instance, codeHash), nil
+}
+
+// NewInstanceFromCompiledCodeWithOptions creates a new tracked instance from
+// precompiled machine code.
+func (builder *coverageInstanceBuilder) NewInstanceFromCompiledCodeWithOptions(
+	compiledCode []byte,
+	options wasmer.CompilationOptions,
+) (wasmer.InstanceHandler, error) {
+	instance, err := builder.innerBuilder.NewInstanceFromCompiledCodeWithOptions(compiledCode, options)
+	if err != nil {
+		return nil, err
+	}
+
+	codeHash := builder.findCodeHashOfCompiledCode(compiledCode)
+	return builder.wrapInstance(instance, codeHash), nil
+}
+
+func (builder *coverageInstanceBuilder) wrapInstance(instance wasmer.InstanceHandler, codeHash []byte) wasmer.InstanceHandler {
+	builder.tracker.addContractExports(codeHash, instance.GetExports())
+	return &coverageInstance{
+		InstanceHandler: instance,
+		codeHash:        codeHash,
+		host:            builder.host,
+		tracker:         builder.tracker,
+	}
+}
+
+// the compiled code is saved in the world by the runtime, keyed by code hash
+func (builder *coverageInstanceBuilder) findCodeHashOfCompiledCode(compiledCode []byte) []byte {
+	for codeHash, code := range builder.world.CompiledCode {
+		if bytes.Equal(code, compiledCode) {
+			return []byte(codeHash)
+		}
+	}
+
+	return nil
+}
+
+// coverageInstance is a Wasmer instance that records every exported function called on it.
+type coverageInstance struct {
+	wasmer.InstanceHandler
+	codeHash []byte
+	host     vmhost.VMHost
+	tracker  *coverageTracker
+}
+
+// CallFunction records the call, then forwards it to the wrapped instance.
+func (instance *coverageInstance) CallFunction(funcName string) (wasmer.Value, error) {
+	contextAddress := instance.host.Runtime().GetContextAddress()
+	instance.tracker.addEndpointCall(instance.codeHash, contextAddress, funcName)
+	return instance.InstanceHandler.CallFunction(funcName)
+}
+
+var _ vmhost.InstanceBuilder = (*coverageInstanceBuilder)(nil)
+var _ wasmer.InstanceHandler = (*coverageInstance)(nil)
@@ -0,0 +1,139 @@
+package scenarioexec
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	logger "github.com/multiversx/mx-chain-logger-go"
+	contextmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/context"
+	worldmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/world"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/wasmer"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCoverageBuilder(t *testing.T, ae *VMTestExecutor, runtime *contextmock.RuntimeContextMock) (*coverageInstanceBuilder, *contextmock.InstanceMock) {
+	innerBuilder := contextmock.NewInstanceBuilderMock(ae.World)
+	host := &contextmock.VMHostStub{
+		RuntimeCalled: func() vmhost.RuntimeContext {
+			return runtime
+		},
+	}
+	instance := innerBuilder.CreateAndStoreInstanceMock(t, host, []byte("contract"), nil, nil, nil, 0, 0)
+	instance.AddMockMethod("init", func() *contextmock.InstanceMock { return instance })
+	instance.AddMockMethod("add", func() *contextmock.InstanceMock { return instance })
+	instance.AddMockMethod("getSum", func() *contextmock.InstanceMock { return instance })
+	innerBuilder.InstanceMap["contract"] = *instance
+
+	builder := &coverageInstanceBuilder{
+		innerBuilder: innerBuilder,
+		host:         host,
+		world:        ae.World,
+		tracker:      ae.coverage,
+	}
+	return builder, instance
+}
+
+func TestCoverage_EndpointCalls(t *testing.T) {
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	ae.EnableCoverage()
+
+	runtime := &contextmock.RuntimeContextMock{SCAddress: []byte("sc-address______________________")}
+	builder, _ := newTestCoverageBuilder(t, ae, runtime)
+
+	instance, err := builder.NewInstanceWithOptions([]byte("contract"), wasmer.CompilationOptions{})
+	require.Nil(t, err)
+	_, err = instance.CallFunction("add")
+	require.Nil(t, err)
+	_, err = instance.CallFunction("add")
+	require.Nil(t, err)
+	_, err = instance.CallFunction("init")
+	require.Nil(t, err)
+
+	report := ae.GetCoverageReport()
+	require.Len(t, report.Contracts, 1)
+	contract := report.Contracts[0]
+	require.Equal(t, map[string]uint64{"init": 1, "add": 2, "getSum": 0}, contract.Endpoints)
+	require.Equal(t, []string{"getSum"}, contract.NeverCalledEndpoints)
+	require.Equal(t, []string{"address:sc-address"}, contract.Addresses)
+	require.Nil(t, contract.VMHooks)
+
+	text := &bytes.Buffer{}
+	report.WriteText(text)
+	require.Contains(t, text.String(), "endpoints called: 2/3")
+	require.Contains(t, text.String(), "never called")
+	require.NotContains(t, text.String(), "vm hooks")
+}
+
+func TestCoverage_CompiledCodeMatchedByCodeHash(t *testing.T) {
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	ae.EnableCoverage()
+
+	runtime := &contextmock.RuntimeContextMock{SCAddress: []byte("sc-address______________________")}
+	builder, _ := newTestCoverageBuilder(t, ae, runtime)
+	codeHash := worldmock.DefaultHasher.Compute("original code")
+	ae.World.CompiledCode[string(codeHash)] = []byte("contract")
+
+	instance, err := builder.NewInstanceFromCompiledCodeWithOptions([]byte("contract"), wasmer.CompilationOptions{})
+	require.Nil(t, err)
+	_, err = instance.CallFunction("getSum")
+	require.Nil(t, err)
+
+	require.Equal(t, uint64(1), ae.coverage.contracts[string(codeHash)].endpointCalls["getSum"])
+}
+
+func TestCoverage_VMHooksFromGasTrace(t *testing.T) {
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	ae.EnableCoverage()
+	ae.coverage.vmHooks = true
+
+	ae.coverage.addEndpointCall([]byte("code hash"), []byte("sc"), "add")
+	ae.coverage.addGasTrace(map[string]map[string][]uint64{
+		"sc":    {"bigIntAdd": {10, 10}, "storageStore": {100}},
+		"other": {"bigIntAdd": {10}},
+	})
+	ae.coverage.addGasTrace(map[string]map[string][]uint64{
+		"sc": {"bigIntAdd": {10}},
+	})
+
+	report := ae.GetCoverageReport()
+	require.Len(t, report.Contracts, 1)
+	require.Equal(t, map[string]uint64{"bigIntAdd": 3, "storageStore": 1}, report.Contracts[0].VMHooks)
+
+	text := &bytes.Buffer{}
+	report.WriteText(text)
+	require.Contains(t, text.String(), "vm hooks called (from gas trace): 2")
+
+	path := filepath.Join(t.TempDir(), "coverage", "report.json")
+	require.Nil(t, report.WriteJSONFile(path))
+	jsonBytes, err := ioutil.ReadFile(path)
+	require.Nil(t, err)
+	loaded := &CoverageReport{}
+	require.Nil(t, json.Unmarshal(jsonBytes, loaded))
+	require.Equal(t, report, loaded)
+}
+
+func TestCoverage_VMHooksKeepGasTraceWithoutChangingTheLogger(t *testing.T) {
+	gasTraceLogger := logger.GetOrCreate("gasTrace")
+	logLevel := gasTraceLogger.GetLevel()
+
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	require.False(t, ae.keepsGasTrace())
+	ae.EnableVMHookCoverage()
+	require.True(t, ae.keepsGasTrace())
+	require.Equal(t, logLevel, gasTraceLogger.GetLevel())
+}
+
+func TestCoverage_NotEnabled(t *testing.T) {
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	require.Nil(t, ae.GetCoverageReport())
+	require.False(t, ae.isVMHookCoverageActive())
+}
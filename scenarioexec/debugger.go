@@ -0,0 +1,317 @@
+package scenarioexec
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	vmi "github.com/multiversx/mx-chain-vm-common-go"
+	ei "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/expression/interpreter"
+	er "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/expression/reconstructor"
+	mjwrite "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/write"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	oj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/orderedjson"
+)
+
+// ErrDebuggerQuit signals that the scenario was aborted from the debugger prompt.
+var ErrDebuggerQuit = errors.New("scenario aborted from the debugger")
+
+const debuggerHelp = `commands:
+  step, s                            run the current step and pause before the next one
+  continue, c                        run until the next breakpoint or failure
+  break, b <step number or id>       pause before the given step
+  show                               print the current step
+  accounts                           list all accounts, with nonce and balance
+  account <address>                  print an account, as in a setState step
+  storage <address> [<key>]          print the storage of an account, or a single key
+  query <address> <function> [args]  run a query against the current state, without changing it
+  quit, q                            abort the scenario
+values are scenario expressions, without spaces, e.g. address:owner, sc:adder, str:key, 5`
+
+// Debugger pauses the scenario execution before chosen steps, or when a step fails,
+// and opens a prompt to inspect the accounts and storage and to run queries against the current state.
+// Steps are numbered from 1 in each top-level scenario file, in execution order,
+// including the steps in external steps files.
+type Debugger struct {
+	// BreakOnFailure opens the prompt when a step fails, before the error is reported.
+	BreakOnFailure bool
+
+	breakpoints map[string]bool
+	input       *bufio.Scanner
+	output      io.Writer
+	stepNr      int
+	stepping    bool
+	inputClosed bool
+}
+
+// NewDebugger creates a debugger reading commands from the input, e.g. stdin.
+func NewDebugger(input io.Reader, output io.Writer) *Debugger {
+	return &Debugger{
+		breakpoints: make(map[string]bool),
+		input:       bufio.NewScanner(input),
+		output:      output,
+	}
+}
+
+// AddBreakpoint makes the debugger pause before the step with the given number or id.
+func (debugger *Debugger) AddBreakpoint(stepNrOrID string) {
+	debugger.breakpoints[stepNrOrID] = true
+}
+
+// EnableDebugger makes the executor pause in the given debugger.
+func (ae *VMTestExecutor) EnableDebugger(debugger *Debugger) {
+	ae.debugger = debugger
+}
+
+func (ae *VMTestExecutor) beginDebuggerScenarioFile() {
+	if ae.debugger != nil {
+		ae.debugger.stepNr = 0
+	}
+}
+
+// debugBeforeStep opens the prompt if the debugger should pause before the step.
+// External steps are not paused on as a whole, only their own steps.
+func (ae *VMTestExecutor) debugBeforeStep(generalStep mj.Step) error {
+	debugger := ae.debugger
+	if debugger == nil {
+		return nil
+	}
+	if _, isExternal := generalStep.(*mj.ExternalStepsStep); isExternal {
+		return nil
+	}
+
+	debugger.stepNr++
+	stepID := stepIdent(generalStep)
+	isBreakpoint := debugger.breakpoints[strconv.Itoa(debugger.stepNr)] ||
+		(len(stepID) > 0 && debugger.breakpoints[stepID])
+	if !debugger.stepping && !isBreakpoint {
+		return nil
+	}
+
+	debugger.printf("paused before step %s\n", debugger.describeStep(generalStep))
+	return ae.debugPrompt(generalStep, nil)
+}
+
+// debugStepFailed opens the prompt if the debugger should pause on failures, then yields the step error.
+func (ae *VMTestExecutor) debugStepFailed(generalStep mj.Step, stepErr error) error {
+	debugger := ae.debugger
+	if debugger == nil || !debugger.BreakOnFailure || errors.Is(stepErr, ErrDebuggerQuit) {
+		return stepErr
+	}
+	if _, isExternal := generalStep.(*mj.ExternalStepsStep); isExternal {
+		// already handled for the failed step in the external steps file
+		return stepErr
+	}
+
+	debugger.printf("step %s failed: %s\n", debugger.describeStep(generalStep), stepErr.Error())
+	err := ae.debugPrompt(generalStep, stepErr)
+	if err != nil {
+		return err
+	}
+	return stepErr
+}
+
+func (debugger *Debugger) describeStep(generalStep mj.Step) string {
+	description := fmt.Sprintf("%d (%s", debugger.stepNr, generalStep.StepTypeName())
+	stepID := stepIdent(generalStep)
+	if len(stepID) > 0 {
+		description += " " + stepID
+	}
+	return description + ")"
+}
+
+func (debugger *Debugger) printf(format string, args ...interface{}) {
+	_, _ = fmt.Fprintf(debugger.output, format, args...)
+}
+
+// debugPrompt reads commands until one of them resumes the execution.
+// The step error is given if the step already failed, in which case the execution cannot go on.
+func (ae *VMTestExecutor) debugPrompt(generalStep mj.Step, stepErr error) error {
+	debugger := ae.debugger
+	for {
+		debugger.printf("(debug) ")
+		if debugger.inputClosed || !debugger.input.Scan() {
+			// nobody to answer, so the scenario runs on without pausing
+			debugger.inputClosed = true
+			debugger.stepping = false
+			debugger.printf("\n")
+			return nil
+		}
+
+		fields := strings.Fields(debugger.input.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		command, args := fields[0], fields[1:]
+
+		switch command {
+		case "step", "s":
+			if stepErr != nil {
+				debugger.printf("the step failed, the scenario cannot go on\n")
+				continue
+			}
+			debugger.stepping = true
+			return nil
+		case "continue", "c":
+			debugger.stepping = false
+			return nil
+		case "quit", "q":
+			return ErrDebuggerQuit
+		case "break", "b":
+			if len(args) != 1 {
+				debugger.printf("expected a step number or id\n")
+				continue
+			}
+			debugger.AddBreakpoint(args[0])
+		case "show":
+			debugger.printf("%s\n", mjwrite.ScenarioStepToJSONString(generalStep))
+		case "accounts":
+			ae.debugPrintAccounts()
+		case "account":
+			ae.debugRunCommand(args, 1, 1, ae.debugPrintAccount)
+		case "storage":
+			ae.debugRunCommand(args, 1, 2, ae.debugPrintStorage)
+		case "query":
+			ae.debugRunCommand(args, 2, -1, ae.debugQuery)
+		case "help", "h":
+			debugger.printf("%s\n", debuggerHelp)
+		default:
+			debugger.printf("unknown command %s, type help for the list of commands\n", command)
+		}
+	}
+}
+
+// debugRunCommand checks the number of arguments, then runs the command, printing its error, if any.
+// A negative maximum allows any number of arguments.
+func (ae *VMTestExecutor) debugRunCommand(args []string, minArgs int, maxArgs int, command func(args []string) error) {
+	if len(args) < minArgs || (maxArgs >= 0 && len(args) > maxArgs) {
+		ae.debugger.printf("wrong number of arguments, type help for the list of commands\n")
+		return
+	}
+
+	err := command(args)
+	if err != nil {
+		ae.debugger.printf("error: %s\n", err.Error())
+	}
+}
+
+func (ae *VMTestExecutor) debugInterpret(expression string) ([]byte, error) {
+	interpreter := ei.ExprInterpreter{FileResolver: ae.fileResolver}
+	return interpreter.InterpretString(expression)
+}
+
+func (ae *VMTestExecutor) debugPrintAccounts() {
+	ae.World.LoadAllAccounts()
+	addresses := make([]string, 0, len(ae.World.AcctMap))
+	for address := range ae.World.AcctMap {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		account := ae.World.AcctMap[address]
+		ae.debugger.printf("%s  nonce: %d  balance: %s\n",
+			ae.exprReconstructor.Reconstruct(account.Address, er.AddressHint),
+			account.Nonce,
+			ae.exprReconstructor.ReconstructFromBigInt(account.Balance))
+	}
+}
+
+func (ae *VMTestExecutor) debugPrintAccount(args []string) error {
+	address, err := ae.debugInterpret(args[0])
+	if err != nil {
+		return err
+	}
+	account := ae.World.GetAccount(address)
+	if account == nil {
+		return fmt.Errorf("account %s not found", args[0])
+	}
+
+	scenAccount, err := ae.convertMockAccountToScenarioFormat(account)
+	if err != nil {
+		return err
+	}
+	ae.debugger.printf("%s\n", oj.JSONString(mjwrite.AccountsToOJ([]*mj.Account{scenAccount})))
+	return nil
+}
+
+func (ae *VMTestExecutor) debugPrintStorage(args []string) error {
+	address, err := ae.debugInterpret(args[0])
+	if err != nil {
+		return err
+	}
+	account := ae.World.GetAccount(address)
+	if account == nil {
+		return fmt.Errorf("account %s not found", args[0])
+	}
+
+	if len(args) == 2 {
+		key, err := ae.debugInterpret(args[1])
+		if err != nil {
+			return err
+		}
+		ae.debugger.printf("%s\n", ae.exprReconstructor.Reconstruct(account.StorageValue(string(key)), er.InferHint))
+		return nil
+	}
+
+	account.LoadStorage()
+	keys := make([]string, 0, len(account.Storage))
+	for key := range account.Storage {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		ae.debugger.printf("%s: %s\n",
+			ae.exprReconstructor.Reconstruct([]byte(key), er.InferHint),
+			ae.exprReconstructor.Reconstruct(account.Storage[key], er.InferHint))
+	}
+	return nil
+}
+
+// debugQuery runs a SC query, then restores the state, so that the scenario is not affected.
+func (ae *VMTestExecutor) debugQuery(args []string) error {
+	to, err := ae.debugInterpret(args[0])
+	if err != nil {
+		return err
+	}
+	tx := &mj.Transaction{
+		Type:      mj.ScQuery,
+		To:        mj.NewJSONBytesFromString(to, args[0]),
+		Function:  args[1],
+		EGLDValue: mj.JSONBigIntZero(),
+	}
+	for _, arg := range args[2:] {
+		argValue, err := ae.debugInterpret(arg)
+		if err != nil {
+			return err
+		}
+		tx.Arguments = append(tx.Arguments, mj.JSONBytesFromTree{
+			Value:    argValue,
+			Original: &oj.OJsonString{Value: arg},
+		})
+	}
+
+	savedState := ae.World.CloneState()
+	output, err := ae.executeTx("debugger-query", tx)
+	ae.World.RestoreState(savedState)
+	if err != nil {
+		return err
+	}
+
+	ae.debugPrintQueryOutput(output)
+	return nil
+}
+
+func (ae *VMTestExecutor) debugPrintQueryOutput(output *vmi.VMOutput) {
+	ae.debugger.printf("status: %d\n", output.ReturnCode)
+	if len(output.ReturnMessage) > 0 {
+		ae.debugger.printf("message: %s\n", output.ReturnMessage)
+	}
+	for i, returnData := range output.ReturnData {
+		ae.debugger.printf("out[%d]: %s\n", i, ae.exprReconstructor.Reconstruct(returnData, er.InferHint))
+	}
+}
@@ -0,0 +1,342 @@
+package scenarioexec
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/config"
+	contextmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/context"
+	mc "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/controller"
+	mjparse "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/parse"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
+	"github.com/stretchr/testify/require"
+)
+
+const debuggerTestSetState = `{
+	"step": "setState",
+	"id": "setup",
+	"accounts": {
+		"address:owner": { "nonce": "0", "balance": "1000" },
+		"sc:counter": {
+			"nonce": "0",
+			"balance": "0",
+			"storage": { "str:count": "5" },
+			"code": "str:counter code"
+		}
+	}
+}`
+
+const debuggerTestIncrement = `{
+	"step": "scCall",
+	"id": "increment",
+	"tx": {
+		"from": "address:owner",
+		"to": "sc:counter",
+		"function": "increment",
+		"arguments": [],
+		"gasLimit": "1000",
+		"gasPrice": "0"
+	},
+	"expect": { "out": ["*"], "status": "0", "gas": "*", "refund": "*" }
+}`
+
+func debuggerTestCheckState(count string) string {
+	return `{
+		"step": "checkState",
+		"id": "check",
+		"accounts": {
+			"address:owner": { "nonce": "*", "balance": "1000" },
+			"sc:counter": {
+				"nonce": "0",
+				"balance": "0",
+				"storage": { "str:count": "` + count + `" },
+				"code": "*"
+			}
+		}
+	}`
+}
+
+func debuggerTestScenario(steps ...string) string {
+	return `{ "name": "debugger test", "steps": [` + strings.Join(steps, ",") + `] }`
+}
+
+// newDebuggerTestExecutor replaces the VM with a stub for a counter contract,
+// whose "increment" endpoint adds 1 to the count in storage and returns the new count
+func newDebuggerTestExecutor(t *testing.T) *VMTestExecutor {
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	gasCost, err := config.CreateGasConfig(config.MakeGasMapForTests())
+	require.Nil(t, err)
+
+	counterKey := "count"
+	host := &contextmock.VMHostStub{
+		RunSmartContractCallCalled: func(input *vmcommon.ContractCallInput) (*vmcommon.VMOutput, error) {
+			count := big.NewInt(0).SetBytes(ae.World.AcctMap.GetAccount(input.RecipientAddr).Storage[counterKey])
+			output := &vmcommon.VMOutput{
+				ReturnCode:     vmcommon.Ok,
+				GasRemaining:   input.GasProvided - 100,
+				OutputAccounts: make(map[string]*vmcommon.OutputAccount),
+			}
+			if input.Function == "increment" {
+				count.Add(count, big.NewInt(1))
+				output.OutputAccounts[string(input.RecipientAddr)] = &vmcommon.OutputAccount{
+					Address:      input.RecipientAddr,
+					BalanceDelta: big.NewInt(0),
+					StorageUpdates: map[string]*vmcommon.StorageUpdate{
+						counterKey: {Offset: []byte(counterKey), Data: count.Bytes()},
+					},
+				}
+			}
+			output.ReturnData = [][]byte{count.Bytes()}
+			return output, nil
+		},
+		RuntimeCalled: func() vmhost.RuntimeContext {
+			return &contextmock.RuntimeContextMock{}
+		},
+		MeteringCalled: func() vmhost.MeteringContext {
+			return &contextmock.MeteringContextMock{GasCost: gasCost}
+		},
+	}
+	ae.vm = host
+	ae.vmHost = host
+	return ae
+}
+
+func debuggerTestCount(t *testing.T, ae *VMTestExecutor) []byte {
+	address, err := ae.debugInterpret("sc:counter")
+	require.Nil(t, err)
+	return ae.World.AcctMap.GetAccount(address).Storage["count"]
+}
+
+// runDebuggerSession runs the scenario, answering the debugger prompts with the given commands
+func runDebuggerSession(
+	t *testing.T,
+	ae *VMTestExecutor,
+	scenarioPath string,
+	scenarioJSON string,
+	setup func(debugger *Debugger),
+	commands ...string,
+) (string, error) {
+	input := strings.NewReader(strings.Join(commands, "\n"))
+	output := &bytes.Buffer{}
+	debugger := NewDebugger(input, output)
+	setup(debugger)
+	ae.EnableDebugger(debugger)
+
+	fileResolver := mc.NewDefaultFileResolver()
+	fileResolver.SetContext(scenarioPath)
+	parser := mjparse.NewParser(fileResolver)
+	scenario, err := parser.ParseScenarioFile([]byte(scenarioJSON))
+	require.Nil(t, err)
+	scenario.IsNewTest = true
+	ae.BeginScenarioFile(scenarioPath)
+	err = ae.ExecuteScenario(scenario, fileResolver)
+	ae.EndScenarioFile(scenarioPath, err)
+	return output.String(), err
+}
+
+func TestDebugger_BreakpointAndStep(t *testing.T) {
+	ae := newDebuggerTestExecutor(t)
+	scenario := debuggerTestScenario(debuggerTestSetState, debuggerTestIncrement, debuggerTestCheckState("6"))
+
+	output, err := runDebuggerSession(t, ae, "debugger.scen.json", scenario,
+		func(debugger *Debugger) {
+			debugger.AddBreakpoint("increment")
+		},
+		"storage sc:counter str:count",
+		"step",
+		"storage sc:counter str:count",
+		"accounts",
+		"c",
+	)
+	require.Nil(t, err)
+	require.Equal(t,
+		"paused before step 2 (scCall increment)\n"+
+			"(debug) 5\n"+
+			"(debug) paused before step 3 (checkState check)\n"+
+			"(debug) 6\n"+
+			"(debug) sc:counter  nonce: 0  balance: 0\n"+
+			"address:owner  nonce: 1  balance: 1000\n"+
+			"(debug) ",
+		output)
+}
+
+func TestDebugger_BreakpointByStepNumber(t *testing.T) {
+	ae := newDebuggerTestExecutor(t)
+	scenario := debuggerTestScenario(debuggerTestSetState, debuggerTestIncrement, debuggerTestCheckState("6"))
+
+	output, err := runDebuggerSession(t, ae, "debugger.scen.json", scenario,
+		func(debugger *Debugger) {
+			debugger.AddBreakpoint("1")
+		},
+		"break 3",
+		"continue",
+		"storage sc:counter",
+		"c",
+	)
+	require.Nil(t, err)
+	require.Equal(t,
+		"paused before step 1 (setState setup)\n"+
+			"(debug) (debug) paused before step 3 (checkState check)\n"+
+			"(debug) str:count: 6\n"+
+			"(debug) ",
+		output)
+}
+
+func TestDebugger_QueryRestoresTheState(t *testing.T) {
+	ae := newDebuggerTestExecutor(t)
+	scenario := debuggerTestScenario(debuggerTestSetState, debuggerTestIncrement, debuggerTestCheckState("6"))
+
+	output, err := runDebuggerSession(t, ae, "debugger.scen.json", scenario,
+		func(debugger *Debugger) {
+			debugger.AddBreakpoint("check")
+		},
+		"query sc:counter increment",
+		"query sc:counter increment",
+		"storage sc:counter str:count",
+		"query sc:missing increment",
+		"c",
+	)
+	// the checkState step only passes if the queries left the count unchanged
+	require.Nil(t, err)
+	require.Equal(t,
+		"paused before step 3 (checkState check)\n"+
+			"(debug) status: 0\n"+
+			"out[0]: 7\n"+
+			"(debug) status: 0\n"+
+			"out[0]: 7\n"+
+			"(debug) 6\n"+
+			"(debug) error: tx recipient (address: 00000000000000006d697373696e675f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f5f) does not exist\n"+
+			"(debug) ",
+		output)
+	require.Equal(t, []byte{6}, debuggerTestCount(t, ae))
+}
+
+func TestDebugger_BreakOnFailure(t *testing.T) {
+	ae := newDebuggerTestExecutor(t)
+	scenario := debuggerTestScenario(debuggerTestSetState, debuggerTestIncrement, debuggerTestCheckState("7"))
+
+	output, err := runDebuggerSession(t, ae, "debugger.scen.json", scenario,
+		func(debugger *Debugger) {
+			debugger.BreakOnFailure = true
+		},
+		"s",
+		"storage sc:counter str:count",
+		"q",
+	)
+	require.Equal(t, ErrDebuggerQuit, err)
+	require.True(t, strings.HasPrefix(output, "step 3 (checkState check) failed: "))
+	require.True(t, strings.HasSuffix(output,
+		"(debug) the step failed, the scenario cannot go on\n"+
+			"(debug) 6\n"+
+			"(debug) "))
+
+	// when the prompt is left, the step error is reported
+	ae = newDebuggerTestExecutor(t)
+	_, err = runDebuggerSession(t, ae, "debugger.scen.json", scenario,
+		func(debugger *Debugger) {
+			debugger.BreakOnFailure = true
+		},
+		"c",
+	)
+	require.NotNil(t, err)
+	require.NotEqual(t, ErrDebuggerQuit, err)
+}
+
+func TestDebugger_QuitAbortsTheScenario(t *testing.T) {
+	ae := newDebuggerTestExecutor(t)
+	scenario := debuggerTestScenario(debuggerTestSetState, debuggerTestIncrement, debuggerTestCheckState("6"))
+
+	_, err := runDebuggerSession(t, ae, "debugger.scen.json", scenario,
+		func(debugger *Debugger) {
+			debugger.AddBreakpoint("increment")
+		},
+		"quit",
+	)
+	require.Equal(t, ErrDebuggerQuit, err)
+	// the paused step did not run
+	require.Equal(t, []byte{5}, debuggerTestCount(t, ae))
+}
+
+func TestDebugger_ClosedInputRunsOn(t *testing.T) {
+	ae := newDebuggerTestExecutor(t)
+	scenario := debuggerTestScenario(debuggerTestSetState, debuggerTestIncrement, debuggerTestCheckState("6"))
+
+	output, err := runDebuggerSession(t, ae, "debugger.scen.json", scenario,
+		func(debugger *Debugger) {
+			debugger.AddBreakpoint("1")
+			debugger.AddBreakpoint("3")
+		},
+		"s",
+	)
+	require.Nil(t, err)
+	// stepping stops when nobody answers, the last breakpoint does not pause either
+	require.Equal(t,
+		"paused before step 1 (setState setup)\n"+
+			"(debug) paused before step 2 (scCall increment)\n"+
+			"(debug) \n"+
+			"paused before step 3 (checkState check)\n"+
+			"(debug) \n",
+		output)
+}
+
+func TestDebugger_WrongCommands(t *testing.T) {
+	ae := newDebuggerTestExecutor(t)
+	scenario := debuggerTestScenario(debuggerTestSetState)
+
+	output, err := runDebuggerSession(t, ae, "debugger.scen.json", scenario,
+		func(debugger *Debugger) {
+			debugger.AddBreakpoint("setup")
+		},
+		"",
+		"jump",
+		"break",
+		"account",
+		"storage a b c",
+		"account address:missing",
+		"account str:x|bad",
+		"c",
+	)
+	require.Nil(t, err)
+	require.Equal(t,
+		"paused before step 1 (setState setup)\n"+
+			"(debug) (debug) unknown command jump, type help for the list of commands\n"+
+			"(debug) expected a step number or id\n"+
+			"(debug) wrong number of arguments, type help for the list of commands\n"+
+			"(debug) wrong number of arguments, type help for the list of commands\n"+
+			"(debug) error: account address:missing not found\n"+
+			"(debug) error: could not parse base 10 value: bad\n"+
+			"(debug) ",
+		output)
+}
+
+func TestDebugger_ExternalStepsAreNumberedInOrder(t *testing.T) {
+	dir := t.TempDir()
+	externalSteps := debuggerTestScenario(debuggerTestSetState, debuggerTestIncrement)
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "init.steps.json"), []byte(externalSteps), 0644))
+
+	ae := newDebuggerTestExecutor(t)
+	scenario := debuggerTestScenario(
+		`{ "step": "externalSteps", "path": "init.steps.json" }`,
+		debuggerTestCheckState("6"))
+
+	output, err := runDebuggerSession(t, ae, filepath.Join(dir, "debugger.scen.json"), scenario,
+		func(debugger *Debugger) {
+			debugger.AddBreakpoint("2")
+		},
+		"s",
+		"show",
+		"c",
+	)
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(output,
+		"paused before step 2 (scCall increment)\n"+
+			"(debug) paused before step 3 (checkState check)\n"+
+			"(debug) {\n"+
+			`    "step": "checkState",`))
+}
@@ -0,0 +1,59 @@
+package scenarioexec
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/multiversx/mx-chain-core-go/core/check"
+	worldhook "github.com/multiversx/mx-chain-vm-v1_4-go/mock/world"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+)
+
+// setEnableEpochs configures the activation epochs of the protocol flags, as given in the scenario.
+// The flags that the scenario does not mention keep their default activation epochs.
+// The flags of the epoch of the current block become active right away, the later ones when a later block is set.
+func (ae *VMTestExecutor) setEnableEpochs(enableEpochs *mj.EnableEpochs) error {
+	activationEpochs := worldhook.DefaultActivationEpochs()
+
+	if len(enableEpochs.File.Original) > 0 {
+		fileEpochs, err := worldhook.ParseEnableEpochsTOML(string(enableEpochs.File.Value))
+		if err != nil {
+			return fmt.Errorf("bad enable epochs file %s: %w", enableEpochs.File.Original, err)
+		}
+		for flag, epoch := range fileEpochs {
+			activationEpochs[flag] = epoch
+		}
+	}
+
+	for _, flag := range enableEpochs.Flags {
+		if flag.Epoch.Value > uint64(worldhook.DisabledEpoch) {
+			return fmt.Errorf("activation epoch of flag %s too large: %d", flag.Name, flag.Epoch.Value)
+		}
+		activationEpochs[flag.Name] = uint32(flag.Epoch.Value)
+	}
+
+	err := ae.setActivationEpochs(activationEpochs)
+	if err != nil {
+		return err
+	}
+
+	ae.World.ConfirmCurrentEpoch()
+	return nil
+}
+
+// setActivationEpochs replaces the activation epochs of the world.
+// If they change, the VM drops its cached instances and compiled code, as it does at the activation epochs,
+// since the flags active in the current epoch might have changed.
+func (ae *VMTestExecutor) setActivationEpochs(activationEpochs map[string]uint32) error {
+	changed := !reflect.DeepEqual(ae.World.EnableEpochsHandler.ActivationEpochs(), activationEpochs)
+	err := ae.World.EnableEpochsHandler.SetActivationEpochs(activationEpochs)
+	if err != nil {
+		return err
+	}
+
+	if changed && !check.IfNil(ae.vmHost) {
+		ae.vmHost.Runtime().ClearWarmInstanceCache()
+		ae.vmHost.Blockchain().ClearCompiledCodes()
+	}
+	return nil
+}
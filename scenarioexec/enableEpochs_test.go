@@ -0,0 +1,151 @@
+package scenarioexec
+
+import (
+	"fmt"
+	"testing"
+
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/config"
+	contextmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/context"
+	worldhook "github.com/multiversx/mx-chain-vm-v1_4-go/mock/world"
+	fr "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/fileresolver"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const epochsTestSetState = `{
+	"step": "setState",
+	"accounts": {
+		"address:owner": { "nonce": "0", "balance": "0" },
+		"sc:contract": { "nonce": "0", "balance": "0", "code": "str:contract code" }
+	}
+}`
+
+func epochsTestBlock(epoch int) string {
+	return fmt.Sprintf(`{
+		"step": "setState",
+		"currentBlockInfo": { "blockEpoch": "%d" }
+	}`, epoch)
+}
+
+const epochsTestCall = `{
+	"step": "scCall",
+	"tx": {
+		"from": "address:owner",
+		"to": "sc:contract",
+		"function": "call",
+		"arguments": [],
+		"gasLimit": "1000",
+		"gasPrice": "0"
+	},
+	"expect": { "status": "0", "gas": "*", "refund": "*" }
+}`
+
+// cacheClearsCountingBlockchainContext counts how many times the compiled codes were cleared
+type cacheClearsCountingBlockchainContext struct {
+	mock.BlockchainContextMock
+	nrClears int
+}
+
+func (b *cacheClearsCountingBlockchainContext) ClearCompiledCodes() {
+	b.nrClears++
+}
+
+// newEpochsTestExecutor replaces the VM with a stub that records
+// whether the FixOOGReturnCode flag is active when the contract is called
+func newEpochsTestExecutor(t *testing.T) (*VMTestExecutor, *[]bool, *cacheClearsCountingBlockchainContext) {
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	gasCost, err := config.CreateGasConfig(config.MakeGasMapForTests())
+	require.Nil(t, err)
+
+	flagActive := make([]bool, 0)
+	blockchainContext := &cacheClearsCountingBlockchainContext{}
+	host := &contextmock.VMHostStub{
+		RunSmartContractCallCalled: func(input *vmcommon.ContractCallInput) (*vmcommon.VMOutput, error) {
+			flagActive = append(flagActive, ae.World.EnableEpochsHandler.IsFixOOGReturnCodeFlagEnabled())
+			return &vmcommon.VMOutput{ReturnCode: vmcommon.Ok}, nil
+		},
+		BlockchainCalled: func() vmhost.BlockchainContext {
+			return blockchainContext
+		},
+		RuntimeCalled: func() vmhost.RuntimeContext {
+			return &contextmock.RuntimeContextMock{}
+		},
+		MeteringCalled: func() vmhost.MeteringContext {
+			return &contextmock.MeteringContextMock{GasCost: gasCost}
+		},
+	}
+	ae.vm = host
+	ae.vmHost = host
+	return ae, &flagActive, blockchainContext
+}
+
+func TestSetEnableEpochs_ActivationAtTheBlockEpoch(t *testing.T) {
+	ae, flagActive, _ := newEpochsTestExecutor(t)
+	require.Nil(t, ae.setEnableEpochs(&mj.EnableEpochs{
+		Flags: []*mj.EnableEpochsFlag{
+			{Name: worldhook.FixOOGReturnCodeFlag, Epoch: mj.JSONUint64{Value: 2, Original: "2"}},
+		},
+	}))
+	// the flags not mentioned keep their default epochs
+	require.True(t, ae.World.EnableEpochsHandler.IsManagedCryptoAPIsFlagEnabled())
+
+	require.Nil(t, executeTestSteps(t, ae,
+		epochsTestSetState,
+		epochsTestCall,
+		epochsTestBlock(1), epochsTestCall,
+		epochsTestBlock(2), epochsTestCall,
+		epochsTestBlock(3), epochsTestCall,
+		epochsTestBlock(1), epochsTestCall,
+	))
+	require.Equal(t, []bool{false, false, true, true, false}, *flagActive)
+}
+
+func TestSetEnableEpochs_FromFile(t *testing.T) {
+	ae, _, _ := newEpochsTestExecutor(t)
+	require.Nil(t, executeTestSteps(t, ae, epochsTestBlock(3)))
+
+	enableEpochs := &mj.EnableEpochs{
+		File: mj.NewJSONBytesFromString([]byte("FixOOGReturnCodeEnableEpoch = 3\nManagedCryptoAPIs = 4"), "file:epochs.toml"),
+		Flags: []*mj.EnableEpochsFlag{
+			{Name: worldhook.ManagedCryptoAPIsFlag, Epoch: mj.JSONUint64{Value: 3, Original: "3"}},
+		},
+	}
+	require.Nil(t, ae.setEnableEpochs(enableEpochs))
+	// the flags of the current block are active right away, the scenario flags win over the file
+	require.True(t, ae.World.EnableEpochsHandler.IsFixOOGReturnCodeFlagEnabled())
+	require.True(t, ae.World.EnableEpochsHandler.IsManagedCryptoAPIsFlagEnabled())
+
+	enableEpochs.File = mj.NewJSONBytesFromString([]byte("UnknownFlag = 3"), "file:epochs.toml")
+	err := ae.setEnableEpochs(enableEpochs)
+	require.EqualError(t, err, "bad enable epochs file file:epochs.toml: unknown enable epochs flag: UnknownFlag")
+
+	enableEpochs.File = mj.JSONBytesFromString{}
+	enableEpochs.Flags = []*mj.EnableEpochsFlag{{Name: "UnknownFlag", Epoch: mj.JSONUint64{Value: 3, Original: "3"}}}
+	err = ae.setEnableEpochs(enableEpochs)
+	require.EqualError(t, err, "unknown enable epochs flag: UnknownFlag")
+}
+
+func TestSetEnableEpochs_ClearsTheVMCaches(t *testing.T) {
+	ae, _, blockchainContext := newEpochsTestExecutor(t)
+
+	scenario := &mj.Scenario{EnableEpochs: &mj.EnableEpochs{
+		Flags: []*mj.EnableEpochsFlag{
+			{Name: worldhook.FixOOGReturnCodeFlag, Epoch: mj.JSONUint64{Value: 2, Original: "2"}},
+		},
+	}}
+	require.Nil(t, ae.ExecuteScenario(scenario, fr.NewDefaultFileResolver()))
+	require.Equal(t, 1, blockchainContext.nrClears)
+
+	// the same epochs again leave the caches as they are
+	require.Nil(t, ae.ExecuteScenario(scenario, fr.NewDefaultFileResolver()))
+	require.Equal(t, 1, blockchainContext.nrClears)
+
+	// back to the defaults
+	ae.Reset()
+	require.Equal(t, 2, blockchainContext.nrClears)
+	require.True(t, ae.World.EnableEpochsHandler.IsFixOOGReturnCodeFlagEnabled())
+}
@@ -1,6 +1,7 @@
 package scenarioexec
 
 import (
+	"bytes"
 	"fmt"
 
 	"github.com/multiversx/mx-chain-core-go/core"
@@ -15,8 +16,8 @@ import (
 	fr "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/fileresolver"
 	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
 	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost/contexts"
 	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost/hostCore"
-	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost/mock"
 )
 
 var log = logger.GetOrCreate("vm/scenarios")
@@ -26,30 +27,59 @@ var TestVMType = []byte{0, 0}
 
 // VMTestExecutor parses, interprets and executes both .test.json tests and .scen.json scenarios with VM.
 type VMTestExecutor struct {
-	World             *worldhook.MockWorld
-	vm                vmi.VMExecutionHandler
-	vmHost            vmhost.VMHost
-	checkGas          bool
-	scenarioTraceGas  []bool
-	fileResolver      fr.FileResolver
-	exprReconstructor er.ExprReconstructor
+	World                *worldhook.MockWorld
+	vm                   vmi.VMExecutionHandler
+	vmHost               vmhost.VMHost
+	checkGas             bool
+	scenarioTraceGas     []bool
+	fileResolver         fr.FileResolver
+	exprReconstructor    er.ExprReconstructor
+	coverage             *coverageTracker
+	gasProfiler          *gasProfiler
+	savedStates          map[string]*worldhook.WorldState
+	lastInternalErrors   []error
+	lastVMAbort          error
+	drawnRandomValues    [][]byte
+	systemSCMocksEnabled bool
+	codeFilePaths        map[string]string
+	stepTimer            *stepTimer
+	debugger             *Debugger
 }
 
 var _ mc.TestExecutor = (*VMTestExecutor)(nil)
 var _ mc.ScenarioExecutor = (*VMTestExecutor)(nil)
+var _ mc.ScenarioFileListener = (*VMTestExecutor)(nil)
 
 // NewVMTestExecutor prepares a new VMTestExecutor instance.
 func NewVMTestExecutor() (*VMTestExecutor, error) {
 	world := worldhook.NewMockWorld()
 
-	return &VMTestExecutor{
+	executor := &VMTestExecutor{
 		World:             world,
 		vm:                nil,
 		checkGas:          true,
 		scenarioTraceGas:  make([]bool, 0),
 		fileResolver:      nil,
 		exprReconstructor: er.ExprReconstructor{},
-	}, nil
+		savedStates:       make(map[string]*worldhook.WorldState),
+		codeFilePaths:     make(map[string]string),
+	}
+	executor.exprReconstructor.KnownAddress = executor.isKnownAddress
+	return executor, nil
+}
+
+// isKnownAddress tells whether an account with the given address exists in the current world,
+// or is about to be created, so that dumps and diffs can show it as an address.
+func (ae *VMTestExecutor) isKnownAddress(address []byte) bool {
+	if ae.World.GetAccount(address) != nil {
+		return true
+	}
+	for _, newAddressMock := range ae.World.NewAddressMocks {
+		if bytes.Equal(newAddressMock.NewAddress, address) {
+			return true
+		}
+	}
+	return false
 }
 
 // InitVM will initialize the VM and the builtin function container.
@@ -72,38 +102,14 @@ func (ae *VMTestExecutor) InitVM(scenGasSchedule mj.GasSchedule) error {
 	blockGasLimit := uint64(10000000)
 	esdtTransferParser, _ := parsers.NewESDTTransferParser(worldhook.WorldMarshalizer)
 	vm, err := hostCore.NewVMHost(ae.World, &vmhost.VMHostParameters{
-		VMType:               TestVMType,
-		BlockGasLimit:        blockGasLimit,
-		GasSchedule:          gasSchedule,
-		BuiltInFuncContainer: ae.World.BuiltinFuncs.Container,
-		ProtectedKeyPrefix:   []byte(core.ProtectedKeyPrefix),
-		ESDTTransferParser:   esdtTransferParser,
-		EpochNotifier:        &mock.EpochNotifierStub{},
-		EnableEpochsHandler: &mock.EnableEpochsHandlerStub{
-			IsStorageAPICostOptimizationFlagEnabledField:         true,
-			IsMultiESDTTransferFixOnCallBackFlagEnabledField:     true,
-			IsFixOOGReturnCodeFlagEnabledField:                   true,
-			IsRemoveNonUpdatedStorageFlagEnabledField:            true,
-			IsCreateNFTThroughExecByCallerFlagEnabledField:       true,
-			IsManagedCryptoAPIsFlagEnabledField:                  true,
-			IsFailExecutionOnEveryAPIErrorFlagEnabledField:       true,
-			IsRefactorContextFlagEnabledField:                    true,
-			IsCheckCorrectTokenIDForTransferRoleFlagEnabledField: true,
-			IsDisableExecByCallerFlagEnabledField:                true,
-			IsESDTTransferRoleFlagEnabledField:                   true,
-			IsGlobalMintBurnFlagEnabledField:                     true,
-			IsTransferToMetaFlagEnabledField:                     true,
-			IsCheckFrozenCollectionFlagEnabledField:              true,
-			IsFixAsyncCallbackCheckFlagEnabledField:              true,
-			IsESDTNFTImprovementV1FlagEnabledField:               true,
-			IsSaveToSystemAccountFlagEnabledField:                true,
-			IsValueLengthCheckFlagEnabledField:                   true,
-			IsSCDeployFlagEnabledField:                           true,
-			IsRepairCallbackFlagEnabledField:                     true,
-			IsAheadOfTimeGasUsageFlagEnabledField:                true,
-			IsCheckFunctionArgumentFlagEnabledField:              true,
-			IsCheckExecuteOnReadOnlyFlagEnabledField:             true,
-		},
+		VMType:                   TestVMType,
+		BlockGasLimit:            blockGasLimit,
+		GasSchedule:              gasSchedule,
+		BuiltInFuncContainer:     ae.World.BuiltinFuncs.Container,
+		ProtectedKeyPrefix:       []byte(core.ProtectedKeyPrefix),
+		ESDTTransferParser:       esdtTransferParser,
+		EpochNotifier:            ae.World.EnableEpochsHandler,
+		EnableEpochsHandler:      ae.World.EnableEpochsHandler,
 		WasmerSIGSEGVPassthrough: false,
 		Hasher:                   worldhook.DefaultHasher,
 	})
@@ -113,9 +119,57 @@ func (ae *VMTestExecutor) InitVM(scenGasSchedule mj.GasSchedule) error {
 
 	ae.vm = vm
 	ae.vmHost = vm
+	vm.ManagedTypes().SetRandomnessObserver(ae.recordRandomValue)
+	if ae.coverage != nil || ae.systemSCMocksEnabled || ae.stepTimer != nil {
+		ae.installInstanceBuilder()
+	}
 	return nil
 }
 
+// EnableSystemSCMocks registers Go-native mocks of the ESDT, DNS and staking system smart contracts in the world.
+// Contracts under test can then call them, including via async calls.
+func (ae *VMTestExecutor) EnableSystemSCMocks() {
+	if ae.systemSCMocksEnabled {
+		return
+	}
+
+	ae.systemSCMocksEnabled = true
+	ae.World.RegisterDefaultSystemSCMocks()
+	if ae.vmHost != nil {
+		ae.installInstanceBuilder()
+	}
+}
+
+// installInstanceBuilder replaces the default Wasmer instance builder with one that
+// also times instance creation, runs the system SC mocks and/or records coverage, as configured.
+func (ae *VMTestExecutor) installInstanceBuilder() {
+	var builder vmhost.InstanceBuilder = &contexts.WasmerInstanceBuilder{}
+	if ae.stepTimer != nil {
+		builder = &timingInstanceBuilder{
+			innerBuilder: builder,
+			timer:        ae.stepTimer,
+		}
+	}
+	if ae.systemSCMocksEnabled {
+		builder = &systemSCInstanceBuilder{
+			innerBuilder: builder,
+			host:         ae.vmHost,
+			world:        ae.World,
+		}
+	}
+	if ae.coverage != nil {
+		builder = &coverageInstanceBuilder{
+			innerBuilder: builder,
+			host:         ae.vmHost,
+			world:        ae.World,
+			tracker:      ae.coverage,
+		}
+	}
+
+	ae.vmHost.Runtime().ReplaceInstanceBuilder(builder)
+	ae.vmHost.Runtime().ClearWarmInstanceCache()
+}
+
 // GetVM yields a reference to the VMExecutionHandler used.
 func (ae *VMTestExecutor) GetVM() vmi.VMExecutionHandler {
 	return ae.vm
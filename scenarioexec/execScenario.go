@@ -2,9 +2,11 @@ package scenarioexec
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/multiversx/mx-chain-core-go/core/check"
 	vmi "github.com/multiversx/mx-chain-vm-common-go"
+	worldhook "github.com/multiversx/mx-chain-vm-v1_4-go/mock/world"
 	mc "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/controller"
 	fr "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/fileresolver"
 	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
@@ -18,6 +20,10 @@ func (ae *VMTestExecutor) Reset() {
 		ae.vmHost.Reset()
 	}
 	ae.World.Clear()
+	ae.savedStates = make(map[string]*worldhook.WorldState)
+	_ = ae.setActivationEpochs(worldhook.DefaultActivationEpochs())
+	ae.World.StateTrie = nil
+	ae.World.DeriveContractAddresses = false
 }
 
 // Close will simply close the VM
@@ -27,6 +33,22 @@ func (ae *VMTestExecutor) Close() {
 	}
 }
 
+// BeginScenarioFile prepares the per-file reports, before a top-level scenario file runs.
+func (ae *VMTestExecutor) BeginScenarioFile(scenFilePath string) {
+	ae.beginGasProfile(scenFilePath)
+	ae.beginScenarioTiming(scenFilePath)
+	ae.beginDebuggerScenarioFile()
+}
+
+// EndScenarioFile finalizes the per-file reports, after a top-level scenario file ran.
+func (ae *VMTestExecutor) EndScenarioFile(scenFilePath string, scenErr error) {
+	ae.endScenarioTiming()
+	err := ae.endGasProfile(scenFilePath, scenErr)
+	if err != nil {
+		log.Error("could not write gas profile", "scenario", scenFilePath, "error", err)
+	}
+}
+
 // ExecuteScenario executes an individual test.
 func (ae *VMTestExecutor) ExecuteScenario(scenario *mj.Scenario, fileResolver fr.FileResolver) error {
 	ae.fileResolver = fileResolver
@@ -37,14 +59,34 @@ func (ae *VMTestExecutor) ExecuteScenario(scenario *mj.Scenario, fileResolver fr
 	if err != nil {
 		return err
 	}
+	if len(scenario.RandomSeedMaster.Original) > 0 {
+		ae.World.RandomSeedMaster = scenario.RandomSeedMaster.Value
+	}
+	if scenario.StateTrie {
+		ae.World.EnableStateTrie()
+	}
+	if scenario.DeriveContractAddresses {
+		ae.World.DeriveContractAddresses = true
+	}
+	if scenario.EnableEpochs != nil {
+		err = ae.setEnableEpochs(scenario.EnableEpochs)
+		if err != nil {
+			return err
+		}
+	}
+	ae.setGasProfileSchedule(scenario.GasSchedule)
 
 	txIndex := 0
 	for _, generalStep := range scenario.Steps {
-		setGasTraceInMetering(ae, true)
-		err := ae.ExecuteStep(generalStep)
+		err := ae.debugBeforeStep(generalStep)
 		if err != nil {
 			return err
 		}
+		setGasTraceInMetering(ae, true)
+		err = ae.ExecuteStep(generalStep)
+		if err != nil {
+			return ae.debugStepFailed(generalStep, err)
+		}
 		setGasTraceInMetering(ae, false)
 		txIndex++
 	}
@@ -55,6 +97,7 @@ func (ae *VMTestExecutor) ExecuteScenario(scenario *mj.Scenario, fileResolver fr
 // ExecuteStep executes an individual step from a scenario.
 func (ae *VMTestExecutor) ExecuteStep(generalStep mj.Step) error {
 	err := error(nil)
+	timingStart := ae.beginStepTiming()
 
 	switch step := generalStep.(type) {
 	case *mj.ExternalStepsStep:
@@ -66,12 +109,20 @@ func (ae *VMTestExecutor) ExecuteStep(generalStep mj.Step) error {
 		err = ae.ExecuteSetStateStep(step)
 	case *mj.CheckStateStep:
 		err = ae.ExecuteCheckStateStep(step)
+	case *mj.FuzzStep:
+		err = ae.ExecuteFuzzStep(step)
 	case *mj.TxStep:
 		_, err = ae.ExecuteTxStep(step)
 	case *mj.DumpStateStep:
-		err = ae.DumpWorld()
+		err = ae.ExecuteDumpStateStep(step)
+	case *mj.SaveStateStep:
+		ae.savedStates[step.StateName] = ae.World.CloneState()
+	case *mj.RestoreStateStep:
+		err = ae.ExecuteRestoreStateStep(step)
 	}
 
+	// external steps are not timed as a whole, only their own steps
+	ae.endStepTiming(generalStep, timingStart)
 	logGasTrace(ae)
 
 	return err
@@ -108,6 +159,7 @@ func (ae *VMTestExecutor) ExecuteSetStateStep(step *mj.SetStateStep) error {
 	}
 
 	for _, scenAccount := range step.Accounts {
+		ae.recordCodeFilePath(scenAccount.Code)
 		if scenAccount.Update {
 			err := ae.UpdateAccount(scenAccount)
 			if err != nil {
@@ -136,7 +188,8 @@ func (ae *VMTestExecutor) ExecuteSetStateStep(step *mj.SetStateStep) error {
 	addressMocksToAdd := convertNewAddressMocks(step.NewAddressMocks)
 	ae.World.NewAddressMocks = append(ae.World.NewAddressMocks, addressMocksToAdd...)
 
-	return nil
+	// also saves the accounts to the accounts store, if the world has one
+	return ae.World.CommitChanges()
 }
 
 // ExecuteTxStep executes a TxStep.
@@ -150,15 +203,22 @@ func (ae *VMTestExecutor) ExecuteTxStep(step *mj.TxStep) (*vmi.VMOutput, error)
 		vmhost.SetLoggingForTests()
 	}
 
+	ae.resetGasProfileCounters()
 	output, err := ae.executeTx(step.TxIdent, step.Tx)
 	if err != nil {
 		return nil, err
 	}
+	ae.recordGasProfileStep(step, output)
 
 	if step.DisplayLogs {
 		vmhost.DisableLoggingForTests()
 	}
 
+	err = ae.checkVMAbort(step.TxIdent, step.ExpectedResult)
+	if err != nil {
+		return nil, err
+	}
+
 	// check results
 	if step.ExpectedResult != nil {
 		err = ae.checkTxResults(step.TxIdent, step.ExpectedResult, ae.checkGas, output)
@@ -182,6 +242,7 @@ func (ae *VMTestExecutor) PutNewAccount(scenAccount *mj.Account) error {
 	}
 
 	ae.World.AcctMap.PutAccount(worldAccount)
+	ae.World.MarkAccountChanged(worldAccount.Address)
 	return nil
 }
 
@@ -196,7 +257,7 @@ func (ae *VMTestExecutor) UpdateAccount(scenAccount *mj.Account) error {
 		return err
 	}
 
-	existingAccount := ae.World.AcctMap.GetAccount(scenAccount.Address.Value)
+	existingAccount := ae.World.GetAccount(scenAccount.Address.Value)
 	if existingAccount == nil {
 		return errors.New("account not found. could not update")
 	}
@@ -225,5 +286,17 @@ func (ae *VMTestExecutor) UpdateAccount(scenAccount *mj.Account) error {
 	existingAccount.AsyncCallData = worldAccount.AsyncCallData
 
 	ae.World.AcctMap.PutAccount(existingAccount)
+	ae.World.MarkAccountChanged(existingAccount.Address)
+	return nil
+}
+
+// ExecuteRestoreStateStep brings the world back to a state previously saved with a saveState step.
+func (ae *VMTestExecutor) ExecuteRestoreStateStep(step *mj.RestoreStateStep) error {
+	state, found := ae.savedStates[step.StateName]
+	if !found {
+		return fmt.Errorf("unknown saved state: %s", step.StateName)
+	}
+
+	ae.World.RestoreState(state)
 	return nil
 }
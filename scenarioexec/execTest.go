@@ -19,6 +19,7 @@ func (ae *VMTestExecutor) ExecuteTest(test *mj.Test) error {
 		}
 
 		ae.World.AcctMap.PutAccount(account)
+		ae.World.MarkAccountChanged(account.Address)
 	}
 
 	for _, block := range test.Blocks {
@@ -32,6 +33,10 @@ func (ae *VMTestExecutor) ExecuteTest(test *mj.Test) error {
 			}
 
 			blResult := block.Results[txIndex]
+			err = ae.checkVMAbort(txName, blResult)
+			if err != nil {
+				return err
+			}
 
 			// check results
 			err = ae.checkTxResults(txName, blResult, test.CheckGas, output)
@@ -0,0 +1,466 @@
+package scenarioexec
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+)
+
+// GasProfileFileSuffix is the suffix of the files produced by the gas profiler.
+const GasProfileFileSuffix = ".gasprofile.json"
+
+// GasProfileAPIFunction holds the number of calls and total gas of one API function in a step.
+type GasProfileAPIFunction struct {
+	Calls    uint64 `json:"calls"`
+	TotalGas uint64 `json:"totalGas"`
+}
+
+// GasProfileStep is the gas breakdown of a single transaction step.
+// GasUsed, APIFunctions, BuiltinFunctions and AsyncLock are measured during execution.
+// The estimated costs are not: the init and compilation costs are computed from the gas schedule
+// and the code size, and the storage cost adds up the API functions with "storage" in their name.
+type GasProfileStep struct {
+	StepIndex                int                               `json:"stepIndex"`
+	TxID                     string                            `json:"txId"`
+	StepType                 string                            `json:"stepType"`
+	Function                 string                            `json:"function,omitempty"`
+	GasLimit                 uint64                            `json:"gasLimit"`
+	GasUsed                  uint64                            `json:"gasUsed"`
+	EstimatedInitCost        uint64                            `json:"estimatedInitCost"`
+	EstimatedCompilationCost uint64                            `json:"estimatedCompilationCost"`
+	APIFunctions             map[string]*GasProfileAPIFunction `json:"apiFunctions"`
+	EstimatedStorageCost     uint64                            `json:"estimatedStorageCost"`
+	BuiltinFunctions         map[string]uint64                 `json:"builtinFunctions"`
+	AsyncLock                uint64                            `json:"asyncLock"`
+}
+
+// GasProfile holds the gas breakdown of all transaction steps of a scenario, including external steps.
+// Scenario is the path of the scenario file, relative to the directory the run started from.
+type GasProfile struct {
+	Scenario    string            `json:"scenario"`
+	GasSchedule string            `json:"gasSchedule"`
+	Steps       []*GasProfileStep `json:"steps"`
+}
+
+type gasProfiler struct {
+	outputDir string
+	rootDir   string
+	current   *GasProfile
+	collect   bool
+	collected []*collectedGasProfile
+}
+
+// collectedGasProfile is a gas profile kept in memory, together with the result of its scenario.
+type collectedGasProfile struct {
+	scenFilePath string
+	profile      *GasProfile
+	scenErr      error
+}
+
+// EnableGasProfile makes the executor write a gas profile for every top-level
+// scenario file into outputDir, keeping the layout of the scenario files under rootPath,
+// which is the scenario file or directory being run.
+// The API function costs come from the gas trace, which the host is told to keep during the steps.
+func (ae *VMTestExecutor) EnableGasProfile(outputDir string, rootPath string) {
+	if ae.gasProfiler == nil {
+		ae.gasProfiler = &gasProfiler{}
+	}
+	ae.gasProfiler.outputDir = outputDir
+	ae.gasProfiler.rootDir = gasProfileRootDir(rootPath)
+}
+
+func gasProfileRootDir(rootPath string) string {
+	info, err := os.Stat(rootPath)
+	if err == nil && !info.IsDir() {
+		return filepath.Dir(rootPath)
+	}
+	return rootPath
+}
+
+// gasProfileName is the path of the scenario file relative to the root directory,
+// so that scenarios with the same file name in different directories get different profiles.
+func (profiler *gasProfiler) gasProfileName(scenFilePath string) string {
+	if len(profiler.rootDir) == 0 {
+		return filepath.Base(scenFilePath)
+	}
+	relativePath, err := filepath.Rel(profiler.rootDir, scenFilePath)
+	if err != nil || strings.HasPrefix(relativePath, "..") {
+		return filepath.Base(scenFilePath)
+	}
+	return filepath.ToSlash(relativePath)
+}
+
+// collectGasProfiles makes the executor also keep the gas profiles in memory,
+// even if no output directory is configured.
+func (ae *VMTestExecutor) collectGasProfiles() {
+	if ae.gasProfiler == nil {
+		ae.gasProfiler = &gasProfiler{}
+	}
+	ae.gasProfiler.collect = true
+}
+
+func (ae *VMTestExecutor) beginGasProfile(scenFilePath string) {
+	if ae.gasProfiler == nil {
+		return
+	}
+
+	ae.gasProfiler.current = &GasProfile{
+		Scenario: ae.gasProfiler.gasProfileName(scenFilePath),
+		Steps:    make([]*GasProfileStep, 0),
+	}
+}
+
+func (ae *VMTestExecutor) endGasProfile(scenFilePath string, scenErr error) error {
+	if ae.gasProfiler == nil || ae.gasProfiler.current == nil {
+		return nil
+	}
+
+	profile := ae.gasProfiler.current
+	ae.gasProfiler.current = nil
+
+	if ae.gasProfiler.collect {
+		ae.gasProfiler.collected = append(ae.gasProfiler.collected, &collectedGasProfile{
+			scenFilePath: scenFilePath,
+			profile:      profile,
+			scenErr:      scenErr,
+		})
+	}
+	if len(ae.gasProfiler.outputDir) == 0 {
+		return nil
+	}
+
+	fileName := strings.TrimSuffix(filepath.FromSlash(profile.Scenario), ".scen.json") + GasProfileFileSuffix
+	return profile.WriteJSONFile(filepath.Join(ae.gasProfiler.outputDir, fileName))
+}
+
+func (ae *VMTestExecutor) isGasProfileActive() bool {
+	return ae.gasProfiler != nil && ae.gasProfiler.current != nil
+}
+
+func (ae *VMTestExecutor) setGasProfileSchedule(gasSchedule mj.GasSchedule) {
+	if !ae.isGasProfileActive() || len(ae.gasProfiler.current.GasSchedule) > 0 {
+		return
+	}
+
+	ae.gasProfiler.current.GasSchedule = gasSchedule.String()
+}
+
+func (ae *VMTestExecutor) resetGasProfileCounters() {
+	if !ae.isGasProfileActive() || ae.World.BuiltinFuncs == nil {
+		return
+	}
+
+	ae.World.BuiltinFuncs.RecordGasUsage = true
+	ae.World.BuiltinFuncs.ResetGasUsage()
+}
+
+func (ae *VMTestExecutor) recordGasProfileStep(step *mj.TxStep, output *vmcommon.VMOutput) {
+	if !ae.isGasProfileActive() {
+		return
+	}
+
+	tx := step.Tx
+	profileStep := &GasProfileStep{
+		StepIndex:        len(ae.gasProfiler.current.Steps),
+		TxID:             step.TxIdent,
+		StepType:         step.StepTypeName(),
+		Function:         tx.Function,
+		GasLimit:         tx.GasLimit.Value,
+		APIFunctions:     make(map[string]*GasProfileAPIFunction),
+		BuiltinFunctions: make(map[string]uint64),
+	}
+	if tx.GasLimit.Value > output.GasRemaining {
+		profileStep.GasUsed = tx.GasLimit.Value - output.GasRemaining
+	}
+
+	ae.addGasProfileInitialCosts(profileStep, tx)
+
+	if tx.Type.IsSmartContractTx() {
+		for _, apiTraces := range ae.vmHost.Metering().GetGasTrace() {
+			for apiName, gasValues := range apiTraces {
+				apiFunction, found := profileStep.APIFunctions[apiName]
+				if !found {
+					apiFunction = &GasProfileAPIFunction{}
+					profileStep.APIFunctions[apiName] = apiFunction
+				}
+				for _, gas := range gasValues {
+					apiFunction.Calls++
+					apiFunction.TotalGas += gas
+				}
+			}
+		}
+	}
+
+	for apiName, apiFunction := range profileStep.APIFunctions {
+		if strings.Contains(strings.ToLower(apiName), "storage") {
+			profileStep.EstimatedStorageCost += apiFunction.TotalGas
+		}
+	}
+
+	if ae.World.BuiltinFuncs != nil {
+		for function, gasUsed := range ae.World.BuiltinFuncs.GasUsedByFunction {
+			profileStep.BuiltinFunctions[function] = gasUsed
+		}
+	}
+
+	for _, outputAccount := range output.OutputAccounts {
+		for _, outputTransfer := range outputAccount.OutputTransfers {
+			profileStep.AsyncLock += outputTransfer.GasLocked
+		}
+	}
+
+	ae.gasProfiler.current.Steps = append(ae.gasProfiler.current.Steps, profileStep)
+}
+
+// the initial costs are estimated from the gas schedule, following the way the metering context deducts them;
+// the metering context does not keep them apart from the rest of the gas used
+func (ae *VMTestExecutor) addGasProfileInitialCosts(profileStep *GasProfileStep, tx *mj.Transaction) {
+	gasSchedule := ae.vmHost.Metering().GasSchedule()
+	switch tx.Type {
+	case mj.ScDeploy:
+		profileStep.EstimatedInitCost = gasSchedule.BaseOpsAPICost.CreateContract
+		profileStep.EstimatedCompilationCost = uint64(len(tx.Code.Value)) * gasSchedule.BaseOperationCost.CompilePerByte
+	case mj.ScCall, mj.ScQuery:
+		recipient := ae.World.GetAccount(tx.To.Value)
+		if recipient == nil {
+			return
+		}
+		profileStep.EstimatedInitCost = gasSchedule.BaseOperationCost.GetCode
+		profileStep.EstimatedCompilationCost = uint64(len(recipient.Code)) * gasSchedule.BaseOperationCost.AoTPreparePerByte
+	}
+}
+
+// WriteJSONFile saves the gas profile as JSON.
+func (profile *GasProfile) WriteJSONFile(toPath string) error {
+	jsonBytes, err := json.MarshalIndent(profile, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(toPath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(toPath, append(jsonBytes, '\n'), 0644)
+}
+
+// LoadGasProfile reads a gas profile previously saved with WriteJSONFile.
+func LoadGasProfile(fromPath string) (*GasProfile, error) {
+	jsonBytes, err := ioutil.ReadFile(fromPath)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &GasProfile{}
+	err = json.Unmarshal(jsonBytes, profile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gas profile %s: %w", fromPath, err)
+	}
+
+	return profile, nil
+}
+
+// GasProfileDifference is a gas value that changed between two profiles of the same scenario.
+type GasProfileDifference struct {
+	Scenario     string
+	StepIndex    int
+	TxID         string
+	Item         string
+	OldGas       uint64
+	NewGas       uint64
+	IsRegression bool
+}
+
+// CompareGasProfiles lists the gas values that differ between the steps of two profiles.
+// An increase of more than thresholdPercent is marked as regression.
+func CompareGasProfiles(oldProfile *GasProfile, newProfile *GasProfile, thresholdPercent float64) []*GasProfileDifference {
+	var differences []*GasProfileDifference
+
+	nrSteps := len(oldProfile.Steps)
+	if len(newProfile.Steps) < nrSteps {
+		nrSteps = len(newProfile.Steps)
+	}
+
+	for i := 0; i < nrSteps; i++ {
+		oldStep := oldProfile.Steps[i]
+		newStep := newProfile.Steps[i]
+
+		addDifference := func(item string, oldGas uint64, newGas uint64) {
+			if oldGas == newGas {
+				return
+			}
+			differences = append(differences, &GasProfileDifference{
+				Scenario:     newProfile.Scenario,
+				StepIndex:    newStep.StepIndex,
+				TxID:         newStep.TxID,
+				Item:         item,
+				OldGas:       oldGas,
+				NewGas:       newGas,
+				IsRegression: isGasRegression(oldGas, newGas, thresholdPercent),
+			})
+		}
+
+		addDifference("gasUsed", oldStep.GasUsed, newStep.GasUsed)
+		addDifference("estimatedInitCost", oldStep.EstimatedInitCost, newStep.EstimatedInitCost)
+		addDifference("estimatedCompilationCost", oldStep.EstimatedCompilationCost, newStep.EstimatedCompilationCost)
+		addDifference("estimatedStorageCost", oldStep.EstimatedStorageCost, newStep.EstimatedStorageCost)
+		addDifference("asyncLock", oldStep.AsyncLock, newStep.AsyncLock)
+
+		for _, apiName := range unionOfKeys(apiFunctionTotals(oldStep), apiFunctionTotals(newStep)) {
+			addDifference("api:"+apiName, apiFunctionTotals(oldStep)[apiName], apiFunctionTotals(newStep)[apiName])
+		}
+		for _, function := range unionOfKeys(oldStep.BuiltinFunctions, newStep.BuiltinFunctions) {
+			addDifference("builtin:"+function, oldStep.BuiltinFunctions[function], newStep.BuiltinFunctions[function])
+		}
+	}
+
+	return differences
+}
+
+func isGasRegression(oldGas uint64, newGas uint64, thresholdPercent float64) bool {
+	if newGas <= oldGas {
+		return false
+	}
+	if oldGas == 0 {
+		return true
+	}
+
+	increasePercent := float64(newGas-oldGas) * 100 / float64(oldGas)
+	return increasePercent > thresholdPercent
+}
+
+func apiFunctionTotals(step *GasProfileStep) map[string]uint64 {
+	totals := make(map[string]uint64, len(step.APIFunctions))
+	for apiName, apiFunction := range step.APIFunctions {
+		totals[apiName] = apiFunction.TotalGas
+	}
+	return totals
+}
+
+func unionOfKeys(first map[string]uint64, second map[string]uint64) []string {
+	keySet := make(map[string]uint64, len(first)+len(second))
+	for key := range first {
+		keySet[key] = 0
+	}
+	for key := range second {
+		keySet[key] = 0
+	}
+	return sortedKeys(keySet)
+}
+
+// CompareGasProfileFiles compares two gas profile files, or two directories
+// containing gas profile files with the same relative paths, and prints the differences.
+// Returns the number of regressions found.
+func CompareGasProfileFiles(oldPath string, newPath string, thresholdPercent float64, w io.Writer) (int, error) {
+	pathPairs, err := matchGasProfilePaths(oldPath, newPath)
+	if err != nil {
+		return 0, err
+	}
+
+	nrRegressions := 0
+	for _, pathPair := range pathPairs {
+		oldProfile, err := LoadGasProfile(pathPair[0])
+		if err != nil {
+			return 0, err
+		}
+		newProfile, err := LoadGasProfile(pathPair[1])
+		if err != nil {
+			return 0, err
+		}
+
+		if len(oldProfile.Steps) != len(newProfile.Steps) {
+			_, _ = fmt.Fprintf(w, "%s: number of steps changed from %d to %d, only the first steps are compared\n",
+				newProfile.Scenario, len(oldProfile.Steps), len(newProfile.Steps))
+		}
+
+		for _, difference := range CompareGasProfiles(oldProfile, newProfile, thresholdPercent) {
+			marker := ""
+			if difference.IsRegression {
+				marker = "  REGRESSION"
+				nrRegressions++
+			}
+			_, _ = fmt.Fprintf(w, "%s step %d (%s) %s: %d -> %d (%s)%s\n",
+				difference.Scenario,
+				difference.StepIndex,
+				difference.TxID,
+				difference.Item,
+				difference.OldGas,
+				difference.NewGas,
+				formatGasChange(difference.OldGas, difference.NewGas),
+				marker)
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "Compared %d gas profiles. Regressions above %.2f%%: %d.\n", len(pathPairs), thresholdPercent, nrRegressions)
+	return nrRegressions, nil
+}
+
+func formatGasChange(oldGas uint64, newGas uint64) string {
+	if oldGas == newGas {
+		return "+0.00%"
+	}
+	if oldGas == 0 {
+		return "new"
+	}
+	change := (float64(newGas) - float64(oldGas)) * 100 / float64(oldGas)
+	return fmt.Sprintf("%+.2f%%", math.Round(change*100)/100)
+}
+
+func matchGasProfilePaths(oldPath string, newPath string) ([][2]string, error) {
+	oldInfo, err := os.Stat(oldPath)
+	if err != nil {
+		return nil, err
+	}
+	newInfo, err := os.Stat(newPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if oldInfo.IsDir() != newInfo.IsDir() {
+		return nil, fmt.Errorf("cannot compare a file with a directory: %s, %s", oldPath, newPath)
+	}
+	if !oldInfo.IsDir() {
+		return [][2]string{{oldPath, newPath}}, nil
+	}
+
+	var oldFiles []string
+	err = filepath.Walk(oldPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, GasProfileFileSuffix) {
+			oldFiles = append(oldFiles, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(oldFiles)
+
+	var pathPairs [][2]string
+	for _, oldFile := range oldFiles {
+		relativePath, err := filepath.Rel(oldPath, oldFile)
+		if err != nil {
+			return nil, err
+		}
+		newFile := filepath.Join(newPath, relativePath)
+		_, err = os.Stat(newFile)
+		if os.IsNotExist(err) {
+			continue
+		}
+		pathPairs = append(pathPairs, [2]string{oldFile, newFile})
+	}
+
+	return pathPairs, nil
+}
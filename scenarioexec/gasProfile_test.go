@@ -0,0 +1,153 @@
+package scenarioexec
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	logger "github.com/multiversx/mx-chain-logger-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsGasRegression(t *testing.T) {
+	testCases := []struct {
+		name             string
+		oldGas           uint64
+		newGas           uint64
+		thresholdPercent float64
+		expected         bool
+	}{
+		{name: "unchanged", oldGas: 100, newGas: 100, thresholdPercent: 0, expected: false},
+		{name: "decrease", oldGas: 100, newGas: 50, thresholdPercent: 0, expected: false},
+		{name: "any increase without threshold", oldGas: 100, newGas: 101, thresholdPercent: 0, expected: true},
+		{name: "increase below threshold", oldGas: 100, newGas: 104, thresholdPercent: 5, expected: false},
+		{name: "increase at threshold", oldGas: 100, newGas: 105, thresholdPercent: 5, expected: false},
+		{name: "increase above threshold", oldGas: 100, newGas: 106, thresholdPercent: 5, expected: true},
+		{name: "new cost", oldGas: 0, newGas: 1, thresholdPercent: 50, expected: true},
+		{name: "removed cost", oldGas: 1, newGas: 0, thresholdPercent: 0, expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			require.Equal(t, testCase.expected, isGasRegression(testCase.oldGas, testCase.newGas, testCase.thresholdPercent))
+		})
+	}
+}
+
+func newTestGasProfileStep(stepIndex int, gasUsed uint64) *GasProfileStep {
+	return &GasProfileStep{
+		StepIndex:        stepIndex,
+		TxID:             "tx",
+		GasUsed:          gasUsed,
+		APIFunctions:     make(map[string]*GasProfileAPIFunction),
+		BuiltinFunctions: make(map[string]uint64),
+	}
+}
+
+func TestCompareGasProfiles(t *testing.T) {
+	oldStep := newTestGasProfileStep(0, 1000)
+	oldStep.EstimatedInitCost = 10
+	oldStep.APIFunctions["bigIntAdd"] = &GasProfileAPIFunction{Calls: 2, TotalGas: 20}
+	oldStep.APIFunctions["storageLoad"] = &GasProfileAPIFunction{Calls: 1, TotalGas: 100}
+	oldStep.BuiltinFunctions["ESDTTransfer"] = 300
+
+	newStep := newTestGasProfileStep(0, 1200)
+	newStep.EstimatedInitCost = 10
+	newStep.APIFunctions["bigIntAdd"] = &GasProfileAPIFunction{Calls: 2, TotalGas: 20}
+	newStep.APIFunctions["getCaller"] = &GasProfileAPIFunction{Calls: 1, TotalGas: 5}
+	newStep.BuiltinFunctions["ESDTTransfer"] = 290
+
+	oldProfile := &GasProfile{Scenario: "a/x.scen.json", Steps: []*GasProfileStep{oldStep, newTestGasProfileStep(1, 7)}}
+	newProfile := &GasProfile{Scenario: "a/x.scen.json", Steps: []*GasProfileStep{newStep}}
+
+	differences := CompareGasProfiles(oldProfile, newProfile, 10)
+	itemDifferences := make(map[string]*GasProfileDifference)
+	for _, difference := range differences {
+		require.Equal(t, "a/x.scen.json", difference.Scenario)
+		require.Equal(t, 0, difference.StepIndex)
+		itemDifferences[difference.Item] = difference
+	}
+
+	require.Len(t, itemDifferences, 4)
+	require.Equal(t, &GasProfileDifference{
+		Scenario:     "a/x.scen.json",
+		TxID:         "tx",
+		Item:         "gasUsed",
+		OldGas:       1000,
+		NewGas:       1200,
+		IsRegression: true,
+	}, itemDifferences["gasUsed"])
+	require.False(t, itemDifferences["api:storageLoad"].IsRegression)
+	require.Equal(t, uint64(0), itemDifferences["api:storageLoad"].NewGas)
+	require.True(t, itemDifferences["api:getCaller"].IsRegression)
+	require.False(t, itemDifferences["builtin:ESDTTransfer"].IsRegression)
+
+	require.Empty(t, CompareGasProfiles(oldProfile, oldProfile, 0))
+}
+
+func TestGasProfile_NamedByRelativePath(t *testing.T) {
+	rootDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	ae.EnableGasProfile(outputDir, rootDir)
+
+	for _, subDir := range []string{"a", "b"} {
+		scenFilePath := filepath.Join(rootDir, subDir, "x.scen.json")
+		ae.beginGasProfile(scenFilePath)
+		require.Equal(t, subDir+"/x.scen.json", ae.gasProfiler.current.Scenario)
+		require.Nil(t, ae.endGasProfile(scenFilePath, nil))
+	}
+
+	profileA, err := LoadGasProfile(filepath.Join(outputDir, "a", "x"+GasProfileFileSuffix))
+	require.Nil(t, err)
+	require.Equal(t, "a/x.scen.json", profileA.Scenario)
+	profileB, err := LoadGasProfile(filepath.Join(outputDir, "b", "x"+GasProfileFileSuffix))
+	require.Nil(t, err)
+	require.Equal(t, "b/x.scen.json", profileB.Scenario)
+
+	// scenario files outside the root keep their file name
+	require.Equal(t, "y.scen.json", ae.gasProfiler.gasProfileName(filepath.Join(filepath.Dir(rootDir), "y.scen.json")))
+}
+
+func TestGasProfile_KeepsGasTraceWithoutChangingTheLogger(t *testing.T) {
+	gasTraceLogger := logger.GetOrCreate("gasTrace")
+	logLevel := gasTraceLogger.GetLevel()
+
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	ae.EnableGasProfile(t.TempDir(), t.TempDir())
+	require.Equal(t, logLevel, gasTraceLogger.GetLevel())
+
+	// only while a scenario is profiled
+	require.False(t, ae.keepsGasTrace())
+	scenFilePath := filepath.Join(ae.gasProfiler.rootDir, "x.scen.json")
+	ae.beginGasProfile(scenFilePath)
+	require.True(t, ae.keepsGasTrace())
+	require.Nil(t, ae.endGasProfile(scenFilePath, nil))
+	require.False(t, ae.keepsGasTrace())
+}
+
+func TestCompareGasProfileFiles_Directories(t *testing.T) {
+	oldDir := t.TempDir()
+	newDir := t.TempDir()
+
+	writeProfile := func(dir string, name string, gasUsed uint64) {
+		profile := &GasProfile{Scenario: name + ".scen.json", Steps: []*GasProfileStep{newTestGasProfileStep(0, gasUsed)}}
+		require.Nil(t, profile.WriteJSONFile(filepath.Join(dir, filepath.FromSlash(name)+GasProfileFileSuffix)))
+	}
+	writeProfile(oldDir, "a/x", 100)
+	writeProfile(oldDir, "b/x", 100)
+	writeProfile(oldDir, "only-old", 100)
+	writeProfile(newDir, "a/x", 100)
+	writeProfile(newDir, "b/x", 200)
+
+	output := &bytes.Buffer{}
+	nrRegressions, err := CompareGasProfileFiles(oldDir, newDir, 5, output)
+	require.Nil(t, err)
+	require.Equal(t, 1, nrRegressions)
+	require.Contains(t, output.String(), "b/x.scen.json step 0 (tx) gasUsed: 100 -> 200 (+100.00%)  REGRESSION")
+	require.NotContains(t, output.String(), "a/x.scen.json step")
+	require.Contains(t, output.String(), "Compared 2 gas profiles.")
+}
@@ -0,0 +1,212 @@
+package scenarioexec
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mc "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/controller"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+)
+
+// GasScheduleScenarioResult holds the outcome of one scenario file, for each of the gas schedules compared.
+type GasScheduleScenarioResult struct {
+	Scenario string
+	Errors   []error
+	Profiles []*GasProfile
+}
+
+// GasScheduleComparison is the outcome of running the same scenarios once per gas schedule.
+type GasScheduleComparison struct {
+	GasSchedules []mj.GasSchedule
+	Scenarios    []*GasScheduleScenarioResult
+}
+
+// RunScenariosWithGasSchedules runs the scenarios at scenPath, a .scen.json file or a directory,
+// once for each of the given gas schedules, overriding the gas schedule in the scenario files.
+// Each run gets a new executor, since the VM is only initialized once per executor.
+// If not nil, configureExecutor is called on every new executor, before running.
+func RunScenariosWithGasSchedules(
+	scenPath string,
+	excludedFilePatterns []string,
+	schedules []mj.GasSchedule,
+	options *mc.RunScenarioOptions,
+	configureExecutor func(executor *VMTestExecutor, gasSchedule mj.GasSchedule),
+) (*GasScheduleComparison, error) {
+	if len(schedules) == 0 {
+		return nil, fmt.Errorf("no gas schedules to run the scenarios with")
+	}
+
+	fi, err := os.Stat(scenPath)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &GasScheduleComparison{
+		GasSchedules: schedules,
+	}
+	resultsByScenario := make(map[string]*GasScheduleScenarioResult)
+
+	for scheduleIndex, gasSchedule := range schedules {
+		fmt.Printf("Gas schedule: %s\n", gasSchedule.String())
+
+		executor, err := NewVMTestExecutor()
+		if err != nil {
+			return nil, err
+		}
+		if configureExecutor != nil {
+			configureExecutor(executor, gasSchedule)
+		}
+		executor.collectGasProfiles()
+
+		scheduleOptions := *options
+		scheduleOptions.ForceGasSchedule = true
+		scheduleOptions.GasSchedule = gasSchedule
+
+		runner := mc.NewScenarioRunner(
+			executor,
+			mc.NewDefaultFileResolver(),
+		)
+		if fi.IsDir() {
+			// failures are collected per scenario below
+			_ = runner.RunAllJSONScenariosInDirectory(
+				scenPath,
+				"",
+				".scen.json",
+				excludedFilePatterns,
+				&scheduleOptions)
+		} else {
+			_ = runner.RunTopLevelJSONScenario(scenPath, &scheduleOptions)
+		}
+
+		for _, collected := range executor.gasProfiler.collected {
+			result, found := resultsByScenario[collected.scenFilePath]
+			if !found {
+				result = &GasScheduleScenarioResult{
+					Scenario: collected.scenFilePath,
+					Errors:   make([]error, len(schedules)),
+					Profiles: make([]*GasProfile, len(schedules)),
+				}
+				resultsByScenario[collected.scenFilePath] = result
+				comparison.Scenarios = append(comparison.Scenarios, result)
+			}
+			result.Errors[scheduleIndex] = collected.scenErr
+			result.Profiles[scheduleIndex] = collected.profile
+		}
+
+		executor.Close()
+	}
+
+	return comparison, nil
+}
+
+// NrFailed yields the number of scenarios that failed or did not run with the gas schedule at the given index.
+func (comparison *GasScheduleComparison) NrFailed(scheduleIndex int) int {
+	nrFailed := 0
+	for _, result := range comparison.Scenarios {
+		if result.Profiles[scheduleIndex] == nil || result.Errors[scheduleIndex] != nil {
+			nrFailed++
+		}
+	}
+	return nrFailed
+}
+
+// HasFailures is true if any scenario failed with any of the gas schedules.
+func (comparison *GasScheduleComparison) HasFailures() bool {
+	for scheduleIndex := range comparison.GasSchedules {
+		if comparison.NrFailed(scheduleIndex) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteText prints the pass/fail summary for each gas schedule,
+// followed by the gas used by each tx step, under each gas schedule.
+// The changes are relative to the first gas schedule.
+func (comparison *GasScheduleComparison) WriteText(w io.Writer) {
+	_, _ = fmt.Fprintln(w, "Gas schedule results:")
+	for scheduleIndex, gasSchedule := range comparison.GasSchedules {
+		nrFailed := comparison.NrFailed(scheduleIndex)
+		_, _ = fmt.Fprintf(w, "  %-10s passed: %d. failed: %d.\n",
+			gasSchedule.String(),
+			len(comparison.Scenarios)-nrFailed,
+			nrFailed)
+		for _, result := range comparison.Scenarios {
+			scenErr := result.Errors[scheduleIndex]
+			if result.Profiles[scheduleIndex] == nil {
+				scenErr = fmt.Errorf("did not run")
+			}
+			if scenErr != nil {
+				_, _ = fmt.Fprintf(w, "    FAIL %s: %s\n", result.Scenario, scenErr.Error())
+			}
+		}
+	}
+
+	_, _ = fmt.Fprintln(w, "Gas used per tx step:")
+	header := fmt.Sprintf("  %-6s %-30s", "step", "txId")
+	for scheduleIndex, gasSchedule := range comparison.GasSchedules {
+		header += fmt.Sprintf(" %15s", gasSchedule.String())
+		if scheduleIndex > 0 {
+			header += fmt.Sprintf(" %10s", "change")
+		}
+	}
+	for _, result := range comparison.Scenarios {
+		_, _ = fmt.Fprintf(w, "%s\n", filepath.Base(result.Scenario))
+		_, _ = fmt.Fprintln(w, header)
+		for stepIndex := 0; stepIndex < result.maxNrSteps(); stepIndex++ {
+			_, _ = fmt.Fprintln(w, result.stepGasRow(stepIndex))
+		}
+	}
+}
+
+func (result *GasScheduleScenarioResult) maxNrSteps() int {
+	maxNrSteps := 0
+	for _, profile := range result.Profiles {
+		if profile != nil && len(profile.Steps) > maxNrSteps {
+			maxNrSteps = len(profile.Steps)
+		}
+	}
+	return maxNrSteps
+}
+
+func (result *GasScheduleScenarioResult) stepGasRow(stepIndex int) string {
+	var row strings.Builder
+	txID := ""
+	for _, profile := range result.Profiles {
+		if profile != nil && stepIndex < len(profile.Steps) {
+			txID = profile.Steps[stepIndex].TxID
+			break
+		}
+	}
+	row.WriteString(fmt.Sprintf("  %-6d %-30s", stepIndex, txID))
+
+	var firstStep *GasProfileStep
+	for scheduleIndex, profile := range result.Profiles {
+		var step *GasProfileStep
+		if profile != nil && stepIndex < len(profile.Steps) {
+			step = profile.Steps[stepIndex]
+		}
+		if scheduleIndex == 0 {
+			firstStep = step
+		}
+
+		if step == nil {
+			row.WriteString(fmt.Sprintf(" %15s", "-"))
+		} else {
+			row.WriteString(fmt.Sprintf(" %15d", step.GasUsed))
+		}
+
+		if scheduleIndex > 0 {
+			change := "-"
+			if step != nil && firstStep != nil {
+				change = formatGasChange(firstStep.GasUsed, step.GasUsed)
+			}
+			row.WriteString(fmt.Sprintf(" %10s", change))
+		}
+	}
+
+	return row.String()
+}
@@ -0,0 +1,168 @@
+package scenarioexec
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/config"
+	contextmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/context"
+	mc "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/controller"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
+	"github.com/stretchr/testify/require"
+)
+
+const gasScheduleTestScenario = `{
+	"steps": [
+		{
+			"step": "setState",
+			"accounts": {
+				"address:owner": { "nonce": "0", "balance": "0" },
+				"sc:adder": { "nonce": "0", "balance": "0", "code": "str:adder code" }
+			}
+		},
+		{
+			"step": "scCall",
+			"txId": "add",
+			"tx": {
+				"from": "address:owner",
+				"to": "sc:adder",
+				"function": "add",
+				"arguments": [],
+				"gasLimit": "1000",
+				"gasPrice": "0"
+			},
+			"expect": {
+				"out": [],
+				"status": "0",
+				"gas": "*",
+				"refund": "*"
+			}
+		},
+		{
+			"step": "checkState",
+			"accounts": {
+				"address:owner": { "nonce": "1", "balance": "%s" },
+				"+": ""
+			}
+		}
+	]
+}`
+
+// the VM is replaced by a stub that uses more gas under gas schedule V4
+func configureGasScheduleTestExecutor(t *testing.T) func(executor *VMTestExecutor, gasSchedule mj.GasSchedule) {
+	gasCost, err := config.CreateGasConfig(config.MakeGasMapForTests())
+	require.Nil(t, err)
+
+	return func(executor *VMTestExecutor, gasSchedule mj.GasSchedule) {
+		gasUsed := uint64(100)
+		if gasSchedule == mj.GasScheduleV4 {
+			gasUsed = 150
+		}
+		host := &contextmock.VMHostStub{
+			RunSmartContractCallCalled: func(input *vmcommon.ContractCallInput) (*vmcommon.VMOutput, error) {
+				return &vmcommon.VMOutput{
+					ReturnCode:   vmcommon.Ok,
+					GasRemaining: input.GasProvided - gasUsed,
+				}, nil
+			},
+			RuntimeCalled: func() vmhost.RuntimeContext {
+				return &contextmock.RuntimeContextMock{}
+			},
+			MeteringCalled: func() vmhost.MeteringContext {
+				return &contextmock.MeteringContextMock{GasCost: gasCost}
+			},
+		}
+		executor.vm = host
+		executor.vmHost = host
+	}
+}
+
+func writeGasScheduleTestScenario(t *testing.T, path string, expectedBalance string) {
+	scenario := []byte(fmt.Sprintf(gasScheduleTestScenario, expectedBalance))
+	require.Nil(t, ioutil.WriteFile(path, scenario, 0644))
+}
+
+func TestRunScenariosWithGasSchedules(t *testing.T) {
+	scenDir := t.TempDir()
+	writeGasScheduleTestScenario(t, filepath.Join(scenDir, "pass.scen.json"), "0")
+	writeGasScheduleTestScenario(t, filepath.Join(scenDir, "fail.scen.json"), "1")
+
+	var configuredSchedules []mj.GasSchedule
+	configure := configureGasScheduleTestExecutor(t)
+	comparison, err := RunScenariosWithGasSchedules(
+		scenDir,
+		nil,
+		[]mj.GasSchedule{mj.GasScheduleV3, mj.GasScheduleV4},
+		mc.DefaultRunScenarioOptions(),
+		func(executor *VMTestExecutor, gasSchedule mj.GasSchedule) {
+			configuredSchedules = append(configuredSchedules, gasSchedule)
+			configure(executor, gasSchedule)
+		})
+	require.Nil(t, err)
+	require.Equal(t, []mj.GasSchedule{mj.GasScheduleV3, mj.GasScheduleV4}, configuredSchedules)
+
+	require.Len(t, comparison.Scenarios, 2)
+	require.True(t, comparison.HasFailures())
+	require.Equal(t, 1, comparison.NrFailed(0))
+	require.Equal(t, 1, comparison.NrFailed(1))
+	for _, result := range comparison.Scenarios {
+		failed := filepath.Base(result.Scenario) == "fail.scen.json"
+		for scheduleIndex, expectedGasUsed := range []uint64{100, 150} {
+			require.Equal(t, failed, result.Errors[scheduleIndex] != nil)
+			require.Len(t, result.Profiles[scheduleIndex].Steps, 1)
+			require.Equal(t, "add", result.Profiles[scheduleIndex].Steps[0].TxID)
+			require.Equal(t, expectedGasUsed, result.Profiles[scheduleIndex].Steps[0].GasUsed)
+		}
+	}
+
+	_, err = RunScenariosWithGasSchedules(scenDir, nil, nil, mc.DefaultRunScenarioOptions(), nil)
+	require.NotNil(t, err)
+}
+
+func newTestGasScheduleResult(scenario string, gasUsed ...uint64) *GasScheduleScenarioResult {
+	result := &GasScheduleScenarioResult{
+		Scenario: scenario,
+		Errors:   make([]error, len(gasUsed)),
+		Profiles: make([]*GasProfile, len(gasUsed)),
+	}
+	for scheduleIndex, gas := range gasUsed {
+		result.Profiles[scheduleIndex] = &GasProfile{Steps: []*GasProfileStep{newTestGasProfileStep(0, gas)}}
+	}
+	return result
+}
+
+func TestGasScheduleComparison_WriteText(t *testing.T) {
+	unchanged := newTestGasScheduleResult("dir/unchanged.scen.json", 100, 100)
+	changed := newTestGasScheduleResult("dir/changed.scen.json", 200, 150)
+	changed.Profiles[1].Steps = append(changed.Profiles[1].Steps, newTestGasProfileStep(1, 10))
+	notRun := newTestGasScheduleResult("dir/notRun.scen.json", 100, 0)
+	notRun.Profiles[1] = nil
+
+	comparison := &GasScheduleComparison{
+		GasSchedules: []mj.GasSchedule{mj.GasScheduleV3, mj.GasScheduleV4},
+		Scenarios:    []*GasScheduleScenarioResult{unchanged, changed, notRun},
+	}
+	require.Equal(t, 0, comparison.NrFailed(0))
+	require.Equal(t, 1, comparison.NrFailed(1))
+	require.True(t, comparison.HasFailures())
+
+	output := &bytes.Buffer{}
+	comparison.WriteText(output)
+	text := output.String()
+	require.Contains(t, text, "  v3         passed: 3. failed: 0.\n")
+	require.Contains(t, text, "  v4         passed: 2. failed: 1.\n")
+	require.Contains(t, text, "    FAIL dir/notRun.scen.json: did not run\n")
+	require.Contains(t, text, "unchanged.scen.json\n")
+	require.Contains(t, text, "  0      tx                                         100             100     +0.00%\n")
+	require.Contains(t, text, "  0      tx                                         200             150    -25.00%\n")
+	require.Contains(t, text, "  1      tx                                           -              10          -\n")
+	require.Contains(t, text, "  0      tx                                         100               -          -\n")
+
+	comparison.Scenarios = []*GasScheduleScenarioResult{unchanged}
+	require.False(t, comparison.HasFailures())
+}
@@ -0,0 +1,121 @@
+package scenarioexec
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
+)
+
+// GetLastInternalErrors yields the internal VM error chain of the last transaction executed by the VM.
+func (ae *VMTestExecutor) GetLastInternalErrors() []error {
+	return ae.lastInternalErrors
+}
+
+// collectInternalErrors saves the error chain accumulated by the runtime, together with
+// the error returned by the VM itself, if any (e.g. timeouts and panics).
+func (ae *VMTestExecutor) collectInternalErrors(executionErr error) {
+	ae.lastInternalErrors = make([]error, 0)
+
+	runtimeErrors := ae.vmHost.Runtime().GetAllErrors()
+	wrappableErr, isWrappable := runtimeErrors.(vmhost.WrappableError)
+	if isWrappable {
+		ae.lastInternalErrors = append(ae.lastInternalErrors, wrappableErr.GetAllErrors()...)
+	} else if runtimeErrors != nil {
+		ae.lastInternalErrors = append(ae.lastInternalErrors, runtimeErrors)
+	}
+
+	if executionErr != nil {
+		ae.lastInternalErrors = append(ae.lastInternalErrors, executionErr)
+	}
+}
+
+// lastInternalErrorCategory classifies the last internal error chain.
+// The most specific cause wins, regardless of its position in the chain.
+func (ae *VMTestExecutor) lastInternalErrorCategory() string {
+	return internalErrorCategory(ae.lastInternalErrors)
+}
+
+func internalErrorCategory(errs []error) string {
+	if len(errs) == 0 {
+		return mj.InternalErrorNone
+	}
+
+	categories := []struct {
+		target   error
+		category string
+	}{
+		{vmhost.ErrExecutionFailedWithTimeout, mj.InternalErrorTimeout},
+		{vmhost.ErrMaxInstancesReached, mj.InternalErrorMaxInstances},
+		{vmhost.ErrMemoryLimit, mj.InternalErrorMemoryLimit},
+		{vmhost.ErrNotEnoughGas, mj.InternalErrorOutOfGas},
+		{vmhost.ErrExecutionPanicked, mj.InternalErrorPanic},
+		{vmhost.ErrSignalError, mj.InternalErrorSignalError},
+	}
+	for _, c := range categories {
+		for _, err := range errs {
+			if errors.Is(err, c.target) {
+				return c.category
+			}
+		}
+	}
+
+	return mj.InternalErrorExecutionFailed
+}
+
+func (ae *VMTestExecutor) internalErrorsPretty() string {
+	messages := make([]string, 0, len(ae.lastInternalErrors))
+	for _, err := range ae.lastInternalErrors {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, " <- ")
+}
+
+// isExpectedVMFailure yields true for the errors returned by the VM when execution
+// could not finish normally, which we want to check as regular failed transactions.
+func isExpectedVMFailure(err error) bool {
+	return errors.Is(err, vmhost.ErrExecutionFailedWithTimeout) ||
+		errors.Is(err, vmhost.ErrExecutionPanicked)
+}
+
+// vmAbortCategory yields the internal error category of an execution aborted by the VM.
+func vmAbortCategory(err error) string {
+	if errors.Is(err, vmhost.ErrExecutionFailedWithTimeout) {
+		return mj.InternalErrorTimeout
+	}
+	return mj.InternalErrorPanic
+}
+
+// checkVMAbort fails a transaction aborted by the VM with a timeout or a panic,
+// unless the expected result names that internal error category explicitly.
+// Neither "*" nor a status check is enough, since both also hold for regular failures.
+func (ae *VMTestExecutor) checkVMAbort(txIndex string, blResult *mj.TransactionResult) error {
+	if ae.lastVMAbort == nil {
+		return nil
+	}
+
+	category := vmAbortCategory(ae.lastVMAbort)
+	if blResult != nil && blResult.InternalError.Category == category {
+		return nil
+	}
+	return fmt.Errorf("tx '%s' aborted by the VM (%s): %w. Expect \"internalError\": \"%s\" if this is intended",
+		txIndex, category, ae.lastVMAbort, category)
+}
+
+func vmFailureResult(err error) *vmcommon.VMOutput {
+	return &vmcommon.VMOutput{
+		ReturnData:      make([][]byte, 0),
+		ReturnCode:      vmcommon.ExecutionFailed,
+		ReturnMessage:   err.Error(),
+		GasRemaining:    0,
+		GasRefund:       big.NewInt(0),
+		OutputAccounts:  make(map[string]*vmcommon.OutputAccount),
+		DeletedAccounts: make([][]byte, 0),
+		TouchedAccounts: make([][]byte, 0),
+		Logs:            make([]*vmcommon.LogEntry, 0),
+	}
+}
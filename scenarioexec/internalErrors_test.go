@@ -0,0 +1,89 @@
+package scenarioexec
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+	contextmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/context"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
+	"github.com/stretchr/testify/require"
+)
+
+func expectInternalError(category string) *mj.TransactionResult {
+	return &mj.TransactionResult{InternalError: mj.JSONCheckInternalError{Category: category}}
+}
+
+func newTestExecutorWithVM(t *testing.T) *VMTestExecutor {
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	runtime := &contextmock.RuntimeContextMock{}
+	ae.vmHost = &contextmock.VMHostStub{
+		RuntimeCalled: func() vmhost.RuntimeContext {
+			return runtime
+		},
+	}
+	return ae
+}
+
+func TestHandleVMExecutionResult_AbortsAreKeptApart(t *testing.T) {
+	ae := newTestExecutorWithVM(t)
+
+	testCases := []struct {
+		name     string
+		vmErr    error
+		category string
+	}{
+		{name: "timeout", vmErr: vmhost.ErrExecutionFailedWithTimeout, category: mj.InternalErrorTimeout},
+		{name: "wrapped timeout", vmErr: fmt.Errorf("call: %w", vmhost.ErrExecutionFailedWithTimeout), category: mj.InternalErrorTimeout},
+		{name: "panic", vmErr: vmhost.ErrExecutionPanicked, category: mj.InternalErrorPanic},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ae.lastVMAbort = nil
+			output, err := ae.handleVMExecutionResult(nil, testCase.vmErr)
+			require.Nil(t, err)
+			require.Equal(t, vmcommon.ExecutionFailed, output.ReturnCode)
+			require.Equal(t, testCase.vmErr.Error(), output.ReturnMessage)
+			require.Equal(t, testCase.category, ae.lastInternalErrorCategory())
+
+			// neither a missing expectation, nor "*", nor another category allows an abort
+			err = ae.checkVMAbort("tx", nil)
+			require.True(t, errors.Is(err, testCase.vmErr))
+			require.Contains(t, err.Error(), "aborted by the VM ("+testCase.category+")")
+			require.NotNil(t, ae.checkVMAbort("tx", &mj.TransactionResult{InternalError: mj.JSONCheckInternalErrorUnspecified()}))
+			require.NotNil(t, ae.checkVMAbort("tx", &mj.TransactionResult{InternalError: mj.JSONCheckInternalError{IsStar: true}}))
+			require.NotNil(t, ae.checkVMAbort("tx", expectInternalError(mj.InternalErrorExecutionFailed)))
+
+			require.Nil(t, ae.checkVMAbort("tx", expectInternalError(testCase.category)))
+		})
+	}
+}
+
+func TestHandleVMExecutionResult_OtherErrors(t *testing.T) {
+	ae := newTestExecutorWithVM(t)
+
+	unexpectedErr := errors.New("unexpected")
+	output, err := ae.handleVMExecutionResult(nil, unexpectedErr)
+	require.Nil(t, output)
+	require.Equal(t, unexpectedErr, err)
+	require.Nil(t, ae.lastVMAbort)
+
+	vmOutput := &vmcommon.VMOutput{ReturnCode: vmcommon.UserError}
+	output, err = ae.handleVMExecutionResult(vmOutput, nil)
+	require.Nil(t, err)
+	require.Equal(t, vmOutput, output)
+	require.Nil(t, ae.checkVMAbort("tx", nil))
+}
+
+func TestInternalErrorCategory(t *testing.T) {
+	require.Equal(t, mj.InternalErrorNone, internalErrorCategory(nil))
+	require.Equal(t, mj.InternalErrorExecutionFailed, internalErrorCategory([]error{errors.New("other")}))
+	require.Equal(t, mj.InternalErrorSignalError, internalErrorCategory([]error{vmhost.ErrSignalError}))
+	// the most specific cause wins
+	require.Equal(t, mj.InternalErrorOutOfGas, internalErrorCategory([]error{vmhost.ErrSignalError, vmhost.ErrNotEnoughGas}))
+	require.Equal(t, mj.InternalErrorTimeout, internalErrorCategory([]error{vmhost.ErrNotEnoughGas, vmhost.ErrExecutionFailedWithTimeout}))
+}
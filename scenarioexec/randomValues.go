@@ -0,0 +1,41 @@
+package scenarioexec
+
+import (
+	"bytes"
+	"fmt"
+
+	er "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/expression/reconstructor"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+)
+
+// recordRandomValue is registered with the VM, which calls it with every value a contract draws
+// from its pseudo-randomness generator (e.g. via mBufferSetRandom) during the current tx.
+func (ae *VMTestExecutor) recordRandomValue(randomBytes []byte) {
+	ae.drawnRandomValues = append(ae.drawnRandomValues, randomBytes)
+}
+
+// checkRandomValues verifies that the random values contracts drew during the tx were the expected ones, in order.
+func (ae *VMTestExecutor) checkRandomValues(txIndex string, expectedValues mj.JSONValueList) error {
+	if expectedValues.IsUnspecified() {
+		return nil
+	}
+
+	if len(ae.drawnRandomValues) != len(expectedValues.Values) {
+		return fmt.Errorf("random value count mismatch. Tx '%s'. Want: %d. Have: %d",
+			txIndex,
+			len(expectedValues.Values),
+			len(ae.drawnRandomValues))
+	}
+
+	for i, expected := range expectedValues.Values {
+		if !bytes.Equal(expected.Value, ae.drawnRandomValues[i]) {
+			return fmt.Errorf("random value mismatch. Tx '%s'. Random value #%d. Want: %s. Have: %s",
+				txIndex,
+				i,
+				expected.Original,
+				ae.exprReconstructor.Reconstruct(ae.drawnRandomValues[i], er.NoHint))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package scenarioexec
+
+import (
+	"testing"
+
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/config"
+	contextmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/context"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost/contexts"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const randomTestSetState = `{
+	"step": "setState",
+	"accounts": {
+		"address:owner": { "nonce": "0", "balance": "0" },
+		"sc:lottery": { "nonce": "0", "balance": "0", "code": "str:lottery code" }
+	}
+}`
+
+func randomTestCall(function string, expectedRandomValues string) string {
+	return `{
+		"step": "scCall",
+		"txId": "` + function + `",
+		"tx": {
+			"from": "address:owner",
+			"to": "sc:lottery",
+			"function": "` + function + `",
+			"arguments": [],
+			"gasLimit": "1000",
+			"gasPrice": "0"
+		},
+		"expect": {
+			"status": "0",
+			"gas": "*",
+			"refund": "*"` + expectedRandomValues + `
+		}
+	}`
+}
+
+// the VM is replaced by a stub whose "draw" endpoint draws 2 random values,
+// from a real managed types context, with the executor observing it
+func newRandomTestExecutor(t *testing.T) (*VMTestExecutor, *[][]byte) {
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	gasCost, err := config.CreateGasConfig(config.MakeGasMapForTests())
+	require.Nil(t, err)
+
+	drawn := make([][]byte, 0)
+	var managedTypes vmhost.ManagedTypesContext
+	host := &contextmock.VMHostStub{
+		RunSmartContractCallCalled: func(input *vmcommon.ContractCallInput) (*vmcommon.VMOutput, error) {
+			if input.Function == "draw" {
+				for _, length := range []int{4, 2} {
+					value := make([]byte, length)
+					_, _ = managedTypes.GetRandReader().Read(value)
+					drawn = append(drawn, value)
+				}
+			}
+			return &vmcommon.VMOutput{ReturnCode: vmcommon.Ok}, nil
+		},
+		BlockchainCalled: func() vmhost.BlockchainContext {
+			return &mock.BlockchainContextMock{}
+		},
+		RuntimeCalled: func() vmhost.RuntimeContext {
+			return &contextmock.RuntimeContextMock{}
+		},
+		MeteringCalled: func() vmhost.MeteringContext {
+			return &contextmock.MeteringContextMock{GasCost: gasCost}
+		},
+	}
+	managedTypes, err = contexts.NewManagedTypesContext(host)
+	require.Nil(t, err)
+	managedTypes.SetRandomnessObserver(ae.recordRandomValue)
+	ae.vm = host
+	ae.vmHost = host
+
+	require.Nil(t, executeTestSteps(t, ae, randomTestSetState))
+	return ae, &drawn
+}
+
+func expectedRandomValues(values ...[]byte) mj.JSONValueList {
+	list := mj.JSONValueList{}
+	for _, value := range values {
+		list.Values = append(list.Values, mj.JSONBytesFromString{Value: value, Original: "0x.."})
+	}
+	return list
+}
+
+func TestCheckRandomValues_ValuesDrawnDuringTheTx(t *testing.T) {
+	ae, drawn := newRandomTestExecutor(t)
+
+	require.Nil(t, executeTestSteps(t, ae, randomTestCall("draw", "")))
+	require.Len(t, *drawn, 2)
+	require.Equal(t, *drawn, ae.drawnRandomValues)
+
+	first, second := (*drawn)[0], (*drawn)[1]
+	require.Nil(t, ae.checkRandomValues("draw", mj.JSONValueList{}))
+	require.Nil(t, ae.checkRandomValues("draw", expectedRandomValues(first, second)))
+
+	err := ae.checkRandomValues("draw", expectedRandomValues(second, first))
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "random value mismatch. Tx 'draw'. Random value #0")
+
+	err = ae.checkRandomValues("draw", expectedRandomValues(first))
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "random value count mismatch. Tx 'draw'. Want: 1. Have: 2")
+
+	// each tx only sees its own draws
+	require.Nil(t, executeTestSteps(t, ae, randomTestCall("noDraw", "")))
+	require.Nil(t, ae.drawnRandomValues)
+}
+
+func TestCheckRandomValues_CheckedWithTheTxResult(t *testing.T) {
+	ae, _ := newRandomTestExecutor(t)
+
+	err := executeTestSteps(t, ae, randomTestCall("noDraw", `,
+			"randomValues": ["0x01020304"]`))
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "random value count mismatch. Tx 'noDraw'. Want: 1. Have: 0")
+
+	err = executeTestSteps(t, ae, randomTestCall("draw", `,
+			"randomValues": ["0x01020304", "0x0506"]`))
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "random value mismatch. Tx 'draw'. Random value #0. Want: 0x01020304")
+}
@@ -23,9 +23,33 @@ func (ae *VMTestExecutor) ExecuteCheckStateStep(step *mj.CheckStateStep) error {
 	}
 
 	baseErrMsg := checkStateBaseErrorMsg(step)
+	if checksRootHashes(step) {
+		// the root hashes can be computed at any point, the tries are built from the current accounts
+		ae.World.EnableStateTrie()
+	}
+
+	if !step.StateRootHash.Check(ae.World.StateRootHash) {
+		return fmt.Errorf("%s bad state root hash. Want: %s. Have: \"%s\"",
+			baseErrMsg,
+			oj.JSONString(step.StateRootHash.Original),
+			ae.exprReconstructor.Reconstruct(ae.World.StateRootHash, er.NoHint))
+	}
+
 	return ae.checkAccounts(baseErrMsg, step.CheckAccounts)
 }
 
+func checksRootHashes(step *mj.CheckStateStep) bool {
+	if !step.StateRootHash.IsUnspecified() {
+		return true
+	}
+	for _, checkAccount := range step.CheckAccounts.Accounts {
+		if !checkAccount.RootHash.IsUnspecified() {
+			return true
+		}
+	}
+	return false
+}
+
 func checkStateBaseErrorMsg(step *mj.CheckStateStep) string {
 	if len(step.CheckStateIdent) > 0 {
 		return fmt.Sprintf("Check state \"%s\":", step.CheckStateIdent)
@@ -35,9 +59,12 @@ func checkStateBaseErrorMsg(step *mj.CheckStateStep) string {
 
 func (ae *VMTestExecutor) checkAccounts(baseErrMsg string, checkAccounts *mj.CheckAccounts) error {
 	if !checkAccounts.MoreAccountsAllowed {
+		ae.World.LoadAllAccounts()
 		for worldAcctAddr := range ae.World.AcctMap {
 			postAcctMatch := mj.FindCheckAccount(checkAccounts.Accounts, []byte(worldAcctAddr))
-			if postAcctMatch == nil && !bytes.Equal([]byte(worldAcctAddr), vmcommon.SystemAccountAddress) {
+			isImplicitAccount := bytes.Equal([]byte(worldAcctAddr), vmcommon.SystemAccountAddress) ||
+				ae.World.IsSystemSCMockAddress([]byte(worldAcctAddr))
+			if postAcctMatch == nil && !isImplicitAccount {
 				return fmt.Errorf("%s unexpected account address: %s",
 					baseErrMsg,
 					ae.exprReconstructor.Reconstruct(
@@ -48,8 +75,8 @@ func (ae *VMTestExecutor) checkAccounts(baseErrMsg string, checkAccounts *mj.Che
 	}
 
 	for _, expectedAcct := range checkAccounts.Accounts {
-		matchingAcct, isMatch := ae.World.AcctMap[string(expectedAcct.Address.Value)]
-		if !isMatch {
+		matchingAcct := ae.World.GetAccount(expectedAcct.Address.Value)
+		if matchingAcct == nil {
 			return fmt.Errorf("%s account %s expected but not found after running test",
 				baseErrMsg,
 				expectedAcct.Address.Original)
@@ -109,6 +136,16 @@ func (ae *VMTestExecutor) checkAccounts(baseErrMsg string, checkAccounts *mj.Che
 					er.AddressHint))
 		}
 
+		if !expectedAcct.RootHash.IsUnspecified() && !expectedAcct.RootHash.Check(matchingAcct.RootHash) {
+			return fmt.Errorf("%s bad account root hash. Account: %s. Want: %s. Have: \"%s\"",
+				baseErrMsg,
+				expectedAcct.Address.Original,
+				oj.JSONString(expectedAcct.RootHash.Original),
+				ae.exprReconstructor.Reconstruct(
+					matchingAcct.RootHash,
+					er.NoHint))
+		}
+
 		// currently ignoring asyncCallData that is unspecified in the json
 		if !expectedAcct.AsyncCallData.IsUnspecified() &&
 			!expectedAcct.AsyncCallData.Check([]byte(matchingAcct.AsyncCallData)) {
@@ -147,6 +184,7 @@ func (ae *VMTestExecutor) checkAccountStorage(baseErrMsg string, expectedAcct *m
 	for k := range expectedStorage {
 		allKeys[k] = true
 	}
+	matchingAcct.LoadStorage()
 	for k := range matchingAcct.Storage {
 		allKeys[k] = true
 	}
@@ -173,9 +211,9 @@ func (ae *VMTestExecutor) checkAccountStorage(baseErrMsg string, expectedAcct *m
 		if !want.Check(have) {
 			storageError += fmt.Sprintf(
 				"\n  for key %s: Want: %s. Have: \"%s\"",
-				ae.exprReconstructor.Reconstruct([]byte(k), er.NoHint),
+				ae.exprReconstructor.Reconstruct([]byte(k), er.InferHint),
 				oj.JSONString(want.Original),
-				ae.exprReconstructor.Reconstruct(have, er.NoHint))
+				ae.exprReconstructor.Reconstruct(have, er.InferHint))
 		}
 	}
 	if len(storageError) > 0 {
@@ -192,10 +230,12 @@ func (ae *VMTestExecutor) checkAccountESDT(baseErrMsg string, expectedAcct *mj.C
 	}
 
 	systemAccStorage := make(map[string][]byte)
-	systemAcc, exists := ae.World.AcctMap[string(vmcommon.SystemAccountAddress)]
-	if exists {
+	systemAcc := ae.World.GetAccount(vmcommon.SystemAccountAddress)
+	if systemAcc != nil {
+		systemAcc.LoadStorage()
 		systemAccStorage = systemAcc.Storage
 	}
+	matchingAcct.LoadStorage()
 
 	accountAddress := expectedAcct.Address.Original
 	expectedTokens := getExpectedTokens(expectedAcct)
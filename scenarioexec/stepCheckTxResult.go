@@ -19,6 +19,11 @@ func (ae *VMTestExecutor) checkTxResults(
 ) error {
 
 	if !blResult.Status.Check(big.NewInt(int64(output.ReturnCode))) {
+		if len(ae.lastInternalErrors) > 0 {
+			return fmt.Errorf("result code mismatch. Tx '%s'. Want: %s. Have: %d (%s). Message: %s. Internal errors: %s",
+				txIndex, blResult.Status.Original, int(output.ReturnCode), output.ReturnCode.String(), output.ReturnMessage,
+				ae.internalErrorsPretty())
+		}
 		return fmt.Errorf("result code mismatch. Tx '%s'. Want: %s. Have: %d (%s). Message: %s",
 			txIndex, blResult.Status.Original, int(output.ReturnCode), output.ReturnCode.String(), output.ReturnMessage)
 	}
@@ -28,12 +33,17 @@ func (ae *VMTestExecutor) checkTxResults(
 			txIndex, blResult.Message.Original, output.ReturnMessage)
 	}
 
+	if !blResult.InternalError.Check(ae.lastInternalErrorCategory()) {
+		return fmt.Errorf("internal error mismatch. Tx '%s'. Want: %s. Have: %s. Internal errors: %s",
+			txIndex, blResult.InternalError.String(), ae.lastInternalErrorCategory(), ae.internalErrorsPretty())
+	}
+
 	// check result
 	if !blResult.Out.CheckList(output.ReturnData) {
 		return fmt.Errorf("result mismatch. Tx '%s'. Want: %s. Have: %s",
 			txIndex,
 			checkBytesListPretty(blResult.Out),
-			ae.exprReconstructor.ReconstructList(output.ReturnData, er.NoHint))
+			ae.exprReconstructor.ReconstructList(output.ReturnData, er.InferHint))
 	}
 
 	// check refund
@@ -52,6 +62,11 @@ func (ae *VMTestExecutor) checkTxResults(
 			output.GasRemaining)
 	}
 
+	err := ae.checkRandomValues(txIndex, blResult.RandomValues)
+	if err != nil {
+		return err
+	}
+
 	return ae.checkTxLogs(txIndex, blResult.Logs, output.Logs)
 }
 
@@ -116,7 +131,7 @@ func (ae *VMTestExecutor) checkTxLog(
 			txIndex,
 			logIndex,
 			checkBytesListPretty(expectedLog.Topics),
-			ae.exprReconstructor.ReconstructList(actualLog.Topics, er.NoHint))
+			ae.exprReconstructor.ReconstructList(actualLog.Topics, er.InferHint))
 	}
 	if !expectedLog.Data.Check(actualLog.Data) {
 		return fmt.Errorf("bad log data. Tx '%s'. Log index: %d. Want:\n%s\nGot:\n%s",
@@ -1,7 +1,11 @@
 package scenarioexec
 
 import (
+	"encoding/hex"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -18,6 +22,7 @@ import (
 const includeProtectedStorage = false
 
 func (ae *VMTestExecutor) convertMockAccountToScenarioFormat(account *worldmock.Account) (*mj.Account, error) {
+	account.LoadStorage()
 	var storageKeys []string
 	for storageKey := range account.Storage {
 		storageKeys = append(storageKeys, storageKey)
@@ -32,19 +37,20 @@ func (ae *VMTestExecutor) convertMockAccountToScenarioFormat(account *worldmock.
 			storageKvps = append(storageKvps, &mj.StorageKeyValuePair{
 				Key: mj.JSONBytesFromString{
 					Value:    []byte(storageKey),
-					Original: ae.exprReconstructor.Reconstruct([]byte(storageKey), er.NoHint),
+					Original: ae.exprReconstructor.Reconstruct([]byte(storageKey), er.InferHint),
 				},
 				Value: mj.JSONBytesFromTree{
 					Value:    storageValue,
-					Original: &oj.OJsonString{Value: ae.exprReconstructor.Reconstruct(storageValue, er.NoHint)},
+					Original: &oj.OJsonString{Value: ae.exprReconstructor.Reconstruct(storageValue, er.InferHint)},
 				},
 			})
 		}
 	}
 
 	systemAccStorage := make(map[string][]byte)
-	systemAcc, exists := ae.World.AcctMap[string(vmcommon.SystemAccountAddress)]
-	if exists {
+	systemAcc := ae.World.GetAccount(vmcommon.SystemAccountAddress)
+	if systemAcc != nil {
+		systemAcc.LoadStorage()
 		systemAccStorage = systemAcc.Storage
 	}
 	tokenData, err := esdtconvert.GetFullMockESDTData(account.Storage, systemAccStorage)
@@ -87,7 +93,7 @@ func (ae *VMTestExecutor) convertMockAccountToScenarioFormat(account *worldmock.
 			if len(mockInstance.TokenMetaData.Hash) > 0 {
 				hash = mj.JSONBytesFromString{
 					Value:    mockInstance.TokenMetaData.Hash,
-					Original: ae.exprReconstructor.Reconstruct(mockInstance.TokenMetaData.Hash, er.NoHint),
+					Original: ae.exprReconstructor.Reconstruct(mockInstance.TokenMetaData.Hash, er.InferHint),
 				}
 			}
 
@@ -95,7 +101,7 @@ func (ae *VMTestExecutor) convertMockAccountToScenarioFormat(account *worldmock.
 			for _, uri := range mockInstance.TokenMetaData.URIs {
 				jsonUris = append(jsonUris, mj.JSONBytesFromString{
 					Value:    uri,
-					Original: ae.exprReconstructor.Reconstruct(uri, er.StrHint),
+					Original: ae.exprReconstructor.Reconstruct(uri, er.InferHint),
 				})
 			}
 
@@ -103,7 +109,7 @@ func (ae *VMTestExecutor) convertMockAccountToScenarioFormat(account *worldmock.
 			if len(mockInstance.TokenMetaData.Attributes) > 0 {
 				attributes = mj.JSONBytesFromString{
 					Value:    mockInstance.TokenMetaData.Attributes,
-					Original: ae.exprReconstructor.Reconstruct(mockInstance.TokenMetaData.Attributes, er.NoHint),
+					Original: ae.exprReconstructor.Reconstruct(mockInstance.TokenMetaData.Attributes, er.InferHint),
 				}
 			}
 
@@ -163,6 +169,7 @@ func (ae *VMTestExecutor) convertMockAccountToScenarioFormat(account *worldmock.
 // DumpWorld prints the state of the MockWorld to stdout.
 func (ae *VMTestExecutor) DumpWorld() error {
 	fmt.Print("world state dump:\n")
+	ae.World.LoadAllAccounts()
 	var scenAccounts []*mj.Account
 
 	for _, account := range ae.World.AcctMap {
@@ -179,3 +186,268 @@ func (ae *VMTestExecutor) DumpWorld() error {
 
 	return nil
 }
+
+// WorldStateJSON yields the accounts of the MockWorld as JSON, sorted by address, e.g. to compare two runs.
+// The system account and the system SC mock accounts are left out, since the executor provides them.
+func (ae *VMTestExecutor) WorldStateJSON() (string, error) {
+	var scenAccounts []*mj.Account
+	for _, address := range ae.explicitAccountAddresses() {
+		scenAccount, err := ae.convertMockAccountToScenarioFormat(ae.World.AcctMap[address])
+		if err != nil {
+			return "", err
+		}
+		scenAccounts = append(scenAccounts, scenAccount)
+	}
+
+	return oj.JSONString(mjwrite.AccountsToOJ(scenAccounts)), nil
+}
+
+// explicitAccountAddresses yields the sorted addresses of all accounts,
+// except the system account and the system SC mock accounts.
+func (ae *VMTestExecutor) explicitAccountAddresses() []string {
+	ae.World.LoadAllAccounts()
+	var addresses []string
+	for address := range ae.World.AcctMap {
+		isImplicitAccount := address == string(vmcommon.SystemAccountAddress) ||
+			ae.World.IsSystemSCMockAddress([]byte(address))
+		if !isImplicitAccount {
+			addresses = append(addresses, address)
+		}
+	}
+	sort.Strings(addresses)
+	return addresses
+}
+
+// ExecuteDumpStateStep prints the state of the MockWorld to stdout or,
+// if the step specifies a path, saves it there as a reusable steps file.
+func (ae *VMTestExecutor) ExecuteDumpStateStep(step *mj.DumpStateStep) error {
+	if len(step.Path) == 0 {
+		return ae.DumpWorld()
+	}
+
+	outputPath := ae.fileResolver.ResolveAbsolutePath(step.Path)
+	return ae.WriteWorldStateScenario(outputPath, step.CheckState)
+}
+
+// WriteWorldStateScenario saves the state of the MockWorld as a reusable steps file, at the given path.
+// Optionally, the file also contains a checkState step that expects exactly the same accounts.
+func (ae *VMTestExecutor) WriteWorldStateScenario(outputPath string, includeCheckState bool) error {
+	scenario, err := ae.worldStateToScenario(filepath.Dir(outputPath), includeCheckState)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(outputPath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(outputPath, []byte(mjwrite.ScenarioToJSONString(scenario)), 0644)
+}
+
+// worldStateToScenario yields a scenario that recreates the current accounts and block info with a setState step.
+// Optionally, it also contains a checkState step that expects exactly the same accounts.
+// The system account and the system SC mock accounts are left out, since the executor provides them.
+func (ae *VMTestExecutor) worldStateToScenario(outputDir string, includeCheckState bool) (*mj.Scenario, error) {
+	addresses := ae.explicitAccountAddresses()
+	setStateStep := &mj.SetStateStep{
+		Comment:           "world state dump",
+		PreviousBlockInfo: ae.convertBlockInfoToScenarioFormat(ae.World.PreviousBlockInfo),
+		CurrentBlockInfo:  ae.convertBlockInfoToScenarioFormat(ae.World.CurrentBlockInfo),
+	}
+	for _, address := range addresses {
+		account := ae.World.AcctMap[address]
+		scenAccount, err := ae.convertMockAccountToScenarioFormat(account)
+		if err != nil {
+			return nil, err
+		}
+		ae.addFullAccountFieldsToScenarioFormat(scenAccount, account, outputDir)
+		setStateStep.Accounts = append(setStateStep.Accounts, scenAccount)
+	}
+	for _, blockHash := range ae.World.Blockhashes {
+		setStateStep.BlockHashes.Values = append(setStateStep.BlockHashes.Values, mj.JSONBytesFromString{
+			Value:    blockHash,
+			Original: ae.exprReconstructor.Reconstruct(blockHash, er.InferHint),
+		})
+	}
+
+	scenario := &mj.Scenario{
+		Name:     "world state dump",
+		CheckGas: true,
+		Steps:    []mj.Step{setStateStep},
+	}
+	if includeCheckState {
+		checkAccounts := &mj.CheckAccounts{}
+		for _, scenAccount := range setStateStep.Accounts {
+			checkAccounts.Accounts = append(checkAccounts.Accounts, accountToCheckAccount(scenAccount))
+		}
+		scenario.Steps = append(scenario.Steps, &mj.CheckStateStep{
+			Comment:       "world state dump",
+			StateRootHash: mj.JSONCheckBytesUnspecified(),
+			CheckAccounts: checkAccounts,
+		})
+	}
+
+	return scenario, nil
+}
+
+// addFullAccountFieldsToScenarioFormat adds the fields that the console dump omits, but that a setState needs.
+func (ae *VMTestExecutor) addFullAccountFieldsToScenarioFormat(scenAccount *mj.Account, account *worldmock.Account, outputDir string) {
+	if account.ShardID != 0 {
+		scenAccount.Shard = mj.JSONUint64{
+			Value:    uint64(account.ShardID),
+			Original: ae.exprReconstructor.ReconstructFromUint64(uint64(account.ShardID)),
+		}
+	}
+	if len(account.Username) > 0 {
+		scenAccount.Username = mj.JSONBytesFromString{
+			Value:    account.Username,
+			Original: ae.exprReconstructor.Reconstruct(account.Username, er.StrHint),
+		}
+	}
+	if len(account.Code) > 0 {
+		scenAccount.Code = mj.JSONBytesFromString{
+			Value:    account.Code,
+			Original: ae.codeToScenarioFormat(account.Code, outputDir),
+		}
+	}
+	if account.DeveloperReward != nil && account.DeveloperReward.Sign() > 0 {
+		scenAccount.DeveloperReward = mj.JSONBigInt{
+			Value:    account.DeveloperReward,
+			Original: ae.exprReconstructor.ReconstructFromBigInt(account.DeveloperReward),
+		}
+	}
+	scenAccount.AsyncCallData = account.AsyncCallData
+}
+
+// codeToScenarioFormat references the file the code was originally loaded from, relative to the output directory.
+// Code that did not come from a file is written in full, as hex.
+func (ae *VMTestExecutor) codeToScenarioFormat(code []byte, outputDir string) string {
+	codeHash := worldmock.DefaultHasher.Compute(string(code))
+	codePath, found := ae.codeFilePaths[string(codeHash)]
+	if found {
+		relativePath, err := filepath.Rel(outputDir, codePath)
+		if err == nil {
+			return "file:" + filepath.ToSlash(relativePath)
+		}
+	}
+
+	return "0x" + hex.EncodeToString(code)
+}
+
+// recordCodeFilePath remembers which file a contract code was loaded from, for the state dumps.
+func (ae *VMTestExecutor) recordCodeFilePath(code mj.JSONBytesFromString) {
+	if len(code.Value) == 0 || !strings.HasPrefix(code.Original, "file:") || ae.fileResolver == nil {
+		return
+	}
+
+	codeHash := worldmock.DefaultHasher.Compute(string(code.Value))
+	codePath := ae.fileResolver.ResolveAbsolutePath(strings.TrimPrefix(code.Original, "file:"))
+	ae.codeFilePaths[string(codeHash)] = codePath
+}
+
+func (ae *VMTestExecutor) convertBlockInfoToScenarioFormat(blockInfo *worldmock.BlockInfo) *mj.BlockInfo {
+	if blockInfo == nil {
+		return nil
+	}
+
+	scenBlockInfo := &mj.BlockInfo{
+		BlockTimestamp: mj.JSONUint64{
+			Value:    blockInfo.BlockTimestamp,
+			Original: ae.exprReconstructor.ReconstructFromUint64(blockInfo.BlockTimestamp),
+		},
+		BlockNonce: mj.JSONUint64{
+			Value:    blockInfo.BlockNonce,
+			Original: ae.exprReconstructor.ReconstructFromUint64(blockInfo.BlockNonce),
+		},
+		BlockRound: mj.JSONUint64{
+			Value:    blockInfo.BlockRound,
+			Original: ae.exprReconstructor.ReconstructFromUint64(blockInfo.BlockRound),
+		},
+		BlockEpoch: mj.JSONUint64{
+			Value:    uint64(blockInfo.BlockEpoch),
+			Original: ae.exprReconstructor.ReconstructFromUint64(uint64(blockInfo.BlockEpoch)),
+		},
+	}
+	if blockInfo.RandomSeed != nil {
+		randomSeed := blockInfo.RandomSeed[:]
+		scenBlockInfo.BlockRandomSeed = &mj.JSONBytesFromTree{
+			Value:    randomSeed,
+			Original: &oj.OJsonString{Value: "0x" + hex.EncodeToString(randomSeed)},
+		}
+	}
+
+	return scenBlockInfo
+}
+
+// accountToCheckAccount yields checks that expect exactly the values of a scenario account.
+func accountToCheckAccount(account *mj.Account) *mj.CheckAccount {
+	checkAccount := &mj.CheckAccount{
+		Address:         account.Address,
+		Nonce:           mj.JSONCheckUint64{Value: account.Nonce.Value, Original: account.Nonce.Original},
+		Balance:         mj.JSONCheckBigInt{Value: account.Balance.Value, Original: account.Balance.Original},
+		Username:        checkBytesFromScenarioValue(account.Username),
+		ExplicitStorage: true,
+		Code:            checkBytesFromScenarioValue(account.Code),
+		Owner:           checkBytesFromScenarioValue(account.Owner),
+		RootHash:        mj.JSONCheckBytesUnspecified(),
+		AsyncCallData:   mj.JSONCheckBytesUnspecified(),
+		DeveloperReward: mj.JSONCheckBigIntUnspecified(),
+	}
+	if len(account.AsyncCallData) > 0 {
+		checkAccount.AsyncCallData = mj.JSONCheckBytesReconstructed([]byte(account.AsyncCallData), account.AsyncCallData)
+	}
+	if len(account.DeveloperReward.Original) > 0 {
+		checkAccount.DeveloperReward = mj.JSONCheckBigInt{
+			Value:    account.DeveloperReward.Value,
+			Original: account.DeveloperReward.Original,
+		}
+	}
+
+	for _, storageKvp := range account.Storage {
+		checkAccount.CheckStorage = append(checkAccount.CheckStorage, &mj.CheckStorageKeyValuePair{
+			Key: storageKvp.Key,
+			CheckValue: mj.JSONCheckBytes{
+				Value:    storageKvp.Value.Value,
+				Original: storageKvp.Value.Original,
+			},
+		})
+	}
+
+	for _, esdtData := range account.ESDTData {
+		checkESDTData := &mj.CheckESDTData{
+			TokenIdentifier: esdtData.TokenIdentifier,
+			LastNonce:       mj.JSONCheckUint64{Value: esdtData.LastNonce.Value, Original: esdtData.LastNonce.Original},
+			Roles:           esdtData.Roles,
+			Frozen:          mj.JSONCheckUint64Unspecified(),
+		}
+		for _, instance := range esdtData.Instances {
+			checkInstance := mj.NewCheckESDTInstance()
+			checkInstance.Nonce = instance.Nonce
+			checkInstance.Balance = mj.JSONCheckBigInt{Value: instance.Balance.Value, Original: instance.Balance.Original}
+			checkInstance.Creator = checkBytesFromScenarioValue(instance.Creator)
+			if len(instance.Royalties.Original) > 0 {
+				checkInstance.Royalties = mj.JSONCheckUint64{Value: instance.Royalties.Value, Original: instance.Royalties.Original}
+			}
+			checkInstance.Hash = checkBytesFromScenarioValue(instance.Hash)
+			checkInstance.Attributes = checkBytesFromScenarioValue(instance.Attributes)
+			if len(instance.Uris.Values) > 0 {
+				checkInstance.Uris = mj.JSONCheckValueList{}
+				for _, uri := range instance.Uris.Values {
+					checkInstance.Uris.Values = append(checkInstance.Uris.Values, checkBytesFromScenarioValue(uri))
+				}
+			}
+			checkESDTData.Instances = append(checkESDTData.Instances, checkInstance)
+		}
+		checkAccount.CheckESDTData = append(checkAccount.CheckESDTData, checkESDTData)
+	}
+
+	return checkAccount
+}
+
+func checkBytesFromScenarioValue(value mj.JSONBytesFromString) mj.JSONCheckBytes {
+	if len(value.Original) == 0 {
+		return mj.JSONCheckBytesUnspecified()
+	}
+	return mj.JSONCheckBytesReconstructed(value.Value, value.Original)
+}
@@ -0,0 +1,119 @@
+package scenarioexec
+
+import (
+	"strings"
+	"testing"
+
+	mjparse "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/parse"
+	mjwrite "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/write"
+	"github.com/stretchr/testify/require"
+)
+
+// values that are neither strings nor numbers, which the dump cannot show as such,
+// and strings containing '|', where the interpreter would split them
+const dumpTestSetState = `{
+	"step": "setState",
+	"accounts": {
+		"address:owner": {
+			"nonce": "5",
+			"balance": "1000",
+			"username": "str:owner.elrond",
+			"storage": {
+				"0x00ff01": "0x0001ff",
+				"str:key": "str:value",
+				"0x0000000000000000000000": "0x00",
+				"str:number": "1234567890",
+				"0x706970657c6b6579": "0x617c62",
+				"0x7c7c": "0x706970657c76616c7565"
+			},
+			"esdt": {
+				"str:NFT-123456": {
+					"instances": [
+						{
+							"nonce": "1",
+							"balance": "1",
+							"creator": "address:owner",
+							"royalties": "1000",
+							"hash": "0x00ff10fe",
+							"uri": ["str:https://uri", "0x00ff0102", "0x68747470733a2f2f617c62"],
+							"attributes": "0xff0001"
+						}
+					],
+					"lastNonce": "1",
+					"roles": ["ESDTRoleNFTCreate"]
+				},
+				"str:FUNG-abcdef": "500"
+			}
+		},
+		"sc:contract": {
+			"nonce": "0",
+			"balance": "0",
+			"code": "0x0061736d01000000",
+			"owner": "address:owner",
+			"storage": {
+				"0x00": "0x0000"
+			}
+		}
+	},
+	"currentBlockInfo": {
+		"blockNonce": "3",
+		"blockRandomSeed": "0x00ff01010101010101010101010101010101010101010101010101010101010101010101010101010101010101010101"
+	},
+	"blockHashes": ["0x00ff", "str:hash", "0xfe", "0x686173687c31", "0x686173687c626c6f636b7c31"]
+}`
+
+func TestWorldStateDump_RoundTrip(t *testing.T) {
+	original, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	require.Nil(t, executeTestSteps(t, original, dumpTestSetState))
+
+	scenario, err := original.worldStateToScenario(t.TempDir(), true)
+	require.Nil(t, err)
+	dump := mjwrite.ScenarioToJSONString(scenario)
+	require.NotContains(t, dump, " (str:")
+	require.Contains(t, dump, `"0x00ff0102"`)
+	require.NotContains(t, dump, "|")
+	require.Contains(t, dump, `"0x706970657c76616c7565"`)
+	require.Contains(t, dump, `"0x68747470733a2f2f617c62"`)
+	require.Contains(t, dump, `"0x686173687c626c6f636b7c31"`)
+
+	p := mjparse.Parser{}
+	parsed, err := p.ParseScenarioFile([]byte(dump))
+	require.Nil(t, err)
+
+	// the dump ends with a checkState that expects the accounts it sets
+	reloaded, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	for _, step := range parsed.Steps {
+		require.Nil(t, reloaded.ExecuteStep(step))
+	}
+
+	originalJSON, err := original.WorldStateJSON()
+	require.Nil(t, err)
+	reloadedJSON, err := reloaded.WorldStateJSON()
+	require.Nil(t, err)
+	require.Equal(t, originalJSON, reloadedJSON)
+
+	for _, address := range original.explicitAccountAddresses() {
+		requireSameDumpedAccount(t, original, reloaded, address)
+	}
+	require.Equal(t, original.World.Blockhashes, reloaded.World.Blockhashes)
+	require.Equal(t, original.World.CurrentBlockInfo, reloaded.World.CurrentBlockInfo)
+
+	// dumping the reloaded world yields the same file
+	reloadedScenario, err := reloaded.worldStateToScenario(t.TempDir(), true)
+	require.Nil(t, err)
+	require.Equal(t, dump, mjwrite.ScenarioToJSONString(reloadedScenario))
+}
+
+func requireSameDumpedAccount(t *testing.T, original *VMTestExecutor, reloaded *VMTestExecutor, address string) {
+	originalAccount := original.World.AcctMap[address]
+	reloadedAccount := reloaded.World.AcctMap[address]
+	require.NotNil(t, reloadedAccount, "missing account %s", strings.TrimRight(address, "_"))
+	require.Equal(t, originalAccount.Nonce, reloadedAccount.Nonce)
+	require.Equal(t, originalAccount.Balance, reloadedAccount.Balance)
+	require.Equal(t, originalAccount.Username, reloadedAccount.Username)
+	require.Equal(t, originalAccount.Code, reloadedAccount.Code)
+	require.Equal(t, originalAccount.OwnerAddress, reloadedAccount.OwnerAddress)
+	require.Equal(t, originalAccount.Storage, reloadedAccount.Storage)
+}
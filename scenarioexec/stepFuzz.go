@@ -0,0 +1,84 @@
+package scenarioexec
+
+import (
+	"fmt"
+	"math/rand"
+
+	roulette "github.com/multiversx/mx-chain-vm-v1_4-go/fuzz/weightedroulette"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+)
+
+// ExecuteFuzzStep runs the configured number of random transactions, generated from the weighted templates.
+// The invariants are checked after each transaction.
+// The same seed always produces the same transactions, so failures can be reproduced.
+func (ae *VMTestExecutor) ExecuteFuzzStep(step *mj.FuzzStep) error {
+	if len(step.Comment) > 0 {
+		log.Trace("FuzzStep", "comment", step.Comment)
+	}
+
+	r := rand.New(rand.NewSource(int64(step.Seed.Value)))
+	for iteration := uint64(0); iteration < step.Count.Value; iteration++ {
+		txIdent := fmt.Sprintf("%s#%d", fuzzStepIdent(step), iteration)
+
+		var err error
+		outcomes := make([]roulette.Outcome, 0, len(step.Templates))
+		for _, template := range step.Templates {
+			template := template
+			outcomes = append(outcomes, roulette.Outcome{
+				Weight: int(template.Weight.Value),
+				Event: func() {
+					_, err = ae.ExecuteTxStep(generateFuzzTxStep(r, txIdent, template))
+				},
+			})
+		}
+		roulette.RandomChoice(r, outcomes...)
+		if err != nil {
+			return fmt.Errorf("fuzz step failed, seed %d: %w", step.Seed.Value, err)
+		}
+
+		for _, invariant := range step.Invariants {
+			baseErrMsg := fmt.Sprintf("Fuzz invariant after tx \"%s\", seed %d, %s",
+				txIdent, step.Seed.Value, checkStateBaseErrorMsg(invariant))
+			err = ae.checkAccounts(baseErrMsg, invariant.CheckAccounts)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func fuzzStepIdent(step *mj.FuzzStep) string {
+	if len(step.FuzzIdent) > 0 {
+		return step.FuzzIdent
+	}
+	return "fuzz"
+}
+
+// generateFuzzTxStep copies the template, replacing the fields that have candidates with random choices.
+func generateFuzzTxStep(r *rand.Rand, txIdent string, template *mj.FuzzTemplate) *mj.TxStep {
+	tx := *template.Step.Tx
+	tx.Arguments = make([]mj.JSONBytesFromTree, len(template.Step.Tx.Arguments))
+	copy(tx.Arguments, template.Step.Tx.Arguments)
+
+	if len(template.FromCandidates) > 0 {
+		tx.From = template.FromCandidates[r.Intn(len(template.FromCandidates))]
+	}
+	if len(template.EGLDValueCandidates) > 0 {
+		tx.EGLDValue = template.EGLDValueCandidates[r.Intn(len(template.EGLDValueCandidates))]
+	}
+	for argIndex, argCandidates := range template.ArgumentCandidates {
+		if len(argCandidates) > 0 {
+			tx.Arguments[argIndex] = argCandidates[r.Intn(len(argCandidates))]
+		}
+	}
+
+	return &mj.TxStep{
+		TxIdent:        txIdent,
+		Comment:        template.Step.Comment,
+		DisplayLogs:    template.Step.DisplayLogs,
+		Tx:             &tx,
+		ExpectedResult: template.Step.ExpectedResult,
+	}
+}
@@ -0,0 +1,133 @@
+package scenarioexec
+
+import (
+	"fmt"
+	"math/big"
+	"testing"
+
+	mjparse "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/parse"
+	"github.com/stretchr/testify/require"
+)
+
+const fuzzTestSetState = `{
+	"step": "setState",
+	"accounts": {
+		"address:A": { "nonce": "0", "balance": "1000" },
+		"address:B": { "nonce": "0", "balance": "1000" }
+	}
+}`
+
+var fuzzTestAddressA = []byte("A_______________________________")
+var fuzzTestAddressB = []byte("B_______________________________")
+
+func executeTestSteps(t *testing.T, ae *VMTestExecutor, stepsJSON ...string) error {
+	p := mjparse.Parser{}
+	for _, stepJSON := range stepsJSON {
+		step, err := p.ParseScenarioStep(stepJSON)
+		require.Nil(t, err)
+		err = ae.ExecuteStep(step)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func fuzzTestStep(seed int, count int, invariantBalance string) string {
+	return fmt.Sprintf(`{
+		"step": "fuzz",
+		"id": "transfers",
+		"seed": "%d",
+		"count": "%d",
+		"templates": [
+			{
+				"weight": "3",
+				"step": {
+					"step": "transfer",
+					"tx": { "from": "address:A", "to": "address:B", "egldValue": "0" }
+				},
+				"candidates": {
+					"egldValue": ["1", "2", "3"]
+				}
+			},
+			{
+				"weight": "1",
+				"step": {
+					"step": "transfer",
+					"tx": { "from": "address:B", "to": "address:A", "egldValue": "5" }
+				}
+			}
+		],
+		"invariants": [
+			{
+				"accounts": {
+					"address:A": { "nonce": "*", "balance": "%s" },
+					"+": ""
+				}
+			}
+		]
+	}`, seed, count, invariantBalance)
+}
+
+func runFuzzTest(t *testing.T, seed int, count int, invariantBalance string) (*VMTestExecutor, error) {
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	err = executeTestSteps(t, ae, fuzzTestSetState, fuzzTestStep(seed, count, invariantBalance))
+	return ae, err
+}
+
+func fuzzTestBalances(ae *VMTestExecutor) (*big.Int, *big.Int) {
+	accountA := ae.World.AcctMap.GetAccount(fuzzTestAddressA)
+	accountB := ae.World.AcctMap.GetAccount(fuzzTestAddressB)
+	return accountA.Balance, accountB.Balance
+}
+
+func TestExecuteFuzzStep_SameSeedSameTransactions(t *testing.T) {
+	first, err := runFuzzTest(t, 7, 30, "*")
+	require.Nil(t, err)
+	second, err := runFuzzTest(t, 7, 30, "*")
+	require.Nil(t, err)
+
+	balanceA, balanceB := fuzzTestBalances(first)
+	require.NotEqual(t, big.NewInt(1000), balanceA)
+	require.Equal(t, big.NewInt(2000), big.NewInt(0).Add(balanceA, balanceB))
+	require.Equal(t, first.World.AcctMap.Clone(), second.World.AcctMap.Clone())
+
+	// every transaction is executed, whichever template it comes from
+	nonceA := first.World.AcctMap.GetAccount(fuzzTestAddressA).Nonce
+	nonceB := first.World.AcctMap.GetAccount(fuzzTestAddressB).Nonce
+	require.Equal(t, uint64(30), nonceA+nonceB)
+	require.NotZero(t, nonceA)
+	require.NotZero(t, nonceB)
+}
+
+func TestExecuteFuzzStep_InvariantChecked(t *testing.T) {
+	_, err := runFuzzTest(t, 7, 30, "1000")
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), `Fuzz invariant after tx "transfers#0", seed 7`)
+
+	_, err = runFuzzTest(t, 7, 0, "1000")
+	require.Nil(t, err)
+}
+
+func TestExecuteFuzzStep_FailedTransaction(t *testing.T) {
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	err = executeTestSteps(t, ae, fuzzTestSetState, `{
+		"step": "fuzz",
+		"seed": "3",
+		"count": "5",
+		"templates": [
+			{
+				"weight": "1",
+				"step": {
+					"step": "transfer",
+					"tx": { "from": "address:missing", "to": "address:B", "egldValue": "1" }
+				}
+			}
+		]
+	}`)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "fuzz step failed, seed 3")
+	require.Contains(t, err.Error(), "could not set up tx fuzz#0")
+}
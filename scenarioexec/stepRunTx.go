@@ -13,6 +13,7 @@ import (
 )
 
 func (ae *VMTestExecutor) executeTx(txIndex string, tx *mj.Transaction) (*vmcommon.VMOutput, error) {
+	ae.World.ConfirmCurrentEpoch()
 	ae.World.CreateStateBackup()
 
 	var err error
@@ -30,6 +31,9 @@ func (ae *VMTestExecutor) executeTx(txIndex string, tx *mj.Transaction) (*vmcomm
 		}
 	}()
 
+	ae.lastInternalErrors = nil
+	ae.lastVMAbort = nil
+	ae.drawnRandomValues = nil
 	gasForExecution := uint64(0)
 
 	if tx.Type.HasSender() {
@@ -62,10 +66,13 @@ func (ae *VMTestExecutor) executeTx(txIndex string, tx *mj.Transaction) (*vmcomm
 	} else {
 		switch tx.Type {
 		case mj.ScDeploy:
-			output, err = ae.scCreate(txIndex, tx, gasForExecution)
+			var vmErr error
+			output, vmErr = ae.scCreate(txIndex, tx, gasForExecution)
+			output, err = ae.handleVMExecutionResult(output, vmErr)
 			if err != nil {
 				return nil, err
 			}
+			ae.recordVMHookCoverage()
 			if ae.PeekTraceGas() {
 				fmt.Println("\nIn txID:", txIndex, ", step type:Deploy", ", total gas used:", gasForExecution-output.GasRemaining)
 			}
@@ -78,10 +85,13 @@ func (ae *VMTestExecutor) executeTx(txIndex string, tx *mj.Transaction) (*vmcomm
 			gasForExecution = math.MaxUint64
 			fallthrough
 		case mj.ScCall:
-			output, err = ae.scCall(txIndex, tx, gasForExecution)
+			var vmErr error
+			output, vmErr = ae.scCall(txIndex, tx, gasForExecution)
+			output, err = ae.handleVMExecutionResult(output, vmErr)
 			if err != nil {
 				return nil, err
 			}
+			ae.recordVMHookCoverage()
 			if ae.PeekTraceGas() {
 				fmt.Println("\nIn txID:", txIndex, ", step type:ScCall, function:", tx.Function, ", total gas used:", gasForExecution-output.GasRemaining)
 			}
@@ -111,11 +121,31 @@ func (ae *VMTestExecutor) executeTx(txIndex string, tx *mj.Transaction) (*vmcomm
 	return output, nil
 }
 
+// handleVMExecutionResult records the internal error chain of a VM execution.
+// Timeouts and panics are turned into failed transactions, so that the step expectations can be checked,
+// but they are also kept apart, to fail the step unless it expects them (see checkVMAbort).
+func (ae *VMTestExecutor) handleVMExecutionResult(output *vmcommon.VMOutput, vmErr error) (*vmcommon.VMOutput, error) {
+	if ae.vmHost == nil {
+		return output, vmErr
+	}
+
+	ae.collectInternalErrors(vmErr)
+	if vmErr == nil {
+		return output, nil
+	}
+	if !isExpectedVMFailure(vmErr) {
+		return nil, vmErr
+	}
+
+	ae.lastVMAbort = vmErr
+	return vmFailureResult(vmErr), nil
+}
+
 func (ae *VMTestExecutor) senderHasEnoughBalance(tx *mj.Transaction) bool {
 	if !tx.Type.HasSender() {
 		return true
 	}
-	sender := ae.World.AcctMap.GetAccount(tx.From.Value)
+	sender := ae.World.GetAccount(tx.From.Value)
 	return sender.Balance.Cmp(tx.EGLDValue.Value) >= 0
 }
 
@@ -141,7 +171,7 @@ func (ae *VMTestExecutor) simpleTransferOutput(tx *mj.Transaction) *vmcommon.VMO
 
 func (ae *VMTestExecutor) validatorRewardOutput(tx *mj.Transaction) (*vmcommon.VMOutput, error) {
 	reward := tx.EGLDValue.Value
-	recipient := ae.World.AcctMap.GetAccount(tx.To.Value)
+	recipient := ae.World.GetAccount(tx.To.Value)
 	if recipient == nil {
 		return nil, fmt.Errorf("tx recipient (address: %s) does not exist", hex.EncodeToString(tx.To.Value))
 	}
@@ -203,6 +233,7 @@ func (ae *VMTestExecutor) scCreate(txIndex string, tx *mj.Transaction, gasLimit
 		ESDTTransfers:  make([]*vmcommon.ESDTTransfer, 0),
 	}
 	addESDTToVMInput(tx.ESDTValue, &vmInput)
+	ae.recordCodeFilePath(tx.Code)
 	input := &vmcommon.ContractCreateInput{
 		ContractCode: tx.Code.Value,
 		VMInput:      vmInput,
@@ -212,7 +243,7 @@ func (ae *VMTestExecutor) scCreate(txIndex string, tx *mj.Transaction, gasLimit
 }
 
 func (ae *VMTestExecutor) scCall(txIndex string, tx *mj.Transaction, gasLimit uint64) (*vmcommon.VMOutput, error) {
-	recipient := ae.World.AcctMap.GetAccount(tx.To.Value)
+	recipient := ae.World.GetAccount(tx.To.Value)
 	if recipient == nil {
 		return nil, fmt.Errorf("tx recipient (address: %s) does not exist", hex.EncodeToString(tx.To.Value))
 	}
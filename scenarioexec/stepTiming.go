@@ -0,0 +1,222 @@
+package scenarioexec
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/wasmer"
+)
+
+// StepTiming is the time it took to run one scenario step.
+// Steps from external steps files are attributed to the top-level scenario.
+type StepTiming struct {
+	Scenario         string
+	StepIndex        int
+	StepType         string
+	StepID           string
+	Total            time.Duration
+	InstanceCreation time.Duration
+}
+
+// Execution is the time spent in the step outside instance creation and compilation.
+func (timing *StepTiming) Execution() time.Duration {
+	return timing.Total - timing.InstanceCreation
+}
+
+// ScenarioTiming is the time it took to run one top-level scenario file.
+type ScenarioTiming struct {
+	Scenario string
+	NrSteps  int
+	Total    time.Duration
+}
+
+// TimingReport holds the timings of all steps and scenarios run by the executor.
+type TimingReport struct {
+	Steps     []*StepTiming
+	Scenarios []*ScenarioTiming
+}
+
+type stepTimer struct {
+	report           *TimingReport
+	currentScenario  *ScenarioTiming
+	scenarioStart    time.Time
+	instanceCreation time.Duration
+}
+
+// EnableStepTiming makes the executor time every step, separating the instance creation
+// and compilation time from the rest, where the VM creates a new instance.
+func (ae *VMTestExecutor) EnableStepTiming() {
+	if ae.stepTimer != nil {
+		return
+	}
+
+	ae.stepTimer = &stepTimer{
+		report: &TimingReport{},
+	}
+	if ae.vmHost != nil {
+		ae.installInstanceBuilder()
+	}
+}
+
+// GetTimingReport yields the step timings gathered so far, or nil if step timing is not enabled.
+func (ae *VMTestExecutor) GetTimingReport() *TimingReport {
+	if ae.stepTimer == nil {
+		return nil
+	}
+
+	return ae.stepTimer.report
+}
+
+func (ae *VMTestExecutor) beginScenarioTiming(scenFilePath string) {
+	if ae.stepTimer == nil {
+		return
+	}
+
+	ae.stepTimer.currentScenario = &ScenarioTiming{
+		Scenario: scenFilePath,
+	}
+	ae.stepTimer.scenarioStart = time.Now()
+}
+
+func (ae *VMTestExecutor) endScenarioTiming() {
+	if ae.stepTimer == nil || ae.stepTimer.currentScenario == nil {
+		return
+	}
+
+	scenarioTiming := ae.stepTimer.currentScenario
+	scenarioTiming.Total = time.Since(ae.stepTimer.scenarioStart)
+	ae.stepTimer.report.Scenarios = append(ae.stepTimer.report.Scenarios, scenarioTiming)
+	ae.stepTimer.currentScenario = nil
+}
+
+func (ae *VMTestExecutor) beginStepTiming() time.Time {
+	if ae.stepTimer != nil {
+		ae.stepTimer.instanceCreation = 0
+	}
+	return time.Now()
+}
+
+func (ae *VMTestExecutor) endStepTiming(generalStep mj.Step, start time.Time) {
+	if ae.stepTimer == nil {
+		return
+	}
+
+	stepTiming := &StepTiming{
+		StepType:         generalStep.StepTypeName(),
+		StepID:           stepIdent(generalStep),
+		Total:            time.Since(start),
+		InstanceCreation: ae.stepTimer.instanceCreation,
+	}
+	if ae.stepTimer.currentScenario != nil {
+		stepTiming.Scenario = ae.stepTimer.currentScenario.Scenario
+		stepTiming.StepIndex = ae.stepTimer.currentScenario.NrSteps
+		ae.stepTimer.currentScenario.NrSteps++
+	}
+	ae.stepTimer.report.Steps = append(ae.stepTimer.report.Steps, stepTiming)
+}
+
+func stepIdent(generalStep mj.Step) string {
+	switch step := generalStep.(type) {
+	case *mj.SetStateStep:
+		return step.SetStateIdent
+	case *mj.CheckStateStep:
+		return step.CheckStateIdent
+	case *mj.FuzzStep:
+		return step.FuzzIdent
+	case *mj.TxStep:
+		return step.TxIdent
+	case *mj.SaveStateStep:
+		return step.StateName
+	case *mj.RestoreStateStep:
+		return step.StateName
+	default:
+		return ""
+	}
+}
+
+// WriteText prints the topN slowest steps and the topN slowest scenarios.
+func (report *TimingReport) WriteText(w io.Writer, topN int) {
+	steps := make([]*StepTiming, len(report.Steps))
+	copy(steps, report.Steps)
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].Total > steps[j].Total
+	})
+	if len(steps) > topN {
+		steps = steps[:topN]
+	}
+
+	_, _ = fmt.Fprintf(w, "Slowest %d steps:\n", len(steps))
+	_, _ = fmt.Fprintf(w, "  %12s %12s %12s  %s\n", "total", "instances", "execution", "step")
+	for _, step := range steps {
+		_, _ = fmt.Fprintf(w, "  %12s %12s %12s  %s step %d (%s %s)\n",
+			formatDuration(step.Total),
+			formatDuration(step.InstanceCreation),
+			formatDuration(step.Execution()),
+			step.Scenario,
+			step.StepIndex,
+			step.StepType,
+			step.StepID)
+	}
+
+	scenarios := make([]*ScenarioTiming, len(report.Scenarios))
+	copy(scenarios, report.Scenarios)
+	sort.SliceStable(scenarios, func(i, j int) bool {
+		return scenarios[i].Total > scenarios[j].Total
+	})
+	if len(scenarios) > topN {
+		scenarios = scenarios[:topN]
+	}
+
+	_, _ = fmt.Fprintf(w, "Slowest %d scenarios:\n", len(scenarios))
+	_, _ = fmt.Fprintf(w, "  %12s %8s  %s\n", "total", "steps", "scenario")
+	for _, scenario := range scenarios {
+		_, _ = fmt.Fprintf(w, "  %12s %8d  %s\n",
+			formatDuration(scenario.Total),
+			scenario.NrSteps,
+			scenario.Scenario)
+	}
+}
+
+func formatDuration(duration time.Duration) string {
+	return duration.Round(time.Microsecond).String()
+}
+
+// timingInstanceBuilder wraps another instance builder and adds the time
+// spent creating instances, including compilation, to the current step.
+type timingInstanceBuilder struct {
+	innerBuilder vmhost.InstanceBuilder
+	timer        *stepTimer
+}
+
+// NewInstanceWithOptions creates a new instance from WASM bytecode, measuring the time it takes.
+func (builder *timingInstanceBuilder) NewInstanceWithOptions(
+	contractCode []byte,
+	options wasmer.CompilationOptions,
+) (wasmer.InstanceHandler, error) {
+	start := time.Now()
+	defer builder.addInstanceCreationTime(start)
+
+	return builder.innerBuilder.NewInstanceWithOptions(contractCode, options)
+}
+
+// NewInstanceFromCompiledCodeWithOptions creates a new instance from precompiled machine code,
+// measuring the time it takes.
+func (builder *timingInstanceBuilder) NewInstanceFromCompiledCodeWithOptions(
+	compiledCode []byte,
+	options wasmer.CompilationOptions,
+) (wasmer.InstanceHandler, error) {
+	start := time.Now()
+	defer builder.addInstanceCreationTime(start)
+
+	return builder.innerBuilder.NewInstanceFromCompiledCodeWithOptions(compiledCode, options)
+}
+
+func (builder *timingInstanceBuilder) addInstanceCreationTime(start time.Time) {
+	builder.timer.instanceCreation += time.Since(start)
+}
+
+var _ vmhost.InstanceBuilder = (*timingInstanceBuilder)(nil)
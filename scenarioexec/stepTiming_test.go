@@ -0,0 +1,170 @@
+package scenarioexec
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/wasmer"
+	"github.com/stretchr/testify/require"
+)
+
+const timingTestSetState = `{
+	"step": "setState",
+	"id": "setup",
+	"accounts": {
+		"address:A": { "nonce": "0", "balance": "1000" }
+	}
+}`
+
+const timingTestCheckState = `{
+	"step": "checkState",
+	"id": "check",
+	"accounts": {
+		"address:A": { "nonce": "0", "balance": "1000" }
+	}
+}`
+
+// slowInstanceBuilder takes a fixed time to create each instance
+type slowInstanceBuilder struct {
+	delay time.Duration
+}
+
+func (builder *slowInstanceBuilder) NewInstanceWithOptions(
+	_ []byte,
+	_ wasmer.CompilationOptions,
+) (wasmer.InstanceHandler, error) {
+	time.Sleep(builder.delay)
+	return nil, nil
+}
+
+func (builder *slowInstanceBuilder) NewInstanceFromCompiledCodeWithOptions(
+	_ []byte,
+	_ wasmer.CompilationOptions,
+) (wasmer.InstanceHandler, error) {
+	time.Sleep(builder.delay)
+	return nil, nil
+}
+
+func TestStepTiming_NotEnabled(t *testing.T) {
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+
+	ae.BeginScenarioFile("test.scen.json")
+	require.Nil(t, executeTestSteps(t, ae, timingTestSetState))
+	ae.EndScenarioFile("test.scen.json", nil)
+	require.Nil(t, ae.GetTimingReport())
+}
+
+func TestStepTiming_StepsAttributedToScenarios(t *testing.T) {
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	ae.EnableStepTiming()
+	report := ae.GetTimingReport()
+	ae.EnableStepTiming()
+	require.True(t, report == ae.GetTimingReport())
+
+	// steps outside any scenario file are timed, but not attributed
+	require.Nil(t, executeTestSteps(t, ae, timingTestSetState))
+
+	ae.BeginScenarioFile("first.scen.json")
+	require.Nil(t, executeTestSteps(t, ae, timingTestSetState, timingTestCheckState, `{ "step": "saveState", "name": "saved" }`))
+	ae.EndScenarioFile("first.scen.json", nil)
+
+	ae.BeginScenarioFile("second.scen.json")
+	require.NotNil(t, executeTestSteps(t, ae, `{ "step": "restoreState", "name": "missing" }`))
+	ae.EndScenarioFile("second.scen.json", nil)
+
+	expectedSteps := []struct {
+		scenario  string
+		stepIndex int
+		stepType  string
+		stepID    string
+	}{
+		{"", 0, mj.StepNameSetState, "setup"},
+		{"first.scen.json", 0, mj.StepNameSetState, "setup"},
+		{"first.scen.json", 1, mj.StepNameCheckState, "check"},
+		{"first.scen.json", 2, mj.StepNameSaveState, "saved"},
+		{"second.scen.json", 0, mj.StepNameRestoreState, "missing"},
+	}
+	require.Len(t, report.Steps, len(expectedSteps))
+	for i, expected := range expectedSteps {
+		step := report.Steps[i]
+		require.Equal(t, expected.scenario, step.Scenario)
+		require.Equal(t, expected.stepIndex, step.StepIndex)
+		require.Equal(t, expected.stepType, step.StepType)
+		require.Equal(t, expected.stepID, step.StepID)
+		require.Zero(t, step.InstanceCreation)
+		require.Equal(t, step.Total, step.Execution())
+	}
+
+	require.Len(t, report.Scenarios, 2)
+	require.Equal(t, "first.scen.json", report.Scenarios[0].Scenario)
+	require.Equal(t, 3, report.Scenarios[0].NrSteps)
+	require.Equal(t, "second.scen.json", report.Scenarios[1].Scenario)
+	require.Equal(t, 1, report.Scenarios[1].NrSteps)
+
+	// ending a scenario twice does not record it twice
+	ae.EndScenarioFile("second.scen.json", nil)
+	require.Len(t, report.Scenarios, 2)
+}
+
+func TestStepTiming_InstanceCreation(t *testing.T) {
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	ae.EnableStepTiming()
+
+	builder := &timingInstanceBuilder{
+		innerBuilder: &slowInstanceBuilder{delay: 5 * time.Millisecond},
+		timer:        ae.stepTimer,
+	}
+
+	start := ae.beginStepTiming()
+	_, err = builder.NewInstanceWithOptions(nil, wasmer.CompilationOptions{})
+	require.Nil(t, err)
+	_, err = builder.NewInstanceFromCompiledCodeWithOptions(nil, wasmer.CompilationOptions{})
+	require.Nil(t, err)
+	ae.endStepTiming(&mj.SetStateStep{}, start)
+
+	// the next step starts from zero
+	start = ae.beginStepTiming()
+	ae.endStepTiming(&mj.SetStateStep{}, start)
+
+	steps := ae.GetTimingReport().Steps
+	require.Len(t, steps, 2)
+	require.GreaterOrEqual(t, int64(steps[0].InstanceCreation), int64(10*time.Millisecond))
+	require.GreaterOrEqual(t, int64(steps[0].Total), int64(steps[0].InstanceCreation))
+	require.Equal(t, steps[0].Total-steps[0].InstanceCreation, steps[0].Execution())
+	require.Zero(t, steps[1].InstanceCreation)
+}
+
+func TestTimingReport_WriteText(t *testing.T) {
+	report := &TimingReport{
+		Steps: []*StepTiming{
+			{Scenario: "a.scen.json", StepIndex: 0, StepType: "setState", Total: 2 * time.Millisecond},
+			{Scenario: "a.scen.json", StepIndex: 1, StepType: "scCall", StepID: "call", Total: 30 * time.Millisecond, InstanceCreation: 20 * time.Millisecond},
+			{Scenario: "b.scen.json", StepIndex: 0, StepType: "scDeploy", StepID: "deploy", Total: 10*time.Millisecond + 1234*time.Nanosecond},
+		},
+		Scenarios: []*ScenarioTiming{
+			{Scenario: "a.scen.json", NrSteps: 2, Total: 32 * time.Millisecond},
+			{Scenario: "b.scen.json", NrSteps: 1, Total: 11 * time.Millisecond},
+		},
+	}
+
+	output := &bytes.Buffer{}
+	report.WriteText(output, 2)
+	require.Equal(t,
+		"Slowest 2 steps:\n"+
+			"         total    instances    execution  step\n"+
+			"          30ms         20ms         10ms  a.scen.json step 1 (scCall call)\n"+
+			"      10.001ms           0s     10.001ms  b.scen.json step 0 (scDeploy deploy)\n"+
+			"Slowest 2 scenarios:\n"+
+			"         total    steps  scenario\n"+
+			"          32ms        2  a.scen.json\n"+
+			"          11ms        1  b.scen.json\n",
+		output.String())
+
+	// the report itself is not reordered
+	require.Equal(t, "setState", report.Steps[0].StepType)
+}
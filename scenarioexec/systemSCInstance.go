@@ -0,0 +1,320 @@
+package scenarioexec
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/multiversx/mx-chain-core-go/core"
+	"github.com/multiversx/mx-chain-core-go/data/vm"
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
+	worldmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/world"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
+	"github.com/multiversx/mx-chain-vm-v1_4-go/wasmer"
+)
+
+// systemSCInstanceBuilder creates Go-native instances for the accounts backed by system smart contract mocks,
+// and delegates to the inner builder for all other code.
+type systemSCInstanceBuilder struct {
+	innerBuilder vmhost.InstanceBuilder
+	host         vmhost.VMHost
+	world        *worldmock.MockWorld
+}
+
+// NewInstanceWithOptions creates a system SC mock instance, or a Wasmer instance from WASM bytecode.
+func (builder *systemSCInstanceBuilder) NewInstanceWithOptions(
+	contractCode []byte,
+	options wasmer.CompilationOptions,
+) (wasmer.InstanceHandler, error) {
+	scMock := builder.world.GetSystemSCMockByCode(contractCode)
+	if scMock != nil {
+		return newSystemSCInstance(scMock, builder.host), nil
+	}
+
+	return builder.innerBuilder.NewInstanceWithOptions(contractCode, options)
+}
+
+// NewInstanceFromCompiledCodeWithOptions creates a system SC mock instance, or a Wasmer instance from precompiled code.
+// The "compiled" code of a mock instance is its code, unchanged.
+func (builder *systemSCInstanceBuilder) NewInstanceFromCompiledCodeWithOptions(
+	compiledCode []byte,
+	options wasmer.CompilationOptions,
+) (wasmer.InstanceHandler, error) {
+	scMock := builder.world.GetSystemSCMockByCode(compiledCode)
+	if scMock != nil {
+		return newSystemSCInstance(scMock, builder.host), nil
+	}
+
+	return builder.innerBuilder.NewInstanceFromCompiledCodeWithOptions(compiledCode, options)
+}
+
+// systemSCInstance runs the Go functions of a system smart contract mock as if they were contract endpoints.
+type systemSCInstance struct {
+	scMock          *worldmock.SystemSCMock
+	host            vmhost.VMHost
+	exports         wasmer.ExportsMap
+	memory          wasmer.MemoryHandler
+	data            uintptr
+	points          uint64
+	gasLimit        uint64
+	breakpointValue uint64
+	alreadyCleaned  bool
+}
+
+func newSystemSCInstance(scMock *worldmock.SystemSCMock, host vmhost.VMHost) *systemSCInstance {
+	exports := make(wasmer.ExportsMap)
+	for funcName := range scMock.Functions {
+		exports[funcName] = &wasmer.ExportedFunctionCallInfo{}
+	}
+
+	return &systemSCInstance{
+		scMock:  scMock,
+		host:    host,
+		exports: exports,
+		memory:  &systemSCMemory{},
+	}
+}
+
+// CallFunction runs the mock function, in the context of the current call.
+func (instance *systemSCInstance) CallFunction(funcName string) (wasmer.Value, error) {
+	function, found := instance.scMock.Functions[funcName]
+	if !found {
+		return wasmer.Void(), vmhost.ErrFuncNotFound
+	}
+
+	function(&systemSCContext{host: instance.host})
+
+	if vmhost.BreakpointValue(instance.breakpointValue) != vmhost.BreakpointNone {
+		return wasmer.Void(), errors.New(instance.host.Output().ReturnMessage())
+	}
+	return wasmer.Void(), nil
+}
+
+// HasMemory returns true, the mocks do not use it, but the VM expects it.
+func (instance *systemSCInstance) HasMemory() bool {
+	return true
+}
+
+// HasFunction returns true if the mock has a function with the given name.
+func (instance *systemSCInstance) HasFunction(funcName string) bool {
+	_, found := instance.scMock.Functions[funcName]
+	return found
+}
+
+// SetContextData -
+func (instance *systemSCInstance) SetContextData(data uintptr) {
+	instance.data = data
+}
+
+// GetPointsUsed -
+func (instance *systemSCInstance) GetPointsUsed() uint64 {
+	return instance.points
+}
+
+// SetPointsUsed -
+func (instance *systemSCInstance) SetPointsUsed(points uint64) {
+	instance.points = points
+}
+
+// SetGasLimit -
+func (instance *systemSCInstance) SetGasLimit(gasLimit uint64) {
+	instance.gasLimit = gasLimit
+}
+
+// SetBreakpointValue -
+func (instance *systemSCInstance) SetBreakpointValue(value uint64) {
+	instance.breakpointValue = value
+}
+
+// GetBreakpointValue -
+func (instance *systemSCInstance) GetBreakpointValue() uint64 {
+	return instance.breakpointValue
+}
+
+// Cache yields the mock code, which is all that is needed to recreate the instance.
+func (instance *systemSCInstance) Cache() ([]byte, error) {
+	return instance.scMock.Code(), nil
+}
+
+// Clean -
+func (instance *systemSCInstance) Clean() bool {
+	instance.alreadyCleaned = true
+	return true
+}
+
+// AlreadyCleaned -
+func (instance *systemSCInstance) AlreadyCleaned() bool {
+	return instance.alreadyCleaned
+}
+
+// GetExports yields the mock functions.
+func (instance *systemSCInstance) GetExports() wasmer.ExportsMap {
+	return instance.exports
+}
+
+// GetSignature yields an empty signature for the mock functions, just like for regular endpoints.
+func (instance *systemSCInstance) GetSignature(functionName string) (*wasmer.ExportedFunctionSignature, bool) {
+	_, found := instance.exports[functionName]
+	if !found {
+		return nil, false
+	}
+	return &wasmer.ExportedFunctionSignature{}, true
+}
+
+// GetData -
+func (instance *systemSCInstance) GetData() uintptr {
+	return instance.data
+}
+
+// GetInstanceCtxMemory -
+func (instance *systemSCInstance) GetInstanceCtxMemory() wasmer.MemoryHandler {
+	return instance.memory
+}
+
+// GetMemory -
+func (instance *systemSCInstance) GetMemory() wasmer.MemoryHandler {
+	return instance.memory
+}
+
+// SetMemory -
+func (instance *systemSCInstance) SetMemory(_ []byte) bool {
+	return true
+}
+
+// IsFunctionImported -
+func (instance *systemSCInstance) IsFunctionImported(_ string) bool {
+	return false
+}
+
+// Reset -
+func (instance *systemSCInstance) Reset() bool {
+	instance.breakpointValue = 0
+	return true
+}
+
+// ID -
+func (instance *systemSCInstance) ID() string {
+	return fmt.Sprintf("%p", instance)
+}
+
+// IsInterfaceNil -
+func (instance *systemSCInstance) IsInterfaceNil() bool {
+	return instance == nil
+}
+
+// systemSCMemory is an empty memory, the mocks work with Go values directly.
+type systemSCMemory struct{}
+
+// Length -
+func (memory *systemSCMemory) Length() uint32 {
+	return 0
+}
+
+// Data -
+func (memory *systemSCMemory) Data() []byte {
+	return nil
+}
+
+// Grow -
+func (memory *systemSCMemory) Grow(_ uint32) error {
+	return nil
+}
+
+// Destroy -
+func (memory *systemSCMemory) Destroy() {
+}
+
+// IsInterfaceNil -
+func (memory *systemSCMemory) IsInterfaceNil() bool {
+	return memory == nil
+}
+
+// systemSCContext gives the mock functions access to the current call, through the VM host.
+type systemSCContext struct {
+	host vmhost.VMHost
+}
+
+// Input yields the input of the current call.
+func (ctx *systemSCContext) Input() *vmcommon.ContractCallInput {
+	return ctx.host.Runtime().GetVMInput()
+}
+
+// GasLeft yields the gas left for the current call.
+func (ctx *systemSCContext) GasLeft() uint64 {
+	return ctx.host.Metering().GasLeft()
+}
+
+// Finish adds a result to the output.
+func (ctx *systemSCContext) Finish(data []byte) {
+	ctx.host.Output().Finish(data)
+}
+
+// SignalError fails the current call with a user error.
+func (ctx *systemSCContext) SignalError(message string) {
+	ctx.host.Runtime().SignalUserError(message)
+}
+
+// GetStorage reads from the storage of the mock.
+func (ctx *systemSCContext) GetStorage(key []byte) ([]byte, error) {
+	value, _, err := ctx.host.Storage().GetStorage(key)
+	return value, err
+}
+
+// SetStorage writes to the storage of the mock, an empty value clears the key.
+func (ctx *systemSCContext) SetStorage(key []byte, value []byte) error {
+	_, err := ctx.host.Storage().SetStorage(key, value)
+	return err
+}
+
+// TransferValue sends EGLD from the mock to the destination.
+func (ctx *systemSCContext) TransferValue(destination []byte, value *big.Int) error {
+	sender := ctx.host.Runtime().GetContextAddress()
+	return ctx.host.Output().Transfer(destination, sender, 0, 0, value, nil, vm.DirectCall)
+}
+
+// TransferESDT sends fungible tokens from the mock to the destination.
+func (ctx *systemSCContext) TransferESDT(destination []byte, tokenID []byte, value *big.Int) error {
+	sender := ctx.host.Runtime().GetContextAddress()
+	transfer := &vmcommon.ESDTTransfer{
+		ESDTValue:      value,
+		ESDTTokenName:  tokenID,
+		ESDTTokenType:  uint32(core.Fungible),
+		ESDTTokenNonce: 0,
+	}
+	_, err := ctx.host.Output().TransferESDT(destination, sender, []*vmcommon.ESDTTransfer{transfer}, nil)
+	return err
+}
+
+// ExecuteBuiltin calls a builtin function on behalf of the mock, on the destination context,
+// so its changes are reverted if the call fails.
+func (ctx *systemSCContext) ExecuteBuiltin(recipient []byte, function string, args [][]byte, gasLimit uint64) error {
+	runtime := ctx.host.Runtime()
+	input := &vmcommon.ContractCallInput{
+		VMInput: vmcommon.VMInput{
+			CallerAddr:     runtime.GetContextAddress(),
+			Arguments:      args,
+			CallValue:      big.NewInt(0),
+			CallType:       vm.DirectCall,
+			GasPrice:       runtime.GetVMInput().GasPrice,
+			GasProvided:    gasLimit,
+			CurrentTxHash:  runtime.GetCurrentTxHash(),
+			OriginalTxHash: runtime.GetOriginalTxHash(),
+		},
+		RecipientAddr: recipient,
+		Function:      function,
+	}
+
+	vmOutput, _, err := ctx.host.ExecuteOnDestContext(input)
+	if err != nil {
+		return err
+	}
+	if vmOutput.ReturnCode != vmcommon.Ok {
+		return errors.New(vmOutput.ReturnMessage)
+	}
+	return nil
+}
+
+var _ vmhost.InstanceBuilder = (*systemSCInstanceBuilder)(nil)
+var _ wasmer.InstanceHandler = (*systemSCInstance)(nil)
+var _ wasmer.MemoryHandler = (*systemSCMemory)(nil)
+var _ worldmock.SystemSCContext = (*systemSCContext)(nil)
@@ -0,0 +1,184 @@
+package scenarioexec
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/multiversx/mx-chain-core-go/core"
+	"github.com/multiversx/mx-chain-vm-common-go/txDataBuilder"
+	contextmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/context"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	"github.com/stretchr/testify/require"
+)
+
+const systemSCTestSetState = `{
+	"step": "setState",
+	"accounts": {
+		"address:owner": { "nonce": "0", "balance": "0" },
+		"sc:caller": { "nonce": "0", "balance": "0", "code": "str:system sc caller code" }
+	}
+}`
+
+func systemSCTestCall(function string, arguments ...string) string {
+	argumentsJSON := ""
+	for i, argument := range arguments {
+		if i > 0 {
+			argumentsJSON += ", "
+		}
+		argumentsJSON += `"` + argument + `"`
+	}
+	return `{
+		"step": "scCall",
+		"tx": {
+			"from": "address:owner",
+			"to": "sc:caller",
+			"function": "` + function + `",
+			"arguments": [` + argumentsJSON + `],
+			"gasLimit": "100,000,000",
+			"gasPrice": "0"
+		},
+		"expect": { "out": "*", "status": "0", "gas": "*", "refund": "*" }
+	}`
+}
+
+// addSystemSCTestCaller adds the mock of a contract that calls the system SC mocks asynchronously.
+// Its callback keeps the callback arguments and the tokens received in storage.
+func addSystemSCTestCaller(t *testing.T, ae *VMTestExecutor, builder *contextmock.InstanceBuilderMock) {
+	instance := contextmock.NewInstanceMock([]byte("system sc caller code"))
+	instance.T = t
+	instance.Host = ae.vmHost
+
+	asyncCall := func(destination []byte, data []byte) *contextmock.InstanceMock {
+		err := ae.vmHost.Runtime().ExecuteAsyncCall(destination, data, big.NewInt(0).Bytes())
+		require.Nil(t, err)
+		return instance
+	}
+
+	// issue@name@ticker@initialSupply
+	instance.AddMockMethod("issue", func() *contextmock.InstanceMock {
+		args := ae.vmHost.Runtime().Arguments()
+		callData := txDataBuilder.NewBuilder()
+		callData.Func("issue").Bytes(args[0]).Bytes(args[1]).Bytes(args[2]).Int64(0)
+		return asyncCall(core.ESDTSCAddress, callData.ToBytes())
+	})
+	// setRole@tokenID@role
+	instance.AddMockMethod("setRole", func() *contextmock.InstanceMock {
+		args := ae.vmHost.Runtime().Arguments()
+		callData := txDataBuilder.NewBuilder()
+		callData.Func("setSpecialRole").Bytes(args[0]).Bytes(ae.vmHost.Runtime().GetContextAddress()).Bytes(args[1])
+		return asyncCall(core.ESDTSCAddress, callData.ToBytes())
+	})
+	// register@dnsAddress@name
+	instance.AddMockMethod("register", func() *contextmock.InstanceMock {
+		args := ae.vmHost.Runtime().Arguments()
+		callData := txDataBuilder.NewBuilder()
+		callData.Func("register").Bytes(args[1])
+		return asyncCall(args[0], callData.ToBytes())
+	})
+	instance.AddMockMethod("callBack", func() *contextmock.InstanceMock {
+		runtime := ae.vmHost.Runtime()
+		storage := ae.vmHost.Storage()
+		args := runtime.Arguments()
+		_, _ = storage.SetStorage([]byte("callbackReturnCode"), args[0])
+		if len(args) > 1 {
+			_, _ = storage.SetStorage([]byte("callbackResult"), args[1])
+		}
+		esdtTransfers := runtime.GetVMInput().ESDTTransfers
+		if len(esdtTransfers) > 0 {
+			_, _ = storage.SetStorage([]byte("receivedToken"), esdtTransfers[0].ESDTTokenName)
+			_, _ = storage.SetStorage([]byte("receivedAmount"), esdtTransfers[0].ESDTValue.Bytes())
+		}
+		return instance
+	})
+
+	builder.InstanceMap[string(instance.Code)] = *instance
+}
+
+// newSystemSCTestExecutor creates an executor with the system SC mocks enabled, as with -system-sc-mocks,
+// where the contract under test is a Go mock instead of WASM code
+func newSystemSCTestExecutor(t *testing.T) *VMTestExecutor {
+	ae, err := NewVMTestExecutor()
+	require.Nil(t, err)
+	ae.EnableSystemSCMocks()
+	require.Nil(t, ae.InitVM(mj.GasScheduleDummy))
+
+	innerBuilder := contextmock.NewInstanceBuilderMock(ae.World)
+	addSystemSCTestCaller(t, ae, innerBuilder)
+	ae.vmHost.Runtime().ReplaceInstanceBuilder(&systemSCInstanceBuilder{
+		innerBuilder: innerBuilder,
+		host:         ae.vmHost,
+		world:        ae.World,
+	})
+	ae.vmHost.Runtime().ClearWarmInstanceCache()
+	return ae
+}
+
+func TestSystemSCMocks_AsyncCallsFromContract(t *testing.T) {
+	ae := newSystemSCTestExecutor(t)
+	err := executeTestSteps(t, ae,
+		systemSCTestSetState,
+		systemSCTestCall("issue", "str:Test Token", "str:TEST", "1000"),
+	)
+	require.Nil(t, err)
+
+	caller := ae.World.AcctMap.GetAccount([]byte("\x00\x00\x00\x00\x00\x00\x00\x00caller__________________"))
+	require.NotNil(t, caller)
+	tokenID := caller.Storage["callbackResult"]
+	require.Regexp(t, "^TEST-[0-9a-f]{6}$", string(tokenID))
+	require.Equal(t, []byte{0}, caller.Storage["callbackReturnCode"])
+	require.Equal(t, tokenID, caller.Storage["receivedToken"])
+	require.Equal(t, big.NewInt(1000).Bytes(), caller.Storage["receivedAmount"])
+	balance, err := caller.GetTokenBalance(tokenID, 0)
+	require.Nil(t, err)
+	require.Equal(t, big.NewInt(1000), balance)
+
+	err = executeTestSteps(t, ae,
+		systemSCTestCall("setRole", "str:"+string(tokenID), "str:"+core.ESDTRoleLocalMint),
+		`{
+			"step": "checkState",
+			"accounts": {
+				"sc:caller": {
+					"nonce": "*",
+					"balance": "0",
+					"esdt": {
+						"str:`+string(tokenID)+`": {
+							"instances": [{ "nonce": "0", "balance": "1000" }],
+							"roles": ["`+core.ESDTRoleLocalMint+`"]
+						}
+					},
+					"storage": "*",
+					"code": "*"
+				},
+				"+": ""
+			}
+		}`,
+	)
+	require.Nil(t, err)
+	require.Equal(t, []byte{0}, caller.Storage["callbackReturnCode"])
+
+	err = executeTestSteps(t, ae,
+		systemSCTestCall("register", "sc:dns#00", "str:caller.elrond"),
+		`{
+			"step": "checkState",
+			"accounts": {
+				"sc:caller": {
+					"nonce": "*",
+					"balance": "0",
+					"username": "str:caller.elrond",
+					"esdt": "*",
+					"storage": "*",
+					"code": "*"
+				},
+				"+": ""
+			}
+		}`,
+	)
+	require.Nil(t, err)
+	require.Equal(t, []byte{0}, caller.Storage["callbackReturnCode"])
+
+	// the name is taken now, and the callback gets the error
+	err = executeTestSteps(t, ae, systemSCTestCall("register", "sc:dns#00", "str:caller.elrond"))
+	require.Nil(t, err)
+	require.Equal(t, []byte{byte(4)}, caller.Storage["callbackReturnCode"])
+	require.Equal(t, []byte("name already taken"), caller.Storage["callbackResult"])
+}
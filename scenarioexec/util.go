@@ -138,7 +138,7 @@ func (ae *VMTestExecutor) convertLogToTestFormat(outputLog *vmcommon.LogEntry) *
 		topics.Values[i] = mj.JSONCheckBytesReconstructed(
 			topic,
 			ae.exprReconstructor.Reconstruct(topic,
-				er.NoHint))
+				er.InferHint))
 	}
 	testLog := mj.LogEntry{
 		Address: mj.JSONCheckBytesReconstructed(
@@ -206,9 +206,17 @@ func logGasTrace(ae *VMTestExecutor) {
 	}
 }
 
+// keepsGasTrace tells whether the executor itself needs the gas trace, in which case the host
+// keeps gas tracing as set by the executor, regardless of the level of the gasTrace logger.
+func (ae *VMTestExecutor) keepsGasTrace() bool {
+	return ae.isVMHookCoverageActive() || ae.isGasProfileActive()
+}
+
 func setGasTraceInMetering(ae *VMTestExecutor, enable bool) {
-	metering := ae.GetVMHost().Metering()
-	if enable && ae.PeekTraceGas() {
+	host := ae.GetVMHost()
+	host.SetKeepGasTracing(ae.keepsGasTrace())
+	metering := host.Metering()
+	if enable && (ae.PeekTraceGas() || ae.isVMHookCoverageActive() || ae.isGasProfileActive()) {
 		metering.SetGasTracing(true)
 	} else {
 		metering.SetGasTracing(false)
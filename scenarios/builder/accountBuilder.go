@@ -0,0 +1,164 @@
+package scenbuilder
+
+// storageEntry is a storage key and value, as scenario expressions.
+type storageEntry struct {
+	key   string
+	value string
+}
+
+// esdtBalance is a fungible ESDT balance, as scenario expressions.
+type esdtBalance struct {
+	tokenIdentifier string
+	balance         string
+}
+
+// AccountBuilder describes an account for a setState step. All values are scenario expressions,
+// e.g. "address:owner", "str:key" or "1,000".
+type AccountBuilder struct {
+	address      string
+	comment      string
+	nonce        string
+	balance      string
+	username     string
+	storage      []storageEntry
+	code         string
+	owner        string
+	esdtBalances []esdtBalance
+}
+
+// Account starts describing an account for a setState step.
+func Account(address string) *AccountBuilder {
+	return &AccountBuilder{address: address}
+}
+
+// Comment sets the account comment.
+func (ab *AccountBuilder) Comment(comment string) *AccountBuilder {
+	ab.comment = comment
+	return ab
+}
+
+// Nonce sets the account nonce.
+func (ab *AccountBuilder) Nonce(nonce string) *AccountBuilder {
+	ab.nonce = nonce
+	return ab
+}
+
+// Balance sets the EGLD balance.
+func (ab *AccountBuilder) Balance(balance string) *AccountBuilder {
+	ab.balance = balance
+	return ab
+}
+
+// Username sets the account username.
+func (ab *AccountBuilder) Username(username string) *AccountBuilder {
+	ab.username = username
+	return ab
+}
+
+// Storage adds a storage entry.
+func (ab *AccountBuilder) Storage(key string, value string) *AccountBuilder {
+	ab.storage = append(ab.storage, storageEntry{key: key, value: value})
+	return ab
+}
+
+// Code sets the contract code, usually "file:<path to wasm>".
+func (ab *AccountBuilder) Code(code string) *AccountBuilder {
+	ab.code = code
+	return ab
+}
+
+// Owner sets the contract owner.
+func (ab *AccountBuilder) Owner(owner string) *AccountBuilder {
+	ab.owner = owner
+	return ab
+}
+
+// ESDTBalance adds a fungible ESDT balance.
+func (ab *AccountBuilder) ESDTBalance(tokenIdentifier string, balance string) *AccountBuilder {
+	ab.esdtBalances = append(ab.esdtBalances, esdtBalance{tokenIdentifier: tokenIdentifier, balance: balance})
+	return ab
+}
+
+// CheckAccountBuilder describes the expected state of an account, for a checkState step.
+// All values are scenario expressions, "*" accepting any value. Unset fields are not checked.
+type CheckAccountBuilder struct {
+	address            string
+	comment            string
+	nonce              string
+	balance            string
+	username           string
+	storage            []storageEntry
+	explicitStorage    bool
+	moreStorageAllowed bool
+	code               string
+	owner              string
+	esdtBalances       []esdtBalance
+}
+
+// CheckAccount starts describing the expected state of an account.
+func CheckAccount(address string) *CheckAccountBuilder {
+	return &CheckAccountBuilder{address: address}
+}
+
+// Comment sets the account comment.
+func (cab *CheckAccountBuilder) Comment(comment string) *CheckAccountBuilder {
+	cab.comment = comment
+	return cab
+}
+
+// Nonce sets the expected nonce.
+func (cab *CheckAccountBuilder) Nonce(nonce string) *CheckAccountBuilder {
+	cab.nonce = nonce
+	return cab
+}
+
+// Balance sets the expected EGLD balance.
+func (cab *CheckAccountBuilder) Balance(balance string) *CheckAccountBuilder {
+	cab.balance = balance
+	return cab
+}
+
+// Username sets the expected username.
+func (cab *CheckAccountBuilder) Username(username string) *CheckAccountBuilder {
+	cab.username = username
+	return cab
+}
+
+// Storage adds an expected storage entry. Once storage entries are given, no other entries are allowed,
+// unless MoreStorageAllowed is also called.
+func (cab *CheckAccountBuilder) Storage(key string, value string) *CheckAccountBuilder {
+	cab.explicitStorage = true
+	cab.storage = append(cab.storage, storageEntry{key: key, value: value})
+	return cab
+}
+
+// NoStorage expects the account storage to be empty.
+func (cab *CheckAccountBuilder) NoStorage() *CheckAccountBuilder {
+	cab.explicitStorage = true
+	return cab
+}
+
+// MoreStorageAllowed allows storage entries other than the expected ones.
+func (cab *CheckAccountBuilder) MoreStorageAllowed() *CheckAccountBuilder {
+	cab.explicitStorage = true
+	cab.moreStorageAllowed = true
+	return cab
+}
+
+// Code sets the expected contract code.
+func (cab *CheckAccountBuilder) Code(code string) *CheckAccountBuilder {
+	cab.code = code
+	return cab
+}
+
+// Owner sets the expected contract owner.
+func (cab *CheckAccountBuilder) Owner(owner string) *CheckAccountBuilder {
+	cab.owner = owner
+	return cab
+}
+
+// ESDTBalance adds an expected fungible ESDT balance.
+func (cab *CheckAccountBuilder) ESDTBalance(tokenIdentifier string, balance string) *CheckAccountBuilder {
+	cab.esdtBalances = append(cab.esdtBalances, esdtBalance{tokenIdentifier: tokenIdentifier, balance: balance})
+	return cab
+}
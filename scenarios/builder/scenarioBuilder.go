@@ -0,0 +1,329 @@
+package scenbuilder
+
+import (
+	fr "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/fileresolver"
+	mjparse "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/parse"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+)
+
+// ScenarioBuilder builds scenarios programmatically, as an alternative to parsing JSON snippets.
+// Values are given as scenario expressions, which are interpreted the same way the parser does,
+// so the result can be both executed and written with scenjsonwrite.
+//
+//	scenario, err := scenbuilder.NewScenario("adder").
+//		SetState(scenbuilder.Account("address:owner").Nonce("1")).
+//		NewAddress("address:owner", "1", "sc:adder").
+//		Deploy("deploy", scenbuilder.Tx().From("address:owner").Code("file:adder.wasm").Arguments("5").GasLimit("5,000,000")).
+//		Expect(scenbuilder.Success().Out()).
+//		Build()
+//
+// The first error, e.g. from an invalid expression, is returned by Build.
+type ScenarioBuilder struct {
+	parser   mjparse.Parser
+	scenario *mj.Scenario
+	err      error
+}
+
+// NewScenario starts building a scenario. The file expressions are resolved relative to the working directory.
+func NewScenario(name string) *ScenarioBuilder {
+	return &ScenarioBuilder{
+		parser: mjparse.NewParser(fr.NewDefaultFileResolver()),
+		scenario: &mj.Scenario{
+			Name:        name,
+			CheckGas:    true,
+			GasSchedule: mj.GasScheduleDefault,
+		},
+	}
+}
+
+// WithFileResolver sets the file resolver for the file expressions that follow, e.g. to replace paths.
+func (sb *ScenarioBuilder) WithFileResolver(fileResolver fr.FileResolver) *ScenarioBuilder {
+	sb.parser.ExprInterpreter.FileResolver = fileResolver
+	return sb
+}
+
+// Comment sets the scenario comment.
+func (sb *ScenarioBuilder) Comment(comment string) *ScenarioBuilder {
+	sb.scenario.Comment = comment
+	return sb
+}
+
+// CheckGas sets whether the gas expectations are checked. It is true by default.
+func (sb *ScenarioBuilder) CheckGas(checkGas bool) *ScenarioBuilder {
+	sb.scenario.CheckGas = checkGas
+	return sb
+}
+
+// GasSchedule sets the gas schedule.
+func (sb *ScenarioBuilder) GasSchedule(gasSchedule mj.GasSchedule) *ScenarioBuilder {
+	sb.scenario.GasSchedule = gasSchedule
+	return sb
+}
+
+// Step appends a step built by other means.
+func (sb *ScenarioBuilder) Step(step mj.Step) *ScenarioBuilder {
+	sb.scenario.Steps = append(sb.scenario.Steps, step)
+	return sb
+}
+
+// ExternalSteps appends an externalSteps step, with a path relative to where the scenario is saved.
+func (sb *ScenarioBuilder) ExternalSteps(path string) *ScenarioBuilder {
+	return sb.Step(&mj.ExternalStepsStep{
+		Path:     path,
+		TraceGas: mj.Undefined,
+	})
+}
+
+// SetState appends a setState step with the given accounts.
+func (sb *ScenarioBuilder) SetState(accounts ...*AccountBuilder) *ScenarioBuilder {
+	step := &mj.SetStateStep{}
+	for _, account := range accounts {
+		step.Accounts = append(step.Accounts, sb.account(account))
+	}
+	return sb.Step(step)
+}
+
+// NewAddress adds a new address mock to the last step, if it is a setState step, or to a new setState step otherwise.
+func (sb *ScenarioBuilder) NewAddress(creatorAddress string, creatorNonce string, newAddress string) *ScenarioBuilder {
+	step, isSetState := sb.lastStep().(*mj.SetStateStep)
+	if !isSetState {
+		step = &mj.SetStateStep{}
+		sb.Step(step)
+	}
+	step.NewAddressMocks = append(step.NewAddressMocks, &mj.NewAddressMock{
+		CreatorAddress: sb.address(creatorAddress, "new address creator"),
+		CreatorNonce:   sb.uint64(creatorNonce, "new address creator nonce"),
+		NewAddress:     sb.address(newAddress, "new address"),
+	})
+	return sb
+}
+
+// Deploy appends a scDeploy step.
+func (sb *ScenarioBuilder) Deploy(txID string, tx *TxBuilder) *ScenarioBuilder {
+	return sb.txStep(mj.ScDeploy, txID, tx)
+}
+
+// Call appends a scCall step.
+func (sb *ScenarioBuilder) Call(txID string, tx *TxBuilder) *ScenarioBuilder {
+	return sb.txStep(mj.ScCall, txID, tx)
+}
+
+// Query appends a scQuery step.
+func (sb *ScenarioBuilder) Query(txID string, tx *TxBuilder) *ScenarioBuilder {
+	return sb.txStep(mj.ScQuery, txID, tx)
+}
+
+// Transfer appends a transfer step.
+func (sb *ScenarioBuilder) Transfer(txID string, tx *TxBuilder) *ScenarioBuilder {
+	return sb.txStep(mj.Transfer, txID, tx)
+}
+
+// Expect sets the expected result of the previous scDeploy, scCall or scQuery step.
+func (sb *ScenarioBuilder) Expect(result *ResultBuilder) *ScenarioBuilder {
+	step, isTxStep := sb.lastStep().(*mj.TxStep)
+	if !isTxStep || !step.Tx.Type.IsSmartContractTx() {
+		sb.fail(errNoTxToExpect)
+		return sb
+	}
+	step.ExpectedResult = sb.result(result)
+	return sb
+}
+
+// CheckState appends a checkState step, expecting exactly the given accounts.
+func (sb *ScenarioBuilder) CheckState(accounts ...*CheckAccountBuilder) *ScenarioBuilder {
+	checkAccounts := &mj.CheckAccounts{}
+	for _, account := range accounts {
+		checkAccounts.Accounts = append(checkAccounts.Accounts, sb.checkAccount(account))
+	}
+	return sb.Step(&mj.CheckStateStep{
+		StateRootHash: mj.JSONCheckBytesUnspecified(),
+		CheckAccounts: checkAccounts,
+	})
+}
+
+// AllowOtherAccounts allows accounts other than the expected ones in the previous checkState step.
+func (sb *ScenarioBuilder) AllowOtherAccounts() *ScenarioBuilder {
+	step, isCheckState := sb.lastStep().(*mj.CheckStateStep)
+	if !isCheckState {
+		sb.fail(errNoCheckStateToExtend)
+		return sb
+	}
+	step.CheckAccounts.MoreAccountsAllowed = true
+	return sb
+}
+
+// Build yields the scenario, or the first error encountered while building it.
+func (sb *ScenarioBuilder) Build() (*mj.Scenario, error) {
+	if sb.err != nil {
+		return nil, sb.err
+	}
+	return sb.scenario, nil
+}
+
+func (sb *ScenarioBuilder) lastStep() mj.Step {
+	if len(sb.scenario.Steps) == 0 {
+		return nil
+	}
+	return sb.scenario.Steps[len(sb.scenario.Steps)-1]
+}
+
+func (sb *ScenarioBuilder) account(ab *AccountBuilder) *mj.Account {
+	account := &mj.Account{
+		Address:         sb.address(ab.address, "account address"),
+		Shard:           mj.JSONUint64Zero(),
+		Comment:         ab.comment,
+		Nonce:           sb.uint64(ab.nonce, "account nonce"),
+		Balance:         sb.bigInt(ab.balance, false, "account balance"),
+		Username:        sb.bytesFromString(ab.username, "account username"),
+		Code:            sb.bytesFromString(ab.code, "account code"),
+		Owner:           sb.bytesFromString(ab.owner, "account owner"),
+		DeveloperReward: mj.JSONBigIntZero(),
+	}
+	for _, entry := range ab.storage {
+		account.Storage = append(account.Storage, &mj.StorageKeyValuePair{
+			Key:   sb.bytesFromString(entry.key, "storage key"),
+			Value: sb.bytesFromTree(entry.value, "storage value"),
+		})
+	}
+	for _, esdt := range ab.esdtBalances {
+		account.ESDTData = append(account.ESDTData, &mj.ESDTData{
+			TokenIdentifier: sb.bytesFromString(esdt.tokenIdentifier, "ESDT token identifier"),
+			Instances: []*mj.ESDTInstance{
+				{
+					Nonce:   mj.JSONUint64{Value: 0, Original: ""},
+					Balance: sb.bigInt(esdt.balance, false, "ESDT balance"),
+				},
+			},
+		})
+	}
+	return account
+}
+
+func (sb *ScenarioBuilder) checkAccount(cab *CheckAccountBuilder) *mj.CheckAccount {
+	account := &mj.CheckAccount{
+		Address:         sb.address(cab.address, "check account address"),
+		Comment:         cab.comment,
+		Nonce:           mj.JSONCheckUint64Unspecified(),
+		Balance:         mj.JSONCheckBigIntUnspecified(),
+		Username:        mj.JSONCheckBytesUnspecified(),
+		IgnoreStorage:   !cab.explicitStorage,
+		ExplicitStorage: cab.explicitStorage,
+		Code:            mj.JSONCheckBytesUnspecified(),
+		Owner:           mj.JSONCheckBytesUnspecified(),
+		RootHash:        mj.JSONCheckBytesUnspecified(),
+		AsyncCallData:   mj.JSONCheckBytesUnspecified(),
+		DeveloperReward: mj.JSONCheckBigIntUnspecified(),
+	}
+	if len(cab.nonce) > 0 {
+		account.Nonce = sb.checkUint64(cab.nonce, "check account nonce")
+	}
+	if len(cab.balance) > 0 {
+		account.Balance = sb.checkBigInt(cab.balance, false, "check account balance")
+	}
+	if len(cab.username) > 0 {
+		account.Username = sb.checkBytes(cab.username, "check account username")
+	}
+	if len(cab.code) > 0 {
+		account.Code = sb.checkBytes(cab.code, "check account code")
+	}
+	if len(cab.owner) > 0 {
+		account.Owner = sb.checkBytes(cab.owner, "check account owner")
+	}
+	account.MoreStorageAllowed = cab.moreStorageAllowed
+	for _, entry := range cab.storage {
+		account.CheckStorage = append(account.CheckStorage, &mj.CheckStorageKeyValuePair{
+			Key:        sb.bytesFromString(entry.key, "check storage key"),
+			CheckValue: sb.checkBytes(entry.value, "check storage value"),
+		})
+	}
+	for _, esdt := range cab.esdtBalances {
+		account.CheckESDTData = append(account.CheckESDTData, &mj.CheckESDTData{
+			TokenIdentifier: sb.bytesFromString(esdt.tokenIdentifier, "check ESDT token identifier"),
+			Instances: []*mj.CheckESDTInstance{
+				{
+					Nonce:   mj.JSONUint64Zero(),
+					Balance: sb.checkBigInt(esdt.balance, false, "check ESDT balance"),
+				},
+			},
+		})
+	}
+	return account
+}
+
+func (sb *ScenarioBuilder) txStep(txType mj.TransactionType, txID string, tb *TxBuilder) *ScenarioBuilder {
+	tx := &mj.Transaction{
+		Type:      txType,
+		EGLDValue: mj.JSONBigIntZero(),
+	}
+	if txType.HasSender() {
+		tx.From = sb.address(tb.from, "tx sender")
+	}
+	if txType.HasReceiver() {
+		tx.To = sb.address(tb.to, "tx receiver")
+	}
+	if txType.HasValue() {
+		tx.EGLDValue = sb.bigInt(tb.egldValue, false, "tx EGLD value")
+	}
+	if txType.HasESDT() {
+		for _, transfer := range tb.esdtTransfers {
+			tx.ESDTValue = append(tx.ESDTValue, &mj.ESDTTxData{
+				TokenIdentifier: sb.bytesFromString(transfer.tokenIdentifier, "tx ESDT token identifier"),
+				Nonce:           sb.uint64(transfer.nonce, "tx ESDT nonce"),
+				Value:           sb.bigInt(transfer.value, false, "tx ESDT value"),
+			})
+		}
+	}
+	if txType.HasFunction() {
+		tx.Function = tb.function
+	}
+	if txType == mj.ScDeploy {
+		tx.Code = sb.bytesFromString(tb.code, "tx contract code")
+	}
+	for _, argument := range tb.arguments {
+		tx.Arguments = append(tx.Arguments, sb.bytesFromTree(argument, "tx argument"))
+	}
+	if txType.HasGasLimit() {
+		tx.GasLimit = sb.uint64(tb.gasLimit, "tx gas limit")
+	}
+	if txType.HasGasPrice() {
+		tx.GasPrice = sb.uint64(tb.gasPrice, "tx gas price")
+	}
+
+	return sb.Step(&mj.TxStep{
+		TxIdent: txID,
+		Tx:      tx,
+	})
+}
+
+func (sb *ScenarioBuilder) result(rb *ResultBuilder) *mj.TransactionResult {
+	result := &mj.TransactionResult{
+		Status:        mj.JSONCheckBigIntUnspecified(),
+		Message:       mj.JSONCheckBytesUnspecified(),
+		InternalError: mj.JSONCheckInternalErrorUnspecified(),
+		Gas:           mj.JSONCheckUint64Unspecified(),
+		Refund:        mj.JSONCheckBigIntUnspecified(),
+		Logs:          mj.LogList{IsUnspecified: true, IsStar: true},
+	}
+	if rb.outSet {
+		result.Out = sb.checkValueList(rb.out, "expected out")
+	}
+	if rb.statusSet {
+		result.Status = sb.checkBigInt(rb.status, true, "expected status")
+	}
+	if len(rb.message) > 0 {
+		result.Message = sb.checkBytes(rb.message, "expected message")
+	}
+	if len(rb.gas) > 0 {
+		result.Gas = sb.checkUint64(rb.gas, "expected gas")
+	}
+	if len(rb.refund) > 0 {
+		result.Refund = sb.checkBigInt(rb.refund, false, "expected refund")
+	}
+	if rb.noLogs {
+		result.Logs = mj.LogList{}
+	}
+	if rb.anyLogs {
+		result.Logs = mj.LogList{IsStar: true}
+	}
+	return result
+}
@@ -0,0 +1,226 @@
+package scenbuilder
+
+import (
+	"testing"
+
+	fr "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/fileresolver"
+	mjparse "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/parse"
+	mjwrite "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/write"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	"github.com/stretchr/testify/require"
+)
+
+const expectedScenarioJSON = `{
+	"name": "adder",
+	"steps": [
+		{
+			"step": "setState",
+			"accounts": {
+				"address:owner": {
+					"nonce": "1",
+					"balance": "1,000",
+					"esdt": {
+						"str:TOKEN-123456": "500"
+					},
+					"storage": {
+						"str:counter": "5"
+					}
+				}
+			},
+			"newAddresses": [
+				{
+					"creatorAddress": "address:owner",
+					"creatorNonce": "1",
+					"newAddress": "sc:adder"
+				}
+			]
+		},
+		{
+			"step": "scDeploy",
+			"txId": "deploy",
+			"tx": {
+				"from": "address:owner",
+				"contractCode": "file:../json/integrationTests/exampleFile.txt",
+				"arguments": [
+					"5"
+				],
+				"gasLimit": "5,000,000",
+				"gasPrice": "0"
+			},
+			"expect": {
+				"out": [],
+				"status": "0",
+				"logs": []
+			}
+		},
+		{
+			"step": "scCall",
+			"txId": "add",
+			"tx": {
+				"from": "address:owner",
+				"to": "sc:adder",
+				"egldValue": "10",
+				"esdtValue": [
+					{
+						"tokenIdentifier": "str:TOKEN-123456",
+						"value": "100"
+					}
+				],
+				"function": "add",
+				"arguments": [
+					"u32:3",
+					"str:abc"
+				],
+				"gasLimit": "5,000,000"
+			},
+			"expect": {
+				"out": "*",
+				"status": "4",
+				"message": "str:wrong",
+				"gas": "*"
+			}
+		},
+		{
+			"step": "scQuery",
+			"txId": "get",
+			"tx": {
+				"to": "sc:adder",
+				"function": "getSum",
+				"arguments": []
+			},
+			"expect": {
+				"out": [
+					"8"
+				]
+			}
+		},
+		{
+			"step": "checkState",
+			"accounts": {
+				"address:owner": {
+					"nonce": "*",
+					"balance": "990",
+					"esdt": {
+						"str:TOKEN-123456": "400"
+					},
+					"storage": {
+						"str:counter": "5",
+						"+": ""
+					}
+				},
+				"sc:adder": {
+					"code": "file:../json/integrationTests/exampleFile.txt"
+				},
+				"+": ""
+			}
+		},
+		{
+			"step": "externalSteps",
+			"path": "more.steps.json"
+		}
+	]
+}`
+
+func TestScenarioBuilder_SameAsParsed(t *testing.T) {
+	scenario, err := NewScenario("adder").
+		SetState(Account("address:owner").
+			Nonce("1").
+			Balance("1,000").
+			ESDTBalance("str:TOKEN-123456", "500").
+			Storage("str:counter", "5")).
+		NewAddress("address:owner", "1", "sc:adder").
+		Deploy("deploy", Tx().
+			From("address:owner").
+			Code("file:../json/integrationTests/exampleFile.txt").
+			Arguments("5").
+			GasLimit("5,000,000").
+			GasPrice("0")).
+		Expect(Success().Out().NoLogs()).
+		Call("add", Tx().
+			From("address:owner").
+			To("sc:adder").
+			EGLDValue("10").
+			ESDTTransfer("str:TOKEN-123456", "", "100").
+			Function("add").
+			Arguments("u32:3", "str:abc").
+			GasLimit("5,000,000")).
+		Expect(Result().Out("*").Status("4").Message("str:wrong").Gas("*")).
+		Query("get", Tx().To("sc:adder").Function("getSum")).
+		Expect(Result().Out("8")).
+		CheckState(
+			CheckAccount("address:owner").
+				Nonce("*").
+				Balance("990").
+				ESDTBalance("str:TOKEN-123456", "400").
+				Storage("str:counter", "5").
+				MoreStorageAllowed(),
+			CheckAccount("sc:adder").
+				Code("file:../json/integrationTests/exampleFile.txt")).
+		AllowOtherAccounts().
+		ExternalSteps("more.steps.json").
+		Build()
+	require.Nil(t, err)
+
+	parser := mjparse.NewParser(fr.NewDefaultFileResolver())
+	parsedScenario, err := parser.ParseScenarioFile([]byte(expectedScenarioJSON))
+	require.Nil(t, err)
+
+	require.Equal(t, mjwrite.ScenarioToJSONString(parsedScenario), mjwrite.ScenarioToJSONString(scenario))
+
+	deployStep := scenario.Steps[1].(*mj.TxStep)
+	require.Equal(t, []byte("hello!"), deployStep.Tx.Code.Value)
+	callStep := scenario.Steps[2].(*mj.TxStep)
+	require.Equal(t, parsedScenario.Steps[2].(*mj.TxStep).Tx.Arguments[0].Value, callStep.Tx.Arguments[0].Value)
+	require.Equal(t, int64(4), callStep.ExpectedResult.Status.Value.Int64())
+}
+
+func TestScenarioBuilder_EmptyStatusAndAnyLogs(t *testing.T) {
+	scenario, err := NewScenario("").
+		Query("get", Tx().To("sc:adder").Function("getSum")).
+		Expect(Result().Out().Status("").AnyLogs()).
+		Build()
+	require.Nil(t, err)
+
+	parser := mjparse.NewParser(fr.NewDefaultFileResolver())
+	parsedScenario, err := parser.ParseScenarioFile([]byte(`{
+		"steps": [
+			{
+				"step": "scQuery",
+				"id": "get",
+				"tx": { "to": "sc:adder", "function": "getSum", "arguments": [] },
+				"expect": { "out": [], "status": "", "logs": "*" }
+			}
+		]
+	}`))
+	require.Nil(t, err)
+	require.Equal(t, mjwrite.ScenarioToJSONString(parsedScenario), mjwrite.ScenarioToJSONString(scenario))
+}
+
+func TestScenarioBuilder_Errors(t *testing.T) {
+	_, err := NewScenario("bad address").
+		SetState(Account("str:too short")).
+		Build()
+	require.EqualError(t, err, "account address str:too short is not 32 bytes in length")
+
+	_, err = NewScenario("bad expression").
+		Call("call", Tx().From("address:a").To("address:b").Function("f").GasLimit("not a number")).
+		Build()
+	require.Contains(t, err.Error(), "invalid tx gas limit not a number")
+
+	_, err = NewScenario("expect without tx").
+		SetState().
+		Expect(Success()).
+		Build()
+	require.Equal(t, errNoTxToExpect, err)
+
+	_, err = NewScenario("expect for transfer").
+		Transfer("transfer", Tx().From("address:a").To("address:b").EGLDValue("1")).
+		Expect(Success()).
+		Build()
+	require.Equal(t, errNoTxToExpect, err)
+
+	_, err = NewScenario("other accounts without check state").
+		AllowOtherAccounts().
+		Build()
+	require.Equal(t, errNoCheckStateToExtend, err)
+}
@@ -0,0 +1,155 @@
+package scenbuilder
+
+// esdtTransfer is an ESDT transfer of a transaction, as scenario expressions.
+type esdtTransfer struct {
+	tokenIdentifier string
+	nonce           string
+	value           string
+}
+
+// TxBuilder describes the transaction of a scDeploy, scCall, scQuery or transfer step.
+// All values are scenario expressions. The fields that the step type does not allow are ignored.
+type TxBuilder struct {
+	from          string
+	to            string
+	egldValue     string
+	esdtTransfers []esdtTransfer
+	function      string
+	code          string
+	arguments     []string
+	gasLimit      string
+	gasPrice      string
+}
+
+// Tx starts describing a transaction.
+func Tx() *TxBuilder {
+	return &TxBuilder{}
+}
+
+// From sets the sender.
+func (tb *TxBuilder) From(from string) *TxBuilder {
+	tb.from = from
+	return tb
+}
+
+// To sets the receiver.
+func (tb *TxBuilder) To(to string) *TxBuilder {
+	tb.to = to
+	return tb
+}
+
+// EGLDValue sets the EGLD value transferred.
+func (tb *TxBuilder) EGLDValue(egldValue string) *TxBuilder {
+	tb.egldValue = egldValue
+	return tb
+}
+
+// ESDTTransfer adds an ESDT transfer. The nonce is empty for fungible tokens.
+func (tb *TxBuilder) ESDTTransfer(tokenIdentifier string, nonce string, value string) *TxBuilder {
+	tb.esdtTransfers = append(tb.esdtTransfers, esdtTransfer{
+		tokenIdentifier: tokenIdentifier,
+		nonce:           nonce,
+		value:           value,
+	})
+	return tb
+}
+
+// Function sets the endpoint called.
+func (tb *TxBuilder) Function(function string) *TxBuilder {
+	tb.function = function
+	return tb
+}
+
+// Code sets the code deployed, usually "file:<path to wasm>".
+func (tb *TxBuilder) Code(code string) *TxBuilder {
+	tb.code = code
+	return tb
+}
+
+// Arguments appends arguments.
+func (tb *TxBuilder) Arguments(arguments ...string) *TxBuilder {
+	tb.arguments = append(tb.arguments, arguments...)
+	return tb
+}
+
+// GasLimit sets the gas limit.
+func (tb *TxBuilder) GasLimit(gasLimit string) *TxBuilder {
+	tb.gasLimit = gasLimit
+	return tb
+}
+
+// GasPrice sets the gas price.
+func (tb *TxBuilder) GasPrice(gasPrice string) *TxBuilder {
+	tb.gasPrice = gasPrice
+	return tb
+}
+
+// ResultBuilder describes the expected result of a transaction.
+// All values are scenario expressions, "*" accepting any value. Unset fields are not checked.
+type ResultBuilder struct {
+	out       []string
+	outSet    bool
+	status    string
+	statusSet bool
+	message   string
+	gas       string
+	refund    string
+	noLogs    bool
+	anyLogs   bool
+}
+
+// Result starts describing an expected transaction result.
+func Result() *ResultBuilder {
+	return &ResultBuilder{}
+}
+
+// Success expects status 0. Same as Status("0").
+func Success() *ResultBuilder {
+	return Result().Status("0")
+}
+
+// Out sets the expected returned values. Out("*") accepts any values.
+func (rb *ResultBuilder) Out(out ...string) *ResultBuilder {
+	rb.out = out
+	rb.outSet = true
+	return rb
+}
+
+// Status sets the expected status code. Status("") expects status 0, written as "".
+func (rb *ResultBuilder) Status(status string) *ResultBuilder {
+	rb.status = status
+	rb.statusSet = true
+	return rb
+}
+
+// Message sets the expected error message, e.g. "str:wrong argument".
+func (rb *ResultBuilder) Message(message string) *ResultBuilder {
+	rb.message = message
+	return rb
+}
+
+// Gas sets the expected gas remaining.
+func (rb *ResultBuilder) Gas(gas string) *ResultBuilder {
+	rb.gas = gas
+	return rb
+}
+
+// Refund sets the expected gas refund.
+func (rb *ResultBuilder) Refund(refund string) *ResultBuilder {
+	rb.refund = refund
+	return rb
+}
+
+// NoLogs expects no logs. By default, logs are not checked.
+func (rb *ResultBuilder) NoLogs() *ResultBuilder {
+	rb.noLogs = true
+	rb.anyLogs = false
+	return rb
+}
+
+// AnyLogs accepts any logs, written explicitly as "logs": "*".
+func (rb *ResultBuilder) AnyLogs() *ResultBuilder {
+	rb.anyLogs = true
+	rb.noLogs = false
+	return rb
+}
@@ -0,0 +1,118 @@
+package scenbuilder
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	oj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/orderedjson"
+	twos "github.com/multiversx/mx-components-big-int/twos-complement"
+)
+
+// The conversions below interpret the scenario expressions the same way the JSON parser does,
+// keeping the expressions as originals. The first error is kept by the builder and returned by Build.
+
+func (sb *ScenarioBuilder) fail(err error) {
+	if sb.err == nil {
+		sb.err = err
+	}
+}
+
+func (sb *ScenarioBuilder) interpret(expr string, fieldName string) []byte {
+	value, err := sb.parser.ExprInterpreter.InterpretString(expr)
+	if err != nil {
+		sb.fail(fmt.Errorf("invalid %s %s: %w", fieldName, expr, err))
+	}
+	return value
+}
+
+func (sb *ScenarioBuilder) bytesFromString(expr string, fieldName string) mj.JSONBytesFromString {
+	if len(expr) == 0 {
+		return mj.JSONBytesEmpty()
+	}
+	return mj.NewJSONBytesFromString(sb.interpret(expr, fieldName), expr)
+}
+
+func (sb *ScenarioBuilder) bytesFromTree(expr string, fieldName string) mj.JSONBytesFromTree {
+	return mj.JSONBytesFromTree{
+		Value:    sb.interpret(expr, fieldName),
+		Original: &oj.OJsonString{Value: expr},
+	}
+}
+
+func (sb *ScenarioBuilder) address(expr string, fieldName string) mj.JSONBytesFromString {
+	if len(expr) == 0 {
+		sb.fail(fmt.Errorf("missing %s", fieldName))
+		return mj.JSONBytesEmpty()
+	}
+	value := sb.interpret(expr, fieldName)
+	if value != nil && len(value) != 32 {
+		sb.fail(fmt.Errorf("%s %s is not 32 bytes in length", fieldName, expr))
+	}
+	return mj.NewJSONBytesFromString(value, expr)
+}
+
+func (sb *ScenarioBuilder) bigInt(expr string, signed bool, fieldName string) mj.JSONBigInt {
+	if len(expr) == 0 {
+		return mj.JSONBigIntZero()
+	}
+	value := sb.interpret(expr, fieldName)
+	if signed {
+		return mj.JSONBigInt{Value: twos.FromBytes(value), Original: expr}
+	}
+	return mj.JSONBigInt{Value: big.NewInt(0).SetBytes(value), Original: expr}
+}
+
+func (sb *ScenarioBuilder) uint64(expr string, fieldName string) mj.JSONUint64 {
+	if len(expr) == 0 {
+		return mj.JSONUint64Zero()
+	}
+	value := sb.bigInt(expr, false, fieldName).Value
+	if !value.IsUint64() {
+		sb.fail(fmt.Errorf("%s %s is not uint64", fieldName, expr))
+		return mj.JSONUint64Zero()
+	}
+	return mj.JSONUint64{Value: value.Uint64(), Original: expr}
+}
+
+func (sb *ScenarioBuilder) checkBytes(expr string, fieldName string) mj.JSONCheckBytes {
+	if expr == "*" {
+		return mj.JSONCheckBytesStar()
+	}
+	return mj.JSONCheckBytes{
+		Value:    sb.interpret(expr, fieldName),
+		Original: &oj.OJsonString{Value: expr},
+	}
+}
+
+func (sb *ScenarioBuilder) checkBigInt(expr string, signed bool, fieldName string) mj.JSONCheckBigInt {
+	if expr == "*" {
+		return mj.JSONCheckBigInt{IsStar: true, Original: "*"}
+	}
+	value := sb.bigInt(expr, signed, fieldName)
+	return mj.JSONCheckBigInt{Value: value.Value, Original: expr}
+}
+
+func (sb *ScenarioBuilder) checkUint64(expr string, fieldName string) mj.JSONCheckUint64 {
+	if expr == "*" {
+		return mj.JSONCheckUint64{IsStar: true, Original: "*"}
+	}
+	value := sb.uint64(expr, fieldName)
+	return mj.JSONCheckUint64{Value: value.Value, Original: expr}
+}
+
+func (sb *ScenarioBuilder) checkValueList(exprs []string, fieldName string) mj.JSONCheckValueList {
+	if len(exprs) == 1 && exprs[0] == "*" {
+		return mj.JSONCheckValueListStar()
+	}
+	values := make([]mj.JSONCheckBytes, 0, len(exprs))
+	for _, expr := range exprs {
+		values = append(values, sb.checkBytes(expr, fieldName))
+	}
+	return mj.JSONCheckValueList{Values: values}
+}
+
+var errNoTxToExpect = errors.New("expected results can only follow a scDeploy, scCall or scQuery step")
+
+var errNoCheckStateToExtend = errors.New("other accounts can only be allowed right after a checkState step")
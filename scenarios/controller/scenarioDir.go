@@ -6,11 +6,13 @@ import (
 	"os"
 	"path"
 	"path/filepath"
-	"strings"
+
+	fr "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/fileresolver"
 )
 
 // RunAllJSONScenariosInDirectory walks directory, parses and prepares all json scenarios,
 // then calls scenarioExecutor for each of them.
+// Scenarios in the other supported formats, e.g. .scen.yaml for the .scen.json suffix, are also run.
 func (r *ScenarioRunner) RunAllJSONScenariosInDirectory(
 	generalTestPath string,
 	specificTestPath string,
@@ -19,32 +21,65 @@ func (r *ScenarioRunner) RunAllJSONScenariosInDirectory(
 	options *RunScenarioOptions) error {
 
 	mainDirPath := path.Join(generalTestPath, specificTestPath)
-	var nrPassed, nrFailed, nrSkipped int
-
+	var testFilePaths []string
 	err := filepath.Walk(mainDirPath, func(testFilePath string, info os.FileInfo, err error) error {
-		if strings.HasSuffix(testFilePath, allowedSuffix) {
-			fmt.Printf("Scenario: %s ... ", shortenTestPath(testFilePath, generalTestPath))
-			if isExcluded(excludedFilePatterns, testFilePath, generalTestPath) {
-				nrSkipped++
-				fmt.Print("  skip\n")
-			} else {
-				r.Executor.Reset()
-				r.RunsNewTest = true
-				testErr := r.RunSingleJSONScenario(testFilePath, options)
-				if testErr == nil {
-					nrPassed++
-					fmt.Print("  ok\n")
-				} else {
-					nrFailed++
-					fmt.Printf("  FAIL: %s\n", testErr.Error())
-				}
-			}
+		if HasSuffixInAnyFormat(testFilePath, allowedSuffix) {
+			testFilePaths = append(testFilePaths, testFilePath)
 		}
 		return nil
 	})
 	if err != nil {
 		return err
 	}
+
+	return r.runAllJSONScenarios(testFilePaths, generalTestPath, excludedFilePatterns, options)
+}
+
+// RunAllJSONScenariosInBundle runs all scenarios in a bundle, read through the given bundle file resolver.
+// The runner should have been created with the same resolver, or with one wrapping it.
+func (r *ScenarioRunner) RunAllJSONScenariosInBundle(
+	bundle *fr.BundleFileResolver,
+	allowedSuffix string,
+	excludedFilePatterns []string,
+	options *RunScenarioOptions) error {
+
+	var testFilePaths []string
+	for _, testFilePath := range bundle.Paths() {
+		if HasSuffixInAnyFormat(testFilePath, allowedSuffix) {
+			testFilePaths = append(testFilePaths, testFilePath)
+		}
+	}
+
+	return r.runAllJSONScenarios(testFilePaths, bundle.MountPath(), excludedFilePatterns, options)
+}
+
+func (r *ScenarioRunner) runAllJSONScenarios(
+	testFilePaths []string,
+	generalTestPath string,
+	excludedFilePatterns []string,
+	options *RunScenarioOptions) error {
+
+	var nrPassed, nrFailed, nrSkipped int
+	for _, testFilePath := range testFilePaths {
+		fmt.Printf("Scenario: %s ... ", shortenTestPath(testFilePath, generalTestPath))
+		if isExcluded(excludedFilePatterns, testFilePath, generalTestPath) {
+			nrSkipped++
+			fmt.Print("  skip\n")
+			continue
+		}
+
+		r.Executor.Reset()
+		r.RunsNewTest = true
+		testErr := r.RunTopLevelJSONScenario(testFilePath, options)
+		if testErr == nil {
+			nrPassed++
+			fmt.Print("  ok\n")
+		} else {
+			nrFailed++
+			fmt.Printf("  FAIL: %s\n", testErr.Error())
+		}
+	}
+
 	fmt.Printf("Done. Passed: %d. Failed: %d. Skipped: %d.\n", nrPassed, nrFailed, nrSkipped)
 	if nrFailed > 0 {
 		return errors.New("some tests failed")
@@ -5,12 +5,15 @@ import (
 	"os"
 	"path/filepath"
 
+	fr "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/fileresolver"
 	mjparse "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/parse"
 	mjwrite "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/write"
 	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	oj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/orderedjson"
 )
 
-// ParseScenariosScenario reads and parses a Scenarios scenario from a JSON file.
+// ParseScenariosScenario reads and parses a Scenarios scenario from a JSON, JSON with comments or YAML file,
+// depending on the file extension.
 func ParseScenariosScenario(parser mjparse.Parser, scenFilePath string) (*mj.Scenario, error) {
 	var err error
 	scenFilePath, err = filepath.Abs(scenFilePath)
@@ -18,9 +21,25 @@ func ParseScenariosScenario(parser mjparse.Parser, scenFilePath string) (*mj.Sce
 		return nil, err
 	}
 
+	byteValue, err := readScenarioFile(parser.ExprInterpreter.FileResolver, scenFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	parser.ExprInterpreter.FileResolver.SetContext(scenFilePath)
+	return parser.ParseScenarioFileInFormat(byteValue, oj.FormatFromPath(scenFilePath))
+}
+
+// readScenarioFile reads the scenario file through the file resolver when it does not read from the file system,
+// e.g. when running scenarios from a bundle.
+func readScenarioFile(fileResolver fr.FileResolver, scenFilePath string) ([]byte, error) {
+	fileReader, isFileReader := fileResolver.(fr.FileReader)
+	if isFileReader {
+		return fileReader.ReadFile(scenFilePath)
+	}
+
 	// Open our jsonFile
-	var jsonFile *os.File
-	jsonFile, err = os.Open(scenFilePath)
+	jsonFile, err := os.Open(scenFilePath)
 	// if we os.Open returns an error then handle it
 	if err != nil {
 		return nil, err
@@ -31,13 +50,7 @@ func ParseScenariosScenario(parser mjparse.Parser, scenFilePath string) (*mj.Sce
 		_ = jsonFile.Close()
 	}()
 
-	byteValue, err := ioutil.ReadAll(jsonFile)
-	if err != nil {
-		return nil, err
-	}
-
-	parser.ExprInterpreter.FileResolver.SetContext(scenFilePath)
-	return parser.ParseScenarioFile(byteValue)
+	return ioutil.ReadAll(jsonFile)
 }
 
 // ParseScenariosScenarioDefaultParser reads and parses a Scenarios scenario from a JSON file.
@@ -48,13 +61,17 @@ func ParseScenariosScenarioDefaultParser(scenFilePath string) (*mj.Scenario, err
 }
 
 // WriteScenariosScenario exports a Scenarios scenario to a file, using the default formatting.
+// The output is YAML for .yaml and .yml files, JSON otherwise.
 func WriteScenariosScenario(scenario *mj.Scenario, toPath string) error {
-	jsonString := mjwrite.ScenarioToJSONString(scenario)
+	scenarioString, err := mjwrite.ScenarioToFormattedString(scenario, oj.FormatFromPath(toPath))
+	if err != nil {
+		return err
+	}
 
-	err := os.MkdirAll(filepath.Dir(toPath), os.ModePerm)
+	err = os.MkdirAll(filepath.Dir(toPath), os.ModePerm)
 	if err != nil {
 		return err
 	}
 
-	return ioutil.WriteFile(toPath, []byte(jsonString), 0644)
+	return ioutil.WriteFile(toPath, []byte(scenarioString), 0644)
 }
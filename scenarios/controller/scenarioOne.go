@@ -5,18 +5,24 @@ import (
 )
 
 type RunScenarioOptions struct {
-	ForceTraceGas bool
+	ForceTraceGas    bool
+	ForceGasSchedule bool
+	GasSchedule      mj.GasSchedule
 }
 
 func applyScenarioOptions(scenario *mj.Scenario, options *RunScenarioOptions) {
 	if options.ForceTraceGas {
 		scenario.TraceGas = true
 	}
+	if options.ForceGasSchedule {
+		scenario.GasSchedule = options.GasSchedule
+	}
 }
 
 func DefaultRunScenarioOptions() *RunScenarioOptions {
 	return &RunScenarioOptions{
-		ForceTraceGas: false,
+		ForceTraceGas:    false,
+		ForceGasSchedule: false,
 	}
 }
 
@@ -37,3 +43,20 @@ func (r *ScenarioRunner) RunSingleJSONScenario(contextPath string, options *RunS
 
 	return r.Executor.ExecuteScenario(scenario, r.Parser.ExprInterpreter.FileResolver)
 }
+
+// RunTopLevelJSONScenario runs a scenario file that is not part of another scenario,
+// notifying the executor if it is a ScenarioFileListener.
+func (r *ScenarioRunner) RunTopLevelJSONScenario(scenFilePath string, options *RunScenarioOptions) error {
+	listener, isListener := r.Executor.(ScenarioFileListener)
+	if isListener {
+		listener.BeginScenarioFile(scenFilePath)
+	}
+
+	err := r.RunSingleJSONScenario(scenFilePath, options)
+
+	if isListener {
+		listener.EndScenarioFile(scenFilePath, err)
+	}
+
+	return err
+}
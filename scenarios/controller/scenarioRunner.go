@@ -17,6 +17,16 @@ type ScenarioExecutor interface {
 	ExecuteScenario(*mj.Scenario, fr.FileResolver) error
 }
 
+// ScenarioFileListener is optionally implemented by scenario executors that need
+// to know which top-level scenario file is running, e.g. to produce per-file reports.
+type ScenarioFileListener interface {
+	// BeginScenarioFile is called before running a top-level scenario file, but not for external steps.
+	BeginScenarioFile(scenFilePath string)
+
+	// EndScenarioFile is called after a top-level scenario file was run, with its result.
+	EndScenarioFile(scenFilePath string, scenErr error)
+}
+
 // ScenarioRunner is a component that can run json scenarios, using a provided executor.
 type ScenarioRunner struct {
 	Executor    ScenarioExecutor
@@ -1,7 +1,11 @@
 package scencontroller
 
 import (
+	"path/filepath"
+	"strings"
+
 	fr "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/fileresolver"
+	oj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/orderedjson"
 )
 
 // NewDefaultFileResolver yields a new DefaultFileResolver instance.
@@ -10,3 +14,19 @@ import (
 func NewDefaultFileResolver() *fr.DefaultFileResolver {
 	return fr.NewDefaultFileResolver()
 }
+
+// HasSuffixInAnyFormat checks if the path ends with the given suffix, e.g. ".scen.json",
+// or with the same suffix in any other supported format, e.g. ".scen.yaml".
+func HasSuffixInAnyFormat(path string, suffix string) bool {
+	if strings.HasSuffix(path, suffix) {
+		return true
+	}
+
+	suffixWithoutExtension := strings.TrimSuffix(suffix, filepath.Ext(suffix))
+	for _, extension := range oj.FileExtensions {
+		if strings.HasSuffix(path, suffixWithoutExtension+extension) {
+			return true
+		}
+	}
+	return false
+}
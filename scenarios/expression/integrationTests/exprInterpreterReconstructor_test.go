@@ -1,6 +1,7 @@
 package scenjsontest
 
 import (
+	"bytes"
 	"encoding/hex"
 	"testing"
 
@@ -130,6 +131,21 @@ func TestAddress(t *testing.T) {
 	require.Equal(t, "address:1234567890123456789012345678901#01", er.Reconstruct(result, mer.AddressHint))
 }
 
+func TestAddressNotWrittenInScenario(t *testing.T) {
+	ei := mei.ExprInterpreter{}
+	er := mer.ExprReconstructor{}
+
+	hexAddress := "0x00000000000000000500489c1a6dbdf6d4f2a501ea90912d218597a6a82c2f9b"
+	result, err := ei.InterpretString(hexAddress)
+	require.Nil(t, err)
+	require.Equal(t, hexAddress, er.Reconstruct(result, mer.AddressHint))
+
+	hexAddress = "0x804fd639e5a6980d1cd2392abcce41029cda74a1563523a202f09641cc2618f8"
+	result, err = ei.InterpretString(hexAddress)
+	require.Nil(t, err)
+	require.Equal(t, hexAddress, er.Reconstruct(result, mer.AddressHint))
+}
+
 func TestAddressWithShardId(t *testing.T) {
 	ei := mei.ExprInterpreter{}
 	er := mer.ExprReconstructor{}
@@ -620,3 +636,79 @@ func TestInterpretSubTree2(t *testing.T) {
 	expected = append(expected, []byte("field2elem3b")...)
 	require.Equal(t, expected, result)
 }
+
+func TestReconstructInferred(t *testing.T) {
+	ei := mei.ExprInterpreter{}
+	owner, err := ei.InterpretString("address:owner")
+	require.Nil(t, err)
+	adder, err := ei.InterpretString("sc:adder")
+	require.Nil(t, err)
+	er := mer.ExprReconstructor{
+		KnownAddress: func(value []byte) bool {
+			return bytes.Equal(value, owner) || bytes.Equal(value, adder)
+		},
+	}
+
+	for _, expression := range []string{
+		"",
+		"address:owner",
+		"sc:adder",
+		"str:TTT-010101",
+		"str:TOKEN2-abcdef",
+		"str:counter",
+		"5",
+		"1000000",
+		"18446744073709551615",
+		"0x0001",
+		"0x0102030405060708090a",
+	} {
+		result, err := ei.InterpretString(expression)
+		require.Nil(t, err)
+		require.Equal(t, expression, er.Reconstruct(result, mer.InferHint))
+	}
+
+	// too short to be a string
+	require.Equal(t, "24930", er.Reconstruct([]byte("ab"), mer.InferHint))
+
+	// not a token identifier, but still printable
+	require.Equal(t, "str:TTT-01010G", er.Reconstruct([]byte("TTT-01010G"), mer.InferHint))
+
+	// unknown addresses are not shown as addresses
+	unknown, err := ei.InterpretString("address:unknown")
+	require.Nil(t, err)
+	require.Equal(t, "str:unknown_________________________", er.Reconstruct(unknown, mer.InferHint))
+
+	// no address is known without a world
+	noWorldER := mer.ExprReconstructor{}
+	require.Equal(t, "str:owner___________________________", noWorldER.Reconstruct(owner, mer.InferHint))
+}
+
+func TestReconstructPipe(t *testing.T) {
+	ei := mei.ExprInterpreter{}
+	er := mer.ExprReconstructor{}
+
+	// the interpreter splits concatenated expressions at '|', so strings containing it are written in hex
+	for _, value := range [][]byte{
+		[]byte("pipe|key"),
+		[]byte("storage|key"),
+		[]byte("a|b"),
+		[]byte("||"),
+		[]byte("|"),
+		[]byte("hash|1"),
+	} {
+		for _, hint := range []mer.ExprReconstructorHint{mer.InferHint, mer.StrHint} {
+			expression := er.Reconstruct(value, hint)
+			result, err := ei.InterpretString(expression)
+			require.Nil(t, err)
+			require.Equal(t, value, result, expression)
+		}
+	}
+
+	require.Equal(t, "0x73746f726167657c6b6579", er.Reconstruct([]byte("storage|key"), mer.InferHint))
+	require.Equal(t, "0x706970657c6b6579", er.Reconstruct([]byte("pipe|key"), mer.StrHint))
+	require.Equal(t, "0x617c62", er.Reconstruct([]byte("a|b"), mer.StrHint))
+	require.Equal(t, "0x7c7c", er.Reconstruct([]byte("||"), mer.StrHint))
+
+	// non-printable strings are written in hex as well
+	require.Equal(t, "0x6105", er.Reconstruct([]byte("a\x05"), mer.StrHint))
+}
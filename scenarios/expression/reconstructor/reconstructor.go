@@ -28,23 +28,39 @@ const (
 
 	// CodeHint hints that value should be a smart contract code, normally loaded from a file
 	CodeHint
+
+	// InferHint indicates that the type is not known, but should be guessed from the value,
+	// yielding a valid scenario expression, e.g. for dumps and diffs
+	InferHint
 )
 
 const maxBytesInterpretedAsNumber = 15
 
+// maxBytesInferredAsNumber is the length of the largest value inferred to be a number, that of a uint64
+const maxBytesInferredAsNumber = 8
+
+// minLengthInferredAsString is the length of the shortest value inferred to be a string, shorter printable values being rather numbers
+const minLengthInferredAsString = 3
+
 // ExprReconstructor is a component that attempts to convert raw bytes to a human-readable format.
-type ExprReconstructor struct{}
+type ExprReconstructor struct {
+	// KnownAddress tells whether a value is the address of an account from the current world,
+	// so that InferHint can show it as an address. No value is inferred to be an address if not set.
+	KnownAddress func(value []byte) bool
+}
 
 func (er *ExprReconstructor) Reconstruct(value []byte, hint ExprReconstructorHint) string {
 	switch hint {
 	case NumberHint:
 		return fmt.Sprintf("%d", big.NewInt(0).SetBytes(value))
 	case StrHint:
-		return fmt.Sprintf("str:%s", string(value))
+		return strPretty(value)
 	case AddressHint:
 		return addressPretty(value)
 	case CodeHint:
 		return codePretty(value)
+	case InferHint:
+		return er.inferredPretty(value)
 	default:
 		return unknownByteArrayPretty(value)
 	}
@@ -87,11 +103,105 @@ func unknownByteArrayPretty(bytes []byte) string {
 	return fmt.Sprintf("0x%s (str:%s)", hex.EncodeToString(bytes), strconv.Quote(string(bytes)))
 }
 
+// inferredPretty guesses the type of the value, trying in order: known addresses,
+// token identifiers, printable strings and small numbers, and falls back to hex.
+func (er *ExprReconstructor) inferredPretty(value []byte) string {
+	if len(value) == 0 {
+		return ""
+	}
+
+	if len(value) == 32 && er.KnownAddress != nil && er.KnownAddress(value) {
+		if isScenarioAddress(value) {
+			return addressPretty(value)
+		}
+		return fmt.Sprintf("0x%s", hex.EncodeToString(value))
+	}
+
+	if isTokenIdentifier(value) {
+		return fmt.Sprintf("str:%s", string(value))
+	}
+
+	if len(value) >= minLengthInferredAsString && canWriteAsStr(value) && containsLetter(value) {
+		return fmt.Sprintf("str:%s", string(value))
+	}
+
+	// numbers with leading zeros would lose them when written as decimal
+	if len(value) <= maxBytesInferredAsNumber && value[0] != 0 {
+		return fmt.Sprintf("%d", big.NewInt(0).SetBytes(value))
+	}
+
+	if canWriteAsStr(value) {
+		return fmt.Sprintf("str:%s", string(value))
+	}
+
+	return fmt.Sprintf("0x%s", hex.EncodeToString(value))
+}
+
+// strPretty writes the value as "str:...", or in hex if the string would not be read back the same.
+func strPretty(value []byte) string {
+	if len(value) > 0 && !canWriteAsStr(value) {
+		return fmt.Sprintf("0x%s", hex.EncodeToString(value))
+	}
+	return fmt.Sprintf("str:%s", string(value))
+}
+
+// isScenarioAddress tells whether the address was written in a scenario as "address:..." or "sc:...",
+// i.e. whether addressPretty can show it.
+func isScenarioAddress(value []byte) bool {
+	name := addressName(value)
+	return len(name) > 0 && canWriteAsStr(name)
+}
+
+// addressName yields the name of a 32-byte address, as written after "address:" or "sc:",
+// without the shard id and the padding.
+func addressName(value []byte) []byte {
+	leadingZeros := make([]byte, ei.SCAddressNumLeadingZeros)
+	name := value[:31]
+	if bytes.Equal(value[:ei.SCAddressNumLeadingZeros], leadingZeros) {
+		name = value[ei.SCAddressNumLeadingZeros:31]
+	}
+	return bytes.TrimRight(name, "_")
+}
+
+// isTokenIdentifier tells whether the value looks like an ESDT token identifier, e.g. "TTT-010101":
+// an alphanumeric ticker, a dash and 6 random lowercase hex characters.
+func isTokenIdentifier(value []byte) bool {
+	dashIndex := bytes.IndexByte(value, '-')
+	if dashIndex < 3 || dashIndex > 10 || len(value) != dashIndex+7 {
+		return false
+	}
+	for _, b := range value[:dashIndex] {
+		if !(b >= 'A' && b <= 'Z') && !(b >= '0' && b <= '9') {
+			return false
+		}
+	}
+	for _, b := range value[dashIndex+1:] {
+		if !(b >= 'a' && b <= 'f') && !(b >= '0' && b <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func containsLetter(value []byte) bool {
+	for _, b := range value {
+		if (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') {
+			return true
+		}
+	}
+	return false
+}
+
 func addressPretty(value []byte) string {
 	if len(value) != 32 {
 		return unknownByteArrayPretty(value)
 	}
 
+	// real addresses, e.g. imported from a chain, would not survive being written as text
+	if !canWriteAsAddressName(addressName(value)) {
+		return "0x" + hex.EncodeToString(value)
+	}
+
 	// smart contract addresses
 	leadingZeros := make([]byte, ei.SCAddressNumLeadingZeros)
 	if bytes.Equal(value[:ei.SCAddressNumLeadingZeros], leadingZeros) {
@@ -134,6 +244,24 @@ func canInterpretAsString(bytes []byte) bool {
 	return true
 }
 
+// canWriteAsStr tells whether the value can be written as "str:..." in a scenario expression.
+// Besides being printable, it cannot contain '|', where the interpreter splits concatenated expressions.
+func canWriteAsStr(bytes []byte) bool {
+	return canInterpretAsString(bytes) && strings.IndexByte(string(bytes), '|') < 0
+}
+
+// canWriteAsAddressName tells whether the name can be written after "address:" or "sc:".
+// It is the same check as for "str:", except that the name can be empty, e.g. "address:#05",
+// and can contain control characters, since it is always padded to the address length.
+func canWriteAsAddressName(name []byte) bool {
+	for _, b := range name {
+		if b == 0 || b > 126 || b == '|' {
+			return false
+		}
+	}
+	return true
+}
+
 func codePretty(bytes []byte) string {
 	if len(bytes) == 0 {
 		return ""
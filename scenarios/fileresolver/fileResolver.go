@@ -14,3 +14,10 @@ type FileResolver interface {
 	// ResolveFileValue converts a value prefixed with "file:" and replaces it with the file contents.
 	ResolveFileValue(value string) ([]byte, error)
 }
+
+// FileReader is implemented by the file resolvers that do not read from the file system.
+// The scenario files themselves are then also read through the resolver.
+type FileReader interface {
+	// ReadFile yields the contents of the file at the given absolute path.
+	ReadFile(path string) ([]byte, error)
+}
@@ -0,0 +1,198 @@
+package scenfileresolver
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var _ FileResolver = (*BundleFileResolver)(nil)
+var _ FileReader = (*BundleFileResolver)(nil)
+
+// BundleFileResolver loads file contents from a tar or zip bundle, e.g. of a contract's output/ and scenarios/ directories,
+// so that a whole test suite can be shipped as a single artifact.
+// The bundle is mounted at its own path: a bundle "suite.tar" containing "scenarios/a.scen.json"
+// exposes it as "<absolute path to>/suite.tar/scenarios/a.scen.json", and relative paths are resolved as usual.
+type BundleFileResolver struct {
+	DefaultFileResolver
+	mountPath string
+	files     map[string][]byte
+}
+
+// LoadBundleFileResolver reads a .tar, .tar.gz, .tgz or .zip bundle into memory.
+func LoadBundleFileResolver(bundlePath string) (*BundleFileResolver, error) {
+	mountPath, err := filepath.Abs(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var files map[string][]byte
+	switch {
+	case strings.HasSuffix(bundlePath, ".zip"):
+		files, err = readZipBundle(bundlePath)
+	case strings.HasSuffix(bundlePath, ".tar.gz") || strings.HasSuffix(bundlePath, ".tgz"):
+		files, err = readTarBundle(bundlePath, true)
+	case strings.HasSuffix(bundlePath, ".tar"):
+		files, err = readTarBundle(bundlePath, false)
+	default:
+		return nil, fmt.Errorf("unknown bundle format: %s", bundlePath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read bundle %s: %w", bundlePath, err)
+	}
+
+	return NewBundleFileResolver(mountPath, files), nil
+}
+
+// NewBundleFileResolver yields a resolver for bundle contents already in memory,
+// keyed by their slash-separated path in the bundle.
+func NewBundleFileResolver(mountPath string, files map[string][]byte) *BundleFileResolver {
+	return &BundleFileResolver{
+		DefaultFileResolver: *NewDefaultFileResolver(),
+		mountPath:           mountPath,
+		files:               files,
+	}
+}
+
+// IsBundlePath checks if the path has the extension of a supported bundle format.
+func IsBundlePath(path string) bool {
+	for _, extension := range []string{".zip", ".tar", ".tar.gz", ".tgz"} {
+		if strings.HasSuffix(path, extension) {
+			return true
+		}
+	}
+	return false
+}
+
+func readTarBundle(bundlePath string, gzipped bool) (map[string][]byte, error) {
+	bundleFile, err := os.Open(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = bundleFile.Close()
+	}()
+
+	var reader io.Reader = bundleFile
+	if gzipped {
+		gzipReader, err := gzip.NewReader(bundleFile)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			_ = gzipReader.Close()
+		}()
+		reader = gzipReader
+	}
+
+	files := make(map[string][]byte)
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		contents, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+		files[cleanBundleEntryName(header.Name)] = contents
+	}
+}
+
+func readZipBundle(bundlePath string) (map[string][]byte, error) {
+	zipReader, err := zip.OpenReader(bundlePath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = zipReader.Close()
+	}()
+
+	files := make(map[string][]byte)
+	for _, zipFile := range zipReader.File {
+		if zipFile.FileInfo().IsDir() {
+			continue
+		}
+
+		entryReader, err := zipFile.Open()
+		if err != nil {
+			return nil, err
+		}
+		contents, err := ioutil.ReadAll(entryReader)
+		_ = entryReader.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[cleanBundleEntryName(zipFile.Name)] = contents
+	}
+	return files, nil
+}
+
+func cleanBundleEntryName(name string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(name)), "/")
+}
+
+// MountPath yields the absolute path where the bundle contents are exposed.
+func (fr *BundleFileResolver) MountPath() string {
+	return fr.mountPath
+}
+
+// Paths yields the absolute paths of all files in the bundle, sorted.
+func (fr *BundleFileResolver) Paths() []string {
+	paths := make([]string, 0, len(fr.files))
+	for name := range fr.files {
+		paths = append(paths, filepath.Join(fr.mountPath, filepath.FromSlash(name)))
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Clone creates new instance of the same type, sharing the bundle contents.
+func (fr *BundleFileResolver) Clone() FileResolver {
+	return &BundleFileResolver{
+		DefaultFileResolver: *fr.DefaultFileResolver.Clone().(*DefaultFileResolver),
+		mountPath:           fr.mountPath,
+		files:               fr.files,
+	}
+}
+
+// ReadFile yields the contents of the file at the given absolute path, from the bundle.
+func (fr *BundleFileResolver) ReadFile(path string) ([]byte, error) {
+	relativePath, err := filepath.Rel(fr.mountPath, path)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("file %s is outside the bundle %s", path, fr.mountPath)
+	}
+
+	contents, found := fr.files[filepath.ToSlash(relativePath)]
+	if !found {
+		return nil, fmt.Errorf("file %s not found in bundle %s: %w", filepath.ToSlash(relativePath), fr.mountPath, os.ErrNotExist)
+	}
+	return contents, nil
+}
+
+// ResolveFileValue converts a value prefixed with "file:" and replaces it with the file contents, from the bundle.
+func (fr *BundleFileResolver) ResolveFileValue(value string) ([]byte, error) {
+	if len(value) == 0 {
+		return []byte{}, nil
+	}
+	contents, err := fr.ReadFile(fr.ResolveAbsolutePath(value))
+	if err != nil {
+		return []byte{}, err
+	}
+	return contents, nil
+}
@@ -0,0 +1,157 @@
+package scenfileresolver
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+var _ FileResolver = (*ManifestFileResolver)(nil)
+
+// ManifestFileResolver wraps another file resolver and checks the files it loads against a manifest of expected SHA-256 hashes,
+// so that tests fail clearly when a contract changed unexpectedly.
+// The manifest uses the sha256sum format: one "<hex hash>  <path>" line per file, with paths relative to the manifest.
+type ManifestFileResolver struct {
+	inner          FileResolver
+	manifestDir    string
+	expectedHashes map[string]string
+
+	// RequireListed makes the resolver also fail for files missing from the manifest.
+	RequireListed bool
+}
+
+// NewManifestFileResolver reads the manifest, through the inner resolver if it does not read from the file system,
+// and yields a resolver checking the files loaded by the inner resolver against it.
+func NewManifestFileResolver(inner FileResolver, manifestPath string) (*ManifestFileResolver, error) {
+	manifestPath, err := filepath.Abs(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest []byte
+	reader, isReader := inner.(FileReader)
+	if isReader {
+		manifest, err = reader.ReadFile(manifestPath)
+	} else {
+		manifest, err = ioutil.ReadFile(manifestPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	expectedHashes, err := ParseSHA256Manifest(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", manifestPath, err)
+	}
+
+	return &ManifestFileResolver{
+		inner:          inner,
+		manifestDir:    filepath.Dir(manifestPath),
+		expectedHashes: expectedHashes,
+	}, nil
+}
+
+// ParseSHA256Manifest reads the hex encoded hashes of a manifest in the sha256sum format, keyed by slash-separated path.
+// Empty lines and lines starting with # are ignored.
+// The path is the rest of the line after the hash and the mode, so it can contain spaces.
+func ParseSHA256Manifest(manifest []byte) (map[string]string, error) {
+	expectedHashes := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(manifest))
+	lineNr := 0
+	for scanner.Scan() {
+		lineNr++
+		line := strings.TrimLeft(strings.TrimSuffix(scanner.Text(), "\r"), " \t")
+		if len(strings.TrimSpace(line)) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// sha256sum separates the hash from the path with a space and the mode:
+		// another space for files read in text mode, a * for files read in binary mode
+		separatorIndex := strings.IndexByte(line, ' ')
+		if separatorIndex < 0 || len(line) < separatorIndex+3 || (line[separatorIndex+1] != ' ' && line[separatorIndex+1] != '*') {
+			return nil, fmt.Errorf("line %d: expected a hash and a path", lineNr)
+		}
+		hashStr := line[:separatorIndex]
+		hash := strings.ToLower(hashStr)
+		hashBytes, err := hex.DecodeString(hash)
+		if err != nil || len(hashBytes) != sha256.Size {
+			return nil, fmt.Errorf("line %d: invalid SHA-256 hash: %s", lineNr, hashStr)
+		}
+		path := filepath.ToSlash(filepath.Clean(line[separatorIndex+2:]))
+		expectedHashes[path] = hash
+	}
+
+	return expectedHashes, scanner.Err()
+}
+
+// Clone creates new instance of the same type.
+func (fr *ManifestFileResolver) Clone() FileResolver {
+	return &ManifestFileResolver{
+		inner:          fr.inner.Clone(),
+		manifestDir:    fr.manifestDir,
+		expectedHashes: fr.expectedHashes,
+		RequireListed:  fr.RequireListed,
+	}
+}
+
+// SetContext sets directory where the test runs, to help resolve relative paths.
+func (fr *ManifestFileResolver) SetContext(contextPath string) {
+	fr.inner.SetContext(contextPath)
+}
+
+// ResolveAbsolutePath yields absolute value based on context.
+func (fr *ManifestFileResolver) ResolveAbsolutePath(value string) string {
+	return fr.inner.ResolveAbsolutePath(value)
+}
+
+// ResolveFileValue yields the file contents, after checking them against the manifest.
+func (fr *ManifestFileResolver) ResolveFileValue(value string) ([]byte, error) {
+	contents, err := fr.inner.ResolveFileValue(value)
+	if err != nil || len(value) == 0 {
+		return contents, err
+	}
+
+	err = fr.checkFile(fr.inner.ResolveAbsolutePath(value), contents)
+	if err != nil {
+		return []byte{}, err
+	}
+	return contents, nil
+}
+
+// ReadFile yields the contents of a scenario file, through the inner resolver if it does not read from the file system.
+// Scenario files are not checked against the manifest.
+func (fr *ManifestFileResolver) ReadFile(path string) ([]byte, error) {
+	reader, isReader := fr.inner.(FileReader)
+	if isReader {
+		return reader.ReadFile(path)
+	}
+	return ioutil.ReadFile(path)
+}
+
+func (fr *ManifestFileResolver) checkFile(absolutePath string, contents []byte) error {
+	manifestPath := absolutePath
+	relativePath, err := filepath.Rel(fr.manifestDir, absolutePath)
+	if err == nil {
+		manifestPath = filepath.ToSlash(relativePath)
+	}
+
+	expectedHash, listed := fr.expectedHashes[manifestPath]
+	if !listed {
+		if fr.RequireListed {
+			return fmt.Errorf("file %s is not listed in the manifest", manifestPath)
+		}
+		return nil
+	}
+
+	actualHash := sha256.Sum256(contents)
+	if hex.EncodeToString(actualHash[:]) != expectedHash {
+		return fmt.Errorf("file %s changed unexpectedly: expected SHA-256 %s, got %s",
+			manifestPath, expectedHash, hex.EncodeToString(actualHash[:]))
+	}
+	return nil
+}
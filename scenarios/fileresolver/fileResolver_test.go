@@ -0,0 +1,193 @@
+package scenfileresolver
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testBundleFiles = map[string][]byte{
+	"output/adder.wasm":         []byte("adder code"),
+	"scenarios/adder.scen.json": []byte("{}"),
+}
+
+func writeTarBundle(t *testing.T, bundlePath string) {
+	bundleFile, err := os.Create(bundlePath)
+	require.Nil(t, err)
+	tarWriter := tar.NewWriter(bundleFile)
+	for name, contents := range testBundleFiles {
+		err = tarWriter.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(contents)),
+			Typeflag: tar.TypeReg,
+		})
+		require.Nil(t, err)
+		_, err = tarWriter.Write(contents)
+		require.Nil(t, err)
+	}
+	require.Nil(t, tarWriter.Close())
+	require.Nil(t, bundleFile.Close())
+}
+
+func writeZipBundle(t *testing.T, bundlePath string) {
+	bundleFile, err := os.Create(bundlePath)
+	require.Nil(t, err)
+	zipWriter := zip.NewWriter(bundleFile)
+	for name, contents := range testBundleFiles {
+		entryWriter, err := zipWriter.Create(name)
+		require.Nil(t, err)
+		_, err = entryWriter.Write(contents)
+		require.Nil(t, err)
+	}
+	require.Nil(t, zipWriter.Close())
+	require.Nil(t, bundleFile.Close())
+}
+
+func sha256Hex(contents []byte) string {
+	hash := sha256.Sum256(contents)
+	return hex.EncodeToString(hash[:])
+}
+
+func TestBundleFileResolver_Tar(t *testing.T) {
+	bundlePath := filepath.Join(t.TempDir(), "suite.tar")
+	writeTarBundle(t, bundlePath)
+
+	bundle, err := LoadBundleFileResolver(bundlePath)
+	require.Nil(t, err)
+	require.Equal(t, []string{
+		filepath.Join(bundlePath, "output", "adder.wasm"),
+		filepath.Join(bundlePath, "scenarios", "adder.scen.json"),
+	}, bundle.Paths())
+
+	bundle.SetContext(filepath.Join(bundlePath, "scenarios", "adder.scen.json"))
+	contents, err := bundle.ResolveFileValue("../output/adder.wasm")
+	require.Nil(t, err)
+	require.Equal(t, []byte("adder code"), contents)
+}
+
+func TestBundleFileResolver_Zip(t *testing.T) {
+	bundlePath := filepath.Join(t.TempDir(), "suite.zip")
+	writeZipBundle(t, bundlePath)
+
+	bundle, err := LoadBundleFileResolver(bundlePath)
+	require.Nil(t, err)
+
+	contents, err := bundle.ReadFile(filepath.Join(bundlePath, "scenarios", "adder.scen.json"))
+	require.Nil(t, err)
+	require.Equal(t, []byte("{}"), contents)
+
+	cloned := bundle.Clone()
+	cloned.SetContext(filepath.Join(bundlePath, "scenarios", "adder.scen.json"))
+	contents, err = cloned.ResolveFileValue("../output/adder.wasm")
+	require.Nil(t, err)
+	require.Equal(t, []byte("adder code"), contents)
+}
+
+func TestBundleFileResolver_MissingAndOutsideFiles(t *testing.T) {
+	bundle := NewBundleFileResolver("/bundle.tar", testBundleFiles)
+	bundle.SetContext("/bundle.tar/scenarios/adder.scen.json")
+
+	_, err := bundle.ResolveFileValue("../output/missing.wasm")
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	_, err = bundle.ResolveFileValue("../../adder.wasm")
+	require.EqualError(t, err, "file /adder.wasm is outside the bundle /bundle.tar")
+}
+
+func TestIsBundlePath(t *testing.T) {
+	require.True(t, IsBundlePath("suite.tar"))
+	require.True(t, IsBundlePath("suite.tar.gz"))
+	require.True(t, IsBundlePath("suite.tgz"))
+	require.True(t, IsBundlePath("suite.zip"))
+	require.False(t, IsBundlePath("adder.scen.json"))
+}
+
+func TestParseSHA256Manifest(t *testing.T) {
+	hash := sha256Hex([]byte("adder code"))
+	manifest := fmt.Sprintf("# contracts\n%s  output/adder.wasm\n\n%s *./other.wasm\n", hash, hash)
+
+	expectedHashes, err := ParseSHA256Manifest([]byte(manifest))
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{
+		"output/adder.wasm": hash,
+		"other.wasm":        hash,
+	}, expectedHashes)
+
+	_, err = ParseSHA256Manifest([]byte("abcd  output/adder.wasm"))
+	require.EqualError(t, err, "line 1: invalid SHA-256 hash: abcd")
+	_, err = ParseSHA256Manifest([]byte(hash + " output/adder.wasm"))
+	require.EqualError(t, err, "line 1: expected a hash and a path")
+	_, err = ParseSHA256Manifest([]byte(hash))
+	require.EqualError(t, err, "line 1: expected a hash and a path")
+}
+
+func TestParseSHA256Manifest_PathsWithSpaces(t *testing.T) {
+	hash := sha256Hex([]byte("adder code"))
+	manifest := fmt.Sprintf("%s  my contracts/adder v2.wasm\r\n%s *output/two  spaces.wasm\n", hash, hash)
+
+	expectedHashes, err := ParseSHA256Manifest([]byte(manifest))
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{
+		"my contracts/adder v2.wasm": hash,
+		"output/two  spaces.wasm":    hash,
+	}, expectedHashes)
+}
+
+func TestManifestFileResolver(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "output"), 0755))
+	require.Nil(t, os.MkdirAll(filepath.Join(dir, "scenarios"), 0755))
+	wasmPath := filepath.Join(dir, "output", "adder.wasm")
+	require.Nil(t, ioutil.WriteFile(wasmPath, []byte("adder code"), 0644))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "output", "other.wasm"), []byte("other code"), 0644))
+	manifestPath := filepath.Join(dir, "sha256sums.txt")
+	manifest := sha256Hex([]byte("adder code")) + "  output/adder.wasm\n"
+	require.Nil(t, ioutil.WriteFile(manifestPath, []byte(manifest), 0644))
+
+	resolver, err := NewManifestFileResolver(NewDefaultFileResolver(), manifestPath)
+	require.Nil(t, err)
+	resolver.SetContext(filepath.Join(dir, "scenarios", "adder.scen.json"))
+
+	contents, err := resolver.ResolveFileValue("../output/adder.wasm")
+	require.Nil(t, err)
+	require.Equal(t, []byte("adder code"), contents)
+
+	_, err = resolver.ResolveFileValue("../output/other.wasm")
+	require.Nil(t, err)
+	resolver.RequireListed = true
+	_, err = resolver.Clone().ResolveFileValue("../output/other.wasm")
+	require.EqualError(t, err, "file output/other.wasm is not listed in the manifest")
+
+	require.Nil(t, ioutil.WriteFile(wasmPath, []byte("changed code"), 0644))
+	_, err = resolver.ResolveFileValue("../output/adder.wasm")
+	require.EqualError(t, err, fmt.Sprintf("file output/adder.wasm changed unexpectedly: expected SHA-256 %s, got %s",
+		sha256Hex([]byte("adder code")), sha256Hex([]byte("changed code"))))
+}
+
+func TestManifestFileResolver_InBundle(t *testing.T) {
+	files := map[string][]byte{
+		"output/adder.wasm": []byte("adder code"),
+		"sha256sums.txt":    []byte(sha256Hex([]byte("other code")) + "  output/adder.wasm\n"),
+	}
+	bundle := NewBundleFileResolver("/bundle.zip", files)
+
+	resolver, err := NewManifestFileResolver(bundle, "/bundle.zip/sha256sums.txt")
+	require.Nil(t, err)
+	resolver.SetContext("/bundle.zip/scenarios/adder.scen.json")
+
+	_, err = resolver.ResolveFileValue("../output/adder.wasm")
+	require.Contains(t, err.Error(), "file output/adder.wasm changed unexpectedly")
+
+	contents, err := resolver.ReadFile("/bundle.zip/output/adder.wasm")
+	require.Nil(t, err)
+	require.Equal(t, []byte("adder code"), contents)
+}
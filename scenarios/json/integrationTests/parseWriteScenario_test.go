@@ -7,6 +7,7 @@ import (
 	fr "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/fileresolver"
 	mjparse "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/parse"
 	mjwrite "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/write"
+	oj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/orderedjson"
 	"github.com/stretchr/testify/require"
 )
 
@@ -29,3 +30,101 @@ func TestWriteScenario(t *testing.T) {
 
 	require.Equal(t, contents, []byte(serialized))
 }
+
+func TestWriteScenarioThroughYAML(t *testing.T) {
+	contents, err := loadExampleFile("example.scen.json")
+	require.Nil(t, err)
+
+	p := mjparse.NewParser(
+		fr.NewDefaultFileResolver().ReplacePath(
+			"smart-contract.wasm",
+			"exampleFile.txt"))
+
+	scenario, parseErr := p.ParseScenarioFile(contents)
+	require.Nil(t, parseErr)
+
+	yamlString, err := mjwrite.ScenarioToFormattedString(scenario, oj.FormatYAML)
+	require.Nil(t, err)
+
+	scenarioFromYAML, parseErr := p.ParseScenarioFileInFormat([]byte(yamlString), oj.FormatYAML)
+	require.Nil(t, parseErr)
+
+	serialized := mjwrite.ScenarioToJSONString(scenarioFromYAML)
+	require.Equal(t, contents, []byte(serialized))
+}
+
+func TestParseScenarioEnableEpochsFile(t *testing.T) {
+	contents := []byte(`{
+    "enableEpochs": "file:exampleFile.txt",
+    "steps": []
+}
+`)
+
+	p := mjparse.NewParser(fr.NewDefaultFileResolver())
+	scenario, parseErr := p.ParseScenarioFile(contents)
+	require.Nil(t, parseErr)
+	require.Equal(t, "file:exampleFile.txt", scenario.EnableEpochs.File.Original)
+	require.Equal(t, []byte("hello!"), scenario.EnableEpochs.File.Value)
+	require.Equal(t, string(contents), mjwrite.ScenarioToJSONString(scenario))
+}
+
+func TestParseScenarioWithComments(t *testing.T) {
+	contents := []byte(`
+	// a scenario with comments
+	{
+		"name": "comments", /* inline comment, with a "quote" */
+		"steps": [
+			{
+				"step": "setState",
+				"comment": "not // a comment",
+				"accounts": {
+					"address:owner": {
+						"nonce": "1", // trailing comma next
+					},
+				},
+			},
+		],
+	}
+	`)
+
+	p := mjparse.NewParser(fr.NewDefaultFileResolver())
+	scenario, parseErr := p.ParseScenarioFileInFormat(contents, oj.FormatJSONWithComments)
+	require.Nil(t, parseErr)
+	require.Equal(t, "comments", scenario.Name)
+	require.Len(t, scenario.Steps, 1)
+
+	_, parseErr = p.ParseScenarioFileInFormat(contents, oj.FormatJSON)
+	require.NotNil(t, parseErr)
+}
+
+func TestParseScenarioYAML(t *testing.T) {
+	contents := []byte(`
+# a scenario in YAML
+name: yaml
+checkGas: false
+steps:
+  - step: setState
+    accounts:
+      address:owner:
+        nonce: 1
+        balance: 0x100
+        storage: {}
+  - step: checkState
+    accounts:
+      address:owner:
+        nonce: "1"
+        balance: "256"
+      +: ""
+`)
+
+	p := mjparse.NewParser(fr.NewDefaultFileResolver())
+	scenario, parseErr := p.ParseScenarioFileInFormat(contents, oj.FormatYAML)
+	require.Nil(t, parseErr)
+	require.Equal(t, "yaml", scenario.Name)
+	require.False(t, scenario.CheckGas)
+	require.Len(t, scenario.Steps, 2)
+
+	serialized := mjwrite.ScenarioToJSONString(scenario)
+	require.Contains(t, serialized, `"balance": "0x100"`)
+	require.Contains(t, serialized, `"address:owner": {`)
+}
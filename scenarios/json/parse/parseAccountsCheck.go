@@ -25,6 +25,7 @@ func (p *Parser) processCheckAccount(acctRaw oj.OJsonObject) (*mj.CheckAccount,
 		CheckStorage:          nil,
 		Code:                  mj.JSONCheckBytesUnspecified(),
 		Owner:                 mj.JSONCheckBytesUnspecified(),
+		RootHash:              mj.JSONCheckBytesUnspecified(),
 		AsyncCallData:         mj.JSONCheckBytesUnspecified(),
 		IgnoreESDT:            false,
 		MoreESDTTokensAllowed: false,
@@ -117,6 +118,11 @@ func (p *Parser) processCheckAccount(acctRaw oj.OJsonObject) (*mj.CheckAccount,
 			if err != nil {
 				return nil, fmt.Errorf("invalid account owner: %w", err)
 			}
+		case "rootHash":
+			acct.RootHash, err = p.parseCheckBytes(kvp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid account root hash: %w", err)
+			}
 		case "asyncCallData":
 			acct.AsyncCallData, err = p.parseCheckBytes(kvp.Value)
 			if err != nil {
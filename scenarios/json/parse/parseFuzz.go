@@ -0,0 +1,210 @@
+package scenjsonparse
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	oj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/orderedjson"
+)
+
+// maxFuzzWeight bounds the template weights and their sum, so that the weighted choice can add them up as ints.
+const maxFuzzWeight = math.MaxInt32
+
+func (p *Parser) parseFuzzStep(stepMap *oj.OJsonMap) (*mj.FuzzStep, error) {
+	step := &mj.FuzzStep{}
+	var err error
+	for _, kvp := range stepMap.OrderedKV {
+		switch kvp.Key {
+		case "step":
+		case "id":
+			step.FuzzIdent, err = p.parseString(kvp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("bad fuzz step id: %w", err)
+			}
+		case "comment":
+			step.Comment, err = p.parseString(kvp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("bad fuzz step comment: %w", err)
+			}
+		case "seed":
+			step.Seed, err = p.processUint64(kvp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("bad fuzz step seed: %w", err)
+			}
+		case "count":
+			step.Count, err = p.processUint64(kvp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("bad fuzz step count: %w", err)
+			}
+		case "templates":
+			step.Templates, err = p.processFuzzTemplateList(kvp.Value)
+			if err != nil {
+				return nil, err
+			}
+		case "invariants":
+			step.Invariants, err = p.processFuzzInvariantList(kvp.Value)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("invalid fuzz step field: %s", kvp.Key)
+		}
+	}
+
+	if len(step.Templates) == 0 {
+		return nil, errors.New("fuzz step requires at least one template")
+	}
+	totalWeight := uint64(0)
+	for _, template := range step.Templates {
+		totalWeight += template.Weight.Value
+		if totalWeight > maxFuzzWeight {
+			return nil, fmt.Errorf("total fuzz template weight must not exceed %d", maxFuzzWeight)
+		}
+	}
+	return step, nil
+}
+
+func (p *Parser) processFuzzTemplateList(obj oj.OJsonObject) ([]*mj.FuzzTemplate, error) {
+	listRaw, isList := obj.(*oj.OJsonList)
+	if !isList {
+		return nil, errors.New("fuzz templates not a JSON list")
+	}
+
+	var templates []*mj.FuzzTemplate
+	for _, templateRaw := range listRaw.AsList() {
+		template, err := p.processFuzzTemplate(templateRaw)
+		if err != nil {
+			return nil, fmt.Errorf("bad fuzz template: %w", err)
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+func (p *Parser) processFuzzTemplate(obj oj.OJsonObject) (*mj.FuzzTemplate, error) {
+	templateMap, isMap := obj.(*oj.OJsonMap)
+	if !isMap {
+		return nil, errors.New("unmarshalled fuzz template is not a map")
+	}
+
+	template := &mj.FuzzTemplate{}
+	var err error
+	for _, kvp := range templateMap.OrderedKV {
+		switch kvp.Key {
+		case "weight":
+			template.Weight, err = p.processUint64(kvp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("bad weight: %w", err)
+			}
+		case "step":
+			step, err := p.processScenarioStep(kvp.Value)
+			if err != nil {
+				return nil, err
+			}
+			txStep, isTxStep := step.(*mj.TxStep)
+			if !isTxStep {
+				return nil, fmt.Errorf("fuzz template step must be a transaction, not %s", step.StepTypeName())
+			}
+			template.Step = txStep
+		case "candidates":
+			err = p.processFuzzCandidates(kvp.Value, template)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("invalid fuzz template field: %s", kvp.Key)
+		}
+	}
+
+	if template.Step == nil || template.Step.Tx == nil {
+		return nil, errors.New("missing transaction step")
+	}
+	if len(template.ArgumentCandidates) > len(template.Step.Tx.Arguments) {
+		return nil, errors.New("more argument candidate lists than template arguments")
+	}
+	if template.Weight.Value == 0 {
+		return nil, errors.New("weight must be positive")
+	}
+	if template.Weight.Value > maxFuzzWeight {
+		return nil, fmt.Errorf("weight must not exceed %d", maxFuzzWeight)
+	}
+	return template, nil
+}
+
+func (p *Parser) processFuzzCandidates(obj oj.OJsonObject, template *mj.FuzzTemplate) error {
+	candidatesMap, isMap := obj.(*oj.OJsonMap)
+	if !isMap {
+		return errors.New("unmarshalled fuzz candidates object is not a map")
+	}
+
+	for _, kvp := range candidatesMap.OrderedKV {
+		switch kvp.Key {
+		case "from":
+			fromList, err := p.processStringList(kvp.Value)
+			if err != nil {
+				return fmt.Errorf("bad from candidates: %w", err)
+			}
+			for _, fromStr := range fromList {
+				from, err := p.parseAccountAddress(fromStr)
+				if err != nil {
+					return err
+				}
+				template.FromCandidates = append(template.FromCandidates, from)
+			}
+		case "egldValue":
+			valueList, isList := kvp.Value.(*oj.OJsonList)
+			if !isList {
+				return errors.New("egldValue candidates not a JSON list")
+			}
+			for _, valueRaw := range valueList.AsList() {
+				value, err := p.processBigInt(valueRaw, bigIntUnsignedBytes)
+				if err != nil {
+					return fmt.Errorf("bad egldValue candidate: %w", err)
+				}
+				template.EGLDValueCandidates = append(template.EGLDValueCandidates, value)
+			}
+		case "arguments":
+			argumentsList, isList := kvp.Value.(*oj.OJsonList)
+			if !isList {
+				return errors.New("argument candidates not a JSON list")
+			}
+			for _, argCandidatesRaw := range argumentsList.AsList() {
+				argCandidates, err := p.parseSubTreeList(argCandidatesRaw)
+				if err != nil {
+					return fmt.Errorf("bad argument candidates: %w", err)
+				}
+				template.ArgumentCandidates = append(template.ArgumentCandidates, argCandidates)
+			}
+		default:
+			return fmt.Errorf("invalid fuzz candidates field: %s", kvp.Key)
+		}
+	}
+
+	return nil
+}
+
+func (p *Parser) processFuzzInvariantList(obj oj.OJsonObject) ([]*mj.CheckStateStep, error) {
+	listRaw, isList := obj.(*oj.OJsonList)
+	if !isList {
+		return nil, errors.New("fuzz invariants not a JSON list")
+	}
+
+	var invariants []*mj.CheckStateStep
+	for _, invariantRaw := range listRaw.AsList() {
+		invariantMap, isMap := invariantRaw.(*oj.OJsonMap)
+		if !isMap {
+			return nil, errors.New("unmarshalled fuzz invariant is not a map")
+		}
+		invariant, err := p.parseCheckStateStep(invariantMap)
+		if err != nil {
+			return nil, fmt.Errorf("bad fuzz invariant: %w", err)
+		}
+		if invariant.CheckAccounts == nil {
+			return nil, errors.New("fuzz invariant requires accounts")
+		}
+		invariants = append(invariants, invariant)
+	}
+	return invariants, nil
+}
@@ -10,7 +10,13 @@ import (
 
 // ParseScenarioFile converts a scenario json string to scenario object representation
 func (p *Parser) ParseScenarioFile(jsonString []byte) (*mj.Scenario, error) {
-	jobj, err := oj.ParseOrderedJSON(jsonString)
+	return p.ParseScenarioFileInFormat(jsonString, oj.FormatJSON)
+}
+
+// ParseScenarioFileInFormat converts a scenario, in any of the formats convertible to ordered JSON,
+// to scenario object representation.
+func (p *Parser) ParseScenarioFileInFormat(input []byte, format oj.Format) (*mj.Scenario, error) {
+	jobj, err := oj.ParseOrdered(input, format)
 	if err != nil {
 		return nil, err
 	}
@@ -50,11 +56,33 @@ func (p *Parser) ParseScenarioFile(jsonString []byte) (*mj.Scenario, error) {
 				return nil, errors.New("scenario traceGas flag is not boolean")
 			}
 			scenario.TraceGas = bool(*traceGasOJ)
+		case "stateTrie":
+			stateTrieOJ, isBool := kvp.Value.(*oj.OJsonBool)
+			if !isBool {
+				return nil, errors.New("scenario stateTrie flag is not boolean")
+			}
+			scenario.StateTrie = bool(*stateTrieOJ)
+		case "deriveContractAddresses":
+			deriveOJ, isBool := kvp.Value.(*oj.OJsonBool)
+			if !isBool {
+				return nil, errors.New("scenario deriveContractAddresses flag is not boolean")
+			}
+			scenario.DeriveContractAddresses = bool(*deriveOJ)
 		case "gasSchedule":
 			scenario.GasSchedule, err = p.parseGasSchedule(kvp.Value)
 			if err != nil {
 				return nil, fmt.Errorf("bad scenario gasSchedule: %w", err)
 			}
+		case "enableEpochs":
+			scenario.EnableEpochs, err = p.parseEnableEpochs(kvp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("bad scenario enableEpochs: %w", err)
+			}
+		case "randomSeedMaster":
+			scenario.RandomSeedMaster, err = p.processStringAsByteArray(kvp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("bad scenario randomSeedMaster: %w", err)
+			}
 		case "steps":
 			scenario.Steps, err = p.processScenarioStepList(kvp.Value)
 			if err != nil {
@@ -72,18 +100,7 @@ func (p *Parser) parseGasSchedule(value oj.OJsonObject) (mj.GasSchedule, error)
 	if err != nil {
 		return mj.GasScheduleDummy, fmt.Errorf("gasSchedule type not a string: %w", err)
 	}
-	switch gasScheduleStr {
-	case "default":
-		return mj.GasScheduleDefault, nil
-	case "dummy":
-		return mj.GasScheduleDummy, nil
-	case "v3":
-		return mj.GasScheduleV3, nil
-	case "v4":
-		return mj.GasScheduleV4, nil
-	default:
-		return mj.GasScheduleDummy, fmt.Errorf("invalid gasSchedule: %s", gasScheduleStr)
-	}
+	return mj.GasScheduleFromString(gasScheduleStr)
 }
 
 func (p *Parser) processScenarioStepList(obj interface{}) ([]mj.Step, error) {
@@ -210,45 +227,80 @@ func (p *Parser) processScenarioStep(stepObj oj.OJsonObject) (mj.Step, error) {
 		}
 		return step, nil
 	case mj.StepNameCheckState:
-		step := &mj.CheckStateStep{}
+		return p.parseCheckStateStep(stepMap)
+	case mj.StepNameDumpState:
+		step := &mj.DumpStateStep{}
 		for _, kvp := range stepMap.OrderedKV {
 			switch kvp.Key {
 			case "step":
-			case "id":
-				step.CheckStateIdent, err = p.parseString(kvp.Value)
-				if err != nil {
-					return nil, fmt.Errorf("bad check state step id: %w", err)
-				}
 			case "comment":
 				step.Comment, err = p.parseString(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("bad check state step comment: %w", err)
+					return nil, fmt.Errorf("bad dump state step comment: %w", err)
 				}
-			case "accounts":
-				step.CheckAccounts, err = p.processCheckAccountMap(kvp.Value)
+			case "path":
+				step.Path, err = p.parseString(kvp.Value)
+				if err != nil {
+					return nil, fmt.Errorf("bad dump state step path: %w", err)
+				}
+			case "checkState":
+				step.CheckState, err = p.parseBool(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("cannot parse check state step: %w", err)
+					return nil, fmt.Errorf("bad dump state step checkState flag: %w", err)
 				}
 			default:
-				return nil, fmt.Errorf("invalid check state field: %s", kvp.Key)
+				return nil, fmt.Errorf("invalid dump state field: %s", kvp.Key)
 			}
 		}
 		return step, nil
-	case mj.StepNameDumpState:
-		step := &mj.DumpStateStep{}
+	case mj.StepNameSaveState:
+		step := &mj.SaveStateStep{}
+		for _, kvp := range stepMap.OrderedKV {
+			switch kvp.Key {
+			case "step":
+			case "name":
+				step.StateName, err = p.parseString(kvp.Value)
+				if err != nil {
+					return nil, fmt.Errorf("bad save state step name: %w", err)
+				}
+			case "comment":
+				step.Comment, err = p.parseString(kvp.Value)
+				if err != nil {
+					return nil, fmt.Errorf("bad save state step comment: %w", err)
+				}
+			default:
+				return nil, fmt.Errorf("invalid save state field: %s", kvp.Key)
+			}
+		}
+		if len(step.StateName) == 0 {
+			return nil, errors.New("save state step requires a name")
+		}
+		return step, nil
+	case mj.StepNameRestoreState:
+		step := &mj.RestoreStateStep{}
 		for _, kvp := range stepMap.OrderedKV {
 			switch kvp.Key {
 			case "step":
+			case "name":
+				step.StateName, err = p.parseString(kvp.Value)
+				if err != nil {
+					return nil, fmt.Errorf("bad restore state step name: %w", err)
+				}
 			case "comment":
 				step.Comment, err = p.parseString(kvp.Value)
 				if err != nil {
-					return nil, fmt.Errorf("bad check state step comment: %w", err)
+					return nil, fmt.Errorf("bad restore state step comment: %w", err)
 				}
 			default:
-				return nil, fmt.Errorf("invalid check state field: %s", kvp.Key)
+				return nil, fmt.Errorf("invalid restore state field: %s", kvp.Key)
 			}
 		}
+		if len(step.StateName) == 0 {
+			return nil, errors.New("restore state step requires a name")
+		}
 		return step, nil
+	case mj.StepNameFuzz:
+		return p.parseFuzzStep(stepMap)
 	case mj.StepNameScCall:
 		return p.parseTxStep(mj.ScCall, stepMap)
 	case mj.StepNameScDeploy:
@@ -264,6 +316,41 @@ func (p *Parser) processScenarioStep(stepObj oj.OJsonObject) (mj.Step, error) {
 	}
 }
 
+func (p *Parser) parseCheckStateStep(stepMap *oj.OJsonMap) (*mj.CheckStateStep, error) {
+	step := &mj.CheckStateStep{
+		StateRootHash: mj.JSONCheckBytesUnspecified(),
+	}
+	var err error
+	for _, kvp := range stepMap.OrderedKV {
+		switch kvp.Key {
+		case "step":
+		case "id":
+			step.CheckStateIdent, err = p.parseString(kvp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("bad check state step id: %w", err)
+			}
+		case "comment":
+			step.Comment, err = p.parseString(kvp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("bad check state step comment: %w", err)
+			}
+		case "stateRootHash":
+			step.StateRootHash, err = p.parseCheckBytes(kvp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("bad check state step root hash: %w", err)
+			}
+		case "accounts":
+			step.CheckAccounts, err = p.processCheckAccountMap(kvp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse check state step: %w", err)
+			}
+		default:
+			return nil, fmt.Errorf("invalid check state field: %s", kvp.Key)
+		}
+	}
+	return step, nil
+}
+
 func (p *Parser) parseTxStep(txType mj.TransactionType, stepMap *oj.OJsonMap) (*mj.TxStep, error) {
 	step := &mj.TxStep{}
 	var err error
@@ -306,3 +393,33 @@ func (p *Parser) parseTxStep(txType mj.TransactionType, stepMap *oj.OJsonMap) (*
 	}
 	return step, nil
 }
+
+// parseEnableEpochs accepts either a TOML file, e.g. "file:enableEpochs.toml",
+// or a map of flag names to activation epochs.
+func (p *Parser) parseEnableEpochs(value oj.OJsonObject) (*mj.EnableEpochs, error) {
+	if _, isString := value.(*oj.OJsonString); isString {
+		file, err := p.processStringAsByteArray(value)
+		if err != nil {
+			return nil, err
+		}
+		return &mj.EnableEpochs{File: file}, nil
+	}
+
+	flagsMap, isMap := value.(*oj.OJsonMap)
+	if !isMap {
+		return nil, errors.New("enableEpochs is neither a file nor a map")
+	}
+
+	enableEpochs := &mj.EnableEpochs{}
+	for _, kvp := range flagsMap.OrderedKV {
+		epoch, err := p.processUint64(kvp.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid activation epoch for flag %s: %w", kvp.Key, err)
+		}
+		enableEpochs.Flags = append(enableEpochs.Flags, &mj.EnableEpochsFlag{
+			Name:  kvp.Key,
+			Epoch: epoch,
+		})
+	}
+	return enableEpochs, nil
+}
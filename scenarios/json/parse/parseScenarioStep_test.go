@@ -1,6 +1,7 @@
 package scenjsonparse
 
 import (
+	"strings"
 	"testing"
 
 	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
@@ -55,3 +56,174 @@ func TestParseScenario(t *testing.T) {
 	require.Equal(t, "scCall", step.StepTypeName())
 	require.Equal(t, true, step.(*mj.TxStep).DisplayLogs)
 }
+
+func TestParseSaveRestoreState(t *testing.T) {
+	p := Parser{}
+
+	step, parseErr := p.ParseScenarioStep(`{ "step": "saveState", "name": "after-setup" }`)
+	require.Nil(t, parseErr)
+	require.Equal(t, "saveState", step.StepTypeName())
+	require.Equal(t, "after-setup", step.(*mj.SaveStateStep).StateName)
+
+	step, parseErr = p.ParseScenarioStep(`{ "step": "restoreState", "name": "after-setup", "comment": "try again" }`)
+	require.Nil(t, parseErr)
+	require.Equal(t, "restoreState", step.StepTypeName())
+	require.Equal(t, "after-setup", step.(*mj.RestoreStateStep).StateName)
+	require.Equal(t, "try again", step.(*mj.RestoreStateStep).Comment)
+
+	_, parseErr = p.ParseScenarioStep(`{ "step": "restoreState" }`)
+	require.NotNil(t, parseErr)
+}
+
+func TestParseInternalError(t *testing.T) {
+	p := Parser{}
+	snippet := `
+	{
+		"step": "scCall",
+		"txId": "timeout",
+		"tx": {
+			"from": "address:owner",
+			"to": "sc:contract",
+			"function": "loopForever",
+			"arguments": [],
+			"gasLimit": "0x100000",
+			"gasPrice": "0"
+		},
+		"expect": {
+			"out": [],
+			"status": "10",
+			"internalError": "timeout"
+		}
+	}`
+
+	step, parseErr := p.ParseScenarioStep(snippet)
+	require.Nil(t, parseErr)
+	internalError := step.(*mj.TxStep).ExpectedResult.InternalError
+	require.False(t, internalError.IsUnspecified())
+	require.True(t, internalError.Check(mj.InternalErrorTimeout))
+	require.False(t, internalError.Check(mj.InternalErrorOutOfGas))
+
+	_, parseErr = p.ParseScenarioStep(strings.Replace(snippet, `"internalError": "timeout"`, `"internalError": "tooSlow"`, 1))
+	require.NotNil(t, parseErr)
+}
+
+func TestParseFuzzStep(t *testing.T) {
+	p := Parser{}
+	step, parseErr := p.ParseScenarioStep(`{
+		"step": "fuzz",
+		"id": "random-calls",
+		"seed": "12",
+		"count": "100",
+		"templates": [
+			{
+				"weight": "2",
+				"step": {
+					"step": "scCall",
+					"tx": {
+						"from": "address:A",
+						"to": "sc:adder",
+						"function": "add",
+						"arguments": ["0", "1"],
+						"gasLimit": "5,000,000",
+						"gasPrice": "0"
+					}
+				},
+				"candidates": {
+					"from": ["address:A", "address:B"],
+					"egldValue": ["0", "10"],
+					"arguments": [["1", "2", "3"]]
+				}
+			}
+		],
+		"invariants": [
+			{ "accounts": { "sc:adder": { "storage": "*", "code": "*" }, "+": "" } }
+		]
+	}`)
+	require.Nil(t, parseErr)
+	require.Equal(t, "fuzz", step.StepTypeName())
+
+	fuzzStep := step.(*mj.FuzzStep)
+	require.Equal(t, "random-calls", fuzzStep.FuzzIdent)
+	require.Equal(t, uint64(12), fuzzStep.Seed.Value)
+	require.Equal(t, uint64(100), fuzzStep.Count.Value)
+	require.Len(t, fuzzStep.Templates, 1)
+	template := fuzzStep.Templates[0]
+	require.Equal(t, uint64(2), template.Weight.Value)
+	require.Equal(t, "add", template.Step.Tx.Function)
+	require.Len(t, template.FromCandidates, 2)
+	require.Len(t, template.EGLDValueCandidates, 2)
+	require.Len(t, template.ArgumentCandidates, 1)
+	require.Len(t, template.ArgumentCandidates[0], 3)
+	require.Len(t, fuzzStep.Invariants, 1)
+}
+
+func TestParseFuzzStep_Errors(t *testing.T) {
+	transferStep := `{ "step": "transfer", "tx": { "from": "address:A", "to": "address:B", "egldValue": "1" } }`
+	testCases := []struct {
+		name     string
+		snippet  string
+		expected string
+	}{
+		{
+			name:     "no templates",
+			snippet:  `{ "step": "fuzz", "seed": "1", "count": "1", "templates": [] }`,
+			expected: "at least one template",
+		},
+		{
+			name:     "zero weight",
+			snippet:  `{ "step": "fuzz", "templates": [{ "weight": "0", "step": ` + transferStep + ` }] }`,
+			expected: "weight must be positive",
+		},
+		{
+			name:     "weight too large",
+			snippet:  `{ "step": "fuzz", "templates": [{ "weight": "0xffffffffffffffff", "step": ` + transferStep + ` }] }`,
+			expected: "weight must not exceed 2147483647",
+		},
+		{
+			name: "total weight too large",
+			snippet: `{ "step": "fuzz", "templates": [` +
+				`{ "weight": "2000000000", "step": ` + transferStep + ` }, ` +
+				`{ "weight": "2000000000", "step": ` + transferStep + ` }] }`,
+			expected: "total fuzz template weight must not exceed 2147483647",
+		},
+		{
+			name:     "not a transaction",
+			snippet:  `{ "step": "fuzz", "templates": [{ "weight": "1", "step": { "step": "saveState", "name": "x" } }] }`,
+			expected: "must be a transaction",
+		},
+		{
+			name:     "missing step",
+			snippet:  `{ "step": "fuzz", "templates": [{ "weight": "1" }] }`,
+			expected: "missing transaction step",
+		},
+		{
+			name:     "too many argument candidates",
+			snippet:  `{ "step": "fuzz", "templates": [{ "weight": "1", "step": ` + transferStep + `, "candidates": { "arguments": [["1"]] } }] }`,
+			expected: "more argument candidate lists than template arguments",
+		},
+		{
+			name:     "unknown candidates field",
+			snippet:  `{ "step": "fuzz", "templates": [{ "weight": "1", "step": ` + transferStep + `, "candidates": { "to": [] } }] }`,
+			expected: "invalid fuzz candidates field",
+		},
+		{
+			name:     "invariant without accounts",
+			snippet:  `{ "step": "fuzz", "templates": [{ "weight": "1", "step": ` + transferStep + ` }], "invariants": [{ "comment": "x" }] }`,
+			expected: "fuzz invariant requires accounts",
+		},
+		{
+			name:     "unknown field",
+			snippet:  `{ "step": "fuzz", "seeds": "1", "templates": [{ "weight": "1", "step": ` + transferStep + ` }] }`,
+			expected: "invalid fuzz step field",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			p := Parser{}
+			_, parseErr := p.ParseScenarioStep(testCase.snippet)
+			require.NotNil(t, parseErr)
+			require.Contains(t, parseErr.Error(), testCase.expected)
+		})
+	}
+}
@@ -15,11 +15,12 @@ func (p *Parser) processTxExpectedResult(blrRaw oj.OJsonObject) (*mj.Transaction
 	}
 
 	blr := mj.TransactionResult{
-		Status:  mj.JSONCheckBigIntUnspecified(),
-		Message: mj.JSONCheckBytesUnspecified(),
-		Gas:     mj.JSONCheckUint64Unspecified(),
-		Refund:  mj.JSONCheckBigIntUnspecified(),
-		Logs:    mj.LogList{IsUnspecified: true, IsStar: true},
+		Status:        mj.JSONCheckBigIntUnspecified(),
+		Message:       mj.JSONCheckBytesUnspecified(),
+		InternalError: mj.JSONCheckInternalErrorUnspecified(),
+		Gas:           mj.JSONCheckUint64Unspecified(),
+		Refund:        mj.JSONCheckBigIntUnspecified(),
+		Logs:          mj.LogList{IsUnspecified: true, IsStar: true},
 	}
 	var err error
 	for _, kvp := range blrMap.OrderedKV {
@@ -39,6 +40,11 @@ func (p *Parser) processTxExpectedResult(blrRaw oj.OJsonObject) (*mj.Transaction
 			if err != nil {
 				return nil, fmt.Errorf("invalid block result message: %w", err)
 			}
+		case "internalError":
+			blr.InternalError, err = p.processCheckInternalError(kvp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid block result internal error: %w", err)
+			}
 		case "logs":
 			blr.Logs, err = p.processLogList(kvp.Value)
 			if err != nil {
@@ -54,6 +60,11 @@ func (p *Parser) processTxExpectedResult(blrRaw oj.OJsonObject) (*mj.Transaction
 			if err != nil {
 				return nil, fmt.Errorf("invalid block result refund: %w", err)
 			}
+		case "randomValues":
+			blr.RandomValues, err = p.parseValueList(kvp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid block result random values: %w", err)
+			}
 		default:
 			return nil, fmt.Errorf("unknown tx result field: %s", kvp.Key)
 		}
@@ -61,3 +72,20 @@ func (p *Parser) processTxExpectedResult(blrRaw oj.OJsonObject) (*mj.Transaction
 
 	return &blr, nil
 }
+
+func (p *Parser) processCheckInternalError(obj oj.OJsonObject) (mj.JSONCheckInternalError, error) {
+	if IsStar(obj) {
+		return mj.JSONCheckInternalError{IsStar: true}, nil
+	}
+
+	strVal, err := p.parseString(obj)
+	if err != nil {
+		return mj.JSONCheckInternalError{}, err
+	}
+
+	if !mj.IsInternalErrorCategory(strVal) {
+		return mj.JSONCheckInternalError{}, fmt.Errorf("unknown internal error category: %s", strVal)
+	}
+
+	return mj.JSONCheckInternalError{Category: strVal}, nil
+}
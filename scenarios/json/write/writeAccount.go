@@ -98,6 +98,9 @@ func checkAccountsToOJ(checkAccounts *mj.CheckAccounts) oj.OJsonObject {
 		if !checkAccount.Owner.IsUnspecified() {
 			acctOJ.Put("owner", checkBytesToOJ(checkAccount.Owner))
 		}
+		if !checkAccount.RootHash.IsUnspecified() {
+			acctOJ.Put("rootHash", checkBytesToOJ(checkAccount.RootHash))
+		}
 		if !checkAccount.DeveloperReward.IsUnspecified() {
 			acctOJ.Put("developerRewards", checkBigIntToOJ(checkAccount.DeveloperReward))
 		}
@@ -18,6 +18,9 @@ func resultToOJ(res *mj.TransactionResult) oj.OJsonObject {
 	if !res.Message.IsUnspecified() {
 		resultOJ.Put("message", checkBytesToOJ(res.Message))
 	}
+	if !res.InternalError.IsUnspecified() {
+		resultOJ.Put("internalError", stringToOJ(res.InternalError.String()))
+	}
 	if !res.Logs.IsUnspecified {
 		if res.Logs.IsStar {
 			resultOJ.Put("logs", stringToOJ("*"))
@@ -32,6 +35,9 @@ func resultToOJ(res *mj.TransactionResult) oj.OJsonObject {
 	if !res.Refund.IsUnspecified() {
 		resultOJ.Put("refund", checkBigIntToOJ(res.Refund))
 	}
+	if !res.RandomValues.IsUnspecified() {
+		resultOJ.Put("randomValues", valueListToOJ(res.RandomValues))
+	}
 
 	return resultOJ
 }
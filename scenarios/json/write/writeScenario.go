@@ -11,6 +11,19 @@ func ScenarioToJSONString(scenario *mj.Scenario) string {
 	return oj.JSONString(jobj) + "\n"
 }
 
+// ScenarioToFormattedString converts a scenario object to its representation in the given format.
+func ScenarioToFormattedString(scenario *mj.Scenario, format oj.Format) (string, error) {
+	if format != oj.FormatYAML {
+		return ScenarioToJSONString(scenario), nil
+	}
+	return oj.YAMLString(ScenarioToOrderedJSON(scenario))
+}
+
+// ScenarioStepToJSONString converts a single scenario step to its JSON representation.
+func ScenarioStepToJSONString(step mj.Step) string {
+	return oj.JSONString(stepToOJ(step))
+}
+
 // ScenarioToOrderedJSON converts a scenario object to an ordered JSON object.
 func ScenarioToOrderedJSON(scenario *mj.Scenario) oj.OJsonObject {
 	scenarioOJ := oj.NewMap()
@@ -33,78 +46,194 @@ func ScenarioToOrderedJSON(scenario *mj.Scenario) oj.OJsonObject {
 		scenarioOJ.Put("traceGas", &ojTrue)
 	}
 
+	if scenario.StateTrie {
+		ojTrue := oj.OJsonBool(true)
+		scenarioOJ.Put("stateTrie", &ojTrue)
+	}
+
+	if scenario.DeriveContractAddresses {
+		ojTrue := oj.OJsonBool(true)
+		scenarioOJ.Put("deriveContractAddresses", &ojTrue)
+	}
+
 	if scenario.GasSchedule != mj.GasScheduleDefault {
 		scenarioOJ.Put("gasSchedule", gasScheduleToOJ(scenario.GasSchedule))
 	}
 
+	if scenario.EnableEpochs != nil {
+		scenarioOJ.Put("enableEpochs", enableEpochsToOJ(scenario.EnableEpochs))
+	}
+
+	if len(scenario.RandomSeedMaster.Original) > 0 {
+		scenarioOJ.Put("randomSeedMaster", bytesFromStringToOJ(scenario.RandomSeedMaster))
+	}
+
 	var stepOJList []oj.OJsonObject
 
 	for _, generalStep := range scenario.Steps {
-		stepOJ := oj.NewMap()
-		stepOJ.Put("step", stringToOJ(generalStep.StepTypeName()))
-		switch step := generalStep.(type) {
-		case *mj.ExternalStepsStep:
-			if len(step.Comment) > 0 {
-				stepOJ.Put("comment", stringToOJ(step.Comment))
-			}
+		stepOJList = append(stepOJList, stepToOJ(generalStep))
+	}
+
+	stepsOJ := oj.OJsonList(stepOJList)
+	scenarioOJ.Put("steps", &stepsOJ)
+
+	return scenarioOJ
+}
+
+func stepToOJ(generalStep mj.Step) oj.OJsonObject {
+	stepOJ := oj.NewMap()
+	stepOJ.Put("step", stringToOJ(generalStep.StepTypeName()))
+	switch step := generalStep.(type) {
+	case *mj.ExternalStepsStep:
+		if len(step.Comment) > 0 {
+			stepOJ.Put("comment", stringToOJ(step.Comment))
+		}
+		stepOJ.Put("path", stringToOJ(step.Path))
+	case *mj.SetStateStep:
+		if len(step.SetStateIdent) > 0 {
+			stepOJ.Put("id", stringToOJ(step.SetStateIdent))
+		}
+		if len(step.Comment) > 0 {
+			stepOJ.Put("comment", stringToOJ(step.Comment))
+		}
+		if len(step.Accounts) > 0 {
+			stepOJ.Put("accounts", AccountsToOJ(step.Accounts))
+		}
+		if len(step.NewAddressMocks) > 0 {
+			stepOJ.Put("newAddresses", newAddressMocksToOJ(step.NewAddressMocks))
+		}
+		if step.PreviousBlockInfo != nil {
+			stepOJ.Put("previousBlockInfo", blockInfoToOJ(step.PreviousBlockInfo))
+		}
+		if step.CurrentBlockInfo != nil {
+			stepOJ.Put("currentBlockInfo", blockInfoToOJ(step.CurrentBlockInfo))
+		}
+		if !step.BlockHashes.IsUnspecified() {
+			stepOJ.Put("blockHashes", valueListToOJ(step.BlockHashes))
+		}
+	case *mj.CheckStateStep:
+		putCheckStateFields(stepOJ, step)
+	case *mj.DumpStateStep:
+		if len(step.Comment) > 0 {
+			stepOJ.Put("comment", stringToOJ(step.Comment))
+		}
+		if len(step.Path) > 0 {
 			stepOJ.Put("path", stringToOJ(step.Path))
-		case *mj.SetStateStep:
-			if len(step.SetStateIdent) > 0 {
-				stepOJ.Put("id", stringToOJ(step.SetStateIdent))
-			}
-			if len(step.Comment) > 0 {
-				stepOJ.Put("comment", stringToOJ(step.Comment))
-			}
-			if len(step.Accounts) > 0 {
-				stepOJ.Put("accounts", AccountsToOJ(step.Accounts))
-			}
-			if len(step.NewAddressMocks) > 0 {
-				stepOJ.Put("newAddresses", newAddressMocksToOJ(step.NewAddressMocks))
-			}
-			if step.PreviousBlockInfo != nil {
-				stepOJ.Put("previousBlockInfo", blockInfoToOJ(step.PreviousBlockInfo))
-			}
-			if step.CurrentBlockInfo != nil {
-				stepOJ.Put("currentBlockInfo", blockInfoToOJ(step.CurrentBlockInfo))
-			}
-			if !step.BlockHashes.IsUnspecified() {
-				stepOJ.Put("blockHashes", valueListToOJ(step.BlockHashes))
-			}
-		case *mj.CheckStateStep:
-			if len(step.CheckStateIdent) > 0 {
-				stepOJ.Put("id", stringToOJ(step.CheckStateIdent))
-			}
-			if len(step.Comment) > 0 {
-				stepOJ.Put("comment", stringToOJ(step.Comment))
-			}
-			stepOJ.Put("accounts", checkAccountsToOJ(step.CheckAccounts))
-		case *mj.DumpStateStep:
-			if len(step.Comment) > 0 {
-				stepOJ.Put("comment", stringToOJ(step.Comment))
-			}
-		case *mj.TxStep:
-			if len(step.TxIdent) > 0 {
-				stepOJ.Put("id", stringToOJ(step.TxIdent))
-			}
-			if len(step.Comment) > 0 {
-				stepOJ.Put("comment", stringToOJ(step.Comment))
+		}
+		if step.CheckState {
+			stepOJ.Put("checkState", boolToOJ(step.CheckState))
+		}
+	case *mj.SaveStateStep:
+		stepOJ.Put("name", stringToOJ(step.StateName))
+		if len(step.Comment) > 0 {
+			stepOJ.Put("comment", stringToOJ(step.Comment))
+		}
+	case *mj.RestoreStateStep:
+		stepOJ.Put("name", stringToOJ(step.StateName))
+		if len(step.Comment) > 0 {
+			stepOJ.Put("comment", stringToOJ(step.Comment))
+		}
+	case *mj.FuzzStep:
+		if len(step.FuzzIdent) > 0 {
+			stepOJ.Put("id", stringToOJ(step.FuzzIdent))
+		}
+		if len(step.Comment) > 0 {
+			stepOJ.Put("comment", stringToOJ(step.Comment))
+		}
+		stepOJ.Put("seed", uint64ToOJ(step.Seed))
+		stepOJ.Put("count", uint64ToOJ(step.Count))
+		stepOJ.Put("templates", fuzzTemplatesToOJ(step.Templates))
+		if len(step.Invariants) > 0 {
+			stepOJ.Put("invariants", fuzzInvariantsToOJ(step.Invariants))
+		}
+	case *mj.TxStep:
+		if len(step.TxIdent) > 0 {
+			stepOJ.Put("id", stringToOJ(step.TxIdent))
+		}
+		if len(step.Comment) > 0 {
+			stepOJ.Put("comment", stringToOJ(step.Comment))
+		}
+		if step.DisplayLogs {
+			stepOJ.Put("displayLogs", boolToOJ(step.DisplayLogs))
+		}
+		stepOJ.Put("tx", transactionToScenarioOJ(step.Tx))
+		if step.Tx.Type.IsSmartContractTx() && step.ExpectedResult != nil {
+			stepOJ.Put("expect", resultToOJ(step.ExpectedResult))
+		}
+	}
+
+	return stepOJ
+}
+
+func putCheckStateFields(stepOJ *oj.OJsonMap, step *mj.CheckStateStep) {
+	if len(step.CheckStateIdent) > 0 {
+		stepOJ.Put("id", stringToOJ(step.CheckStateIdent))
+	}
+	if len(step.Comment) > 0 {
+		stepOJ.Put("comment", stringToOJ(step.Comment))
+	}
+	if !step.StateRootHash.IsUnspecified() {
+		stepOJ.Put("stateRootHash", checkBytesToOJ(step.StateRootHash))
+	}
+	stepOJ.Put("accounts", checkAccountsToOJ(step.CheckAccounts))
+}
+
+func fuzzTemplatesToOJ(templates []*mj.FuzzTemplate) oj.OJsonObject {
+	var templateList []oj.OJsonObject
+	for _, template := range templates {
+		templateOJ := oj.NewMap()
+		templateOJ.Put("weight", uint64ToOJ(template.Weight))
+		templateOJ.Put("step", stepToOJ(template.Step))
+
+		candidatesOJ := oj.NewMap()
+		if len(template.FromCandidates) > 0 {
+			var fromList []oj.OJsonObject
+			for _, from := range template.FromCandidates {
+				fromList = append(fromList, bytesFromStringToOJ(from))
 			}
-			if step.DisplayLogs {
-				stepOJ.Put("displayLogs", boolToOJ(step.DisplayLogs))
+			fromOJ := oj.OJsonList(fromList)
+			candidatesOJ.Put("from", &fromOJ)
+		}
+		if len(template.EGLDValueCandidates) > 0 {
+			var valueList []oj.OJsonObject
+			for _, value := range template.EGLDValueCandidates {
+				valueList = append(valueList, bigIntToOJ(value))
 			}
-			stepOJ.Put("tx", transactionToScenarioOJ(step.Tx))
-			if step.Tx.Type.IsSmartContractTx() && step.ExpectedResult != nil {
-				stepOJ.Put("expect", resultToOJ(step.ExpectedResult))
+			valueOJ := oj.OJsonList(valueList)
+			candidatesOJ.Put("egldValue", &valueOJ)
+		}
+		if len(template.ArgumentCandidates) > 0 {
+			var argumentsList []oj.OJsonObject
+			for _, argCandidates := range template.ArgumentCandidates {
+				var argList []oj.OJsonObject
+				for _, arg := range argCandidates {
+					argList = append(argList, bytesFromTreeToOJ(arg))
+				}
+				argOJ := oj.OJsonList(argList)
+				argumentsList = append(argumentsList, &argOJ)
 			}
+			argumentsOJ := oj.OJsonList(argumentsList)
+			candidatesOJ.Put("arguments", &argumentsOJ)
+		}
+		if candidatesOJ.Size() > 0 {
+			templateOJ.Put("candidates", candidatesOJ)
 		}
 
-		stepOJList = append(stepOJList, stepOJ)
+		templateList = append(templateList, templateOJ)
 	}
+	templatesOJ := oj.OJsonList(templateList)
+	return &templatesOJ
+}
 
-	stepsOJ := oj.OJsonList(stepOJList)
-	scenarioOJ.Put("steps", &stepsOJ)
-
-	return scenarioOJ
+func fuzzInvariantsToOJ(invariants []*mj.CheckStateStep) oj.OJsonObject {
+	var invariantList []oj.OJsonObject
+	for _, invariant := range invariants {
+		invariantOJ := oj.NewMap()
+		putCheckStateFields(invariantOJ, invariant)
+		invariantList = append(invariantList, invariantOJ)
+	}
+	invariantsOJ := oj.OJsonList(invariantList)
+	return &invariantsOJ
 }
 
 func transactionToScenarioOJ(tx *mj.Transaction) oj.OJsonObject {
@@ -183,6 +312,18 @@ func blockInfoToOJ(blockInfo *mj.BlockInfo) oj.OJsonObject {
 	return blockInfoOJ
 }
 
+func enableEpochsToOJ(enableEpochs *mj.EnableEpochs) oj.OJsonObject {
+	if len(enableEpochs.File.Original) > 0 {
+		return bytesFromStringToOJ(enableEpochs.File)
+	}
+
+	flagsOJ := oj.NewMap()
+	for _, flag := range enableEpochs.Flags {
+		flagsOJ.Put(flag.Name, uint64ToOJ(flag.Epoch))
+	}
+	return flagsOJ
+}
+
 func gasScheduleToOJ(gasSchedule mj.GasSchedule) oj.OJsonObject {
 	switch gasSchedule {
 	case mj.GasScheduleDefault:
@@ -0,0 +1,269 @@
+package scenlint
+
+import (
+	"bytes"
+	"fmt"
+
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+)
+
+// accountReferences holds the values in a scenario that can contain addresses.
+type accountReferences struct {
+	values       [][]byte
+	newAddresses map[string]struct{}
+}
+
+func (refs *accountReferences) add(value []byte) {
+	if len(value) > 0 {
+		refs.values = append(refs.values, value)
+	}
+}
+
+func (refs *accountReferences) addTx(tx *mj.Transaction) {
+	refs.add(tx.From.Value)
+	refs.add(tx.To.Value)
+	for _, argument := range tx.Arguments {
+		refs.add(argument.Value)
+	}
+}
+
+// contains is true if the address appears in any of the values, possibly encoded together with other data.
+func (refs *accountReferences) contains(address []byte) bool {
+	if _, isNewAddress := refs.newAddresses[string(address)]; isNewAddress {
+		return true
+	}
+	for _, value := range refs.values {
+		if bytes.Contains(value, address) {
+			return true
+		}
+	}
+	return false
+}
+
+// collectReferences gathers the values referencing accounts, from all the steps.
+// The addresses of the accounts in setState steps are not references, but everything else about them is.
+func collectReferences(steps []*locatedStep, includeCheckState bool) *accountReferences {
+	refs := &accountReferences{
+		newAddresses: make(map[string]struct{}),
+	}
+	for _, located := range steps {
+		switch step := located.step.(type) {
+		case *mj.SetStateStep:
+			for _, account := range step.Accounts {
+				refs.add(account.Owner.Value)
+				for _, storageKvp := range account.Storage {
+					refs.add(storageKvp.Key.Value)
+					refs.add(storageKvp.Value.Value)
+				}
+			}
+			for _, newAddressMock := range step.NewAddressMocks {
+				refs.add(newAddressMock.CreatorAddress.Value)
+				refs.newAddresses[string(newAddressMock.NewAddress.Value)] = struct{}{}
+			}
+		case *mj.TxStep:
+			refs.addTx(step.Tx)
+		case *mj.FuzzStep:
+			for _, template := range step.Templates {
+				refs.addTx(template.Step.Tx)
+				for _, candidate := range template.FromCandidates {
+					refs.add(candidate.Value)
+				}
+				for _, candidates := range template.ArgumentCandidates {
+					for _, candidate := range candidates {
+						refs.add(candidate.Value)
+					}
+				}
+			}
+			if includeCheckState {
+				for _, invariant := range step.Invariants {
+					addCheckStateReferences(refs, invariant)
+				}
+			}
+		case *mj.CheckStateStep:
+			if includeCheckState {
+				addCheckStateReferences(refs, step)
+			}
+		}
+	}
+
+	return refs
+}
+
+func addCheckStateReferences(refs *accountReferences, step *mj.CheckStateStep) {
+	for _, checkAccount := range step.CheckAccounts.Accounts {
+		refs.add(checkAccount.Address.Value)
+		refs.add(checkAccount.Owner.Value)
+		for _, checkStorage := range checkAccount.CheckStorage {
+			refs.add(checkStorage.Key.Value)
+			refs.add(checkStorage.CheckValue.Value)
+		}
+	}
+}
+
+// accountUsage records whether an account set in a setState step is used by any of the scenarios running that step.
+type accountUsage struct {
+	filePath string
+	address  string
+	used     bool
+}
+
+// recordAccountUsage marks which of the accounts created in setState steps are referred to by other steps.
+// An account set in an external steps file only needs to be used by one of the scenarios including it.
+func (linter *Linter) recordAccountUsage(steps []*locatedStep) {
+	refs := collectReferences(steps, true)
+
+	for _, located := range steps {
+		setStateStep, isSetState := located.step.(*mj.SetStateStep)
+		if !isSetState {
+			continue
+		}
+		for _, account := range setStateStep.Accounts {
+			usageKey := located.filePath + "|" + string(account.Address.Value)
+			usage, found := linter.accountUsages[usageKey]
+			if !found {
+				usage = &accountUsage{
+					filePath: located.filePath,
+					address:  account.Address.Original,
+				}
+				linter.accountUsages[usageKey] = usage
+				linter.accountUsageOrder = append(linter.accountUsageOrder, usageKey)
+			}
+			if refs.contains(account.Address.Value) {
+				usage.used = true
+			}
+		}
+	}
+}
+
+// takeUnusedAccountFindings reports the accounts that none of the linted scenarios use,
+// each only once, and then forgets them.
+func (linter *Linter) takeUnusedAccountFindings() []*Finding {
+	var findings []*Finding
+	for _, usageKey := range linter.accountUsageOrder {
+		usage := linter.accountUsages[usageKey]
+		if usage.used {
+			continue
+		}
+		findings = append(findings, &Finding{
+			FilePath: usage.filePath,
+			Check:    CheckUnusedAccount,
+			Message:  fmt.Sprintf("account %s is set, but never used", usage.address),
+		})
+	}
+	linter.accountUsages = make(map[string]*accountUsage)
+	linter.accountUsageOrder = nil
+
+	return findings
+}
+
+// checkImpossibleCheckStates reports the checkState steps that cannot pass, whatever the transactions do:
+// they expect accounts that nothing could create, they omit accounts that exist
+// without allowing more accounts, or they expect account nonces to have decreased.
+func checkImpossibleCheckStates(steps []*locatedStep) []*Finding {
+	refs := collectReferences(steps, false)
+
+	var findings []*Finding
+	accountNonces := make(map[string]uint64)
+	var accountOrder []string
+	savedStates := make(map[string]map[string]uint64)
+
+	checkCheckState := func(located *locatedStep, step *mj.CheckStateStep) {
+		stepName := checkStateName(step)
+		addFinding := func(message string) {
+			findings = append(findings, &Finding{
+				FilePath: located.filePath,
+				Check:    CheckImpossibleCheckState,
+				Message:  fmt.Sprintf("%s %s", stepName, message),
+			})
+		}
+
+		for _, checkAccount := range step.CheckAccounts.Accounts {
+			address := string(checkAccount.Address.Value)
+			nonce, exists := accountNonces[address]
+			if !exists && !refs.contains(checkAccount.Address.Value) {
+				addFinding(fmt.Sprintf("expects account %s, which is never created", checkAccount.Address.Original))
+			}
+			if exists && !checkAccount.Nonce.IsUnspecified() && !checkAccount.Nonce.IsStar &&
+				checkAccount.Nonce.Value < nonce {
+				addFinding(fmt.Sprintf("expects nonce %d for account %s, but it is already %d",
+					checkAccount.Nonce.Value, checkAccount.Address.Original, nonce))
+			}
+		}
+
+		if step.CheckAccounts.MoreAccountsAllowed {
+			return
+		}
+		for _, address := range accountOrder {
+			if _, exists := accountNonces[address]; !exists {
+				continue
+			}
+			if mj.FindCheckAccount(step.CheckAccounts.Accounts, []byte(address)) == nil {
+				addFinding(fmt.Sprintf("does not list account %s, which exists, and does not allow other accounts",
+					addressPretty(steps, address)))
+			}
+		}
+	}
+
+	for _, located := range steps {
+		switch step := located.step.(type) {
+		case *mj.SetStateStep:
+			for _, account := range step.Accounts {
+				address := string(account.Address.Value)
+				_, exists := accountNonces[address]
+				if !exists {
+					accountOrder = append(accountOrder, address)
+				}
+				if exists && account.Update && len(account.Nonce.Original) == 0 {
+					continue
+				}
+				accountNonces[address] = account.Nonce.Value
+			}
+		case *mj.SaveStateStep:
+			savedStates[step.StateName] = copyNonces(accountNonces)
+		case *mj.RestoreStateStep:
+			savedNonces, found := savedStates[step.StateName]
+			if found {
+				accountNonces = copyNonces(savedNonces)
+			}
+		case *mj.CheckStateStep:
+			checkCheckState(located, step)
+		case *mj.FuzzStep:
+			for _, invariant := range step.Invariants {
+				checkCheckState(located, invariant)
+			}
+		}
+	}
+
+	return findings
+}
+
+func checkStateName(step *mj.CheckStateStep) string {
+	if len(step.CheckStateIdent) > 0 {
+		return fmt.Sprintf("checkState \"%s\"", step.CheckStateIdent)
+	}
+	return "checkState"
+}
+
+// addressPretty yields the address as written in the first setState that mentions it.
+func addressPretty(steps []*locatedStep, address string) string {
+	for _, located := range steps {
+		setStateStep, isSetState := located.step.(*mj.SetStateStep)
+		if !isSetState {
+			continue
+		}
+		for _, account := range setStateStep.Accounts {
+			if string(account.Address.Value) == address {
+				return account.Address.Original
+			}
+		}
+	}
+	return fmt.Sprintf("0x%x", address)
+}
+
+func copyNonces(nonces map[string]uint64) map[string]uint64 {
+	nonceCopy := make(map[string]uint64, len(nonces))
+	for address, nonce := range nonces {
+		nonceCopy[address] = nonce
+	}
+	return nonceCopy
+}
@@ -0,0 +1,135 @@
+package scenlint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+)
+
+// gasProfileFileSuffix is the suffix of the gas profile files written by the scenario executor.
+const gasProfileFileSuffix = ".gasprofile.json"
+
+// gasProfile holds the fields of the executor gas profiles that the linter needs.
+type gasProfile struct {
+	Scenario string `json:"scenario"`
+	Steps    []struct {
+		TxID    string `json:"txId"`
+		GasUsed uint64 `json:"gasUsed"`
+	} `json:"steps"`
+}
+
+// LoadGasProfiles reads the gas used by each tx from the gas profiles in a directory and its subdirectories,
+// as written by the scenario executor.
+func (linter *Linter) LoadGasProfiles(dirPath string) error {
+	var profilePaths []string
+	err := filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, gasProfileFileSuffix) {
+			profilePaths = append(profilePaths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, profilePath := range profilePaths {
+		jsonBytes, err := ioutil.ReadFile(profilePath)
+		if err != nil {
+			return err
+		}
+
+		profile := &gasProfile{}
+		err = json.Unmarshal(jsonBytes, profile)
+		if err != nil {
+			return fmt.Errorf("invalid gas profile %s: %w", profilePath, err)
+		}
+
+		gasUsedByTx, found := linter.GasUsed[profile.Scenario]
+		if !found {
+			gasUsedByTx = make(map[string]uint64)
+			linter.GasUsed[profile.Scenario] = gasUsedByTx
+		}
+		for _, step := range profile.Steps {
+			if len(step.TxID) > 0 {
+				gasUsedByTx[step.TxID] = step.GasUsed
+			}
+		}
+	}
+
+	return nil
+}
+
+// gasUsedForScenario finds the gas used by the txs of a scenario file.
+// The profiles name the scenarios by their path relative to where the run started,
+// so the longest such path that the file path ends with wins.
+func (linter *Linter) gasUsedForScenario(absPath string) map[string]uint64 {
+	slashPath := filepath.ToSlash(absPath)
+	var gasUsedByTx map[string]uint64
+	matchedLength := 0
+	for scenarioPath, gasUsed := range linter.GasUsed {
+		if len(scenarioPath) <= matchedLength {
+			continue
+		}
+		if slashPath == scenarioPath || strings.HasSuffix(slashPath, "/"+scenarioPath) {
+			gasUsedByTx = gasUsed
+			matchedLength = len(scenarioPath)
+		}
+	}
+	return gasUsedByTx
+}
+
+// checkGasLimits reports the txs with a gas limit much larger than the gas they use.
+func (linter *Linter) checkGasLimits(absPath string, steps []*locatedStep) []*Finding {
+	var findings []*Finding
+	gasUsedByTx := linter.gasUsedForScenario(absPath)
+	for _, located := range steps {
+		txStep, isTx := located.step.(*mj.TxStep)
+		if !isTx || !isGasLimitChecked(txStep.Tx.Type) {
+			continue
+		}
+
+		gasLimit := txStep.Tx.GasLimit.Value
+		gasUsed, known := gasUsedByTx[txStep.TxIdent]
+		if !known || len(txStep.TxIdent) == 0 {
+			gasUsed, known = gasUsedFromExpectation(txStep)
+		}
+		if !known || gasUsed == 0 {
+			continue
+		}
+
+		if gasLimit/gasUsed >= linter.GasLimitFactor {
+			findings = append(findings, &Finding{
+				FilePath: located.filePath,
+				Check:    CheckExcessiveGasLimit,
+				Message: fmt.Sprintf("tx \"%s\" has gasLimit %d, but uses only %d gas",
+					txStep.TxIdent, gasLimit, gasUsed),
+			})
+		}
+	}
+
+	return findings
+}
+
+func isGasLimitChecked(txType mj.TransactionType) bool {
+	return txType == mj.ScDeploy || txType == mj.ScCall
+}
+
+// gasUsedFromExpectation deduces the gas used from an exact expectation of the remaining gas.
+func gasUsedFromExpectation(txStep *mj.TxStep) (uint64, bool) {
+	expected := txStep.ExpectedResult
+	if expected == nil || expected.Gas.IsUnspecified() || expected.Gas.IsStar {
+		return 0, false
+	}
+	if expected.Gas.Value > txStep.Tx.GasLimit.Value {
+		return 0, false
+	}
+	return txStep.Tx.GasLimit.Value - expected.Gas.Value, true
+}
@@ -0,0 +1,52 @@
+package scenlint
+
+import (
+	"os"
+
+	fr "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/fileresolver"
+)
+
+// missingFileRecorder resolves "file:" values like the default resolver, but instead of
+// failing on missing files, it records them and resolves them as empty,
+// so that the rest of the scenario can still be linted.
+type missingFileRecorder struct {
+	inner        *fr.DefaultFileResolver
+	missingFiles *[]string
+}
+
+func newMissingFileRecorder() *missingFileRecorder {
+	return &missingFileRecorder{
+		inner:        fr.NewDefaultFileResolver(),
+		missingFiles: new([]string),
+	}
+}
+
+// Clone creates a new resolver, that records to the same missing file list.
+func (recorder *missingFileRecorder) Clone() fr.FileResolver {
+	return &missingFileRecorder{
+		inner:        recorder.inner.Clone().(*fr.DefaultFileResolver),
+		missingFiles: recorder.missingFiles,
+	}
+}
+
+// SetContext sets directory where the test runs, to help resolve relative paths.
+func (recorder *missingFileRecorder) SetContext(contextPath string) {
+	recorder.inner.SetContext(contextPath)
+}
+
+// ResolveAbsolutePath yields absolute value based on context.
+func (recorder *missingFileRecorder) ResolveAbsolutePath(value string) string {
+	return recorder.inner.ResolveAbsolutePath(value)
+}
+
+// ResolveFileValue yields the file contents, or an empty value if the file is missing.
+func (recorder *missingFileRecorder) ResolveFileValue(value string) ([]byte, error) {
+	contents, err := recorder.inner.ResolveFileValue(value)
+	if os.IsNotExist(err) {
+		*recorder.missingFiles = append(*recorder.missingFiles, recorder.inner.ResolveAbsolutePath(value))
+		return []byte{}, nil
+	}
+	return contents, err
+}
+
+var _ fr.FileResolver = (*missingFileRecorder)(nil)
@@ -0,0 +1,27 @@
+package scenlint
+
+import "fmt"
+
+// Names of the checks performed by the linter, as they appear in the findings.
+const (
+	CheckParseError           = "parse-error"
+	CheckLegacyESDTSyntax     = "legacy-esdt-syntax"
+	CheckMissingFile          = "missing-file"
+	CheckMissingTxID          = "missing-tx-id"
+	CheckDuplicateStepID      = "duplicate-step-id"
+	CheckUnusedAccount        = "unused-account"
+	CheckImpossibleCheckState = "impossible-check-state"
+	CheckExcessiveGasLimit    = "excessive-gas-limit"
+)
+
+// Finding is a problem the linter found in a scenario file.
+type Finding struct {
+	FilePath string
+	Check    string
+	Message  string
+}
+
+// String yields the finding in the "path: [check] message" format.
+func (finding *Finding) String() string {
+	return fmt.Sprintf("%s: [%s] %s", finding.FilePath, finding.Check, finding.Message)
+}
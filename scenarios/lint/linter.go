@@ -0,0 +1,288 @@
+package scenlint
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	mc "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/controller"
+	mjparse "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/parse"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+)
+
+// DefaultGasLimitFactor is how many times the gas used the gas limit can be, before it gets reported.
+const DefaultGasLimitFactor = 10
+
+// ScenarioSuffixes are the suffixes of the files linted when linting a directory,
+// in any of the supported formats.
+var ScenarioSuffixes = []string{".scen.json", ".step.json", ".steps.json"}
+
+// Linter checks scenario files for common mistakes.
+type Linter struct {
+	// GasLimitFactor is how many times the gas used the gas limit can be, before it gets reported.
+	GasLimitFactor uint64
+
+	// GasUsed optionally holds the gas used by each tx, keyed by scenario file path and tx id,
+	// e.g. from gas profiles. Otherwise the gas used is deduced from exact "gas" expectations.
+	GasUsed map[string]map[string]uint64
+
+	parsedFiles       map[string]*parsedFile
+	accountUsages     map[string]*accountUsage
+	accountUsageOrder []string
+}
+
+// parsedFile is a scenario file parsed once, with the findings that only concern the file itself.
+// These findings are reported only once, even if the file is used as external steps by several scenarios.
+type parsedFile struct {
+	scenario *mj.Scenario
+	findings []*Finding
+	reported bool
+}
+
+func (parsed *parsedFile) takeFindings() []*Finding {
+	if parsed.reported {
+		return nil
+	}
+	parsed.reported = true
+	return parsed.findings
+}
+
+// locatedStep is a step of a scenario, after inlining the external steps, with the file it comes from.
+type locatedStep struct {
+	step     mj.Step
+	filePath string
+}
+
+// NewLinter creates a linter with the default settings.
+func NewLinter() *Linter {
+	return &Linter{
+		GasLimitFactor: DefaultGasLimitFactor,
+		GasUsed:        make(map[string]map[string]uint64),
+		parsedFiles:    make(map[string]*parsedFile),
+		accountUsages:  make(map[string]*accountUsage),
+	}
+}
+
+// LintPath lints a scenario file, or all scenario files in a directory.
+// Accounts set in shared external steps files are only reported as unused if no scenario in the directory uses them.
+func (linter *Linter) LintPath(path string) ([]*Finding, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return linter.LintFile(path)
+	}
+
+	var findings []*Finding
+	err = filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !hasScenarioSuffix(filePath) {
+			return nil
+		}
+		fileFindings, err := linter.lintFile(filePath)
+		if err != nil {
+			return err
+		}
+		findings = append(findings, fileFindings...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	findings = append(findings, linter.takeUnusedAccountFindings()...)
+	return findings, nil
+}
+
+func hasScenarioSuffix(path string) bool {
+	for _, suffix := range ScenarioSuffixes {
+		if mc.HasSuffixInAnyFormat(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// LintFile lints a single scenario file. For .scen.json files, the external steps are also
+// taken into account when checking the accounts, the checkState steps and the gas limits.
+func (linter *Linter) LintFile(filePath string) ([]*Finding, error) {
+	findings, err := linter.lintFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	findings = append(findings, linter.takeUnusedAccountFindings()...)
+	return findings, nil
+}
+
+func (linter *Linter) lintFile(filePath string) ([]*Finding, error) {
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := linter.parseFile(absPath)
+	findings := append([]*Finding{}, parsed.takeFindings()...)
+	if parsed.scenario == nil || !mc.HasSuffixInAnyFormat(absPath, ".scen.json") {
+		return findings, nil
+	}
+
+	var steps []*locatedStep
+	findings = append(findings, linter.inlineSteps(absPath, parsed.scenario, &steps, map[string]bool{absPath: true})...)
+
+	linter.recordAccountUsage(steps)
+	findings = append(findings, checkImpossibleCheckStates(steps)...)
+	findings = append(findings, linter.checkGasLimits(absPath, steps)...)
+
+	return findings, nil
+}
+
+// parseFile parses a file once, both strictly and leniently, and performs the checks that concern only that file.
+func (linter *Linter) parseFile(absPath string) *parsedFile {
+	parsed, alreadyParsed := linter.parsedFiles[absPath]
+	if alreadyParsed {
+		return parsed
+	}
+
+	parsed = &parsedFile{}
+	linter.parsedFiles[absPath] = parsed
+
+	fileResolver := newMissingFileRecorder()
+	parser := mjparse.NewParser(fileResolver)
+	scenario, err := mc.ParseScenariosScenario(parser, absPath)
+	if err != nil {
+		parsed.findings = append(parsed.findings, &Finding{
+			FilePath: absPath,
+			Check:    CheckParseError,
+			Message:  err.Error(),
+		})
+		return parsed
+	}
+	parsed.scenario = scenario
+
+	strictParser := mjparse.NewParser(newMissingFileRecorder())
+	strictParser.AllowEsdtTxLegacySyntax = false
+	strictParser.AllowEsdtLegacySetSyntax = false
+	strictParser.AllowEsdtLegacyCheckSyntax = false
+	_, err = mc.ParseScenariosScenario(strictParser, absPath)
+	if err != nil {
+		parsed.findings = append(parsed.findings, &Finding{
+			FilePath: absPath,
+			Check:    CheckLegacyESDTSyntax,
+			Message:  fmt.Sprintf("deprecated legacy ESDT syntax: %s", err.Error()),
+		})
+	}
+
+	for _, missingFile := range uniqueSorted(*fileResolver.missingFiles) {
+		parsed.findings = append(parsed.findings, &Finding{
+			FilePath: absPath,
+			Check:    CheckMissingFile,
+			Message:  fmt.Sprintf("referenced file not found: %s", missingFile),
+		})
+	}
+
+	parsed.findings = append(parsed.findings, checkStepIDs(absPath, scenario)...)
+
+	return parsed
+}
+
+// inlineSteps appends the steps of the scenario to the list, replacing external steps with their contents.
+func (linter *Linter) inlineSteps(
+	absPath string,
+	scenario *mj.Scenario,
+	steps *[]*locatedStep,
+	visiting map[string]bool,
+) []*Finding {
+	var findings []*Finding
+	for _, generalStep := range scenario.Steps {
+		externalStep, isExternal := generalStep.(*mj.ExternalStepsStep)
+		if !isExternal {
+			*steps = append(*steps, &locatedStep{step: generalStep, filePath: absPath})
+			continue
+		}
+
+		externalPath := filepath.Join(filepath.Dir(absPath), externalStep.Path)
+		_, err := os.Stat(externalPath)
+		if err != nil {
+			findings = append(findings, &Finding{
+				FilePath: absPath,
+				Check:    CheckMissingFile,
+				Message:  fmt.Sprintf("external steps file not found: %s", externalPath),
+			})
+			continue
+		}
+		if visiting[externalPath] {
+			continue
+		}
+
+		externalParsed := linter.parseFile(externalPath)
+		findings = append(findings, externalParsed.takeFindings()...)
+		if externalParsed.scenario == nil {
+			continue
+		}
+		visiting[externalPath] = true
+		findings = append(findings, linter.inlineSteps(externalPath, externalParsed.scenario, steps, visiting)...)
+		delete(visiting, externalPath)
+	}
+
+	return findings
+}
+
+// checkStepIDs reports tx steps without id and ids used by more than one step in the same file.
+func checkStepIDs(absPath string, scenario *mj.Scenario) []*Finding {
+	var findings []*Finding
+	seen := make(map[string]int)
+	for stepIndex, generalStep := range scenario.Steps {
+		stepID := ""
+		switch step := generalStep.(type) {
+		case *mj.TxStep:
+			stepID = step.TxIdent
+			if len(stepID) == 0 {
+				findings = append(findings, &Finding{
+					FilePath: absPath,
+					Check:    CheckMissingTxID,
+					Message:  fmt.Sprintf("step %d (%s) has no id", stepIndex, step.StepTypeName()),
+				})
+			}
+		case *mj.SetStateStep:
+			stepID = step.SetStateIdent
+		case *mj.CheckStateStep:
+			stepID = step.CheckStateIdent
+		case *mj.FuzzStep:
+			stepID = step.FuzzIdent
+		}
+
+		if len(stepID) == 0 {
+			continue
+		}
+		previousIndex, duplicate := seen[stepID]
+		if duplicate {
+			findings = append(findings, &Finding{
+				FilePath: absPath,
+				Check:    CheckDuplicateStepID,
+				Message:  fmt.Sprintf("step %d has the same id as step %d: %s", stepIndex, previousIndex, stepID),
+			})
+			continue
+		}
+		seen[stepID] = stepIndex
+	}
+
+	return findings
+}
+
+func uniqueSorted(values []string) []string {
+	set := make(map[string]struct{}, len(values))
+	var result []string
+	for _, value := range values {
+		if _, found := set[value]; !found {
+			set[value] = struct{}{}
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,268 @@
+package scenlint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeScenarioFile(t *testing.T, dir string, name string, contents string) string {
+	path := filepath.Join(dir, name)
+	err := os.WriteFile(path, []byte(contents), 0644)
+	require.Nil(t, err)
+	return path
+}
+
+func findingsWithCheck(findings []*Finding, check string) []*Finding {
+	var result []*Finding
+	for _, finding := range findings {
+		if finding.Check == check {
+			result = append(result, finding)
+		}
+	}
+	return result
+}
+
+func TestLintCleanScenario(t *testing.T) {
+	dir := t.TempDir()
+	path := writeScenarioFile(t, dir, "clean.scen.json", `{
+		"steps": [
+			{
+				"step": "setState",
+				"accounts": {
+					"address:owner": { "nonce": "1", "balance": "100" },
+					"address:receiver": { "nonce": "0", "balance": "0" }
+				}
+			},
+			{
+				"step": "transfer",
+				"txId": "1",
+				"tx": {
+					"from": "address:owner",
+					"to": "address:receiver",
+					"egldValue": "10"
+				}
+			},
+			{
+				"step": "checkState",
+				"accounts": {
+					"address:owner": { "nonce": "*", "balance": "90" },
+					"address:receiver": { "nonce": "0", "balance": "10" }
+				}
+			}
+		]
+	}`)
+
+	findings, err := NewLinter().LintFile(path)
+	require.Nil(t, err)
+	require.Empty(t, findings)
+}
+
+func TestLintParseError(t *testing.T) {
+	dir := t.TempDir()
+	path := writeScenarioFile(t, dir, "broken.scen.json", `{ "steps": [ { "step": "unknownStep" } ] }`)
+
+	findings, err := NewLinter().LintFile(path)
+	require.Nil(t, err)
+	require.Len(t, findings, 1)
+	require.Equal(t, CheckParseError, findings[0].Check)
+}
+
+func TestLintLegacyESDTSyntax(t *testing.T) {
+	dir := t.TempDir()
+	path := writeScenarioFile(t, dir, "legacy.scen.json", `{
+		"steps": [
+			{
+				"step": "setState",
+				"accounts": {
+					"address:owner": {
+						"esdt": {
+							"str:TOKEN-123456": {
+								"balance": "100",
+								"frozen": "false"
+							}
+						}
+					}
+				}
+			}
+		]
+	}`)
+
+	findings, err := NewLinter().LintFile(path)
+	require.Nil(t, err)
+	require.Len(t, findingsWithCheck(findings, CheckLegacyESDTSyntax), 1)
+}
+
+func TestLintMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := writeScenarioFile(t, dir, "missing.scen.json", `{
+		"steps": [
+			{
+				"step": "externalSteps",
+				"path": "missing.steps.json"
+			},
+			{
+				"step": "setState",
+				"accounts": {
+					"sc:contract": { "code": "file:missing.wasm" }
+				}
+			}
+		]
+	}`)
+
+	findings, err := NewLinter().LintFile(path)
+	require.Nil(t, err)
+	missing := findingsWithCheck(findings, CheckMissingFile)
+	require.Len(t, missing, 2)
+}
+
+func TestLintStepIDs(t *testing.T) {
+	dir := t.TempDir()
+	path := writeScenarioFile(t, dir, "ids.steps.json", `{
+		"steps": [
+			{
+				"step": "scQuery",
+				"tx": { "to": "sc:contract", "function": "getValue" }
+			},
+			{
+				"step": "scQuery",
+				"txId": "get",
+				"tx": { "to": "sc:contract", "function": "getValue" }
+			},
+			{
+				"step": "scQuery",
+				"txId": "get",
+				"tx": { "to": "sc:contract", "function": "getValue" }
+			}
+		]
+	}`)
+
+	findings, err := NewLinter().LintFile(path)
+	require.Nil(t, err)
+	require.Len(t, findingsWithCheck(findings, CheckMissingTxID), 1)
+	duplicates := findingsWithCheck(findings, CheckDuplicateStepID)
+	require.Len(t, duplicates, 1)
+	require.Equal(t, "step 2 has the same id as step 1: get", duplicates[0].Message)
+}
+
+func TestLintUnusedAccountInSharedStepsFile(t *testing.T) {
+	dir := t.TempDir()
+	writeScenarioFile(t, dir, "init.steps.json", `{
+		"steps": [
+			{
+				"step": "setState",
+				"accounts": {
+					"address:alice": { "nonce": "0", "balance": "100" },
+					"address:bob": { "nonce": "0", "balance": "100" },
+					"address:carol": { "nonce": "0", "balance": "100" }
+				}
+			}
+		]
+	}`)
+	writeScenarioFile(t, dir, "alice.scen.json", `{
+		"steps": [
+			{ "step": "externalSteps", "path": "init.steps.json" },
+			{
+				"step": "transfer",
+				"txId": "1",
+				"tx": { "from": "address:alice", "to": "address:bob", "egldValue": "1" }
+			}
+		]
+	}`)
+	writeScenarioFile(t, dir, "bob.scen.json", `{
+		"steps": [
+			{ "step": "externalSteps", "path": "init.steps.json" },
+			{
+				"step": "transfer",
+				"txId": "1",
+				"tx": { "from": "address:bob", "to": "address:alice", "egldValue": "1" }
+			}
+		]
+	}`)
+
+	findings, err := NewLinter().LintPath(dir)
+	require.Nil(t, err)
+	require.Len(t, findings, 1)
+	require.Equal(t, CheckUnusedAccount, findings[0].Check)
+	require.Equal(t, filepath.Join(dir, "init.steps.json"), findings[0].FilePath)
+	require.Equal(t, "account address:carol is set, but never used", findings[0].Message)
+}
+
+func TestLintImpossibleCheckStates(t *testing.T) {
+	dir := t.TempDir()
+	path := writeScenarioFile(t, dir, "impossible.scen.json", `{
+		"steps": [
+			{
+				"step": "setState",
+				"accounts": {
+					"address:owner": { "nonce": "5" },
+					"address:other": { "nonce": "0" }
+				}
+			},
+			{
+				"step": "checkState",
+				"id": "check",
+				"accounts": {
+					"address:owner": { "nonce": "3" },
+					"address:ghost": { "nonce": "0" }
+				}
+			}
+		]
+	}`)
+
+	findings, err := NewLinter().LintFile(path)
+	require.Nil(t, err)
+	impossible := findingsWithCheck(findings, CheckImpossibleCheckState)
+	require.Len(t, impossible, 3)
+	require.Equal(t, `checkState "check" expects nonce 3 for account address:owner, but it is already 5`, impossible[0].Message)
+	require.Equal(t, `checkState "check" expects account address:ghost, which is never created`, impossible[1].Message)
+	require.Equal(t, `checkState "check" does not list account address:other, which exists, and does not allow other accounts`, impossible[2].Message)
+}
+
+func TestLintExcessiveGasLimit(t *testing.T) {
+	dir := t.TempDir()
+	path := writeScenarioFile(t, dir, "gas.scen.json", `{
+		"steps": [
+			{
+				"step": "setState",
+				"accounts": {
+					"address:owner": { "nonce": "0", "balance": "1000000" },
+					"sc:contract": { "nonce": "0", "code": "0x0061736d" }
+				}
+			},
+			{
+				"step": "scCall",
+				"txId": "wasteful",
+				"tx": {
+					"from": "address:owner",
+					"to": "sc:contract",
+					"function": "doSomething",
+					"gasLimit": "1000000",
+					"gasPrice": "0"
+				},
+				"expect": { "gas": "990000" }
+			},
+			{
+				"step": "scCall",
+				"txId": "profiled",
+				"tx": {
+					"from": "address:owner",
+					"to": "sc:contract",
+					"function": "doSomething",
+					"gasLimit": "1000000",
+					"gasPrice": "0"
+				}
+			}
+		]
+	}`)
+
+	linter := NewLinter()
+	linter.GasUsed["gas.scen.json"] = map[string]uint64{"profiled": 500000}
+	findings, err := linter.LintFile(path)
+	require.Nil(t, err)
+	excessive := findingsWithCheck(findings, CheckExcessiveGasLimit)
+	require.Len(t, excessive, 1)
+	require.Contains(t, excessive[0].Message, "wasteful")
+}
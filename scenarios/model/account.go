@@ -39,6 +39,7 @@ type CheckAccount struct {
 	CheckStorage          []*CheckStorageKeyValuePair
 	Code                  JSONCheckBytes
 	Owner                 JSONCheckBytes
+	RootHash              JSONCheckBytes
 	AsyncCallData         JSONCheckBytes
 	CheckESDTData         []*CheckESDTData
 	IgnoreESDT            bool
@@ -1,5 +1,7 @@
 package scenjsonmodel
 
+import "fmt"
+
 // GasSchedule encodes the gas model to be used in scenario tests
 type GasSchedule int
 
@@ -17,3 +19,35 @@ const (
 	// GasScheduleV4 is currently used on mainnet.
 	GasScheduleV4
 )
+
+// String yields the name of the gas schedule, as it appears in scenario files.
+func (gs GasSchedule) String() string {
+	switch gs {
+	case GasScheduleDefault:
+		return "default"
+	case GasScheduleDummy:
+		return "dummy"
+	case GasScheduleV3:
+		return "v3"
+	case GasScheduleV4:
+		return "v4"
+	default:
+		return ""
+	}
+}
+
+// GasScheduleFromString parses the name of a gas schedule, as it appears in scenario files.
+func GasScheduleFromString(name string) (GasSchedule, error) {
+	switch name {
+	case "default":
+		return GasScheduleDefault, nil
+	case "dummy":
+		return GasScheduleDummy, nil
+	case "v3":
+		return GasScheduleV3, nil
+	case "v4":
+		return GasScheduleV4, nil
+	default:
+		return GasScheduleDummy, fmt.Errorf("invalid gasSchedule: %s", name)
+	}
+}
@@ -0,0 +1,84 @@
+package scenjsonmodel
+
+// Internal error categories, as they appear in scenario files.
+// They classify the internal VM error chain of a failed transaction, independently of the public return message.
+const (
+	// InternalErrorNone means that the VM reported no internal error.
+	InternalErrorNone = "none"
+
+	// InternalErrorTimeout means that the execution exceeded the configured SC execution timeout.
+	InternalErrorTimeout = "timeout"
+
+	// InternalErrorMaxInstances means that the maximum number of Wasmer instances was reached,
+	// typically through too deep recursion.
+	InternalErrorMaxInstances = "maxInstances"
+
+	// InternalErrorMemoryLimit means that the contract hit the memory limit breakpoint.
+	InternalErrorMemoryLimit = "memoryLimit"
+
+	// InternalErrorOutOfGas means that the execution ran out of gas.
+	InternalErrorOutOfGas = "outOfGas"
+
+	// InternalErrorPanic means that the VM execution panicked.
+	InternalErrorPanic = "panic"
+
+	// InternalErrorSignalError means that the contract signalled an error.
+	InternalErrorSignalError = "signalError"
+
+	// InternalErrorExecutionFailed covers all other internal errors.
+	InternalErrorExecutionFailed = "executionFailed"
+)
+
+// IsInternalErrorCategory yields true if the name is one of the known internal error categories.
+func IsInternalErrorCategory(name string) bool {
+	switch name {
+	case InternalErrorNone,
+		InternalErrorTimeout,
+		InternalErrorMaxInstances,
+		InternalErrorMemoryLimit,
+		InternalErrorOutOfGas,
+		InternalErrorPanic,
+		InternalErrorSignalError,
+		InternalErrorExecutionFailed:
+		return true
+	default:
+		return false
+	}
+}
+
+// JSONCheckInternalError holds an internal error category condition.
+// "*" allows all categories.
+type JSONCheckInternalError struct {
+	Category    string
+	IsStar      bool
+	Unspecified bool
+}
+
+// JSONCheckInternalErrorUnspecified yields a JSONCheckInternalError that accepts any category.
+func JSONCheckInternalErrorUnspecified() JSONCheckInternalError {
+	return JSONCheckInternalError{
+		Unspecified: true,
+	}
+}
+
+// IsUnspecified yields true if the field was originally unspecified.
+func (jcie JSONCheckInternalError) IsUnspecified() bool {
+	return jcie.Unspecified
+}
+
+// Check returns true if the condition holds for the given category.
+// An unspecified condition is not checked.
+func (jcie JSONCheckInternalError) Check(category string) bool {
+	if jcie.Unspecified || jcie.IsStar {
+		return true
+	}
+	return jcie.Category == category
+}
+
+// String yields the condition as it appears in scenario files.
+func (jcie JSONCheckInternalError) String() string {
+	if jcie.IsStar {
+		return "*"
+	}
+	return jcie.Category
+}
@@ -1,14 +1,37 @@
 package scenjsonmodel
 
 // Scenario is a json object representing a test scenario with steps.
+// If RandomSeedMaster is given, blocks without an explicit random seed
+// get one derived from it and from their block nonce.
+// If StateTrie is set, the state root hash is computed from a Merkle trie of the accounts after each commit.
+// If DeriveContractAddresses is set, deployed contracts get the addresses the protocol would give them,
+// unless the setState steps mock them.
 type Scenario struct {
-	Name        string
-	Comment     string
-	CheckGas    bool
-	TraceGas    bool
-	IsNewTest   bool
-	GasSchedule GasSchedule
-	Steps       []Step
+	Name                    string
+	Comment                 string
+	CheckGas                bool
+	TraceGas                bool
+	IsNewTest               bool
+	StateTrie               bool
+	DeriveContractAddresses bool
+	GasSchedule             GasSchedule
+	EnableEpochs            *EnableEpochs
+	RandomSeedMaster        JSONBytesFromString
+	Steps                   []Step
+}
+
+// EnableEpochs configures the epochs when the protocol flags activate,
+// either from a TOML file of flag = epoch lines, or flag by flag.
+// The flags not mentioned keep their default activation epochs.
+type EnableEpochs struct {
+	File  JSONBytesFromString
+	Flags []*EnableEpochsFlag
+}
+
+// EnableEpochsFlag is the activation epoch of a protocol flag, e.g. "FixOOGReturnCode".
+type EnableEpochsFlag struct {
+	Name  string
+	Epoch JSONUint64
 }
 
 // Step is the basic block of a scenario.
@@ -62,12 +85,29 @@ type SetStateStep struct {
 type CheckStateStep struct {
 	CheckStateIdent string
 	Comment         string
+	StateRootHash   JSONCheckBytes
 	CheckAccounts   *CheckAccounts
 }
 
 // DumpStateStep is a step that simply prints the entire state to console. Useful for debugging.
+// If a path is given, the state is instead saved there as a steps file with a setState step,
+// and optionally a checkState step, so it can be reused via externalSteps.
 type DumpStateStep struct {
-	Comment string
+	Comment    string
+	Path       string
+	CheckState bool
+}
+
+// SaveStateStep is a step that keeps a copy of the entire state under a name, to be restored later.
+type SaveStateStep struct {
+	StateName string
+	Comment   string
+}
+
+// RestoreStateStep is a step that replaces the entire state with one previously saved.
+type RestoreStateStep struct {
+	StateName string
+	Comment   string
 }
 
 // TxStep is a step where a transaction is executed.
@@ -79,10 +119,35 @@ type TxStep struct {
 	ExpectedResult *TransactionResult
 }
 
+// FuzzStep generates transactions randomly from weighted templates, runs them
+// and checks the invariants after each of them.
+type FuzzStep struct {
+	FuzzIdent  string
+	Comment    string
+	Seed       JSONUint64
+	Count      JSONUint64
+	Templates  []*FuzzTemplate
+	Invariants []*CheckStateStep
+}
+
+// FuzzTemplate is a transaction step that the fuzz step chooses with a probability proportional to its weight.
+// The transaction sender, value and arguments can be replaced by randomly chosen candidates.
+// An empty candidate list leaves the corresponding field of the template unchanged.
+type FuzzTemplate struct {
+	Weight              JSONUint64
+	Step                *TxStep
+	FromCandidates      []JSONBytesFromString
+	EGLDValueCandidates []JSONBigInt
+	ArgumentCandidates  [][]JSONBytesFromTree
+}
+
 var _ Step = (*ExternalStepsStep)(nil)
 var _ Step = (*SetStateStep)(nil)
 var _ Step = (*CheckStateStep)(nil)
 var _ Step = (*DumpStateStep)(nil)
+var _ Step = (*SaveStateStep)(nil)
+var _ Step = (*RestoreStateStep)(nil)
+var _ Step = (*FuzzStep)(nil)
 var _ Step = (*TxStep)(nil)
 
 // StepNameExternalSteps is a json step type name.
@@ -117,6 +182,30 @@ func (*DumpStateStep) StepTypeName() string {
 	return StepNameDumpState
 }
 
+// StepNameSaveState is a json step type name.
+const StepNameSaveState = "saveState"
+
+// StepTypeName type as string
+func (*SaveStateStep) StepTypeName() string {
+	return StepNameSaveState
+}
+
+// StepNameRestoreState is a json step type name.
+const StepNameRestoreState = "restoreState"
+
+// StepTypeName type as string
+func (*RestoreStateStep) StepTypeName() string {
+	return StepNameRestoreState
+}
+
+// StepNameFuzz is a json step type name.
+const StepNameFuzz = "fuzz"
+
+// StepTypeName type as string
+func (*FuzzStep) StepTypeName() string {
+	return StepNameFuzz
+}
+
 // StepNameScCall is a json step type name.
 const StepNameScCall = "scCall"
 
@@ -0,0 +1,83 @@
+package orderedjson
+
+import (
+	"bytes"
+	"errors"
+)
+
+// StripJSONComments converts JSON with comments to plain JSON.
+// It removes line (//) and block (/* */) comments outside strings, as well as trailing commas
+// before the end of a list or map.
+func StripJSONComments(input []byte) ([]byte, error) {
+	var output bytes.Buffer
+	inString := false
+	pendingComma := false
+
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+
+		if inString {
+			output.WriteByte(c)
+			if c == '\\' && i+1 < len(input) {
+				i++
+				output.WriteByte(input[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+
+		if c == '/' && i+1 < len(input) && input[i+1] == '/' {
+			for i < len(input) && input[i] != '\n' {
+				i++
+			}
+			if i < len(input) {
+				output.WriteByte('\n')
+			}
+			continue
+		}
+		if c == '/' && i+1 < len(input) && input[i+1] == '*' {
+			end := bytes.Index(input[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, errors.New("unterminated block comment")
+			}
+			i += 2 + end + 1
+			output.WriteByte(' ')
+			continue
+		}
+		if isWhitespace(c) {
+			output.WriteByte(c)
+			continue
+		}
+
+		if pendingComma && c != ']' && c != '}' {
+			output.WriteByte(',')
+		}
+		pendingComma = false
+
+		switch c {
+		case ',':
+			pendingComma = true
+		case '"':
+			inString = true
+			output.WriteByte(c)
+		default:
+			output.WriteByte(c)
+		}
+	}
+
+	if pendingComma {
+		output.WriteByte(',')
+	}
+
+	return output.Bytes(), nil
+}
+
+// ParseOrderedJSONWithComments parses JSON with comments and trailing commas, preserving order in maps.
+func ParseOrderedJSONWithComments(input []byte) (OJsonObject, error) {
+	plainJSON, err := StripJSONComments(input)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOrderedJSON(plainJSON)
+}
@@ -0,0 +1,73 @@
+package orderedjson
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Format is a file format that can be converted to and from an ordered JSON tree.
+type Format int
+
+const (
+	// FormatJSON is strict JSON.
+	FormatJSON Format = iota
+
+	// FormatJSONWithComments is JSON that can also contain comments and trailing commas.
+	FormatJSONWithComments
+
+	// FormatYAML is YAML.
+	FormatYAML
+)
+
+// FileExtensions are the file extensions of all supported formats.
+var FileExtensions = []string{".json", ".jsonc", ".yaml", ".yml"}
+
+// FormatFromPath deduces the format of a file from its extension. Unknown extensions are treated as JSON.
+func FormatFromPath(path string) Format {
+	switch filepath.Ext(path) {
+	case ".jsonc":
+		return FormatJSONWithComments
+	case ".yaml", ".yml":
+		return FormatYAML
+	default:
+		return FormatJSON
+	}
+}
+
+// FormatFromName parses the name of a format, e.g. from a command line argument.
+func FormatFromName(name string) (Format, error) {
+	switch name {
+	case "json":
+		return FormatJSON, nil
+	case "jsonc":
+		return FormatJSONWithComments, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	default:
+		return FormatJSON, fmt.Errorf("unknown format: %s", name)
+	}
+}
+
+// Extension yields the usual file extension of the format.
+func (format Format) Extension() string {
+	switch format {
+	case FormatJSONWithComments:
+		return ".jsonc"
+	case FormatYAML:
+		return ".yaml"
+	default:
+		return ".json"
+	}
+}
+
+// ParseOrdered parses any of the supported formats into an ordered JSON tree.
+func ParseOrdered(input []byte, format Format) (OJsonObject, error) {
+	switch format {
+	case FormatJSONWithComments:
+		return ParseOrderedJSONWithComments(input)
+	case FormatYAML:
+		return ParseOrderedYAML(input)
+	default:
+		return ParseOrderedJSON(input)
+	}
+}
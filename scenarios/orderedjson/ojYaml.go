@@ -0,0 +1,123 @@
+package orderedjson
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseOrderedYAML parses YAML into an ordered JSON tree, preserving order in maps.
+// All scalars become strings, except booleans; nulls become empty strings.
+func ParseOrderedYAML(input []byte) (OJsonObject, error) {
+	var document yaml.Node
+	err := yaml.Unmarshal(input, &document)
+	if err != nil {
+		return nil, err
+	}
+	if document.Kind == 0 {
+		return nil, errors.New("empty YAML document")
+	}
+
+	return yamlNodeToOJ(&document)
+}
+
+func yamlNodeToOJ(node *yaml.Node) (OJsonObject, error) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) != 1 {
+			return nil, errors.New("YAML document should contain exactly one value")
+		}
+		return yamlNodeToOJ(node.Content[0])
+	case yaml.MappingNode:
+		result := NewMap()
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode := node.Content[i]
+			if keyNode.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: map keys must be scalars", keyNode.Line)
+			}
+			value, err := yamlNodeToOJ(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			result.Put(keyNode.Value, value)
+		}
+		return result, nil
+	case yaml.SequenceNode:
+		list := make(OJsonList, 0, len(node.Content))
+		for _, itemNode := range node.Content {
+			item, err := yamlNodeToOJ(itemNode)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		return &list, nil
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!bool":
+			var value bool
+			err := node.Decode(&value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", node.Line, err)
+			}
+			result := OJsonBool(value)
+			return &result, nil
+		case "!!null":
+			return &OJsonString{Value: ""}, nil
+		default:
+			return &OJsonString{Value: node.Value}, nil
+		}
+	case yaml.AliasNode:
+		return yamlNodeToOJ(node.Alias)
+	default:
+		return nil, fmt.Errorf("line %d: unsupported YAML node", node.Line)
+	}
+}
+
+// YAMLString returns a YAML representation of an ordered JSON tree.
+func YAMLString(j OJsonObject) (string, error) {
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	err := encoder.Encode(ojToYAMLNode(j))
+	if err != nil {
+		return "", err
+	}
+	err = encoder.Close()
+	if err != nil {
+		return "", err
+	}
+	return buffer.String(), nil
+}
+
+func ojToYAMLNode(j OJsonObject) *yaml.Node {
+	switch value := j.(type) {
+	case *OJsonMap:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		if value.Size() == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		for _, kvp := range value.OrderedKV {
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: kvp.Key},
+				ojToYAMLNode(kvp.Value))
+		}
+		return node
+	case *OJsonList:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		if len(*value) == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		for _, item := range *value {
+			node.Content = append(node.Content, ojToYAMLNode(item))
+		}
+		return node
+	case *OJsonString:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value.Value}
+	case *OJsonBool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprintf("%t", bool(*value))}
+	default:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	}
+}
@@ -8,6 +8,7 @@ type TestAccount struct {
 	balance      *big.Int
 	storage      map[string][]byte
 	code         []byte
+	codeMetadata []byte
 	ownerAddress []byte
 }
 
@@ -51,6 +52,11 @@ func (tAcc *TestAccount) WithCode(code []byte) *TestAccount {
 	return tAcc
 }
 
+func (tAcc *TestAccount) WithCodeMetadata(codeMetadata []byte) *TestAccount {
+	tAcc.codeMetadata = codeMetadata
+	return tAcc
+}
+
 func (tAcc *TestAccount) WithOwner(owner []byte) *TestAccount {
 	tAcc.ownerAddress = append(tAcc.ownerAddress, owner...)
 	return tAcc
@@ -76,6 +82,10 @@ func (tAcc *TestAccount) GetCode() []byte {
 	return tAcc.code
 }
 
+func (tAcc *TestAccount) GetCodeMetadata() []byte {
+	return tAcc.codeMetadata
+}
+
 func (tAcc *TestAccount) GetOwner() []byte {
 	return tAcc.ownerAddress
 }
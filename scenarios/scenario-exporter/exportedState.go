@@ -0,0 +1,108 @@
+package scenario_exporter
+
+import (
+	"errors"
+	"math/big"
+
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+)
+
+var errUpdatedAccountNotFound = errors.New("account not found. could not update")
+
+// exportedState merges the accounts of all the setState steps of a scenario into a single state.
+// Accounts set again in later steps replace the previous ones, in place;
+// accounts updated in later steps only get the fields given in those steps changed,
+// the same way the scenario executor does.
+type exportedState struct {
+	accounts          []*TestAccount
+	accountsByAddress map[string]*TestAccount
+}
+
+func newExportedState() *exportedState {
+	return &exportedState{
+		accounts:          make([]*TestAccount, 0),
+		accountsByAddress: make(map[string]*TestAccount),
+	}
+}
+
+func (state *exportedState) applySetStateStep(setStateStep *mj.SetStateStep) error {
+	for _, scenAccount := range setStateStep.Accounts {
+		var err error
+		if scenAccount.Update {
+			err = state.updateAccount(scenAccount)
+		} else {
+			err = state.putAccount(scenAccount)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (state *exportedState) putAccount(scenAccount *mj.Account) error {
+	account, err := convertScenariosAccountToTestAccount(scenAccount)
+	if err != nil {
+		return err
+	}
+	if len(account.code) > 0 {
+		account.address = append(ScAddressPrefix, account.address[ScAddressPrefixLength:]...)
+	}
+
+	existingAccount, found := state.accountsByAddress[string(scenAccount.Address.Value)]
+	if found {
+		*existingAccount = *account
+		return nil
+	}
+
+	state.accountsByAddress[string(scenAccount.Address.Value)] = account
+	state.accounts = append(state.accounts, account)
+	return nil
+}
+
+func (state *exportedState) updateAccount(scenAccount *mj.Account) error {
+	existingAccount, found := state.accountsByAddress[string(scenAccount.Address.Value)]
+	if !found {
+		return errUpdatedAccountNotFound
+	}
+	update, err := convertScenariosAccountToTestAccount(scenAccount)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range update.storage {
+		existingAccount.storage[key] = value
+	}
+	if !scenAccount.Nonce.Unspecified {
+		existingAccount.nonce = update.nonce
+	}
+	if !scenAccount.Balance.Unspecified {
+		existingAccount.balance = big.NewInt(0).Set(update.balance)
+	}
+	if !scenAccount.Owner.Unspecified {
+		existingAccount.ownerAddress = update.ownerAddress
+	}
+	if !scenAccount.Code.Unspecified {
+		existingAccount.code = update.code
+		existingAccount.codeMetadata = update.codeMetadata
+		if len(existingAccount.code) > 0 {
+			existingAccount.address = append(ScAddressPrefix, scenAccount.Address.Value[ScAddressPrefixLength:]...)
+		}
+	}
+	return nil
+}
+
+// isSmartContract is true if the address belongs to an exported account with code.
+func (state *exportedState) isSmartContract(address []byte) bool {
+	account, found := state.accountsByAddress[string(address)]
+	return found && len(account.code) > 0
+}
+
+// receiverAddress yields the receiver of the tx, with the smart contract address prefix
+// if the receiver is a smart contract, consistent with the exported smart contract accounts.
+func (state *exportedState) receiverAddress(tx *mj.Transaction) []byte {
+	if tx.Type != mj.ScCall && !state.isSmartContract(tx.To.Value) {
+		return tx.To.Value
+	}
+	return append(ScAddressPrefix, tx.To.Value[ScAddressPrefixLength:]...)
+}
@@ -3,6 +3,7 @@ package scenario_exporter
 import (
 	"math/big"
 
+	"github.com/multiversx/mx-chain-core-go/core"
 	"github.com/multiversx/mx-chain-vm-common-go/txDataBuilder"
 	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
 	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
@@ -66,6 +67,54 @@ func (tx *Transaction) GetESDTTransfers() []*mj.ESDTTxData {
 	return tx.esdtValue
 }
 
+// GetTxData yields the data field of the tx, as sent to the protocol.
+// Txs with ESDT payments call the ESDTTransfer, ESDTNFTTransfer or MultiESDTNFTTransfer built-in function,
+// followed by the function to call and its arguments, if any.
+func (tx *Transaction) GetTxData() []byte {
+	tdb := txDataBuilder.NewBuilder()
+	switch {
+	case len(tx.esdtValue) == 0:
+		tdb.Func(tx.function)
+	case len(tx.esdtValue) == 1 && tx.esdtValue[0].Nonce.Value == 0:
+		tdb.Func(core.BuiltInFunctionESDTTransfer)
+		tdb.Bytes(tx.esdtValue[0].TokenIdentifier.Value)
+		tdb.BigInt(tx.esdtValue[0].Value.Value)
+	case len(tx.esdtValue) == 1:
+		tdb.Func(core.BuiltInFunctionESDTNFTTransfer)
+		tdb.Bytes(tx.esdtValue[0].TokenIdentifier.Value)
+		tdb.BigInt(big.NewInt(0).SetUint64(tx.esdtValue[0].Nonce.Value))
+		tdb.BigInt(tx.esdtValue[0].Value.Value)
+		tdb.Bytes(tx.rcvAddr)
+	default:
+		tdb.Func(core.BuiltInFunctionMultiESDTNFTTransfer)
+		tdb.Bytes(tx.rcvAddr)
+		tdb.Int(len(tx.esdtValue))
+		for _, esdtTransfer := range tx.esdtValue {
+			tdb.Bytes(esdtTransfer.TokenIdentifier.Value)
+			tdb.BigInt(big.NewInt(0).SetUint64(esdtTransfer.Nonce.Value))
+			tdb.BigInt(esdtTransfer.Value.Value)
+		}
+	}
+	if len(tx.esdtValue) > 0 && len(tx.function) > 0 {
+		tdb.Str(tx.function)
+	}
+	if len(tx.function) > 0 {
+		for _, arg := range tx.args {
+			tdb.Bytes(arg)
+		}
+	}
+	return tdb.ToBytes()
+}
+
+// GetTxReceiverAddress yields the receiver of the tx, as sent to the protocol.
+// NFT and multi-ESDT transfers are sent to the sender itself, the real receiver being an argument of the built-in function.
+func (tx *Transaction) GetTxReceiverAddress() []byte {
+	if len(tx.esdtValue) > 1 || (len(tx.esdtValue) == 1 && tx.esdtValue[0].Nonce.Value > 0) {
+		return tx.sndAddr
+	}
+	return tx.rcvAddr
+}
+
 func (tx *Transaction) WithCallFunction(functionName string) *Transaction {
 	tx.function = functionName
 	return tx
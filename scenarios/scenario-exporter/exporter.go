@@ -2,15 +2,17 @@ package scenario_exporter
 
 import (
 	"errors"
+	"fmt"
 	"math/big"
+	"path/filepath"
+	"strings"
 
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
 	mc "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/controller"
 	"github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/esdtconvert"
 	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
 )
 
-var errFirstStepMustSetState = errors.New("first step must be of type SetState")
-
 var errNoStepsProvided = errors.New("no steps were provided")
 
 var errScAccountMustHaveOwner = errors.New("scAccount must have owner")
@@ -23,16 +25,34 @@ var ScAddressPrefixLength = 10
 
 var benchmarkTxIdent = "benchmark"
 
+var benchmarkTxIdentPrefix = "benchmark-"
+
 var InvalidBenchmarkTxPos = -1
 
 var minimumAcceptedGasPrice = uint64(1)
 
+// scenarioCodeMetadata is the code metadata that contracts get from "setState" steps, which cannot specify it
+var scenarioCodeMetadata = (&vmcommon.CodeMetadata{
+	Payable:     true,
+	Upgradeable: true,
+	Readable:    true,
+}).ToBytes()
+
+// BenchmarkTx is a tx to benchmark, labeled with its txId, given by its position in Txs.
+type BenchmarkTx struct {
+	Label string
+	TxPos int
+}
+
 type ScenarioWithBenchmark struct {
-	Accs           []*TestAccount
-	DeployedAccs   []*TestAccount
-	Txs            []*Transaction
-	DeployTxs      []*Transaction
+	Accs         []*TestAccount
+	DeployedAccs []*TestAccount
+	Txs          []*Transaction
+	DeployTxs    []*Transaction
+	// BenchmarkTxPos is the position of the first benchmark tx.
 	BenchmarkTxPos int
+	// BenchmarkTxs are all the txs with the id "benchmark", or starting with "benchmark-", in order.
+	BenchmarkTxs []*BenchmarkTx
 }
 
 func getInvalidScenarioWithBenchmark() ScenarioWithBenchmark {
@@ -42,6 +62,7 @@ func getInvalidScenarioWithBenchmark() ScenarioWithBenchmark {
 		Txs:            nil,
 		DeployTxs:      nil,
 		BenchmarkTxPos: InvalidBenchmarkTxPos,
+		BenchmarkTxs:   nil,
 	}
 }
 
@@ -50,7 +71,14 @@ func GetAccountsAndTransactionsFromScenarios(testPath string) (stateAndBenchmark
 	if err != nil {
 		return getInvalidScenarioWithBenchmark(), err
 	}
-	steps := scenario.Steps
+	absPath, err := filepath.Abs(testPath)
+	if err != nil {
+		return getInvalidScenarioWithBenchmark(), err
+	}
+	steps, err := flattenExternalSteps(absPath, scenario.Steps, map[string]bool{absPath: true})
+	if err != nil {
+		return getInvalidScenarioWithBenchmark(), err
+	}
 	stateAndBenchmarkInfo, err = getAccountsAndTransactionsFromSteps(steps)
 	if err != nil {
 		return getInvalidScenarioWithBenchmark(), err
@@ -66,102 +94,118 @@ func getScenario(testPath string) (scenario *mj.Scenario, err error) {
 	return scenario, err
 }
 
-func getAccountsAndTransactionsFromSteps(steps []mj.Step) (stateAndBenchmarkInfo ScenarioWithBenchmark, err error) {
-	stateAndBenchmarkInfo.BenchmarkTxPos = InvalidBenchmarkTxPos
+// flattenExternalSteps replaces the externalSteps steps with the steps of the files they point to, recursively.
+// External steps paths are relative to the file that contains them.
+// The visiting set holds the files being flattened, so that a file that includes itself is reported.
+func flattenExternalSteps(testPath string, steps []mj.Step, visiting map[string]bool) ([]mj.Step, error) {
+	flatSteps := make([]mj.Step, 0, len(steps))
+	for _, step := range steps {
+		externalStepsStep, isExternal := step.(*mj.ExternalStepsStep)
+		if !isExternal {
+			flatSteps = append(flatSteps, step)
+			continue
+		}
 
+		externalPath := filepath.Join(filepath.Dir(testPath), externalStepsStep.Path)
+		if visiting[externalPath] {
+			return nil, fmt.Errorf("external steps cycle: %s includes itself", externalPath)
+		}
+		externalScenario, err := getScenario(externalPath)
+		if err != nil {
+			return nil, err
+		}
+		visiting[externalPath] = true
+		externalSteps, err := flattenExternalSteps(externalPath, externalScenario.Steps, visiting)
+		delete(visiting, externalPath)
+		if err != nil {
+			return nil, err
+		}
+		flatSteps = append(flatSteps, externalSteps...)
+	}
+	return flatSteps, nil
+}
+
+func getAccountsAndTransactionsFromSteps(steps []mj.Step) (stateAndBenchmarkInfo ScenarioWithBenchmark, err error) {
 	if len(steps) == 0 {
 		return getInvalidScenarioWithBenchmark(), errNoStepsProvided
 	}
-	if !stepIsSetState(steps[0]) && !stepIsExternalStep(steps[0]) {
-		return getInvalidScenarioWithBenchmark(), errFirstStepMustSetState
-	}
 
+	stateAndBenchmarkInfo.BenchmarkTxPos = InvalidBenchmarkTxPos
 	stateAndBenchmarkInfo.Txs = make([]*Transaction, 0)
 	stateAndBenchmarkInfo.DeployTxs = make([]*Transaction, 0)
-	stateAndBenchmarkInfo.Accs = make([]*TestAccount, 0)
 	stateAndBenchmarkInfo.DeployedAccs = make([]*TestAccount, 0)
+	stateAndBenchmarkInfo.BenchmarkTxs = make([]*BenchmarkTx, 0)
+	exportedState := newExportedState()
 
-	for i := 0; i < len(steps); i++ {
-		switch step := steps[i].(type) {
+	for _, generalStep := range steps {
+		switch step := generalStep.(type) {
 		case *mj.SetStateStep:
-			setStepAccounts, setStepDeployedAccounts, err := getAccountsFromSetStateStep(step)
+			err = exportedState.applySetStateStep(step)
 			if err != nil {
 				return getInvalidScenarioWithBenchmark(), err
 			}
-			stateAndBenchmarkInfo.Accs = append(stateAndBenchmarkInfo.Accs, setStepAccounts...)
-			stateAndBenchmarkInfo.DeployedAccs = append(stateAndBenchmarkInfo.DeployedAccs, setStepDeployedAccounts...)
-
+			stateAndBenchmarkInfo.DeployedAccs = append(stateAndBenchmarkInfo.DeployedAccs, getDeployedAccountsFromSetStateStep(step)...)
 		case *mj.TxStep:
-			if step.ExpectedResult.Status.Value.Cmp(okStatus) == 0 {
-
-				if step.Tx.GasPrice.Value == 0 {
-					step.Tx.GasPrice.Value = minimumAcceptedGasPrice
-				}
-				arguments := getArguments(step.Tx.Arguments)
-				switch step.StepTypeName() {
-				case "scCall":
-					if txIdRequiresBenchmark(step.TxIdent) && benchmarkTxPosIsNotSet(stateAndBenchmarkInfo.BenchmarkTxPos) {
-						stateAndBenchmarkInfo.BenchmarkTxPos = len(stateAndBenchmarkInfo.Txs)
-					}
-					tx := CreateTransaction(
-						step.Tx.Function,
-						arguments,
-						step.Tx.Nonce.Value,
-						step.Tx.EGLDValue.Value,
-						step.Tx.ESDTValue,
-						step.Tx.From.Value,
-						append(ScAddressPrefix, step.Tx.To.Value[ScAddressPrefixLength:]...),
-						step.Tx.GasLimit.Value,
-						step.Tx.GasPrice.Value,
-					)
-					stateAndBenchmarkInfo.Txs = append(stateAndBenchmarkInfo.Txs, tx)
-				case "scDeploy":
-					deployTx := CreateDeployTransaction(
-						arguments,
-						step.Tx.Code.Original,
-						step.Tx.From.Value,
-						step.Tx.GasLimit.Value,
-						step.Tx.GasPrice.Value,
-					)
-					stateAndBenchmarkInfo.DeployTxs = append(stateAndBenchmarkInfo.DeployTxs, deployTx)
-				default:
-					steps = append(steps[:i], steps[i+1:]...)
-					i--
-				}
+			if !txStepExpectsSuccess(step) {
+				continue
 			}
-		case *mj.ExternalStepsStep:
-			externalStateAndBenchmarkInfo, err := GetAccountsAndTransactionsFromScenarios(step.Path)
-			if err != nil {
-				return getInvalidScenarioWithBenchmark(), err
+			if step.Tx.GasPrice.Value == 0 {
+				step.Tx.GasPrice.Value = minimumAcceptedGasPrice
 			}
-			if benchmarkTxPosIsNotSet(stateAndBenchmarkInfo.BenchmarkTxPos) {
-				stateAndBenchmarkInfo.BenchmarkTxPos = externalStateAndBenchmarkInfo.BenchmarkTxPos
+			arguments := getArguments(step.Tx.Arguments)
+			switch step.Tx.Type {
+			case mj.ScCall, mj.Transfer:
+				if txIdRequiresBenchmark(step.TxIdent) {
+					addBenchmarkTx(&stateAndBenchmarkInfo, step.TxIdent, len(stateAndBenchmarkInfo.Txs))
+				}
+				tx := CreateTransaction(
+					step.Tx.Function,
+					arguments,
+					step.Tx.Nonce.Value,
+					step.Tx.EGLDValue.Value,
+					step.Tx.ESDTValue,
+					step.Tx.From.Value,
+					exportedState.receiverAddress(step.Tx),
+					step.Tx.GasLimit.Value,
+					step.Tx.GasPrice.Value,
+				)
+				stateAndBenchmarkInfo.Txs = append(stateAndBenchmarkInfo.Txs, tx)
+			case mj.ScDeploy:
+				deployTx := CreateDeployTransaction(
+					arguments,
+					step.Tx.Code.Original,
+					step.Tx.From.Value,
+					step.Tx.GasLimit.Value,
+					step.Tx.GasPrice.Value,
+				)
+				stateAndBenchmarkInfo.DeployTxs = append(stateAndBenchmarkInfo.DeployTxs, deployTx)
 			}
-			stateAndBenchmarkInfo.Accs = append(stateAndBenchmarkInfo.Accs, externalStateAndBenchmarkInfo.Accs...)
-			stateAndBenchmarkInfo.DeployedAccs = append(stateAndBenchmarkInfo.DeployedAccs, externalStateAndBenchmarkInfo.DeployedAccs...)
-			stateAndBenchmarkInfo.Txs = append(stateAndBenchmarkInfo.Txs, externalStateAndBenchmarkInfo.Txs...)
-			stateAndBenchmarkInfo.DeployTxs = append(stateAndBenchmarkInfo.DeployTxs, externalStateAndBenchmarkInfo.DeployTxs...)
-		default:
-			steps = append(steps[:i], steps[i+1:]...)
-			i--
 		}
 	}
+
+	stateAndBenchmarkInfo.Accs = exportedState.accounts
 	return stateAndBenchmarkInfo, nil
 }
 
-func getAccountsFromSetStateStep(setStateStep *mj.SetStateStep) (accounts []*TestAccount, deployedAccounts []*TestAccount, err error) {
-	accounts = make([]*TestAccount, 0)
-	deployedAccounts = make([]*TestAccount, 0)
-	for _, scenAccount := range setStateStep.Accounts {
-		account, err := convertScenariosAccountToTestAccount(scenAccount)
-		if err != nil {
-			return nil, nil, err
-		}
-		if len(account.code) > 0 {
-			account.address = append(ScAddressPrefix, account.address[ScAddressPrefixLength:]...)
-		}
-		accounts = append(accounts, account)
+func addBenchmarkTx(stateAndBenchmarkInfo *ScenarioWithBenchmark, label string, txPos int) {
+	if benchmarkTxPosIsNotSet(stateAndBenchmarkInfo.BenchmarkTxPos) {
+		stateAndBenchmarkInfo.BenchmarkTxPos = txPos
+	}
+	stateAndBenchmarkInfo.BenchmarkTxs = append(stateAndBenchmarkInfo.BenchmarkTxs, &BenchmarkTx{
+		Label: label,
+		TxPos: txPos,
+	})
+}
+
+func txStepExpectsSuccess(step *mj.TxStep) bool {
+	if step.ExpectedResult == nil {
+		return true
 	}
+	return step.ExpectedResult.Status.Value.Cmp(okStatus) == 0
+}
+
+func getDeployedAccountsFromSetStateStep(setStateStep *mj.SetStateStep) []*TestAccount {
+	deployedAccounts := make([]*TestAccount, 0)
 	for _, newScenariosAddressMock := range setStateStep.NewAddressMocks {
 		scAddress := append(ScAddressPrefix, newScenariosAddressMock.NewAddress.Value[ScAddressPrefixLength:]...)
 		ownerAddress := newScenariosAddressMock.CreatorAddress.Value
@@ -169,7 +213,7 @@ func getAccountsFromSetStateStep(setStateStep *mj.SetStateStep) (accounts []*Tes
 		deployedAccounts = append(deployedAccounts, account)
 	}
 
-	return accounts, deployedAccounts, nil
+	return deployedAccounts
 }
 
 func convertScenariosAccountToTestAccount(scenAcc *mj.Account) (*TestAccount, error) {
@@ -183,6 +227,9 @@ func convertScenariosAccountToTestAccount(scenAcc *mj.Account) (*TestAccount, er
 	}
 	_ = esdtconvert.WriteScenariosESDTToStorage(scenAcc.ESDTData, storage)
 	account := SetNewAccount(scenAcc.Nonce.Value, scenAcc.Address.Value, scenAcc.Balance.Value, storage, scenAcc.Code.Value, scenAcc.Owner.Value)
+	if len(account.code) > 0 {
+		account.WithCodeMetadata(scenarioCodeMetadata)
+	}
 
 	if len(account.code) != 0 && len(account.ownerAddress) == 0 {
 		return nil, errScAccountMustHaveOwner
@@ -199,18 +246,10 @@ func getArguments(args []mj.JSONBytesFromTree) [][]byte {
 	return arguments
 }
 
-func stepIsSetState(step mj.Step) bool {
-	return step.StepTypeName() == "setState"
-}
-
-func stepIsExternalStep(step mj.Step) bool {
-	return step.StepTypeName() == "externalSteps"
-}
-
 func benchmarkTxPosIsNotSet(benchmarkTxPos int) bool {
 	return benchmarkTxPos == InvalidBenchmarkTxPos
 }
 
 func txIdRequiresBenchmark(txIdent string) bool {
-	return txIdent == benchmarkTxIdent
+	return txIdent == benchmarkTxIdent || strings.HasPrefix(txIdent, benchmarkTxIdentPrefix)
 }
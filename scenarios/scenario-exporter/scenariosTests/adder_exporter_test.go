@@ -1,16 +1,23 @@
 package scenTests
 
 import (
+	"encoding/hex"
+	"io/ioutil"
 	"math/big"
+	"path/filepath"
 	"testing"
 
 	mge "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/scenario-exporter"
 
+	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
 	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
 	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
 	"github.com/stretchr/testify/require"
 )
 
+// the code metadata of contracts from "setState" steps
+var scenarioCodeMetadata = (&vmcommon.CodeMetadata{Payable: true, Upgradeable: true, Readable: true}).ToBytes()
+
 // address:owner
 var addressOwner = []byte("owner___________________________")
 
@@ -36,7 +43,7 @@ func TestGetAccountsAndTransactionsFrom_Adder(t *testing.T) {
 	expectedBenchmarkTxPos := 1
 
 	ownerAccount := mge.SetNewAccount(1, addressOwner, big.NewInt(48), make(map[string][]byte), make([]byte, 0), make([]byte, 0))
-	scAccount := mge.SetNewAccount(0, append(mge.ScAddressPrefix, addressAdder[mge.ScAddressPrefixLength:]...), big.NewInt(0), make(map[string][]byte), vmhost.GetSCCode("../../../test/adder/output/adder.wasm"), addressOwner)
+	scAccount := mge.SetNewAccount(0, append(mge.ScAddressPrefix, addressAdder[mge.ScAddressPrefixLength:]...), big.NewInt(0), make(map[string][]byte), vmhost.GetSCCode("../../../test/adder/output/adder.wasm"), addressOwner).WithCodeMetadata(scenarioCodeMetadata)
 	deployedScAccount := mge.SetNewAccount(0, append(mge.ScAddressPrefix, addressDeployedAdder[mge.ScAddressPrefixLength:]...), big.NewInt(0), make(map[string][]byte), make([]byte, 0), addressOwner)
 	expectedAccs = append(expectedAccs, ownerAccount, scAccount)
 	expectedDeployedAccs = append(expectedDeployedAccs, deployedScAccount)
@@ -46,6 +53,7 @@ func TestGetAccountsAndTransactionsFrom_Adder(t *testing.T) {
 
 	require.Nil(t, err)
 	require.Equal(t, expectedBenchmarkTxPos, sbi.BenchmarkTxPos)
+	require.Equal(t, []*mge.BenchmarkTx{{Label: "benchmark", TxPos: 1}}, sbi.BenchmarkTxs)
 	require.Equal(t, expectedAccs, sbi.Accs)
 	require.Equal(t, expectedDeployedAccs, sbi.DeployedAccs)
 	require.Equal(t, expectedDeployTxs, sbi.DeployTxs)
@@ -61,7 +69,7 @@ func TestGetAccountsAndTransactionsFrom_AdderWithExternalSteps(t *testing.T) {
 	expectedBenchmarkTxPos := 1
 
 	ownerAccount := mge.SetNewAccount(1, addressOwner, big.NewInt(48), make(map[string][]byte), make([]byte, 0), make([]byte, 0))
-	scAccount := mge.SetNewAccount(0, append(mge.ScAddressPrefix, addressAdder[mge.ScAddressPrefixLength:]...), big.NewInt(0), make(map[string][]byte), vmhost.GetSCCode("../../../test/adder/output/adder.wasm"), addressOwner)
+	scAccount := mge.SetNewAccount(0, append(mge.ScAddressPrefix, addressAdder[mge.ScAddressPrefixLength:]...), big.NewInt(0), make(map[string][]byte), vmhost.GetSCCode("../../../test/adder/output/adder.wasm"), addressOwner).WithCodeMetadata(scenarioCodeMetadata)
 	aliceAccount := mge.SetNewAccount(5, addressAlice, big.NewInt(284), make(map[string][]byte), make([]byte, 0), make([]byte, 0))
 	bobAccount := mge.SetNewAccount(3, addressBob, big.NewInt(11), make(map[string][]byte), make([]byte, 0), make([]byte, 0))
 	expectedAccs = append(expectedAccs, aliceAccount, scAccount, bobAccount, ownerAccount)
@@ -72,6 +80,56 @@ func TestGetAccountsAndTransactionsFrom_AdderWithExternalSteps(t *testing.T) {
 	transactionOwner := mge.CreateTransaction("add", [][]byte{{3}}, 0, big.NewInt(0), make([]*mj.ESDTTxData, 0), sbi.Accs[3].GetAddress(), sbi.Accs[1].GetAddress(), 5000000, 1)
 	expectedTxs = append(expectedTxs, transactionBob, transactionAlice, transactionOwner)
 	require.Equal(t, expectedBenchmarkTxPos, sbi.BenchmarkTxPos)
+	require.Equal(t, []*mge.BenchmarkTx{{Label: "benchmark", TxPos: 1}, {Label: "benchmark", TxPos: 2}}, sbi.BenchmarkTxs)
 	require.Equal(t, expectedTxs, sbi.Txs)
 	require.Equal(t, expectedDeployTxs, sbi.DeployTxs)
 }
+
+func TestGetAccountsAndTransactionsFrom_AdderWithESDTAndUpdates(t *testing.T) {
+	sbi, err := mge.GetAccountsAndTransactionsFromScenarios("adder_with_esdt_and_updates.scen.json")
+	require.Nil(t, err)
+
+	ownerAccount := mge.SetNewAccount(2, addressOwner, big.NewInt(1000), make(map[string][]byte), make([]byte, 0), make([]byte, 0))
+	scAccount := mge.SetNewAccount(0, append(mge.ScAddressPrefix, addressAdder[mge.ScAddressPrefixLength:]...), big.NewInt(0), map[string][]byte{"sum": {5}}, vmhost.GetSCCode("../../../test/adder/output/adder.wasm"), addressOwner).WithCodeMetadata(scenarioCodeMetadata)
+	require.Equal(t, []*mge.TestAccount{ownerAccount, scAccount}, sbi.Accs)
+
+	require.Equal(t, 0, sbi.BenchmarkTxPos)
+	require.Equal(t, []*mge.BenchmarkTx{{Label: "benchmark-esdt", TxPos: 0}, {Label: "benchmark-multi-esdt", TxPos: 1}}, sbi.BenchmarkTxs)
+	require.Len(t, sbi.Txs, 2)
+
+	esdtTx := sbi.Txs[0]
+	require.Len(t, esdtTx.GetESDTTransfers(), 1)
+	require.Equal(t, []byte("ADDER-123456"), esdtTx.GetESDTTransfers()[0].TokenIdentifier.Value)
+	require.Equal(t, big.NewInt(10), esdtTx.GetESDTTransfers()[0].Value.Value)
+	require.Equal(t, scAccount.GetAddress(), esdtTx.GetTxReceiverAddress())
+	require.Equal(t, "ESDTTransfer@41444445522d313233343536@0a@616464@03", string(esdtTx.GetTxData()))
+
+	multiESDTTx := sbi.Txs[1]
+	require.Len(t, multiESDTTx.GetESDTTransfers(), 2)
+	require.Equal(t, addressOwner, multiESDTTx.GetTxReceiverAddress())
+	require.Equal(t,
+		"MultiESDTNFTTransfer@"+hex.EncodeToString(scAccount.GetAddress())+"@02"+
+			"@41444445522d313233343536@@0a"+
+			"@41444445522d313233343536@01@01"+
+			"@616464@03",
+		string(multiESDTTx.GetTxData()))
+}
+
+func TestGetAccountsAndTransactionsFrom_ExternalStepsCycle(t *testing.T) {
+	writeSteps := func(path string, externalPath string) {
+		scenario := `{ "steps": [ { "step": "externalSteps", "path": "` + externalPath + `" } ] }`
+		require.Nil(t, ioutil.WriteFile(path, []byte(scenario), 0644))
+	}
+
+	dir := t.TempDir()
+	writeSteps(filepath.Join(dir, "self.scen.json"), "self.scen.json")
+	_, err := mge.GetAccountsAndTransactionsFromScenarios(filepath.Join(dir, "self.scen.json"))
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "external steps cycle")
+
+	writeSteps(filepath.Join(dir, "a.scen.json"), "b.steps.json")
+	writeSteps(filepath.Join(dir, "b.steps.json"), "a.scen.json")
+	_, err = mge.GetAccountsAndTransactionsFromScenarios(filepath.Join(dir, "a.scen.json"))
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "external steps cycle")
+}
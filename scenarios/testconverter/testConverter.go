@@ -0,0 +1,82 @@
+package scentestconverter
+
+import (
+	"fmt"
+
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+)
+
+// Conversion is the result of converting a legacy test to a scenario.
+type Conversion struct {
+	TestName string
+	Scenario *mj.Scenario
+
+	// Losses describe the information from the legacy test that the scenario cannot represent.
+	// None of it affects the execution, since the legacy test runner ignores it too.
+	Losses []string
+}
+
+// IsLossless indicates whether the scenario holds all the information of the legacy test.
+func (conversion *Conversion) IsLossless() bool {
+	return len(conversion.Losses) == 0
+}
+
+// ConvertTest transforms a legacy test into an equivalent scenario:
+// a setState step with the pre-state, a tx step with its expected result for each transaction in the blocks,
+// and a checkState step with the post-state.
+func ConvertTest(test *mj.Test) *Conversion {
+	conversion := &Conversion{
+		TestName: test.TestName,
+		Scenario: &mj.Scenario{
+			Name:        test.TestName,
+			Comment:     "converted from legacy test",
+			CheckGas:    test.CheckGas,
+			GasSchedule: mj.GasScheduleDefault,
+		},
+	}
+
+	if len(test.Network) > 0 {
+		conversion.addLoss("network %s", test.Network)
+	}
+
+	conversion.addStep(&mj.SetStateStep{
+		Accounts:    test.Pre,
+		BlockHashes: test.BlockHashes,
+	})
+
+	for blockIndex, block := range test.Blocks {
+		if block.BlockHeader != nil {
+			conversion.addLoss("header of block %d", blockIndex)
+		}
+
+		for txIndex, tx := range block.Transactions {
+			if tx.Nonce.Value != 0 {
+				conversion.addLoss("nonce %s of transaction %d in block %d", tx.Nonce.Original, txIndex, blockIndex)
+			}
+
+			conversion.addStep(&mj.TxStep{
+				// same as the legacy runner, since the tx hash derives from it
+				TxIdent:        fmt.Sprintf("%d", txIndex),
+				Tx:             tx,
+				ExpectedResult: block.Results[txIndex],
+			})
+		}
+	}
+
+	if test.PostState != nil {
+		conversion.addStep(&mj.CheckStateStep{
+			StateRootHash: mj.JSONCheckBytesUnspecified(),
+			CheckAccounts: test.PostState,
+		})
+	}
+
+	return conversion
+}
+
+func (conversion *Conversion) addStep(step mj.Step) {
+	conversion.Scenario.Steps = append(conversion.Scenario.Steps, step)
+}
+
+func (conversion *Conversion) addLoss(format string, args ...interface{}) {
+	conversion.Losses = append(conversion.Losses, fmt.Sprintf(format, args...))
+}
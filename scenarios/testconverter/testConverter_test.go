@@ -0,0 +1,90 @@
+package scentestconverter
+
+import (
+	"io/ioutil"
+	"testing"
+
+	fr "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/fileresolver"
+	mjparse "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/parse"
+	mjwrite "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/json/write"
+	mj "github.com/multiversx/mx-chain-vm-v1_4-go/scenarios/model"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestParser() *mjparse.Parser {
+	parser := mjparse.NewParser(
+		fr.NewDefaultFileResolver().ReplacePath(
+			"smart-contract.wasm",
+			"../json/integrationTests/exampleFile.txt"))
+	return &parser
+}
+
+func TestConvertTest(t *testing.T) {
+	contents, err := ioutil.ReadFile("../json/integrationTests/example.test.json")
+	require.Nil(t, err)
+	tests, err := newTestParser().ParseTestFile(contents)
+	require.Nil(t, err)
+	require.Len(t, tests, 1)
+
+	conversion := ConvertTest(tests[0])
+	require.False(t, conversion.IsLossless())
+	require.Equal(t, []string{"network VM", "header of block 0"}, conversion.Losses)
+
+	scenario := conversion.Scenario
+	require.Equal(t, "ERC20_IELE", scenario.Name)
+	require.True(t, scenario.CheckGas)
+	require.Len(t, scenario.Steps, 4)
+
+	setStateStep := scenario.Steps[0].(*mj.SetStateStep)
+	require.Len(t, setStateStep.Accounts, 2)
+	require.Len(t, setStateStep.BlockHashes.Values, 2)
+
+	callStep := scenario.Steps[1].(*mj.TxStep)
+	require.Equal(t, "0", callStep.TxIdent)
+	require.Equal(t, mj.StepNameScCall, callStep.StepTypeName())
+	require.Equal(t, "transferToken", callStep.Tx.Function)
+	require.Equal(t, "0x0f7b9d", callStep.ExpectedResult.Gas.Original)
+
+	deployStep := scenario.Steps[2].(*mj.TxStep)
+	require.Equal(t, "1", deployStep.TxIdent)
+	require.Equal(t, mj.StepNameScDeploy, deployStep.StepTypeName())
+
+	checkStateStep := scenario.Steps[3].(*mj.CheckStateStep)
+	require.Len(t, checkStateStep.CheckAccounts.Accounts, 2)
+}
+
+func TestConvertTest_WrittenScenarioParses(t *testing.T) {
+	contents, err := ioutil.ReadFile("../json/integrationTests/example.test.json")
+	require.Nil(t, err)
+	tests, err := newTestParser().ParseTestFile(contents)
+	require.Nil(t, err)
+
+	scenarioJSON := mjwrite.ScenarioToJSONString(ConvertTest(tests[0]).Scenario)
+	scenario, err := newTestParser().ParseScenarioFile([]byte(scenarioJSON))
+	require.Nil(t, err)
+	require.Len(t, scenario.Steps, 4)
+	require.Equal(t, scenarioJSON, mjwrite.ScenarioToJSONString(scenario))
+}
+
+func TestConvertTest_Lossless(t *testing.T) {
+	conversion := ConvertTest(&mj.Test{
+		TestName: "empty",
+		CheckGas: false,
+		Blocks: []*mj.Block{
+			{
+				Transactions: []*mj.Transaction{{Type: mj.ScCall}},
+				Results:      []*mj.TransactionResult{{}},
+			},
+			{
+				Transactions: []*mj.Transaction{{Type: mj.ScCall}},
+				Results:      []*mj.TransactionResult{{}},
+			},
+		},
+	})
+	require.True(t, conversion.IsLossless())
+	require.False(t, conversion.Scenario.CheckGas)
+	require.Len(t, conversion.Scenario.Steps, 3)
+	// the legacy runner numbers the txs of every block from 0
+	require.Equal(t, "0", conversion.Scenario.Steps[1].(*mj.TxStep).TxIdent)
+	require.Equal(t, "0", conversion.Scenario.Steps[2].(*mj.TxStep).TxIdent)
+}
@@ -52,6 +52,7 @@ type managedTypesContext struct {
 	managedTypesValues  managedTypesState
 	managedTypesStack   []managedTypesState
 	randomnessGenerator math.RandomnessGenerator
+	randomnessObserver  func(randomBytes []byte)
 }
 
 type managedTypesState struct {
@@ -96,9 +97,35 @@ func (context *managedTypesContext) GetRandReader() io.Reader {
 	if check.IfNil(context.randomnessGenerator) {
 		context.initRandomizer()
 	}
+	if context.randomnessObserver != nil {
+		return &observedRandReader{
+			reader:   context.randomnessGenerator,
+			observer: context.randomnessObserver,
+		}
+	}
 	return context.randomnessGenerator
 }
 
+// SetRandomnessObserver registers a function that receives the bytes of every read from the
+// pseudo-randomness generator, as drawn by the contracts. Passing nil removes the observer.
+func (context *managedTypesContext) SetRandomnessObserver(observer func(randomBytes []byte)) {
+	context.randomnessObserver = observer
+}
+
+type observedRandReader struct {
+	reader   io.Reader
+	observer func(randomBytes []byte)
+}
+
+// Read reads from the underlying generator and passes a copy of the bytes read to the observer.
+func (reader *observedRandReader) Read(p []byte) (int, error) {
+	n, err := reader.reader.Read(p)
+	randomBytes := make([]byte, n)
+	copy(randomBytes, p[:n])
+	reader.observer(randomBytes)
+	return n, err
+}
+
 // InitState initializes the underlying values map
 func (context *managedTypesContext) InitState() {
 	context.managedTypesValues = managedTypesState{
@@ -77,6 +77,45 @@ func TestManagedTypesContext_Randomness(t *testing.T) {
 	}
 }
 
+func TestManagedTypesContext_RandomnessObserver(t *testing.T) {
+	t.Parallel()
+
+	host := &contextmock.VMHostStub{
+		BlockchainCalled: func() vmhost.BlockchainContext {
+			return &mock.BlockchainContextMock{}
+		},
+		RuntimeCalled: func() vmhost.RuntimeContext {
+			return &contextmock.RuntimeContextMock{CurrentTxHash: bytes.Repeat([]byte{1}, 32)}
+		},
+	}
+	observedCtx, _ := NewManagedTypesContext(host)
+	unobservedCtx, _ := NewManagedTypesContext(host)
+
+	var observed [][]byte
+	observedCtx.SetRandomnessObserver(func(randomBytes []byte) {
+		observed = append(observed, randomBytes)
+	})
+
+	// the observer sees exactly what is drawn, without changing the sequence
+	first := make([]byte, 10)
+	_, _ = observedCtx.GetRandReader().Read(first)
+	second := make([]byte, 3)
+	_, _ = observedCtx.GetRandReader().Read(second)
+	require.Equal(t, [][]byte{first, second}, observed)
+
+	expected := make([]byte, 13)
+	_, _ = unobservedCtx.GetRandReader().Read(expected)
+	require.Equal(t, expected, append(first, second...))
+
+	// the observer keeps its own copy
+	first[0]++
+	require.NotEqual(t, first, observed[0])
+
+	observedCtx.SetRandomnessObserver(nil)
+	_, _ = observedCtx.GetRandReader().Read(make([]byte, 5))
+	require.Len(t, observed, 2)
+}
+
 func TestManagedTypesContext_ClearStateStack(t *testing.T) {
 	t.Parallel()
 	host := &contextmock.VMHostStub{
@@ -50,7 +50,8 @@ type vmHost struct {
 	builtInFuncContainer vmcommon.BuiltInFunctionContainer
 	esdtTransferParser   vmcommon.ESDTTransferParser
 	enableEpochsHandler  vmcommon.EnableEpochsHandler
-	activationEpochMap   map[uint32]struct{}
+
+	keepGasTracing bool
 }
 
 // NewVMHost creates a new VM vmHost
@@ -100,8 +101,6 @@ func NewVMHost(
 		enableEpochsHandler:  hostParameters.EnableEpochsHandler,
 	}
 
-	host.activationEpochMap = createActivationMap(hostParameters)
-
 	newExecutionTimeout := time.Duration(hostParameters.TimeOutForSCExecutionInMilliseconds) * time.Millisecond
 	if newExecutionTimeout > minExecutionTimeout {
 		host.executionTimeout = newExecutionTimeout
@@ -181,19 +180,19 @@ func NewVMHost(
 	return host, nil
 }
 
-func createActivationMap(hostParameters *vmhost.VMHostParameters) map[uint32]struct{} {
+func createActivationMap(enableEpochsHandler vmcommon.EnableEpochsHandler) map[uint32]struct{} {
 	activationMap := make(map[uint32]struct{})
 
-	activationMap[hostParameters.EnableEpochsHandler.CheckExecuteReadOnlyEnableEpoch()] = struct{}{}
-	activationMap[hostParameters.EnableEpochsHandler.DisableExecByCallerEnableEpoch()] = struct{}{}
-	activationMap[hostParameters.EnableEpochsHandler.RefactorContextEnableEpoch()] = struct{}{}
-	activationMap[hostParameters.EnableEpochsHandler.FixFailExecutionOnErrorEnableEpoch()] = struct{}{}
-	activationMap[hostParameters.EnableEpochsHandler.ManagedCryptoAPIEnableEpoch()] = struct{}{}
-	activationMap[hostParameters.EnableEpochsHandler.CreateNFTThroughExecByCallerEnableEpoch()] = struct{}{}
-	activationMap[hostParameters.EnableEpochsHandler.FixOOGReturnCodeEnableEpoch()] = struct{}{}
-	activationMap[hostParameters.EnableEpochsHandler.MultiESDTTransferAsyncCallBackEnableEpoch()] = struct{}{}
-	activationMap[hostParameters.EnableEpochsHandler.RemoveNonUpdatedStorageEnableEpoch()] = struct{}{}
-	activationMap[hostParameters.EnableEpochsHandler.StorageAPICostOptimizationEnableEpoch()] = struct{}{}
+	activationMap[enableEpochsHandler.CheckExecuteReadOnlyEnableEpoch()] = struct{}{}
+	activationMap[enableEpochsHandler.DisableExecByCallerEnableEpoch()] = struct{}{}
+	activationMap[enableEpochsHandler.RefactorContextEnableEpoch()] = struct{}{}
+	activationMap[enableEpochsHandler.FixFailExecutionOnErrorEnableEpoch()] = struct{}{}
+	activationMap[enableEpochsHandler.ManagedCryptoAPIEnableEpoch()] = struct{}{}
+	activationMap[enableEpochsHandler.CreateNFTThroughExecByCallerEnableEpoch()] = struct{}{}
+	activationMap[enableEpochsHandler.FixOOGReturnCodeEnableEpoch()] = struct{}{}
+	activationMap[enableEpochsHandler.MultiESDTTransferAsyncCallBackEnableEpoch()] = struct{}{}
+	activationMap[enableEpochsHandler.RemoveNonUpdatedStorageEnableEpoch()] = struct{}{}
+	activationMap[enableEpochsHandler.StorageAPICostOptimizationEnableEpoch()] = struct{}{}
 
 	return activationMap
 }
@@ -518,9 +517,10 @@ func (host *vmHost) SetBuiltInFunctionsContainer(builtInFuncs vmcommon.BuiltInFu
 	host.builtInFuncContainer = builtInFuncs
 }
 
-// EpochConfirmed is called whenever a new epoch is confirmed
+// EpochConfirmed is called whenever a new epoch is confirmed.
+// The activation epochs are read from the handler every time, since test tools can reconfigure them.
 func (host *vmHost) EpochConfirmed(epoch uint32, _ uint64) {
-	_, ok := host.activationEpochMap[epoch]
+	_, ok := createActivationMap(host.enableEpochsHandler)[epoch]
 	if ok {
 		host.Runtime().ClearWarmInstanceCache()
 		host.Blockchain().ClearCompiledCodes()
@@ -552,7 +552,16 @@ func (host *vmHost) CheckExecuteReadOnly() bool {
 	return host.enableEpochsHandler.IsCheckExecuteOnReadOnlyFlagEnabled()
 }
 
+// SetKeepGasTracing makes the host keep gas tracing as set on the metering context,
+// instead of enabling it only while the gasTrace logger is on trace level
+func (host *vmHost) SetKeepGasTracing(keepGasTracing bool) {
+	host.keepGasTracing = keepGasTracing
+}
+
 func (host *vmHost) setGasTracerEnabledIfLogIsTrace() {
+	if host.keepGasTracing {
+		return
+	}
 	host.Metering().SetGasTracing(false)
 	if logGasTrace.GetLevel() == logger.LogTrace {
 		host.Metering().SetGasTracing(true)
@@ -5,6 +5,7 @@ import (
 
 	"github.com/multiversx/mx-chain-vm-common-go/builtInFunctions"
 	"github.com/multiversx/mx-chain-vm-common-go/parsers"
+	contextmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/context"
 	worldmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/world"
 	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
 	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost/mock"
@@ -84,3 +85,41 @@ func TestNewVMHost(t *testing.T) {
 		require.ErrorIs(t, err, vmhost.ErrNilVMType)
 	})
 }
+
+// clearsCountingBlockchainContext counts how many times the compiled codes were cleared
+type clearsCountingBlockchainContext struct {
+	mock.BlockchainContextMock
+	nrClears int
+}
+
+func (b *clearsCountingBlockchainContext) ClearCompiledCodes() {
+	b.nrClears++
+}
+
+func TestVMHost_EpochConfirmed(t *testing.T) {
+	enableEpochsHandler, err := worldmock.NewEnableEpochsHandler(map[string]uint32{
+		worldmock.FixOOGReturnCodeFlag: 2,
+	})
+	require.Nil(t, err)
+	blockchainContext := &clearsCountingBlockchainContext{}
+	host := &vmHost{
+		blockchainContext:   blockchainContext,
+		runtimeContext:      &contextmock.RuntimeContextMock{},
+		enableEpochsHandler: enableEpochsHandler,
+	}
+
+	host.EpochConfirmed(1, 0)
+	require.Equal(t, 0, blockchainContext.nrClears)
+	host.EpochConfirmed(2, 0)
+	require.Equal(t, 1, blockchainContext.nrClears)
+
+	// the caches are cleared at the activation epochs configured later
+	err = enableEpochsHandler.SetActivationEpochs(map[string]uint32{
+		worldmock.FixOOGReturnCodeFlag: 4,
+	})
+	require.Nil(t, err)
+	host.EpochConfirmed(3, 0)
+	require.Equal(t, 1, blockchainContext.nrClears)
+	host.EpochConfirmed(4, 0)
+	require.Equal(t, 2, blockchainContext.nrClears)
+}
@@ -60,6 +60,7 @@ type VMHost interface {
 	CreateNFTOnExecByCallerEnabled() bool
 	DisableExecByCaller() bool
 	CheckExecuteReadOnly() bool
+	SetKeepGasTracing(keepGasTracing bool)
 	Reset()
 }
 
@@ -171,6 +172,7 @@ type ManagedTypesContext interface {
 	StateStack
 
 	GetRandReader() io.Reader
+	SetRandomnessObserver(observer func(randomBytes []byte))
 	ConsumeGasForThisBigIntNumberOfBytes(byteLen *big.Int)
 	ConsumeGasForThisIntNumberOfBytes(byteLen int)
 	ConsumeGasForBytes(bytes []byte)
@@ -6,10 +6,13 @@ import (
 	"io/ioutil"
 	"os"
 	"path"
+
+	worldmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/world"
 )
 
 type database struct {
-	rootPath string
+	rootPath         string
+	useAccountsStore bool
 }
 
 // newDatabase creates a new debugging database (basically, a folder with JSON files)
@@ -48,6 +51,17 @@ func (db *database) loadWorld(worldID string) (*world, error) {
 		return nil, err
 	}
 
+	accountsStorePath := db.getAccountsStoreFile(worldID)
+	if db.useAccountsStore || fileExists(accountsStorePath) {
+		// the accounts in the JSON file, if any, are moved to the store on the next storeWorld
+		accountsStore, err := worldmock.OpenAccountsStore(accountsStorePath)
+		if err != nil {
+			world.close()
+			return nil, err
+		}
+		world.blockchainHook.UseAccountsStore(accountsStore)
+	}
+
 	return world, nil
 }
 
@@ -55,6 +69,10 @@ func (db *database) getWorldFile(worldID string) string {
 	return path.Join(db.rootPath, "worlds", fmt.Sprintf("%s.json", worldID))
 }
 
+func (db *database) getAccountsStoreFile(worldID string) string {
+	return path.Join(db.rootPath, "worlds", fmt.Sprintf("%s.accounts", worldID))
+}
+
 func fileExists(filePath string) bool {
 	_, err := os.Stat(filePath)
 	return err == nil
@@ -74,6 +92,11 @@ func (db *database) storeWorld(world *world) error {
 	filePath := db.getWorldFile(world.id)
 	log.Trace("Database.storeWorld()", "file", filePath)
 
+	err := world.blockchainHook.CommitChanges()
+	if err != nil {
+		return err
+	}
+
 	dataModel := world.toDataModel()
 	return db.marshalDataModel(filePath, dataModel)
 }
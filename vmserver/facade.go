@@ -3,7 +3,6 @@ package vmserver
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 
 	logger "github.com/multiversx/mx-chain-logger-go"
 )
@@ -28,15 +27,12 @@ func (f *DebugFacade) DeploySmartContract(request DeployRequest) (*DeployRespons
 		return nil, err
 	}
 
-	database := f.loadDatabase(request.DatabasePath)
+	database := f.loadDatabase(request.RequestBase)
 	world, err := database.loadWorld(request.World)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		vmAsClose := world.vm.(io.Closer)
-		_ = vmAsClose.Close()
-	}()
+	defer world.close()
 
 	response := world.deploySmartContract(request)
 
@@ -54,8 +50,9 @@ func (f *DebugFacade) DeploySmartContract(request DeployRequest) (*DeployRespons
 	return response, err
 }
 
-func (f *DebugFacade) loadDatabase(rootPath string) *database {
-	database := newDatabase(rootPath)
+func (f *DebugFacade) loadDatabase(request RequestBase) *database {
+	database := newDatabase(request.DatabasePath)
+	database.useAccountsStore = request.AccountsStore
 	return database
 }
 
@@ -68,15 +65,12 @@ func (f *DebugFacade) UpgradeSmartContract(request UpgradeRequest) (*UpgradeResp
 		return nil, err
 	}
 
-	database := f.loadDatabase(request.DatabasePath)
+	database := f.loadDatabase(request.RequestBase)
 	world, err := database.loadWorld(request.World)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		vmAsClose := world.vm.(io.Closer)
-		_ = vmAsClose.Close()
-	}()
+	defer world.close()
 
 	response := world.upgradeSmartContract(request)
 
@@ -104,15 +98,12 @@ func (f *DebugFacade) RunSmartContract(request RunRequest) (*RunResponse, error)
 		return nil, err
 	}
 
-	database := f.loadDatabase(request.DatabasePath)
+	database := f.loadDatabase(request.RequestBase)
 	world, err := database.loadWorld(request.World)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		vmAsClose := world.vm.(io.Closer)
-		_ = vmAsClose.Close()
-	}()
+	defer world.close()
 
 	response := world.runSmartContract(request)
 
@@ -139,15 +130,12 @@ func (f *DebugFacade) QuerySmartContract(request QueryRequest) (*QueryResponse,
 		return nil, err
 	}
 
-	database := f.loadDatabase(request.DatabasePath)
+	database := f.loadDatabase(request.RequestBase)
 	world, err := database.loadWorld(request.World)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		vmAsClose := world.vm.(io.Closer)
-		_ = vmAsClose.Close()
-	}()
+	defer world.close()
 
 	response := world.querySmartContract(request)
 
@@ -169,15 +157,12 @@ func (f *DebugFacade) CreateAccount(request CreateAccountRequest) (*CreateAccoun
 		return nil, err
 	}
 
-	database := f.loadDatabase(request.DatabasePath)
+	database := f.loadDatabase(request.RequestBase)
 	world, err := database.loadWorld(request.World)
 	if err != nil {
 		return nil, err
 	}
-	defer func() {
-		vmAsClose := world.vm.(io.Closer)
-		_ = vmAsClose.Close()
-	}()
+	defer world.close()
 
 	response := world.createAccount(request)
 
@@ -10,7 +10,10 @@ import (
 type RequestBase struct {
 	DatabasePath string
 	World        string
-	Outcome      string
+	// AccountsStore keeps the accounts of the world in a key-value store, instead of the JSON file.
+	// Worlds that already have an accounts store keep using it regardless.
+	AccountsStore bool
+	Outcome       string
 }
 
 func (request *RequestBase) digest() error {
@@ -1,6 +1,7 @@
 package vmserver
 
 import (
+	"io"
 	"math/big"
 
 	vmcommon "github.com/multiversx/mx-chain-vm-common-go"
@@ -10,18 +11,22 @@ import (
 	worldmock "github.com/multiversx/mx-chain-vm-v1_4-go/mock/world"
 	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost"
 	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost/hostCore"
-	"github.com/multiversx/mx-chain-vm-v1_4-go/vmhost/mock"
 )
 
 type worldDataModel struct {
 	ID       string
 	Accounts worldmock.AccountMap
+	// EnableEpochs maps the protocol flags to their activation epochs; the flags missing never activate
+	EnableEpochs map[string]uint32
+	// BlockEpoch is the epoch of the current block, which decides the active protocol flags
+	BlockEpoch uint32
 }
 
 type world struct {
 	id             string
 	blockchainHook *worldmock.MockWorld
 	vm             vmcommon.VMExecutionHandler
+	enableEpochs   map[string]uint32
 }
 
 func newWorldDataModel(worldID string) *worldDataModel {
@@ -35,10 +40,16 @@ func newWorldDataModel(worldID string) *worldDataModel {
 func newWorld(dataModel *worldDataModel) (*world, error) {
 	blockchainHook := worldmock.NewMockWorld()
 	blockchainHook.AcctMap = dataModel.Accounts
+	blockchainHook.CurrentBlockInfo = &worldmock.BlockInfo{BlockEpoch: dataModel.BlockEpoch}
+	err := blockchainHook.EnableEpochsHandler.SetActivationEpochs(dataModel.EnableEpochs)
+	if err != nil {
+		return nil, err
+	}
+	blockchainHook.ConfirmCurrentEpoch()
 
 	vm, err := hostCore.NewVMHost(
 		blockchainHook,
-		getHostParameters(),
+		getHostParameters(blockchainHook.EnableEpochsHandler),
 	)
 	if err != nil {
 		return nil, err
@@ -48,10 +59,20 @@ func newWorld(dataModel *worldDataModel) (*world, error) {
 		id:             dataModel.ID,
 		blockchainHook: blockchainHook,
 		vm:             vm,
+		enableEpochs:   dataModel.EnableEpochs,
 	}, nil
 }
 
-func getHostParameters() *vmhost.VMHostParameters {
+// close releases the VM and the accounts store, if any.
+func (w *world) close() {
+	vmAsClose := w.vm.(io.Closer)
+	_ = vmAsClose.Close()
+	if w.blockchainHook.AccountsStore != nil {
+		_ = w.blockchainHook.AccountsStore.Close()
+	}
+}
+
+func getHostParameters(enableEpochsHandler *worldmock.EnableEpochsHandler) *vmhost.VMHostParameters {
 	esdtTransferParser, _ := parsers.NewESDTTransferParser(worldmock.WorldMarshalizer)
 	return &vmhost.VMHostParameters{
 		VMType:                   []byte{5, 0},
@@ -60,8 +81,8 @@ func getHostParameters() *vmhost.VMHostParameters {
 		ProtectedKeyPrefix:       []byte("E" + "L" + "R" + "O" + "N" + "D"),
 		BuiltInFuncContainer:     builtInFunctions.NewBuiltInFunctionContainer(),
 		ESDTTransferParser:       esdtTransferParser,
-		EpochNotifier:            &mock.EpochNotifierStub{},
-		EnableEpochsHandler:      &mock.EnableEpochsHandlerStub{},
+		EpochNotifier:            enableEpochsHandler,
+		EnableEpochsHandler:      enableEpochsHandler,
 		WasmerSIGSEGVPassthrough: false,
 		Hasher:                   worldmock.DefaultHasher,
 	}
@@ -140,17 +161,23 @@ func (w *world) createAccount(request CreateAccountRequest) *CreateAccountRespon
 		DeveloperReward: big.NewInt(0),
 	}
 	w.blockchainHook.AcctMap.PutAccount(&account)
+	w.blockchainHook.MarkAccountChanged(account.Address)
 	return &CreateAccountResponse{Account: &account}
 }
 
 func (w *world) toDataModel() *worldDataModel {
-	accounts := w.blockchainHook.AcctMap.Clone()
+	accounts := worldmock.NewAccountMap()
+	if w.blockchainHook.AccountsStore == nil {
+		accounts = w.blockchainHook.AcctMap.Clone()
+	}
 	for _, account := range accounts {
 		account.MockWorld = nil
 	}
 
 	return &worldDataModel{
-		ID:       w.id,
-		Accounts: accounts,
+		ID:           w.id,
+		Accounts:     accounts,
+		EnableEpochs: w.enableEpochs,
+		BlockEpoch:   w.blockchainHook.CurrentBlockInfo.BlockEpoch,
 	}
 }